@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/assets"
+)
+
+// assetsCommands lists the assets subcommands with their descriptions.
+var assetsCommands = []command{
+	{"list", "List the data files embedded in the binary"},
+	{"export", "Write the embedded files to a directory, the config directory by default"},
+}
+
+// assetsFlagSet returns the flags of the assets subcommand.
+func assetsFlagSet() (flags *flag.FlagSet, force *bool) {
+	flags = flag.NewFlagSet("assets", flag.ContinueOnError)
+	force = flags.Bool("force", false, "export: overwrite existing files")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: incipio assets <command> [flags] [dir]\n\nCommands:\n")
+		for _, c := range assetsCommands {
+			fmt.Fprintf(flags.Output(), "  %-7s %s\n", c.name, c.description)
+		}
+		fmt.Fprint(flags.Output(), "\nExported into the config directory, themes and units.yaml replace the\nembedded ones and can be edited.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	return flags, force
+}
+
+// runAssetsCommand handles "incipio assets ..." and returns the exit code.
+func runAssetsCommand(args []string) int {
+	flags, force := assetsFlagSet()
+
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+	command := args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	switch command {
+	case "list":
+		for _, g := range assets.Groups() {
+			files, _ := fs.Glob(g.Files, "*")
+			fmt.Printf("%-17s %-18s %s (%d)\n", g.Name, g.Dir+"/", g.Description, len(files))
+		}
+		return 0
+	case "export":
+		if flags.NArg() > 1 {
+			flags.Usage()
+			return 2
+		}
+		dir := filepath.Join(xdg.ConfigHome, "incipio")
+		if flags.NArg() == 1 {
+			dir = flags.Arg(0)
+		}
+		written, skipped, err := assets.Export(dir, assets.Groups(), *force)
+		for _, path := range written {
+			fmt.Println("Wrote", path)
+		}
+		if len(skipped) > 0 {
+			fmt.Printf("Kept %d existing files, use --force to overwrite them.\n", len(skipped))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown assets command %q.\n\n", command)
+		flags.Usage()
+		return 2
+	}
+}
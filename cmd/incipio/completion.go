@@ -0,0 +1,434 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/barab-i/incipio/internal/app"
+	"github.com/barab-i/incipio/internal/config"
+	"github.com/barab-i/incipio/pkgs/execute"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	"go.uber.org/zap"
+)
+
+const completionUsage = `Usage: incipio completion bash|zsh|fish
+
+Prints a shell completion script. For example:
+  incipio completion bash > ~/.local/share/bash-completion/completions/incipio
+  incipio completion zsh > "${fpath[1]}/_incipio"
+  incipio completion fish > ~/.config/fish/completions/incipio.fish
+
+Plugin flags and keywords are taken from the plugins installed when the
+script is generated; regenerate it after adding Yaegi plugins.
+`
+
+// command is a subcommand with its description.
+type command struct{ name, description string }
+
+// commandNames returns the names of the commands.
+func commandNames(commands []command) []string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	return names
+}
+
+// subcommands lists the subcommands dispatched before flag parsing.
+var subcommands = []command{
+	{"daemon", "Manage the background daemon"},
+	{"completion", "Print a shell completion script"},
+	{"assets", "List or export the data files embedded in the binary"},
+}
+
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionData is what the completion scripts offer.
+type completionData struct {
+	flags       []*flag.Flag      // Flags of the launcher itself.
+	daemonFlags []*flag.Flag      // Flags of "incipio daemon".
+	plugins     []plugin.Metadata // Every known plugin, sorted by keyword.
+	themes      []string          // Names of the available themes, for --theme.
+}
+
+// pluginFlags returns the flags of the optional plugins, for --plugins.
+func (d completionData) pluginFlags() []plugin.Metadata {
+	var optional []plugin.Metadata
+	for _, m := range d.plugins {
+		if m.Flag != "" {
+			optional = append(optional, m)
+		}
+	}
+	return optional
+}
+
+// defaultPluginValues returns the values accepted by --default-plugin: plugin keywords and flags.
+func (d completionData) defaultPluginValues() []string {
+	var values []string
+	for _, m := range d.plugins {
+		values = append(values, m.Keyword)
+		if m.Flag != "" {
+			values = append(values, m.Flag)
+		}
+	}
+	return values
+}
+
+// runCompletionCommand handles "incipio completion <shell>" and returns the exit code.
+func runCompletionCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, completionUsage)
+		return 2
+	}
+
+	var generate func(io.Writer, completionData)
+	switch args[0] {
+	case "bash":
+		generate = bashCompletion
+	case "zsh":
+		generate = zshCompletion
+	case "fish":
+		generate = fishCompletion
+	default:
+		fmt.Fprintf(os.Stderr, "Unsupported shell %q.\n\n%s", args[0], completionUsage)
+		return 2
+	}
+
+	logger := initializeLogger(false)
+	defer logger.Sync()
+
+	data := completionData{plugins: knownPlugins(logger), themes: theme.Names()}
+	// Only the launcher's own flags; dependencies may register more on flag.CommandLine.
+	for _, name := range []string{"plugins", "default-plugin", "debounce", "max-results", "debug", "record", "replay", "profile", "toggle", "theme", "layout", "width", "height", "anchor", "print", "print-format"} {
+		data.flags = append(data.flags, flag.Lookup(name))
+	}
+	daemonFlags, _, _ := daemonFlagSet()
+	daemonFlags.VisitAll(func(f *flag.Flag) { data.daemonFlags = append(data.daemonFlags, f) })
+
+	generate(os.Stdout, data)
+	return 0
+}
+
+// knownPlugins returns the metadata of all built-in and Yaegi plugins, enabled or not.
+func knownPlugins(logger *zap.Logger) []plugin.Metadata {
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Warn("Could not load config file, using defaults.", zap.Error(err))
+	}
+	pluginManager := app.NewPluginManager()
+	registerPlugins(pluginManager, cfg, logger)
+
+	var all []plugin.Metadata
+	for _, p := range pluginManager.GetAllPlugins() {
+		all = append(all, p.Metadata())
+	}
+	for _, m := range pluginManager.GetAllDisabledPluginsMetadatas() {
+		all = append(all, m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Keyword < all[j].Keyword })
+	return all
+}
+
+// quoteEach quotes each word for POSIX shells and fish.
+func quoteEach(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = execute.Quote(w)
+	}
+	return quoted
+}
+
+func flagNames(flags []*flag.Flag) []string {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = "--" + f.Name
+	}
+	return names
+}
+
+// takesValue reports whether the flag needs an argument, i.e. is not a boolean.
+func takesValue(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
+
+// takesPath reports whether the flag's argument is a file or directory path.
+func takesPath(f *flag.Flag) bool {
+	return f.Name == "record" || f.Name == "replay" || f.Name == "profile"
+}
+
+func bashCompletion(w io.Writer, d completionData) {
+	var optional []string
+	for _, m := range d.pluginFlags() {
+		optional = append(optional, m.Flag)
+	}
+	var valueFlags, pathFlags []string
+	for _, f := range d.flags {
+		switch {
+		case takesPath(f):
+			pathFlags = append(pathFlags, "--"+f.Name, "-"+f.Name)
+		case takesValue(f):
+			valueFlags = append(valueFlags, "--"+f.Name, "-"+f.Name)
+		}
+	}
+
+	fmt.Fprintf(w, `# bash completion for incipio, generated by "incipio completion bash".
+
+_incipio() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local plugin_flags=(%s)
+    local default_plugins=(%s)
+    local themes=(%s)
+
+    # "--flag=value" is split at "=" by COMP_WORDBREAKS.
+    if [[ $cur == "=" ]]; then
+        cur=""
+    elif [[ $prev == "=" ]]; then
+        prev="${COMP_WORDS[COMP_CWORD-2]}"
+    fi
+
+    case "${COMP_WORDS[1]}" in
+        daemon)
+            if [[ $COMP_CWORD -eq 2 ]]; then
+                COMPREPLY=($(compgen -W %s -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W %s -- "$cur"))
+            fi
+            return
+            ;;
+        completion)
+            [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W %s -- "$cur"))
+            return
+            ;;
+        assets)
+            if [[ $COMP_CWORD -eq 2 ]]; then
+                COMPREPLY=($(compgen -W %s -- "$cur"))
+            elif [[ $cur == -* ]]; then
+                COMPREPLY=($(compgen -W "--force" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -d -- "$cur"))
+            fi
+            return
+            ;;
+    esac
+
+    case "$prev" in
+        --plugins|-plugins)
+            # Complete the last entry of the comma-separated list.
+            local prefix=""
+            [[ $cur == *,* ]] && prefix="${cur%%,*},"
+            COMPREPLY=($(compgen -P "$prefix" -W "${plugin_flags[*]}" -- "${cur##*,}"))
+            compopt -o nospace 2>/dev/null
+            return
+            ;;
+        --default-plugin|-default-plugin)
+            COMPREPLY=($(compgen -W "${default_plugins[*]}" -- "$cur"))
+            return
+            ;;
+        --theme|-theme)
+            COMPREPLY=($(compgen -W "${themes[*]}" -- "$cur"))
+            return
+            ;;
+        %s)
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return
+            ;;
+        %s)
+            return
+            ;;
+    esac
+
+    if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+        COMPREPLY=($(compgen -W %s -- "$cur"))
+    else
+        COMPREPLY=($(compgen -W %s -- "$cur"))
+    fi
+}
+
+complete -F _incipio incipio
+`,
+		execute.Join(optional),
+		execute.Join(d.defaultPluginValues()),
+		execute.Join(d.themes),
+		execute.Quote(strings.Join(commandNames(daemonCommands), " ")),
+		execute.Quote(strings.Join(flagNames(d.daemonFlags), " ")),
+		execute.Quote(strings.Join(completionShells, " ")),
+		execute.Quote(strings.Join(commandNames(assetsCommands), " ")),
+		strings.Join(pathFlags, "|"),
+		strings.Join(valueFlags, "|"),
+		execute.Quote(strings.Join(commandNames(subcommands), " ")),
+		execute.Quote(strings.Join(flagNames(d.flags), " ")),
+	)
+}
+
+// zshDescription escapes text for use in an _arguments or _describe spec.
+func zshDescription(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+// zshSpecs returns _arguments specs for the flags. Values of --plugins,
+// --default-plugin and --theme complete from the arrays defined by zshCompletion.
+func zshSpecs(flags []*flag.Flag) []string {
+	specs := make([]string, 0, len(flags))
+	for _, f := range flags {
+		spec := fmt.Sprintf("--%s[%s]", f.Name, zshDescription(f.Usage))
+		if takesValue(f) {
+			spec = fmt.Sprintf("--%s=[%s]", f.Name, zshDescription(f.Usage))
+			switch f.Name {
+			case "plugins":
+				spec += ":plugins:_sequence compadd - ${plugin_flags%%:*}"
+			case "default-plugin":
+				spec += ":plugin:_describe plugin default_plugins"
+			case "theme":
+				spec += ":theme:compadd -a themes"
+			case "record", "replay":
+				spec += ":trace:_files"
+			case "profile":
+				spec += ":directory:_files -/"
+			default:
+				spec += ":" + f.Name + ": "
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func zshCompletion(w io.Writer, d completionData) {
+	var optional, defaults, commands, daemonCommandSpecs, assetsCommandSpecs []string
+	for _, m := range d.pluginFlags() {
+		optional = append(optional, zshDescription(m.Flag)+":"+m.Name)
+	}
+	for _, m := range d.plugins {
+		defaults = append(defaults, zshDescription(m.Keyword)+":"+m.Name)
+		if m.Flag != "" {
+			defaults = append(defaults, zshDescription(m.Flag)+":"+m.Name)
+		}
+	}
+	for _, c := range subcommands {
+		commands = append(commands, c.name+":"+c.description)
+	}
+	for _, c := range daemonCommands {
+		daemonCommandSpecs = append(daemonCommandSpecs, c.name+":"+zshDescription(c.description))
+	}
+	for _, c := range assetsCommands {
+		assetsCommandSpecs = append(assetsCommandSpecs, c.name+":"+zshDescription(c.description))
+	}
+
+	fmt.Fprintf(w, `#compdef incipio
+# zsh completion for incipio, generated by "incipio completion zsh".
+
+_incipio() {
+    local -a plugin_flags default_plugins themes commands daemon_commands assets_commands
+    plugin_flags=(%s)
+    default_plugins=(%s)
+    themes=(%s)
+    commands=(%s)
+    daemon_commands=(%s)
+    assets_commands=(%s)
+
+    case $words[2] in
+        daemon)
+            if (( CURRENT == 3 )); then
+                _describe command daemon_commands
+            else
+                _arguments %s
+            fi
+            return
+            ;;
+        completion)
+            (( CURRENT == 3 )) && compadd %s
+            return
+            ;;
+        assets)
+            if (( CURRENT == 3 )); then
+                _describe command assets_commands
+            else
+                _arguments '--force[export: overwrite existing files]' '*:directory:_files -/'
+            fi
+            return
+            ;;
+    esac
+
+    _arguments \
+        %s \
+        '1: :_describe command commands'
+}
+
+_incipio "$@"
+`,
+		execute.Join(optional),
+		execute.Join(defaults),
+		execute.Join(d.themes),
+		execute.Join(commands),
+		execute.Join(daemonCommandSpecs),
+		execute.Join(assetsCommandSpecs),
+		execute.Join(zshSpecs(d.daemonFlags)),
+		strings.Join(completionShells, " "),
+		strings.Join(quoteEach(zshSpecs(d.flags)), " \\\n        "),
+	)
+}
+
+func fishCompletion(w io.Writer, d completionData) {
+	fmt.Fprint(w, `# fish completion for incipio, generated by "incipio completion fish".
+
+complete -c incipio -f
+
+function __incipio_plugin_list
+    # Complete the last entry of the comma-separated --plugins list.
+    set -l prefix (commandline -ct | string replace -r '^--?plugins=' '' | string replace -r '[^,]*$' '')
+`)
+	for _, m := range d.pluginFlags() {
+		fmt.Fprintf(w, "    printf '%%s%%s\\t%%s\\n' $prefix %s %s\n", execute.Quote(m.Flag), execute.Quote(m.Name))
+	}
+	fmt.Fprint(w, "end\n\nfunction __incipio_default_plugins\n")
+	for _, m := range d.plugins {
+		fmt.Fprintf(w, "    printf '%%s\\t%%s\\n' %s %s\n", execute.Quote(m.Keyword), execute.Quote(m.Name))
+		if m.Flag != "" {
+			fmt.Fprintf(w, "    printf '%%s\\t%%s\\n' %s %s\n", execute.Quote(m.Flag), execute.Quote(m.Name))
+		}
+	}
+	fmt.Fprint(w, "end\n\n")
+
+	for _, c := range subcommands {
+		fmt.Fprintf(w, "complete -c incipio -n __fish_use_subcommand -a %s -d %s\n", c.name, execute.Quote(c.description))
+	}
+	for _, f := range d.flags {
+		line := fmt.Sprintf("complete -c incipio -n __fish_use_subcommand -l %s -d %s", f.Name, execute.Quote(f.Usage))
+		switch {
+		case f.Name == "plugins":
+			line += " -x -a '(__incipio_plugin_list)'"
+		case f.Name == "default-plugin":
+			line += " -x -a '(__incipio_default_plugins)'"
+		case f.Name == "theme":
+			line += " -x -a " + execute.Quote(strings.Join(d.themes, " "))
+		case takesPath(f):
+			line += " -r -F"
+		case takesValue(f):
+			line += " -x"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintln(w)
+	for _, c := range daemonCommands {
+		fmt.Fprintf(w, "complete -c incipio -n '__fish_seen_subcommand_from daemon; and not __fish_seen_subcommand_from %s' -a %s -d %s\n",
+			strings.Join(commandNames(daemonCommands), " "), c.name, execute.Quote(c.description))
+	}
+	for _, f := range d.daemonFlags {
+		fmt.Fprintf(w, "complete -c incipio -n '__fish_seen_subcommand_from daemon' -l %s -d %s\n", f.Name, execute.Quote(f.Usage))
+	}
+	fmt.Fprintf(w, "complete -c incipio -n '__fish_seen_subcommand_from completion' -a %s\n", execute.Quote(strings.Join(completionShells, " ")))
+
+	for _, c := range assetsCommands {
+		fmt.Fprintf(w, "complete -c incipio -n '__fish_seen_subcommand_from assets; and not __fish_seen_subcommand_from %s' -a %s -d %s\n",
+			strings.Join(commandNames(assetsCommands), " "), c.name, execute.Quote(c.description))
+	}
+	fmt.Fprintln(w, "complete -c incipio -n '__fish_seen_subcommand_from export' -l force -d 'export: overwrite existing files'")
+	fmt.Fprintln(w, "complete -c incipio -n '__fish_seen_subcommand_from export' -x -a '(__fish_complete_directories)'")
+}
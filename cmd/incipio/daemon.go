@@ -0,0 +1,391 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/barab-i/incipio/internal/app"
+	"github.com/barab-i/incipio/internal/config"
+	"github.com/barab-i/incipio/internal/daemon"
+	"github.com/barab-i/incipio/internal/index"
+	"github.com/barab-i/incipio/internal/ipc"
+	"github.com/barab-i/incipio/internal/logbuffer"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"go.uber.org/zap"
+)
+
+// daemonCommands lists the daemon subcommands with their descriptions.
+var daemonCommands = []command{
+	{"install", "Start the daemon at login (systemd user service, or XDG autostart)"},
+	{"uninstall", "Stop the daemon and remove the service and autostart entry"},
+	{"status", "Show what is installed and whether the service is running"},
+	{"run", "Run the daemon in the foreground (used by the service)"},
+}
+
+// daemonFlagSet returns the flags of the daemon subcommand.
+func daemonFlagSet() (fs *flag.FlagSet, autostart, debug *bool) {
+	fs = flag.NewFlagSet("daemon", flag.ContinueOnError)
+	autostart = fs.Bool("autostart", false, "install: use an XDG autostart entry even if systemd is available")
+	debug = fs.Bool("debug", false, "run: enable debug logging")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "Usage: incipio daemon <command> [flags]\n\nCommands:\n")
+		for _, c := range daemonCommands {
+			fmt.Fprintf(fs.Output(), "  %-11s %s\n", c.name, c.description)
+		}
+		fmt.Fprint(fs.Output(), "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	return fs, autostart, debug
+}
+
+// runDaemonCommand handles "incipio daemon ..." and returns the exit code.
+func runDaemonCommand(args []string) int {
+	fs, autostart, debug := daemonFlagSet()
+
+	if len(args) == 0 {
+		fs.Usage()
+		return 2
+	}
+	command := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	switch command {
+	case "install":
+		return daemonInstall(*autostart)
+	case "uninstall":
+		if err := daemon.Uninstall(); err != nil {
+			fmt.Fprintf(os.Stderr, "Uninstall failed: %v\n", err)
+			return 1
+		}
+		fmt.Println("Removed the incipio daemon service and autostart entry.")
+		return 0
+	case "status":
+		printDaemonStatus(daemon.GetStatus())
+		return 0
+	case "run":
+		return daemonRun(*debug)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown daemon command %q.\n\n", command)
+		fs.Usage()
+		return 2
+	}
+}
+
+func daemonInstall(forceAutostart bool) int {
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not determine the incipio executable: %v\n", err)
+		return 1
+	}
+
+	method := daemon.MethodSystemd
+	if forceAutostart || !daemon.SystemdAvailable() {
+		method = daemon.MethodAutostart
+	}
+	if err := daemon.Install(executable, method); err != nil {
+		fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
+		return 1
+	}
+
+	status := daemon.GetStatus()
+	if method == daemon.MethodSystemd {
+		fmt.Printf("Installed and started %s (%s).\n", daemon.ServiceName, status.ServicePath)
+	} else {
+		fmt.Printf("Installed XDG autostart entry %s; the daemon starts at your next login.\n", status.AutostartPath)
+	}
+	return 0
+}
+
+func printDaemonStatus(s daemon.Status) {
+	switch {
+	case s.ServiceInstalled:
+		fmt.Printf("systemd service: %s\n", s.ServicePath)
+		if s.ServiceEnabled != "" {
+			fmt.Printf("  enabled: %s\n  active:  %s\n", s.ServiceEnabled, s.ServiceActive)
+		} else {
+			fmt.Println("  state unknown: no systemd user instance")
+		}
+	default:
+		fmt.Println("systemd service: not installed")
+	}
+	if s.AutostartInstalled {
+		fmt.Printf("autostart entry: %s\n", s.AutostartPath)
+	} else {
+		fmt.Println("autostart entry: not installed")
+	}
+	if s.Executable != "" {
+		fmt.Printf("command: %s\n", s.Executable)
+	}
+}
+
+// daemonRun keeps incipio resident with its plugins loaded until it receives
+// SIGINT or SIGTERM, showing the launcher on the terminal of each
+// "incipio --toggle" invocation.
+func daemonRun(debug bool) int {
+	logger := initializeLogger(debug)
+	defer logger.Sync()
+	defer index.CloseAll()
+
+	var logs *logbuffer.Buffer
+	if debug {
+		logger, logs = captureLogs(logger)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Warn("Could not load config file, using defaults.", zap.Error(err))
+	}
+
+	// The daemon has no terminal; sessions switch to the mode of theirs.
+	mode := themeMode(cfg.Appearance, func() theme.Mode { return theme.Dark })
+	pluginManager := newPluginManager(cfg, mode, logs, logger)
+	pluginManager.InitPlugins()
+
+	path, err := ipc.SocketPath()
+	if err != nil {
+		logger.Error("Could not create the daemon socket.", zap.Error(err))
+		return 1
+	}
+	listener, err := ipc.Listen(path)
+	if err != nil {
+		logger.Error("Could not listen on the daemon socket.", zap.String("path", path), zap.Error(err))
+		return 1
+	}
+	defer listener.Close()
+
+	launcher := &residentLauncher{pluginManager: pluginManager, cfg: cfg, mode: mode, logger: logger}
+	done := make(chan struct{})
+	defer close(done)
+	watchYaegiPlugins(cfg, done, launcher.reload, logger)
+	watchTheme(done, launcher.themeFilesChanged, logger)
+	go launcher.serve(listener)
+	logger.Info("Daemon started.", zap.Int("pid", os.Getpid()), zap.String("socket", path))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+	logger.Info("Daemon stopping.", zap.String("signal", sig.String()))
+	launcher.stop()
+	pluginManager.Shutdown()
+	saveStats(pluginManager, logger)
+	return 0
+}
+
+// residentLauncher shows the launcher of the daemon on the terminals passed by
+// clients, one at a time, sharing the plugin manager between the sessions.
+type residentLauncher struct {
+	pluginManager *app.PluginManager
+	cfg           config.Config
+	logger        *zap.Logger
+
+	mu         sync.Mutex
+	program    *tea.Program  // Nil while hidden.
+	ended      chan struct{} // Closed when program exits.
+	mode       theme.Mode    // Mode of the loaded theme.
+	themeStale bool          // The theme files changed while hidden.
+}
+
+// serve handles clients until the listener is closed.
+func (l *residentLauncher) serve(listener *ipc.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				l.logger.Error("Could not accept a client.", zap.Error(err))
+			}
+			return
+		}
+		go l.handle(conn)
+	}
+}
+
+// handle toggles the launcher for a client. When the launcher is shown on the
+// client's terminal, it forwards resizes until the launcher exits.
+func (l *residentLauncher) handle(conn *ipc.Conn) {
+	defer conn.Close()
+
+	req, files, err := conn.Receive()
+	if err != nil {
+		l.logger.Debug("Could not read the request of a client.", zap.Error(err))
+		return
+	}
+	defer ipc.CloseFiles(files)
+	if req.Command != ipc.CommandToggle || len(files) != 2 {
+		conn.Reply(ipc.StatusFailed, fmt.Errorf("expected a toggle request with the input and output terminals, got %q with %d files", req.Command, len(files)))
+		return
+	}
+
+	l.mu.Lock()
+	if l.program != nil {
+		l.program.Quit()
+		l.mu.Unlock()
+		conn.Reply(ipc.StatusHidden, nil)
+		return
+	}
+	program := l.newProgram(req, files[0], files[1])
+	ended := make(chan struct{})
+	l.program, l.ended = program, ended
+	l.mu.Unlock()
+
+	go func() {
+		for {
+			req, files, err := conn.Receive()
+			ipc.CloseFiles(files)
+			if err != nil {
+				// The client is gone, and probably its terminal too.
+				program.Kill()
+				return
+			}
+			if req.Command == ipc.CommandResize {
+				program.Send(tea.WindowSize()())
+			}
+		}
+	}()
+
+	conn.Reply(ipc.StatusShown, nil)
+	l.pluginManager.Focus()
+	_, err = program.Run()
+	l.pluginManager.Blur()
+
+	l.mu.Lock()
+	l.program, l.ended = nil, nil
+	close(ended)
+	l.mu.Unlock()
+
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		l.logger.Error("Error running program", zap.Error(err))
+		conn.Reply(ipc.StatusFailed, err)
+		return
+	}
+	conn.Reply(ipc.StatusClosed, nil)
+}
+
+// newProgram creates a launcher for the terminal in and out, described by the
+// client's request. The query starts empty, as in a new process, and the theme
+// follows the client's background. l.mu must be held.
+func (l *residentLauncher) newProgram(req ipc.Request, in, out *os.File) *tea.Program {
+	output := termenv.NewOutput(out, termenv.WithEnvironment(clientEnviron(req.Env)))
+	lipgloss.SetColorProfile(output.EnvColorProfile())
+
+	l.pluginManager.DetermineActivePlugin("")
+	var model tea.Model = app.InitialModel(l.pluginManager, modelOptions(l.cfg, l.logger))
+	mode := themeMode(l.cfg.Appearance, func() theme.Mode {
+		if req.Background == string(theme.Light) {
+			return theme.Light
+		}
+		return theme.Dark
+	})
+	if mode != l.mode || l.themeStale {
+		l.mode, l.themeStale = mode, false
+		model, _ = model.Update(plugin.ThemeChangedMsg{Theme: theme.LoadNamed(l.cfg.Theme, mode)})
+	}
+	return tea.NewProgram(model, programOptions(l.cfg,
+		tea.WithAltScreen(),
+		tea.WithInput(in),
+		tea.WithOutput(out),
+		tea.WithEnvironment(req.Env),
+		tea.WithoutSignalHandler())...)
+}
+
+// reload passes a plugin reload to the shown launcher, or applies it to the
+// plugin manager while hidden.
+func (l *residentLauncher) reload(msg app.PluginReloadMsg) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.program != nil {
+		l.program.Send(msg)
+		return
+	}
+
+	if msg.Err != nil {
+		l.logger.Warn("Could not reload plugin, keeping the previous version.", zap.String("source", msg.Source), zap.Error(msg.Err))
+		return
+	}
+	if err := l.pluginManager.ReloadPlugin(msg.Source, msg.Plugin, msg.Enabled); err != nil {
+		l.logger.Warn("Could not register reloaded plugin.", zap.String("source", msg.Source), zap.Error(err))
+		return
+	}
+	if msg.Plugin != nil && l.pluginManager.IsEnabled(msg.Plugin.Keyword()) {
+		msg.Plugin.Init()
+	}
+}
+
+// themeFilesChanged reloads the theme of the shown launcher, or marks it for
+// reloading by the next one while hidden.
+func (l *residentLauncher) themeFilesChanged() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.program != nil {
+		l.program.Send(plugin.ThemeChangedMsg{Theme: theme.LoadNamed(l.cfg.Theme, l.mode)})
+		return
+	}
+	l.themeStale = true
+}
+
+// stop closes the shown launcher, restoring the client's terminal.
+func (l *residentLauncher) stop() {
+	l.mu.Lock()
+	program, ended := l.program, l.ended
+	l.mu.Unlock()
+	if program != nil {
+		program.Kill()
+		<-ended
+	}
+}
+
+// clientEnviron is the environment of a client, for termenv.
+type clientEnviron []string
+
+// Environ returns the variables as "key=value" pairs.
+func (e clientEnviron) Environ() []string {
+	return e
+}
+
+// Getenv returns the value of the variable named key, or "" if unset.
+func (e clientEnviron) Getenv(key string) string {
+	for _, kv := range e {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// toggleDaemon shows or hides the launcher of the running daemon on this
+// terminal and reports whether a daemon handled the request.
+func toggleDaemon() bool {
+	path, err := ipc.SocketPath()
+	if err != nil {
+		return false
+	}
+
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	defer signal.Stop(resized)
+
+	if _, err := ipc.Toggle(path, os.Stdin, os.Stdout, string(terminalMode()), resized); err != nil {
+		if errors.Is(err, ipc.ErrNotRunning) {
+			return false
+		}
+		fmt.Fprintf(os.Stderr, "Toggle failed: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
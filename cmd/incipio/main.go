@@ -1,42 +1,353 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/barab-i/incipio/internal/app"
+	"github.com/barab-i/incipio/internal/binplugin"
+	"github.com/barab-i/incipio/internal/config"
+	"github.com/barab-i/incipio/internal/index"
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/internal/logbuffer"
+	"github.com/barab-i/incipio/internal/monitor"
+	"github.com/barab-i/incipio/internal/plugins/ai"
 	"github.com/barab-i/incipio/internal/plugins/applauncher"
+	"github.com/barab-i/incipio/internal/plugins/battery"
+	"github.com/barab-i/incipio/internal/plugins/browserhistory"
 	"github.com/barab-i/incipio/internal/plugins/calculator"
+	"github.com/barab-i/incipio/internal/plugins/calendar"
+	"github.com/barab-i/incipio/internal/plugins/cron"
+	"github.com/barab-i/incipio/internal/plugins/debuglog"
+	"github.com/barab-i/incipio/internal/plugins/files"
+	"github.com/barab-i/incipio/internal/plugins/formatter"
+	"github.com/barab-i/incipio/internal/plugins/generator"
+	"github.com/barab-i/incipio/internal/plugins/grep"
+	"github.com/barab-i/incipio/internal/plugins/history"
+	"github.com/barab-i/incipio/internal/plugins/idle"
+	"github.com/barab-i/incipio/internal/plugins/journal"
 	"github.com/barab-i/incipio/internal/plugins/pluginmanager"
-	"github.com/barab-i/incipio/internal/theme"
+	"github.com/barab-i/incipio/internal/plugins/projects"
+	"github.com/barab-i/incipio/internal/plugins/recent"
+	"github.com/barab-i/incipio/internal/plugins/regextester"
+	"github.com/barab-i/incipio/internal/plugins/run"
+	"github.com/barab-i/incipio/internal/plugins/snippets"
+	"github.com/barab-i/incipio/internal/plugins/symbols"
+	"github.com/barab-i/incipio/internal/plugins/sysinfo"
+	"github.com/barab-i/incipio/internal/plugins/timer"
+	"github.com/barab-i/incipio/internal/plugins/todo"
+	"github.com/barab-i/incipio/internal/plugins/usage"
+	"github.com/barab-i/incipio/internal/plugins/websearch"
+	"github.com/barab-i/incipio/internal/plugins/workspaces"
+	"github.com/barab-i/incipio/internal/profile"
+	"github.com/barab-i/incipio/internal/stats"
+	"github.com/barab-i/incipio/internal/trace"
 	"github.com/barab-i/incipio/internal/yaegi"
+	"github.com/barab-i/incipio/pkgs/elevate"
 	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 var (
 	enabledPluginsFlag = flag.String("plugins", "", "Comma-separated list of optional plugins to enable.")
+	defaultPluginFlag  = flag.String("default-plugin", "", "Keyword or flag of the plugin active when no keyword is typed.")
+	debounceFlag       = flag.Duration("debounce", config.DefaultDebounce, "Pause in typing before a query runs.")
+	maxResultsFlag     = flag.Int("max-results", config.DefaultMaxResults, "Maximum number of results per query (0 for no limit).")
 	debugFlag          = flag.Bool("debug", false, "Enable debug logging.")
+	recordFlag         = flag.String("record", "", "Write an anonymized trace of the session to this file, for bug reports.")
+	replayFlag         = flag.String("replay", "", "Replay a trace written with --record without a terminal and report where it diverges.")
+	profileFlag        = flag.String("profile", "", "Write CPU and heap profiles and timings of frames and plugin queries to this directory on exit.")
+	themeFlag          = flag.String("theme", "", "Name of a bundled theme or of a file in the themes directory, e.g. gruvbox.")
+	layoutFlag         = flag.String("layout", "", "Result layout: list, or grid for several columns.")
+	widthFlag          = flag.Int("width", 0, "Maximum content width in terminal cells, -1 for none. Overrides the configured caps.")
+	heightFlag         = flag.Int("height", 0, "Maximum content height in terminal cells, -1 for none. Overrides the configured caps.")
+	printFlag          = flag.Bool("print", false, "Print the selected result to stdout and exit instead of executing it, for shell pipelines.")
+	printFormatFlag    = flag.String("print-format", "identifier", "What --print writes per result: identifier, or json for the whole result.")
+	anchorFlag         = flag.String("anchor", "", "Place content shorter than the terminal at its top, center or bottom.")
+	toggleFlag         = flag.Bool("toggle", false, "Show or hide the launcher of the running daemon on this terminal, or start normally without one.")
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "daemon":
+			os.Exit(runDaemonCommand(os.Args[2:]))
+		case "completion":
+			os.Exit(runCompletionCommand(os.Args[2:]))
+		case "assets":
+			os.Exit(runAssetsCommand(os.Args[2:]))
+		}
+	}
 	flag.Parse()
 
+	if *toggleFlag && toggleDaemon() {
+		return
+	}
+
 	logger := initializeLogger(*debugFlag)
 	defer logger.Sync()
+	defer index.CloseAll()
+
+	var logs *logbuffer.Buffer
+	if *debugFlag {
+		logger, logs = captureLogs(logger)
+	}
+
+	var terminalOpts []tea.ProgramOption
+	if *printFlag {
+		if !slices.Contains(printFormats, *printFormatFlag) {
+			logger.Fatal("Unknown --print-format, expected identifier or json", zap.String("format", *printFormatFlag))
+		}
+		tty, err := openTerminal()
+		if err != nil {
+			logger.Fatal("--print needs a terminal for the interface", zap.Error(err))
+		}
+		defer tty.Close()
+		terminalOpts = append(terminalOpts, tea.WithInput(tty), tea.WithOutput(tty))
+	}
 
-	theme.LoadThemeFromFile()
-	app.InitStyles()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Warn("Could not load config file, using defaults.", zap.Error(err))
+	}
+	applyFlagOverrides(&cfg)
+
+	var profiler *profile.Profiler
+	if *profileFlag != "" {
+		if profiler, err = profile.Start(*profileFlag); err != nil {
+			logger.Fatal("Could not start profiling", zap.Error(err))
+		}
+	}
+
+	mode := themeMode(cfg.Appearance, terminalMode)
+	pluginManager := newPluginManager(cfg, mode, logs, logger)
+	pluginManager.SetProfiler(profiler)
+
+	if *replayFlag != "" {
+		pluginManager.SetStats(nil) // Replayed queries are not usage.
+		code := replay(pluginManager, modelOptions(cfg, logger), logger)
+		stopProfiling(profiler, logger)
+		os.Exit(code)
+	}
+
+	opts := modelOptions(cfg, logger)
+	opts.Profile = profiler
+	if *recordFlag != "" {
+		f, err := os.Create(*recordFlag)
+		if err != nil {
+			logger.Fatal("Could not create trace file", zap.Error(err))
+		}
+		defer f.Close()
+		opts.Trace = trace.NewWriter(f)
+	}
+	var printed []string
+	if *printFlag {
+		opts.Print = func(keyword string, result plugin.Result) {
+			line, err := formatPrinted(*printFormatFlag, keyword, result)
+			if err != nil {
+				logger.Error("Could not format the selected result", zap.Error(err))
+				return
+			}
+			printed = append(printed, line)
+		}
+	}
+
+	initialModel := app.InitialModel(pluginManager, opts)
+	pluginManager.Focus()
+	runProgram(initialModel, cfg, mode, logger, terminalOpts...)
+	for _, line := range printed {
+		fmt.Println(line) // After the interface left the terminal.
+	}
+	pluginManager.Shutdown()
+	saveStats(pluginManager, logger)
+	if opts.Trace != nil && opts.Trace.Err() != nil {
+		logger.Warn("Trace is incomplete", zap.Error(opts.Trace.Err()))
+	}
+	stopProfiling(profiler, logger)
+}
+
+// stopProfiling writes the profiles started with --profile and prints the
+// timings to stderr, once the interface left the terminal.
+func stopProfiling(profiler *profile.Profiler, logger *zap.Logger) {
+	if profiler == nil {
+		return
+	}
+	if err := profiler.Stop(os.Stderr); err != nil {
+		logger.Warn("Could not write profiles", zap.Error(err))
+		return
+	}
+	logger.Info("Wrote profiles", zap.String("dir", *profileFlag))
+}
+
+// newPluginManager loads the theme for mode and registers the plugins enabled
+// by the config, along with the debug log plugin when logs are captured.
+func newPluginManager(cfg config.Config, mode theme.Mode, logs *logbuffer.Buffer, logger *zap.Logger) *app.PluginManager {
+	themes := theme.LoadNamed(cfg.Theme, mode)
+	theme.SetCurrent(themes) // Yaegi plugins may still read theme.CurrentTheme.
+	app.InitStyles(themes.Base())
 
 	pluginManager := app.NewPluginManager()
-	registerPlugins(pluginManager, logger)
+	pluginManager.SetTheme(themes)
+	pluginManager.SetHistory(app.LoadHistory())
+	pluginManager.SetStats(stats.Load())
+	registerPlugins(pluginManager, cfg, logger)
+	if logs != nil {
+		if err := pluginManager.RegisterPlugin(debuglog.New(logs)); err != nil {
+			logger.Warn("Could not register the debug log plugin", zap.Error(err))
+		}
+	}
+	pluginManager.SetMaxResults(cfg.MaxResults)
+	limits := make(map[string]app.PluginLimits, len(cfg.PluginLimits))
+	for name, l := range cfg.PluginLimits {
+		limits[name] = app.PluginLimits{MaxResults: l.MaxResults, Timeout: l.Timeout}
+	}
+	pluginManager.SetPluginLimits(limits)
+	pluginManager.SetMaxPanics(cfg.MaxPluginPanics)
+	pluginManager.SetIntentRouting(cfg.IntentRouting)
+	pluginManager.SetMatchOrder(cfg.MatchOrder)
+	pluginManager.SetAggregate(cfg.Aggregate.Plugins, cfg.Aggregate.Limit)
+	if cfg.DefaultPlugin != "" {
+		if err := pluginManager.SetDefaultPlugin(cfg.DefaultPlugin); err != nil {
+			logger.Warn("Could not set default plugin", zap.Error(err))
+		}
+	}
+	return pluginManager
+}
+
+// saveStats writes the usage statistics recorded since the last execution.
+func saveStats(pluginManager *app.PluginManager, logger *zap.Logger) {
+	if s := pluginManager.Stats(); s != nil {
+		if err := s.Save(); err != nil {
+			logger.Warn("Could not save usage statistics", zap.Error(err))
+		}
+	}
+}
+
+// replay replays the trace given with --replay, also recording the replay when
+// --record is set, and returns the exit code: 1 when the replay diverged.
+func replay(pluginManager *app.PluginManager, opts app.Options, logger *zap.Logger) int {
+	f, err := os.Open(*replayFlag)
+	if err != nil {
+		logger.Error("Could not open trace file", zap.Error(err))
+		return 2
+	}
+	defer f.Close()
+	events, err := trace.Read(f)
+	if err != nil {
+		logger.Error("Could not read trace file", zap.String("path", *replayFlag), zap.Error(err))
+		return 2
+	}
+
+	var record io.Writer
+	if *recordFlag != "" {
+		out, err := os.Create(*recordFlag)
+		if err != nil {
+			logger.Error("Could not create trace file", zap.Error(err))
+			return 2
+		}
+		defer out.Close()
+		record = out
+	}
+
+	diverged, err := app.Replay(pluginManager, opts, events, record, os.Stdout)
+	if err != nil {
+		logger.Error("Replay failed", zap.Error(err))
+		return 2
+	}
+	if diverged {
+		return 1
+	}
+	return 0
+}
+
+// applyFlagOverrides replaces config values with the command-line flags that were explicitly set.
+func applyFlagOverrides(cfg *config.Config) {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "plugins":
+			cfg.Plugins = strings.Split(*enabledPluginsFlag, ",")
+		case "default-plugin":
+			cfg.DefaultPlugin = *defaultPluginFlag
+		case "theme":
+			cfg.Theme = *themeFlag
+		case "layout":
+			cfg.Layout.Mode = *layoutFlag
+		case "width":
+			cfg.Layout.OverrideSize(config.SizeConfig{MaxWidth: *widthFlag})
+		case "height":
+			cfg.Layout.OverrideSize(config.SizeConfig{MaxHeight: *heightFlag})
+		case "anchor":
+			cfg.Layout.Anchor = *anchorFlag
+		case "debounce":
+			cfg.Debounce = max(*debounceFlag, 0)
+		case "max-results":
+			cfg.MaxResults = max(*maxResultsFlag, 0)
+		}
+	})
+}
+
+func modelOptions(cfg config.Config, logger *zap.Logger) app.Options {
+	opts := app.DefaultOptions()
+	opts.Debounce = cfg.Debounce
 
-	initialModel := app.InitialModel(pluginManager)
-	runProgram(initialModel, logger)
+	keys, err := opts.Keys.WithOverrides(cfg.Keybindings)
+	if err != nil {
+		logger.Warn("Invalid keybindings in config, using defaults.", zap.Error(err))
+	} else {
+		opts.Keys = keys
+	}
+	opts.PluginKeys = cfg.PluginKeybindings
+	opts.ShowQueryStats = cfg.ShowQueryStats
+
+	opts.MaxWidth, opts.MaxHeight = contentSizeCaps(cfg.Layout, logger)
+	opts.DropUp = cfg.Layout.DropUp
+	switch cfg.Layout.Mode {
+	case "", "list":
+	case "grid":
+		opts.Grid = true
+	default:
+		logger.Warn("Unknown layout, using the list.", zap.String("layout", cfg.Layout.Mode))
+	}
+	switch cfg.Layout.Anchor {
+	case "", "top":
+	case "center":
+		opts.Anchor = lipgloss.Center
+	case "bottom":
+		opts.Anchor = lipgloss.Bottom
+	default:
+		logger.Warn("Unknown anchor, placing content at the top.", zap.String("anchor", cfg.Layout.Anchor))
+	}
+	return opts
+}
+
+// contentSizeCaps picks the content size caps for the focused monitor, applying
+// the caps configured globally and for that monitor.
+func contentSizeCaps(layout config.LayoutConfig, logger *zap.Logger) (int, int) {
+	output, err := monitor.Focused()
+	if err != nil {
+		logger.Debug("Could not detect the focused monitor, using configured size caps only.", zap.Error(err))
+		return layout.Resolve("", 0, 0)
+	}
+
+	suggestedWidth, suggestedHeight := output.SizeHint()
+	width, height := layout.Resolve(output.Name, suggestedWidth, suggestedHeight)
+	logger.Debug("Detected focused monitor.",
+		zap.String("name", output.Name),
+		zap.String("class", string(output.Classify())),
+		zap.Int("maxWidth", width),
+		zap.Int("maxHeight", height))
+	return width, height
 }
 
 func initializeLogger(debug bool) *zap.Logger {
@@ -62,54 +373,187 @@ func initializeLogger(debug bool) *zap.Logger {
 	return logger
 }
 
-func registerPlugins(pluginManager *app.PluginManager, logger *zap.Logger) {
+// captureLogs makes the logger also record its entries in a buffer, which the
+// debug log plugin shows inside the TUI.
+func captureLogs(logger *zap.Logger) (*zap.Logger, *logbuffer.Buffer) {
+	logs := logbuffer.New(logbuffer.DefaultCapacity)
+	logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, logs.Core(core))
+	}))
+	zap.ReplaceGlobals(logger)
+	return logger, logs
+}
+
+// configureElevation sets the methods plugins run privileged actions with.
+func configureElevation(cfg config.ElevationConfig) {
+	methods := make(map[string]elevate.Method, len(cfg.Plugins))
+	for flag, method := range cfg.Plugins {
+		methods[flag] = elevate.Method(method)
+	}
+	elevate.Configure(elevate.Method(cfg.Method), methods)
+}
+
+func registerPlugins(pluginManager *app.PluginManager, cfg config.Config, logger *zap.Logger) {
+	configureElevation(cfg.Elevation)
+	if err := launch.SetTerminal(cfg.Terminal); err != nil {
+		logger.Warn("Ignoring the configured terminal", zap.Error(err))
+	}
+	if err := pluginManager.SetKeywords(cfg.Keywords); err != nil {
+		logger.Warn("Ignoring the configured keywords", zap.Error(err))
+	}
 	builtInPlugins := []plugin.Plugin{
-		applauncher.New(),
-		calculator.New(),
-		pluginmanager.New(pluginManager),
+		applauncher.New(cfg.AppLauncher.IgnoreShowIn),
+		calculator.New(cfg.Calculator.RatesProvider, cfg.Calculator.RatesTTL),
+		calendar.New(),
+		generator.New(),
+		formatter.New(),
+		regextester.New(),
+		cron.New(elevate.For("cron")),
+		battery.New(),
+		idle.New(),
+		symbols.New(),
+		symbols.NewUnicode(),
+		snippets.New(),
+		todo.New(cfg.Todo.File),
+		timer.New(),
+		sysinfo.New(),
+		projects.New(),
+		workspaces.New(),
+		files.New(),
+		recent.New(),
+		grep.New(cfg.Grep.Directory, cfg.Grep.Args),
+		journal.New(),
+		websearch.New(cfg.WebSearch.Default, cfg.WebSearch.Engines),
+		browserhistory.New(),
+		run.New(cfg.Run.Terminal, cfg.Run.Detached),
+		ai.New(cfg.AI),
+		history.New(pluginManager),
+		usage.New(pluginManager),
+		pluginmanager.New(pluginManager, config.SetPluginEnabled),
 	}
 
 	yaegiPlugins, err := yaegi.LoadPlugins()
 	if err != nil {
 		logger.Warn("Could not load Yaegi plugins", zap.Error(err))
 	}
+	binPlugins, err := binplugin.LoadPlugins()
+	if err != nil {
+		logger.Warn("Could not load executable plugins", zap.Error(err))
+	}
 
-	allPlugins := append(builtInPlugins, yaegiPlugins...)
-	enabledOptionalPlugins := parseEnabledPlugins(*enabledPluginsFlag)
+	allPlugins := builtInPlugins
+	sources := make(map[string]string) // Keyword of each yaegi plugin to its file.
+	for _, loaded := range yaegiPlugins {
+		allPlugins = append(allPlugins, loaded.Plugin)
+		sources[loaded.Plugin.Keyword()] = loaded.Path
+	}
+	for _, loaded := range binPlugins {
+		allPlugins = append(allPlugins, loaded.Plugin)
+	}
 
 	for _, p := range allPlugins {
 		metadata := p.Metadata()
-		_, isEnabled := enabledOptionalPlugins[metadata.Flag]
-		shouldRegister := metadata.IsMandatory || isEnabled
+		shouldRegister := isPluginEnabled(metadata, cfg)
 
 		if shouldRegister {
-			if err := pluginManager.RegisterPlugin(p); err != nil {
+			if err := pluginManager.RegisterPlugin(p); errors.Is(err, app.ErrKeywordTaken) {
+				logger.Error("Plugin not registered, check keywords in the config", zap.String("pluginName", p.Name()), zap.Error(err))
+			} else if err != nil {
 				logger.Fatal("Error registering plugin", zap.String("pluginName", p.Name()), zap.Error(err))
 			}
 		} else if !metadata.IsMandatory {
-			if err := pluginManager.RegisterMetadata(metadata); err != nil {
+			if err := pluginManager.RegisterDisabledPlugin(p); err != nil {
 				logger.Warn("Could not register metadata for plugin", zap.String("pluginName", metadata.Name), zap.Error(err))
 			}
 		}
+		if source, ok := sources[metadata.Keyword]; ok {
+			pluginManager.SetPluginSource(source, metadata.Keyword)
+		}
 	}
 }
 
-func parseEnabledPlugins(flagValue string) map[string]struct{} {
+// isPluginEnabled reports whether a plugin is mandatory or enabled by the configuration.
+func isPluginEnabled(metadata plugin.Metadata, cfg config.Config) bool {
+	_, isEnabled := parseEnabledPlugins(cfg.Plugins)[metadata.Flag]
+	// The configured default plugin is enabled implicitly.
+	isEnabled = isEnabled || (cfg.DefaultPlugin != "" && (cfg.DefaultPlugin == metadata.Keyword || cfg.DefaultPlugin == metadata.Flag))
+	return metadata.IsMandatory || isEnabled
+}
+
+func parseEnabledPlugins(flags []string) map[string]struct{} {
 	enabledPlugins := make(map[string]struct{})
-	if flagValue != "" {
-		for f := range strings.SplitSeq(flagValue, ",") {
-			trimmedFlag := strings.TrimSpace(f)
-			if trimmedFlag != "" {
-				enabledPlugins[trimmedFlag] = struct{}{}
-			}
+	for _, f := range flags {
+		trimmedFlag := strings.TrimSpace(f)
+		if trimmedFlag != "" {
+			enabledPlugins[trimmedFlag] = struct{}{}
 		}
 	}
 	return enabledPlugins
 }
 
-func runProgram(initialModel tea.Model, logger *zap.Logger) {
-	program := tea.NewProgram(initialModel, tea.WithAltScreen())
+func runProgram(initialModel tea.Model, cfg config.Config, mode theme.Mode, logger *zap.Logger, opts ...tea.ProgramOption) {
+	program := tea.NewProgram(initialModel, programOptions(cfg, append(opts, tea.WithAltScreen())...)...)
+
+	done := make(chan struct{})
+	defer close(done)
+	watchYaegiPlugins(cfg, done, func(msg app.PluginReloadMsg) { program.Send(msg) }, logger)
+	watchTheme(done, func() {
+		program.Send(plugin.ThemeChangedMsg{Theme: theme.LoadNamed(cfg.Theme, mode)})
+	}, logger)
+
 	if _, err := program.Run(); err != nil {
 		logger.Fatal("Error running program", zap.Error(err))
 	}
 }
+
+// programOptions adds the options set in the config to opts. Focus changes
+// are reported so plugins can re-read files edited meanwhile.
+func programOptions(cfg config.Config, opts ...tea.ProgramOption) []tea.ProgramOption {
+	opts = append(opts, tea.WithReportFocus())
+	if cfg.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	return opts
+}
+
+// themeMode returns the mode themes are chosen for: the configured appearance,
+// or the one detected by detect when it is "auto" or unset.
+func themeMode(appearance string, detect func() theme.Mode) theme.Mode {
+	switch appearance {
+	case "light":
+		return theme.Light
+	case "dark":
+		return theme.Dark
+	default:
+		return detect()
+	}
+}
+
+// terminalMode detects the background of the terminal incipio runs in.
+func terminalMode() theme.Mode {
+	return theme.DetectMode(os.Getenv("COLORFGBG"), lipgloss.HasDarkBackground)
+}
+
+// watchTheme calls onChange whenever the theme files change.
+func watchTheme(done <-chan struct{}, onChange func(), logger *zap.Logger) {
+	if err := theme.Watch(done, onChange); err != nil {
+		logger.Debug("The theme will not be reloaded on change.", zap.Error(err))
+	}
+}
+
+// watchYaegiPlugins passes a reload message to send whenever the file of a yaegi plugin changes.
+func watchYaegiPlugins(cfg config.Config, done <-chan struct{}, send func(app.PluginReloadMsg), logger *zap.Logger) {
+	err := yaegi.Watch(done, func(path string) {
+		msg := app.PluginReloadMsg{Source: path}
+		if _, err := os.Stat(path); err == nil {
+			msg.Plugin, msg.Err = yaegi.LoadPlugin(path)
+			if msg.Plugin != nil {
+				msg.Enabled = isPluginEnabled(msg.Plugin.Metadata(), cfg)
+			}
+		}
+		send(msg)
+	})
+	if err != nil {
+		logger.Debug("Yaegi plugins will not be reloaded on change.", zap.Error(err))
+	}
+}
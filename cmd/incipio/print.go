@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// printFormats are the values of --print-format.
+var printFormats = []string{"identifier", "json"}
+
+// printedResult is a result as --print-format=json writes it, one per line.
+type printedResult struct {
+	Plugin      string `json:"plugin"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Identifier  string `json:"identifier"`
+	Icon        string `json:"icon,omitempty"`
+}
+
+// formatPrinted renders a result selected in --print mode as a line of output.
+func formatPrinted(format, keyword string, r plugin.Result) (string, error) {
+	switch format {
+	case "identifier":
+		return r.Identifier, nil
+	case "json":
+		b, err := json.Marshal(printedResult{
+			Plugin:      keyword,
+			Title:       r.Title,
+			Description: r.Description,
+			Identifier:  r.Identifier,
+			Icon:        r.Icon,
+		})
+		return string(b), err
+	default:
+		return "", fmt.Errorf("unknown print format %q, expected identifier or json", format)
+	}
+}
+
+// openTerminal opens the controlling terminal for the interface in --print
+// mode, leaving stdout to the selection so incipio can run in a pipeline.
+// Colors and the background are detected on the terminal as well.
+func openTerminal() (*os.File, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(tty))
+	return tty, nil
+}
@@ -0,0 +1,10 @@
+// Package incipio embeds the files of the repository shipped inside the
+// binary. The launcher itself lives in cmd/incipio.
+package incipio
+
+import "embed"
+
+// ExamplePlugins holds the example Yaegi plugins, as templates for new ones.
+//
+//go:embed examples/plugins/*.go
+var ExamplePlugins embed.FS
@@ -0,0 +1,55 @@
+#!/usr/bin/env python3
+"""Example executable plugin for Incipio.
+
+Copy it to ~/.config/incipio/plugins/bin/ and make it executable. Incipio
+sends one JSON-RPC 2.0 request per line on stdin and reads one response per
+line from stdout.
+"""
+import json
+import sys
+
+
+def metadata(_params):
+    return {
+        "name": "Hello (Python)",
+        "description": "Greets whoever you type",
+        "keyword": "!pyhello",
+        "flag": "pyhello",
+        "is_default": False,
+    }
+
+
+def query(params):
+    name = params.get("query", "").strip() or "world"
+    return {
+        "results": [
+            {
+                "title": f"Hello, {name}!",
+                "description": "Enter copies the greeting",
+                "identifier": name,
+            }
+        ]
+    }
+
+
+def execute(params):
+    return {"copy": f"Hello, {params['identifier']}!", "close": True}
+
+
+METHODS = {"metadata": metadata, "query": query, "execute": execute}
+
+
+def main():
+    for line in sys.stdin:
+        request = json.loads(line)
+        response = {"jsonrpc": "2.0", "id": request["id"]}
+        method = METHODS.get(request["method"])
+        if method is None:
+            response["error"] = {"code": -32601, "message": "method not found"}
+        else:
+            response["result"] = method(request.get("params") or {})
+        print(json.dumps(response), flush=True)
+
+
+if __name__ == "__main__":
+    main()
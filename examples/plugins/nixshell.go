@@ -1,14 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
-	"syscall"
 
+	"github.com/barab-i/incipio/internal/index"
 	"github.com/barab-i/incipio/pkgs/plugin"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,20 +19,25 @@ import (
 // keyword is the activation keyword for this plugin.
 const keyword = "!n"
 
+// indexTable is the table in the shared "nix" index store holding nix-locate results.
+const indexTable = "executables"
+
 // metadata defines the properties of the Nix Shell Runner plugin.
 var metadata = plugin.Metadata{
 	Name:    "Nix Shell Runner", // Name displayed in the application.
 	Keyword: keyword,            // Keyword to activate this plugin.
 	Flag:    "nixshell",         // Command-line flag to enable this optional plugin.
+	// Runs nix-locate and nix shell, and keeps an index on disk.
+	Capabilities: []plugin.Capability{plugin.CapabilityExec, plugin.CapabilityFilesystem},
 }
 
 // NixShellPlugin implements the plugin.Plugin interface.
 // It finds executables using `nix-locate` and allows running them via `nix shell`.
 type NixShellPlugin struct {
-	err           error           // Stores any error encountered during plugin operation.
-	cachedResults []plugin.Result // Caches results from `nix-locate` for performance.
-	resultsMutex  sync.RWMutex    // Protects access to cachedResults and err.
-	isLoading     bool            // True if `nix-locate` is running and results are being loaded.
+	err          error        // Stores any error encountered during plugin operation.
+	store        *index.Store // On-disk index of `nix-locate` results, kept out of RAM.
+	resultsMutex sync.RWMutex // Protects access to store and err.
+	isLoading    bool         // True if `nix-locate` is running and results are being loaded.
 }
 
 // New is the constructor for NixShellPlugin, called by the plugin loader (Yaegi).
@@ -57,7 +65,7 @@ func (p *NixShellPlugin) Keyword() string {
 }
 
 // loadNixLocateResults executes `nix-locate` to find available executables
-// and populates the internal cache. This method is run in a goroutine.
+// and populates the on-disk index. This method is run in a goroutine.
 func (p *NixShellPlugin) loadNixLocateResults() {
 	// Ensure isLoading is set to false when this function exits,
 	// regardless of success or failure.
@@ -75,54 +83,68 @@ func (p *NixShellPlugin) loadNixLocateResults() {
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
-
-	p.resultsMutex.Lock()
-	defer p.resultsMutex.Unlock()
-
 	if err != nil {
+		p.resultsMutex.Lock()
+		defer p.resultsMutex.Unlock()
 		// Format a detailed error message including nix-locate's stderr.
+		// The previous index contents are kept so searches still work while offline.
 		errMsg := fmt.Sprintf("failed to run nix-locate: %v. Stderr: %s", err, stderr.String())
 		p.err = fmt.Errorf("%s", errMsg)
-		p.cachedResults = nil // Clear any potentially stale cache on error.
 		return
 	}
 
 	// Process the output of nix-locate.
 	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
 	// Pre-allocate slice for efficiency, assuming most lines are valid.
-	results := make([]plugin.Result, 0, len(lines))
+	entries := make([]index.Entry, 0, len(lines))
 
 	for _, line := range lines {
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			// Skip malformed lines.
-			continue
+		if entry, ok := parseLocateLine(line); ok {
+			entries = append(entries, entry)
 		}
+	}
 
-		// Example nix-locate output line:
-		// nixpkgs.ripgrep.out /nix/store/xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx-ripgrep-13.0.0/bin/rg
-		pkgAttrWithSuffix := parts[0]                            // e.g., "nixpkgs.ripgrep.out"
-		pkgAttr := strings.TrimSuffix(pkgAttrWithSuffix, ".out") // e.g., "nixpkgs.ripgrep" or "ripgrep"
-		fullPath := parts[len(parts)-1]                          // e.g., "/nix/store/.../bin/rg"
-		executable := filepath.Base(fullPath)                    // e.g., "rg"
-
-		var attrForShell string
+	err = p.store.Replace(indexTable, entries)
 
-		// Convert package attribute to the format required by `nix shell` (e.g., nixpkgs#ripgrep).
-		attrForShell = "nixpkgs#" + pkgAttr
+	p.resultsMutex.Lock()
+	defer p.resultsMutex.Unlock()
+	if err != nil {
+		p.err = fmt.Errorf("failed to store nix-locate results: %w", err)
+		return
+	}
+	p.err = nil // Clear any previous error on successful load.
+}
 
-		// Command to be executed when the user selects this result.
-		runCmd := fmt.Sprintf("nix shell %s -c %s", attrForShell, executable)
-		// Description shown to the user, indicating the package attribute.
+// parseLocateLine turns a line of nix-locate output into an index entry that
+// runs the executable with `nix shell`, reporting false for malformed lines.
+func parseLocateLine(line string) (index.Entry, bool) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return index.Entry{}, false
+	}
 
-		results = append(results, plugin.Result{
-			Identifier:  runCmd,     // The actual command string.
-			Title:       executable, // The executable name.
-			Description: pkgAttr,    // The package attribute.
-		})
+	// Example nix-locate output line:
+	// nixpkgs.ripgrep.out /nix/store/xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx-ripgrep-13.0.0/bin/rg
+	pkgAttrWithSuffix := parts[0]                            // e.g., "nixpkgs.ripgrep.out"
+	pkgAttr := strings.TrimSuffix(pkgAttrWithSuffix, ".out") // e.g., "nixpkgs.ripgrep" or "ripgrep"
+	fullPath := parts[len(parts)-1]                          // e.g., "/nix/store/.../bin/rg"
+	executable := filepath.Base(fullPath)                    // e.g., "rg"
+
+	// Convert package attribute to the format required by `nix shell` (e.g., nixpkgs#ripgrep).
+	attrForShell := "nixpkgs#" + pkgAttr
+
+	// Command to be executed when the user selects this result, detached
+	// from the Incipio terminal.
+	runCmd := plugin.Command{
+		Argv:   []string{"nix", "shell", attrForShell, "-c", executable},
+		Detach: true,
 	}
-	p.cachedResults = results
-	p.err = nil // Clear any previous error on successful load.
+
+	return index.Entry{
+		Identifier:  runCmd.Encode(), // The encoded command.
+		Title:       executable,      // The executable name.
+		Description: pkgAttr,         // The package attribute.
+	}, true
 }
 
 // Init is called once when the plugin is loaded.
@@ -138,6 +160,16 @@ func (p *NixShellPlugin) Init() tea.Cmd {
 		return func() tea.Msg { return nil } // Return a no-op command.
 	}
 
+	store, err := index.Open("nix")
+	if err != nil {
+		p.resultsMutex.Lock()
+		p.err = fmt.Errorf("could not open nix index store: %w", err)
+		p.isLoading = false
+		p.resultsMutex.Unlock()
+		return func() tea.Msg { return nil }
+	}
+	p.store = store
+
 	// Start loading nix-locate results in a separate goroutine
 	// to avoid blocking the main application startup.
 	go p.loadNixLocateResults()
@@ -146,7 +178,7 @@ func (p *NixShellPlugin) Init() tea.Cmd {
 }
 
 // GetResults is called by the application to fetch results based on the user's query.
-// It filters the cached `nix-locate` results.
+// It runs a full-text search over the indexed `nix-locate` results.
 func (p *NixShellPlugin) GetResults(query string) ([]plugin.Result, error) {
 	p.resultsMutex.RLock() // Use RLock for reading shared state (err, isLoading).
 
@@ -167,9 +199,6 @@ func (p *NixShellPlugin) GetResults(query string) ([]plugin.Result, error) {
 	}
 	p.resultsMutex.RUnlock() // Unlock early if no error and not loading.
 
-	p.resultsMutex.RLock() // Re-lock for reading cachedResults.
-	defer p.resultsMutex.RUnlock()
-
 	searchQuery := strings.ToLower(strings.TrimSpace(query))
 
 	// If the search query is empty, provide an informational message.
@@ -179,21 +208,18 @@ func (p *NixShellPlugin) GetResults(query string) ([]plugin.Result, error) {
 		}, nil
 	}
 
-	// If the cache is unexpectedly nil (should not happen if isLoading is false and no error),
-	// return an appropriate message.
-	if p.cachedResults == nil {
+	// Match against the executable name (Title) or package attribute (Description).
+	// Only titles are loaded here; package attributes are fetched in Hydrate when visible.
+	entries, err := p.store.SearchTitles(indexTable, searchQuery, index.DefaultSearchLimit)
+	if err != nil {
 		return []plugin.Result{
-			{Title: "Nix results not available", Description: "Cache is empty.", Identifier: "nix_cache_empty"},
+			{Title: "Nix results not available", Description: err.Error(), Identifier: "nix_cache_empty"},
 		}, nil
 	}
 
-	filteredResults := make([]plugin.Result, 0)
-	for _, result := range p.cachedResults {
-		// Match against the executable name (Title) or package attribute (Description).
-		if strings.Contains(strings.ToLower(result.Title), searchQuery) ||
-			strings.Contains(strings.ToLower(result.Description), searchQuery) {
-			filteredResults = append(filteredResults, result)
-		}
+	filteredResults := make([]plugin.Result, 0, len(entries))
+	for _, entry := range entries {
+		filteredResults = append(filteredResults, entry.LazyResult())
 	}
 
 	// If no results match the query.
@@ -206,8 +232,102 @@ func (p *NixShellPlugin) GetResults(query string) ([]plugin.Result, error) {
 	return filteredResults, nil
 }
 
+// streamBatchSize is how many results StreamResults collects before sending them.
+const streamBatchSize = 20
+
+// StreamResults answers queries while the index is still being built, which
+// takes minutes on the first run, by running nix-locate for the query itself
+// and sending its matches as they are printed. Once the index is ready it is
+// searched instead, as GetResults does.
+func (p *NixShellPlugin) StreamResults(ctx context.Context, query string, send func([]plugin.Result)) error {
+	p.resultsMutex.RLock()
+	live := p.isLoading && p.err == nil
+	p.resultsMutex.RUnlock()
+	searchQuery := strings.ToLower(strings.TrimSpace(query))
+	if !live || searchQuery == "" {
+		results, err := p.GetResults(query)
+		send(results)
+		return err
+	}
+
+	// Executables in a bin/ directory whose name contains the query.
+	pattern := "/bin/[^/]*" + regexp.QuoteMeta(searchQuery) + "[^/]*$"
+	cmd := exec.CommandContext(ctx, "nix-locate", "--type", "x", "--top-level", "--regex", pattern)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run nix-locate: %w", err)
+	}
+
+	found := 0
+	var batch []plugin.Result
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() && found < index.DefaultSearchLimit {
+		entry, ok := parseLocateLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		batch = append(batch, entry.Result())
+		found++
+		if len(batch) == streamBatchSize {
+			send(batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		send(batch)
+	}
+	// Stop nix-locate if the limit was reached before it finished.
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+	cmd.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if found == 0 {
+		send([]plugin.Result{
+			{Title: "No results found", Description: fmt.Sprintf("For query: '%s' (the package index is still loading)", query), Identifier: "nix_no_results"},
+		})
+	}
+	return nil
+}
+
+// AsResultStreamer exposes the plugin's StreamResults method to the Yaegi loader.
+func AsResultStreamer(p plugin.Plugin) plugin.ResultStreamer {
+	nixPlugin, ok := p.(*NixShellPlugin)
+	if !ok {
+		return nil
+	}
+	return nixPlugin
+}
+
+// Hydrate loads the package attribute of a result once it becomes visible.
+func (p *NixShellPlugin) Hydrate(identifier string) (plugin.Result, error) {
+	entry, err := p.store.Get(indexTable, identifier)
+	if err != nil {
+		return plugin.Result{}, err
+	}
+	return entry.Result(), nil
+}
+
+// AsHydrator exposes the plugin's Hydrate method to the Yaegi loader, which
+// cannot discover optional interfaces on interpreted types by itself.
+func AsHydrator(p plugin.Plugin) plugin.Hydrator {
+	nixPlugin, ok := p.(*NixShellPlugin)
+	if !ok {
+		return nil
+	}
+	return nixPlugin
+}
+
 // Execute is called when the user selects a result.
-// The `identifier` is the command string generated in `loadNixLocateResults`.
+// The `identifier` is the command encoded in `loadNixLocateResults`.
 func (p *NixShellPlugin) Execute(identifier string) tea.Cmd {
 	// Define placeholder identifiers that should not be executed.
 	placeholders := map[string]struct{}{
@@ -221,43 +341,27 @@ func (p *NixShellPlugin) Execute(identifier string) tea.Cmd {
 		return func() tea.Msg { return nil } // Do nothing for placeholder items.
 	}
 
-	parts := strings.Fields(identifier)
-	// Validate the command structure (e.g., "nix shell nixpkgs#ripgrep -c rg").
-	// Expects at least 5 parts: "nix", "shell", "<package_attr>", "-c", "<executable>"
-	if len(parts) < 5 || parts[0] != "nix" || parts[1] != "shell" || parts[len(parts)-2] != "-c" {
-		p.resultsMutex.Lock()
-		p.err = fmt.Errorf("invalid command identifier format for execution: %s", identifier)
-		p.resultsMutex.Unlock()
-		// The error will be displayed by GetResults on the next update.
-		return func() tea.Msg { return nil }
-	}
-
-	// Prepare the command for execution.
-	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Stdin = nil  // Detach from standard input.
-	cmd.Stdout = nil // Detach from standard output.
-	cmd.Stderr = nil // Detach from standard error.
-	// Create a new session to detach the process from the Incipio terminal.
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true,
-	}
-
-	err := cmd.Start() // Start the command asynchronously.
-	if err != nil {
+	cmd, ok := plugin.DecodeCommand(identifier)
+	if !ok {
 		p.resultsMutex.Lock()
-		p.err = fmt.Errorf("failed to start command '%s': %w", identifier, err)
+		p.err = fmt.Errorf("invalid command identifier for execution: %s", identifier)
 		p.resultsMutex.Unlock()
 		// The error will be displayed by GetResults on the next update.
 		return func() tea.Msg { return nil }
 	}
 
-	// If the command starts successfully, quit the Incipio application.
-	return tea.Quit
+	// Incipio starts the command detached and quits once it has started.
+	return plugin.Run(cmd)
 }
 
 // Update handles messages from the Bubble Tea runtime.
-// This plugin does not currently process any specific messages.
+// It records commands that failed to start so GetResults can report them.
 func (p *NixShellPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	if finished, ok := msg.(plugin.CommandFinishedMsg); ok && finished.Err != nil {
+		p.resultsMutex.Lock()
+		p.err = fmt.Errorf("failed to start command '%s': %w", strings.Join(finished.Command.Argv, " "), finished.Err)
+		p.resultsMutex.Unlock()
+	}
 	return p, func() tea.Msg { return nil } // Return self and a no-op command.
 }
 
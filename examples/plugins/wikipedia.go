@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
-	"github.com/barab-i/incipio/internal/theme"
+	"github.com/barab-i/incipio/pkgs/clipboard"
 	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -18,6 +21,7 @@ import (
 
 const (
 	wikipediaAPI = "https://en.wikipedia.org/w/api.php" // Wikipedia API base URL.
+	articleURL   = "https://en.wikipedia.org/wiki/"     // Base URL of article pages.
 	keyword      = "!w"                                 // Plugin activation keyword.
 	userAgent    = "incipio-launcher/0.1"               // User-Agent for Wikipedia API requests.
 )
@@ -28,6 +32,8 @@ var metadata = plugin.Metadata{
 	Description: "Search Wikipedia articles and view summaries.",
 	Keyword:     keyword,
 	Flag:        "wikipedia",
+	// Queries the Wikipedia API and opens articles with xdg-open.
+	Capabilities: []plugin.Capability{plugin.CapabilityNetwork, plugin.CapabilityExec},
 }
 
 // API response structures
@@ -96,6 +102,9 @@ type WikipediaPlugin struct {
 	ready            bool // True if viewport dimensions are set.
 	err              error
 
+	previewMu sync.Mutex        // Guards previews, which are fetched outside the update loop.
+	previews  map[string]string // Summaries by page title.
+
 	// Theme-aware styles, initialized in New().
 	titleStyle lipgloss.Style
 	infoStyle  lipgloss.Style
@@ -112,22 +121,23 @@ func New() plugin.Plugin {
 		httpClient: &http.Client{},
 		viewport:   vp,
 		keys:       defaultViewportKeys,
-		// Init with base styles; theme applied next.
-		titleStyle: baseTitleStyle,
-		infoStyle:  baseInfoStyle,
-		lineStyle:  baseLineStyle,
-		errorStyle: baseErrorStyle,
+		previews:   make(map[string]string),
 	}
 
-	// Apply theme colors.
-	p.titleStyle = p.titleStyle.BorderForeground(lipgloss.Color(theme.CurrentTheme.Base0D))
-	p.infoStyle = p.infoStyle.BorderForeground(lipgloss.Color(theme.CurrentTheme.Base0D))
-	p.lineStyle = p.lineStyle.Foreground(lipgloss.Color(theme.CurrentTheme.Base0D))
-	p.errorStyle = p.errorStyle.Foreground(lipgloss.Color(theme.CurrentTheme.Base08))
+	// Apply the theme the application started with; later changes arrive as plugin.ThemeChangedMsg.
+	p.applyTheme(theme.For(keyword))
 
 	return p
 }
 
+// applyTheme colors the base styles with the theme, including any overrides for this plugin.
+func (p *WikipediaPlugin) applyTheme(colors theme.Theme) {
+	p.titleStyle = baseTitleStyle.BorderForeground(colors.Accent)
+	p.infoStyle = baseInfoStyle.BorderForeground(colors.Accent)
+	p.lineStyle = baseLineStyle.Foreground(colors.Accent)
+	p.errorStyle = baseErrorStyle.Foreground(colors.Error)
+}
+
 // Metadata returns static plugin metadata.
 func (p *WikipediaPlugin) Metadata() plugin.Metadata {
 	return metadata
@@ -165,6 +175,12 @@ func (p *WikipediaPlugin) resetState() {
 // GetResults searches Wikipedia using OpenSearch API.
 // Returns results or an error item for display.
 func (p *WikipediaPlugin) GetResults(query string) ([]plugin.Result, error) {
+	return p.GetResultsContext(context.Background(), query)
+}
+
+// GetResultsContext searches like GetResults, aborting the request once ctx
+// is cancelled because a new query was typed.
+func (p *WikipediaPlugin) GetResultsContext(ctx context.Context, query string) ([]plugin.Result, error) {
 	p.resetState() // Clear previous view state first.
 
 	if query == "" {
@@ -185,7 +201,10 @@ func (p *WikipediaPlugin) GetResults(query string) ([]plugin.Result, error) {
 	params.Add("format", "json")
 	requestURL := wikipediaAPI + "?" + params.Encode()
 
-	respBody, err := p.doAPIRequest(requestURL, "opensearch")
+	respBody, err := p.doAPIRequest(ctx, requestURL, "opensearch")
+	if ctx.Err() != nil {
+		return nil, ctx.Err() // Superseded, the results are discarded.
+	}
 	if err != nil {
 		// Return API error as a displayable result.
 		return []plugin.Result{
@@ -246,6 +265,60 @@ func (p *WikipediaPlugin) GetResults(query string) ([]plugin.Result, error) {
 	return results, nil
 }
 
+// AsContextQuerier lets the Yaegi loader cancel searches superseded by a new query.
+func AsContextQuerier(p plugin.Plugin) plugin.ContextQuerier {
+	wikiPlugin, ok := p.(*WikipediaPlugin)
+	if !ok {
+		return nil
+	}
+	return wikiPlugin
+}
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionOpen     = "open in browser"
+	actionCopyLink = "copy link"
+)
+
+// Actions lists the actions offered on articles.
+func (p *WikipediaPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionOpen, Keys: []string{"ctrl+b"}},
+		{Name: actionCopyLink, Keys: []string{"ctrl+y"}},
+	}
+}
+
+// RunAction opens the selected article in the browser or copies its link.
+func (p *WikipediaPlugin) RunAction(name, identifier string) tea.Cmd {
+	if strings.HasPrefix(identifier, "wiki_") {
+		return nil // Not an article.
+	}
+	link := articleURL + url.PathEscape(strings.ReplaceAll(identifier, " ", "_"))
+
+	switch name {
+	case actionOpen:
+		return plugin.Run(plugin.Command{Argv: []string{"xdg-open", link}, Detach: true})
+	case actionCopyLink:
+		if err := clipboard.WriteAll(link); err != nil {
+			p.err = fmt.Errorf("failed to copy link: %w", err)
+			p.updateViewportContent()
+			return nil
+		}
+		return tea.Quit
+	}
+	return nil
+}
+
+// AsActor exposes the plugin's actions to the Yaegi loader, which cannot
+// discover optional interfaces on interpreted types by itself.
+func AsActor(p plugin.Plugin) plugin.Actor {
+	wikiPlugin, ok := p.(*WikipediaPlugin)
+	if !ok {
+		return nil
+	}
+	return wikiPlugin
+}
+
 // Execute fetches the summary for the selected article (identified by page title).
 // Returns a tea.Cmd for asynchronous API request.
 func (p *WikipediaPlugin) Execute(identifier string) tea.Cmd {
@@ -263,41 +336,95 @@ func (p *WikipediaPlugin) Execute(identifier string) tea.Cmd {
 
 	// Return command for Bubble Tea runtime.
 	return func() tea.Msg {
-		params := url.Values{}
-		params.Add("action", "query")
-		params.Add("format", "json")
-		params.Add("titles", pageTitle)
-		params.Add("prop", "extracts")    // Request page extracts.
-		params.Add("exintro", "true")     // Introductory section only.
-		params.Add("explaintext", "true") // Plain text, not HTML.
-		params.Add("redirects", "1")      // Follow redirects.
-		requestURL := wikipediaAPI + "?" + params.Encode()
-
-		respBody, err := p.doAPIRequest(requestURL, "fetch-extract")
-		if err != nil {
-			return summaryFetchedMsg{err: err} // Return error in message.
-		}
+		extract, err := p.fetchSummary(pageTitle)
+		return summaryFetchedMsg{content: extract, err: err}
+	}
+}
 
-		var queryResp queryResponse
-		if err := json.Unmarshal(respBody, &queryResp); err != nil {
-			return summaryFetchedMsg{err: fmt.Errorf("failed to parse Wikipedia query response: %w", err)}
-		}
+// fetchSummary requests the introduction of the article with the given title.
+func (p *WikipediaPlugin) fetchSummary(pageTitle string) (string, error) {
+	params := url.Values{}
+	params.Add("action", "query")
+	params.Add("format", "json")
+	params.Add("titles", pageTitle)
+	params.Add("prop", "extracts")    // Request page extracts.
+	params.Add("exintro", "true")     // Introductory section only.
+	params.Add("explaintext", "true") // Plain text, not HTML.
+	params.Add("redirects", "1")      // Follow redirects.
+	requestURL := wikipediaAPI + "?" + params.Encode()
 
-		var extract string
-		// API returns map of pages by ID; iterate to find extract.
-		// Expect one page in response.
-		for _, page := range queryResp.Query.Pages {
-			extract = page.Extract
-			break
-		}
+	respBody, err := p.doAPIRequest(context.Background(), requestURL, "fetch-extract")
+	if err != nil {
+		return "", err
+	}
 
-		if extract == "" {
-			// Handle page existing but no intro text.
-			extract = fmt.Sprintf("No summary found for '%s'. The page might exist but have no introductory text.", pageTitle)
-		}
+	var queryResp queryResponse
+	if err := json.Unmarshal(respBody, &queryResp); err != nil {
+		return "", fmt.Errorf("failed to parse Wikipedia query response: %w", err)
+	}
+
+	var extract string
+	// API returns map of pages by ID; iterate to find extract.
+	// Expect one page in response.
+	for _, page := range queryResp.Query.Pages {
+		extract = page.Extract
+		break
+	}
+
+	if extract == "" {
+		// Handle page existing but no intro text.
+		extract = fmt.Sprintf("No summary found for '%s'. The page might exist but have no introductory text.", pageTitle)
+	}
+	return extract, nil
+}
+
+// Preview returns the summary of the highlighted article, fetched once per title.
+func (p *WikipediaPlugin) Preview(identifier string) string {
+	if strings.HasPrefix(identifier, "wiki_") {
+		return "" // Not an article.
+	}
+
+	p.previewMu.Lock()
+	summary, ok := p.previews[identifier]
+	p.previewMu.Unlock()
+	if ok {
+		return summary
+	}
+
+	summary, err := p.fetchSummary(identifier)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err) // Not cached, so the next preview retries.
+	}
+	p.previewMu.Lock()
+	p.previews[identifier] = summary
+	p.previewMu.Unlock()
+	return summary
+}
 
-		return summaryFetchedMsg{content: extract}
+// AsPreviewer exposes the plugin's previews to the Yaegi loader.
+func AsPreviewer(p plugin.Plugin) plugin.Previewer {
+	wikiPlugin, ok := p.(*WikipediaPlugin)
+	if !ok {
+		return nil
 	}
+	return wikiPlugin
+}
+
+// Help lists the keys scrolling the article summary while it is shown.
+func (p *WikipediaPlugin) Help() []key.Binding {
+	if p.isLoading || p.err != nil || p.currentSummary == "" {
+		return nil
+	}
+	return []key.Binding{p.keys.Up, p.keys.Down, p.keys.PageUp, p.keys.PageDown, p.keys.HalfPageUp, p.keys.HalfPageDown}
+}
+
+// AsHelper exposes the plugin's keybindings to the Yaegi loader.
+func AsHelper(p plugin.Plugin) plugin.Helper {
+	wikiPlugin, ok := p.(*WikipediaPlugin)
+	if !ok {
+		return nil
+	}
+	return wikiPlugin
 }
 
 // Update handles messages (fetched summaries, window size changes, etc.).
@@ -324,6 +451,11 @@ func (p *WikipediaPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		p.resetState()                          // Clear plugin's view and state.
 		return p, func() tea.Msg { return nil } // No-op command.
 
+	case plugin.ThemeChangedMsg:
+		p.applyTheme(msg.Theme.For(keyword))
+		p.updateViewportContent() // Re-render with the new colors.
+		return p, nil
+
 	case tea.WindowSizeMsg:
 		// Constants for main app layout estimation.
 		const mainAppHorizontalPadding = 4
@@ -483,8 +615,8 @@ func (p *WikipediaPlugin) GetError() error {
 // doAPIRequest performs HTTP GET to Wikipedia API.
 // Includes User-Agent and handles common errors.
 // 'operation' string aids error messages.
-func (p *WikipediaPlugin) doAPIRequest(requestURL, operation string) ([]byte, error) {
-	req, err := http.NewRequest("GET", requestURL, nil)
+func (p *WikipediaPlugin) doAPIRequest(ctx context.Context, requestURL, operation string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Wikipedia request (%s): %w", operation, err)
 	}
@@ -18,8 +18,11 @@
         filter = path: type:
           let
             baseName = baseNameOf (toString path);
+            relPath = pkgs.lib.strings.removePrefix (toString ./. + "/") (toString path);
           in
-          !(pkgs.lib.strings.hasSuffix "/examples" (toString path)) &&
+          # The example Yaegi plugins are embedded as templates, see embed.go.
+          !(pkgs.lib.strings.hasPrefix "examples/" relPath &&
+            !(pkgs.lib.strings.hasPrefix "examples/plugins" relPath)) &&
           baseName != ".git";
       };
     in {
@@ -29,7 +32,7 @@
 
         src = filteredSrc;
 
-        vendorHash = "sha256-JONIyyzfLYTQ9fw3or7ljSId7fpwdkJ+dwZbVmoVDAc=";
+        vendorHash = "sha256-4YbBBBXFYOuhDaInHNu8ovkShX7hNv6V3MeeEY9VZGg=";
 
         subPackages = [ "./cmd/incipio" ];
 
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+)
+
+// actionBinding binds keys to an action of a plugin.Actor.
+type actionBinding struct {
+	action  string
+	binding key.Binding
+}
+
+// actionBindings returns the bindings of the actions p offers, with the keys
+// configured for p in plugin_keybindings replacing the plugin's defaults.
+// Unbound actions are included so the help overlay can list them.
+func (m model) actionBindings(p plugin.Plugin) []actionBinding {
+	actor, ok := optionalInterface[plugin.Actor](p)
+	if !ok {
+		return nil
+	}
+
+	overrides := m.pluginKeyOverrides(p)
+	var bindings []actionBinding
+	for _, action := range actor.Actions() {
+		keys := action.Keys
+		for name, configured := range overrides {
+			if strings.EqualFold(name, action.Name) {
+				keys = configured
+			}
+		}
+		helpKey := strings.Join(keys, "/")
+		if len(keys) == 0 {
+			// An empty key is enabled, so it is listed, but matches no key press.
+			keys, helpKey = []string{""}, "unbound"
+		}
+		bindings = append(bindings, actionBinding{
+			action:  action.Name,
+			binding: key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKey, action.Name)),
+		})
+	}
+	return bindings
+}
+
+// pluginKeyOverrides returns the keys configured for the actions of p, by
+// its flag or keyword.
+func (m model) pluginKeyOverrides(p plugin.Plugin) map[string][]string {
+	overrides := make(map[string][]string)
+	for _, name := range []string{p.Metadata().Flag, p.Keyword()} {
+		if name == "" {
+			continue
+		}
+		for action, keys := range m.pluginKeys[name] {
+			overrides[action] = keys
+		}
+	}
+	return overrides
+}
+
+// runPluginAction runs the action of the active plugin bound to the pressed
+// key on the selected result, reporting whether one was bound.
+func (m *model) runPluginAction(msg tea.KeyMsg) (tea.Cmd, bool) {
+	active := m.pluginManager.GetCurrentPlugin()
+	selected, ok := m.list.SelectedItem().(listItem)
+	if active == nil || !ok {
+		return nil, false
+	}
+	actor, ok := optionalInterface[plugin.Actor](active)
+	if !ok {
+		return nil, false
+	}
+
+	for _, b := range m.actionBindings(active) {
+		if key.Matches(msg, b.binding) {
+			zap.L().Debug("Running plugin action.",
+				zap.String("plugin", active.Name()),
+				zap.String("action", b.action))
+			cmd := m.pluginManager.execute(active, "RunAction", func() tea.Cmd {
+				return actor.RunAction(b.action, selected.Identifier())
+			})
+			if cmd == nil {
+				return nil, true
+			}
+			return m.confirm(b.action, cmd, false), true
+		}
+	}
+	return nil, false
+}
+
+// warnUnknownPluginKeys logs plugin_keybindings entries naming no registered
+// plugin or no action of it.
+func (m model) warnUnknownPluginKeys() {
+	plugins := m.pluginManager.GetAllPlugins()
+	for name, actions := range m.pluginKeys {
+		var target plugin.Plugin
+		for keyword, p := range plugins {
+			if keyword == name || p.Metadata().Flag == name {
+				target = p
+			}
+		}
+		if target == nil {
+			zap.L().Warn("Keybindings configured for an unknown plugin.", zap.String("plugin", name))
+			continue
+		}
+
+		known := m.actionBindings(target)
+		for action := range actions {
+			found := false
+			for _, b := range known {
+				found = found || strings.EqualFold(b.action, action)
+			}
+			if !found {
+				zap.L().Warn("Keybinding configured for an unknown plugin action.",
+					zap.String("plugin", name),
+					zap.String("action", action))
+			}
+		}
+	}
+}
+
+// renderHelp renders the help overlay: the application's keybindings, and the
+// keybindings and actions of the active plugin.
+func (m model) renderHelp() string {
+	h := help.New()
+	h.Width = m.list.Width()
+
+	navigation := []key.Binding{m.keys.Up, m.keys.Down, m.keys.NextSection, m.keys.PrevSection, m.keys.Enter, m.keys.Mark, m.keys.Esc}
+	if m.grid {
+		navigation = slices.Insert(navigation, 2, m.keys.Left, m.keys.Right)
+	}
+	sections := []string{
+		listHeaderStyle.Render("Keybindings"),
+		h.FullHelpView([][]key.Binding{
+			navigation,
+			{m.keys.Quit, m.keys.Peek, m.keys.Preview, m.keys.Intent, m.keys.Help},
+		}),
+	}
+	if active := m.pluginManager.GetCurrentPlugin(); active != nil {
+		var columns [][]key.Binding
+		if helper, ok := optionalInterface[plugin.Helper](active); ok {
+			if bindings := helper.Help(); len(bindings) > 0 {
+				columns = append(columns, bindings)
+			}
+		}
+		if actions := m.actionBindings(active); len(actions) > 0 {
+			bindings := make([]key.Binding, len(actions))
+			for i, a := range actions {
+				bindings[i] = a.binding
+			}
+			columns = append(columns, bindings)
+		}
+		if len(columns) > 0 {
+			sections = append(sections, "", listHeaderStyle.Render(active.Name()), h.FullHelpView(columns))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
@@ -0,0 +1,379 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// DefaultAggregateLimit is the number of results each plugin contributes to
+// the aggregated results when no limit is configured.
+const DefaultAggregateLimit = 5
+
+// aggregatePrefix starts the identifiers of aggregated results, followed by
+// the keyword of the plugin that returned the result, aggregateSeparator and
+// the plugin's own identifier.
+const (
+	aggregatePrefix    = "aggregate:"
+	aggregateSeparator = "\x1f"
+)
+
+// aggregateIdentifier returns the identifier of a result returned by the
+// plugin of keyword in aggregated results.
+func aggregateIdentifier(keyword, identifier string) string {
+	return aggregatePrefix + keyword + aggregateSeparator + identifier
+}
+
+// splitAggregateIdentifier returns the keyword of the plugin and its own
+// identifier of an aggregated result, and false for other identifiers.
+func splitAggregateIdentifier(identifier string) (keyword, own string, ok bool) {
+	rest, ok := strings.CutPrefix(identifier, aggregatePrefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, aggregateSeparator)
+}
+
+// aggregator stands in for the default plugin when aggregation is set up with
+// SetAggregate: queries typed without a keyword go to several plugins at
+// once, and the first results of each are listed under the plugin's name.
+// Executing, previewing and running actions on a result is passed on to the
+// plugin that returned it.
+type aggregator struct {
+	pm      *PluginManager
+	members []string // Flags or keywords of the plugins queried, in order.
+	limit   int      // Results kept of each plugin.
+}
+
+// plugins returns the enabled plugins aggregated, the default plugin first
+// unless listed elsewhere.
+func (a *aggregator) plugins() []plugin.Plugin {
+	a.pm.mu.RLock()
+	defer a.pm.mu.RUnlock()
+	var plugins []plugin.Plugin
+	add := func(p plugin.Plugin) {
+		if p != nil && !slices.ContainsFunc(plugins, func(q plugin.Plugin) bool { return q.Keyword() == p.Keyword() }) {
+			plugins = append(plugins, p)
+		}
+	}
+	listed := func(p plugin.Plugin) bool {
+		return slices.ContainsFunc(a.members, func(name string) bool {
+			return name == p.Keyword() || (name != "" && name == p.Metadata().Flag) || a.pm.typedKeywords[name] == p.Keyword()
+		})
+	}
+	if a.pm.defaultPlugin != nil && !listed(a.pm.defaultPlugin) {
+		add(a.pm.defaultPlugin)
+	}
+	for _, name := range a.members {
+		if owner, typed := a.pm.typedKeywords[name]; typed {
+			name = owner
+		}
+		for keyword, p := range a.pm.plugins {
+			if keyword == name || (name != "" && p.Metadata().Flag == name) {
+				add(p)
+			}
+		}
+	}
+	return plugins
+}
+
+// plugin returns the enabled plugin with the given keyword, or nil.
+func (a *aggregator) plugin(keyword string) plugin.Plugin {
+	a.pm.mu.RLock()
+	defer a.pm.mu.RUnlock()
+	return a.pm.plugins[keyword]
+}
+
+// defaultPlugin returns the plugin the aggregator stands in for.
+func (a *aggregator) defaultPlugin() plugin.Plugin {
+	a.pm.mu.RLock()
+	defer a.pm.mu.RUnlock()
+	return a.pm.defaultPlugin
+}
+
+// Metadata returns the metadata of the default plugin, whose place it takes.
+func (a *aggregator) Metadata() plugin.Metadata {
+	if p := a.defaultPlugin(); p != nil {
+		return p.Metadata()
+	}
+	return plugin.Metadata{}
+}
+
+// Name returns the default plugin's name.
+func (a *aggregator) Name() string {
+	return a.Metadata().Name
+}
+
+// Keyword returns the default plugin's keyword, so queries reach the
+// aggregated plugins unchanged.
+func (a *aggregator) Keyword() string {
+	return a.Metadata().Keyword
+}
+
+// Init does nothing; the aggregated plugins are initialized on their own.
+func (a *aggregator) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults returns the aggregated results of the query.
+func (a *aggregator) GetResults(query string) ([]plugin.Result, error) {
+	return a.GetResultsContext(context.Background(), query)
+}
+
+// GetResultsContext queries the aggregated plugins concurrently and lists the
+// first results of each, in the order of the plugins, under a section named
+// after the plugin. Plugins that fail are left out, unless all do; those
+// timing out are reported with a TimeoutError along the other results.
+func (a *aggregator) GetResultsContext(ctx context.Context, query string) ([]plugin.Result, error) {
+	plugins := a.plugins()
+	results := make([][]plugin.Result, len(plugins))
+	errs := make([]error, len(plugins))
+
+	limits := make([]PluginLimits, len(plugins))
+	a.pm.mu.RLock()
+	for i, p := range plugins {
+		limits[i] = a.pm.limitsLocked(p)
+	}
+	a.pm.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for i, p := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = queryWithTimeout(ctx, p.Name(), limits[i].Timeout, func(ctx context.Context) ([]plugin.Result, error) {
+				return a.query(ctx, p, query)
+			})
+		}()
+	}
+	wg.Wait()
+
+	var merged []plugin.Result
+	var timedOut []error
+	for i, p := range plugins {
+		if _, only := timeouts(errs[i]); errs[i] != nil && only {
+			timedOut = append(timedOut, errs[i])
+		} else if errs[i] != nil {
+			zap.L().Warn("Aggregated plugin failed.", zap.String("plugin", p.Name()), zap.Error(errs[i]))
+			continue
+		}
+		limit := a.limit
+		if limits[i].MaxResults > 0 {
+			limit = min(limit, limits[i].MaxResults)
+		}
+		for _, r := range results[i][:min(len(results[i]), limit)] {
+			r.Identifier = aggregateIdentifier(p.Keyword(), r.Identifier)
+			if r.Section == "" {
+				r.Section = p.Name()
+			} else {
+				r.Section = p.Name() + " · " + r.Section
+			}
+			merged = append(merged, r)
+		}
+	}
+	if len(merged) == 0 && len(timedOut) < len(plugins) {
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return merged, err
+	}
+	return merged, errors.Join(timedOut...)
+}
+
+// query returns the results of one aggregated plugin, collecting all batches
+// of plugins streaming their results.
+func (a *aggregator) query(ctx context.Context, p plugin.Plugin, query string) (_ []plugin.Result, err error) {
+	defer a.pm.recoverPanic(p, "GetResults", &err)
+	defer a.pm.observeQuery(p, "GetResults", time.Now())
+	if streamer, ok := optionalInterface[plugin.ResultStreamer](p); ok {
+		var mu sync.Mutex
+		var results []plugin.Result
+		err := streamer.StreamResults(ctx, query, func(batch []plugin.Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, batch...)
+		})
+		return results, err
+	}
+	if querier, ok := optionalInterface[plugin.ContextQuerier](p); ok {
+		return querier.GetResultsContext(ctx, query)
+	}
+	return p.GetResults(query)
+}
+
+// owner returns the plugin that returned the aggregated result with the given
+// identifier and its own identifier.
+func (a *aggregator) owner(identifier string) (plugin.Plugin, string, bool) {
+	keyword, own, ok := splitAggregateIdentifier(identifier)
+	if !ok {
+		return nil, "", false
+	}
+	p := a.plugin(keyword)
+	return p, own, p != nil
+}
+
+// Execute executes the result with the plugin that returned it.
+func (a *aggregator) Execute(identifier string) tea.Cmd {
+	p, own, ok := a.owner(identifier)
+	if !ok {
+		zap.L().Warn("No aggregated plugin for the result.", zap.String("identifier", identifier))
+		return nil
+	}
+	return a.pm.execute(p, "Execute", func() tea.Cmd { return p.Execute(own) })
+}
+
+// ExecuteBatch executes the marked results, passing those of each plugin
+// implementing plugin.BatchExecutor together and executing the others one by one.
+func (a *aggregator) ExecuteBatch(identifiers []string) tea.Cmd {
+	var keywords []string
+	batches := make(map[string][]string)
+	for _, identifier := range identifiers {
+		keyword, own, ok := splitAggregateIdentifier(identifier)
+		if !ok {
+			continue
+		}
+		if _, seen := batches[keyword]; !seen {
+			keywords = append(keywords, keyword)
+		}
+		batches[keyword] = append(batches[keyword], own)
+	}
+
+	var cmds []tea.Cmd
+	for _, keyword := range keywords {
+		p := a.plugin(keyword)
+		if p == nil {
+			continue
+		}
+		if executor, ok := optionalInterface[plugin.BatchExecutor](p); ok {
+			cmds = append(cmds, a.pm.execute(p, "ExecuteBatch", func() tea.Cmd { return executor.ExecuteBatch(batches[keyword]) }))
+			continue
+		}
+		for _, own := range batches[keyword] {
+			cmds = append(cmds, a.pm.execute(p, "Execute", func() tea.Cmd { return p.Execute(own) }))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// Hydrate loads a lazy result from the plugin that returned it.
+func (a *aggregator) Hydrate(identifier string) (_ plugin.Result, err error) {
+	p, own, ok := a.owner(identifier)
+	if !ok {
+		return plugin.Result{}, fmt.Errorf("no aggregated plugin for result '%s'", identifier)
+	}
+	hydrator, ok := optionalInterface[plugin.Hydrator](p)
+	if !ok {
+		return plugin.Result{}, fmt.Errorf("plugin '%s' does not support lazy results", p.Name())
+	}
+	defer a.pm.recoverPanic(p, "Hydrate", &err)
+	result, err := hydrator.Hydrate(own)
+	result.Identifier = identifier
+	return result, err
+}
+
+// Preview returns the preview of the plugin that returned the result, if it
+// implements plugin.Previewer.
+func (a *aggregator) Preview(identifier string) string {
+	p, own, ok := a.owner(identifier)
+	if !ok {
+		return ""
+	}
+	return a.pm.Preview(p, own)
+}
+
+// Actions lists the actions of the aggregated plugins, each once by name.
+func (a *aggregator) Actions() []plugin.Action {
+	var actions []plugin.Action
+	for _, p := range a.plugins() {
+		actor, ok := optionalInterface[plugin.Actor](p)
+		if !ok {
+			continue
+		}
+		for _, action := range actor.Actions() {
+			if !slices.ContainsFunc(actions, func(b plugin.Action) bool { return b.Name == action.Name }) {
+				actions = append(actions, action)
+			}
+		}
+	}
+	return actions
+}
+
+// RunAction runs the named action of the plugin that returned the result, if
+// it offers it.
+func (a *aggregator) RunAction(name, identifier string) tea.Cmd {
+	p, own, ok := a.owner(identifier)
+	if !ok {
+		return nil
+	}
+	actor, ok := optionalInterface[plugin.Actor](p)
+	if !ok || !slices.ContainsFunc(actor.Actions(), func(action plugin.Action) bool { return action.Name == name }) {
+		return nil
+	}
+	return a.pm.execute(p, "RunAction", func() tea.Cmd { return actor.RunAction(name, own) })
+}
+
+// RefreshInterval returns the shortest refresh interval of the aggregated
+// plugins implementing plugin.Refresher, or zero.
+func (a *aggregator) RefreshInterval() time.Duration {
+	var interval time.Duration
+	for _, p := range a.plugins() {
+		if refresher, ok := optionalInterface[plugin.Refresher](p); ok {
+			if d := refresher.RefreshInterval(); d > 0 && (interval == 0 || d < interval) {
+				interval = d
+			}
+		}
+	}
+	return interval
+}
+
+// OnFocus focuses every aggregated plugin implementing plugin.Focuser.
+func (a *aggregator) OnFocus() {
+	for _, p := range a.plugins() {
+		a.pm.focus(p, true)
+	}
+}
+
+// OnBlur blurs every aggregated plugin implementing plugin.Focuser.
+func (a *aggregator) OnBlur() {
+	for _, p := range a.plugins() {
+		a.pm.focus(p, false)
+	}
+}
+
+// Update passes the message on to every aggregated plugin.
+func (a *aggregator) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	var cmds []tea.Cmd
+	for _, p := range a.plugins() {
+		updated, cmd := a.pm.UpdatePlugin(p, msg)
+		if updated != nil {
+			a.pm.UpdatePluginInstance(updated)
+		}
+		cmds = append(cmds, cmd)
+	}
+	return a, tea.Batch(cmds...)
+}
+
+// View returns an empty string, as aggregated results use the list view.
+func (a *aggregator) View() string {
+	return ""
+}
+
+// GetError returns the first error of the aggregated plugins.
+func (a *aggregator) GetError() error {
+	for _, p := range a.plugins() {
+		if err := p.GetError(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
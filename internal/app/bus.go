@@ -0,0 +1,109 @@
+package app
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// maxPendingEvents caps the events waiting for the update loop; more are dropped.
+const maxPendingEvents = 64
+
+// bus passes the events plugins publish through the update loop, where the
+// plugins subscribed to their topic receive them in Update; see
+// plugin.Publisher and plugin.Subscriber. Events published while no update
+// loop listens, such as while a daemon is hidden, wait for the next one.
+type bus struct {
+	events chan plugin.Event
+
+	mu   sync.Mutex
+	stop chan struct{} // Closed when the listening update loop ends; see listen.
+}
+
+func newBus() *bus {
+	return &bus{events: make(chan plugin.Event, maxPendingEvents)}
+}
+
+// publisher returns the function the plugin with the given keyword publishes
+// events with.
+func (b *bus) publisher(keyword string) func(topic string, data any) {
+	return func(topic string, data any) {
+		select {
+		case b.events <- plugin.Event{Topic: topic, Source: keyword, Data: data}:
+		default:
+			zap.L().Warn("Too many pending events, dropping one.", zap.String("plugin", keyword), zap.String("topic", topic))
+		}
+	}
+}
+
+// listen returns a command passing the next event to the update loop as an
+// eventMsg, ending the listening of a previous loop.
+func (b *bus) listen() tea.Cmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil {
+		close(b.stop)
+	}
+	b.stop = make(chan struct{})
+	return b.next(b.stop)
+}
+
+// unlisten ends the listening of the update loop, once it quits, so events
+// published meanwhile wait for the next one.
+func (b *bus) unlisten() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+}
+
+// next returns a command waiting for an event until stop is closed.
+func (b *bus) next(stop chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case event := <-b.events:
+			select {
+			case <-stop:
+				// Stopped meanwhile; leave it to the next loop unless full.
+				select {
+				case b.events <- event:
+				default:
+				}
+				return nil
+			default:
+				return eventMsg{event: event, stop: stop}
+			}
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// eventMsg carries a published event to the update loop.
+type eventMsg struct {
+	event plugin.Event
+	stop  chan struct{} // Of the listening update loop; see bus.listen.
+}
+
+// deliverEvent passes the event to the Update of every plugin subscribed to
+// its topic, other than the one that published it, and waits for the next.
+func (m *model) deliverEvent(msg eventMsg) tea.Cmd {
+	cmds := []tea.Cmd{m.pluginManager.bus.next(msg.stop)}
+	plugins := m.pluginManager.GetAllPlugins()
+	for _, keyword := range slices.Sorted(maps.Keys(plugins)) {
+		subscriber, ok := optionalInterface[plugin.Subscriber](plugins[keyword])
+		if !ok || keyword == msg.event.Source || !slices.Contains(subscriber.Topics(), msg.event.Topic) {
+			continue
+		}
+		updated, cmd := m.pluginManager.UpdatePlugin(plugins[keyword], plugin.EventMsg{Event: msg.event})
+		m.updatePluginState(updated)
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// runCommand runs a command returned by a plugin. Detached commands are
+// started and the launcher quits; others take over the terminal until they
+// exit, after which handleCommandFinished decides whether to quit.
+func (m *model) runCommand(c plugin.Command) tea.Cmd {
+	if c.Detach || c.Terminal {
+		if err := launch.Run(c); err != nil {
+			return m.handleCommandFinished(plugin.CommandFinishedMsg{Command: c, Err: err})
+		}
+		m.quitting = true
+		return tea.Quit
+	}
+
+	cmd, err := launch.Command(c)
+	if err != nil {
+		return m.handleCommandFinished(plugin.CommandFinishedMsg{Command: c, Err: err})
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return plugin.CommandFinishedMsg{Command: c, Err: err}
+	})
+}
+
+// handleCommandFinished tells the active plugin how a command ended. The
+// launcher quits after a successful command and stays open after a failed one.
+func (m *model) handleCommandFinished(msg plugin.CommandFinishedMsg) tea.Cmd {
+	var cmd tea.Cmd
+	if activePlugin := m.pluginManager.GetCurrentPlugin(); activePlugin != nil {
+		var updatedPlugin plugin.Plugin
+		updatedPlugin, cmd = m.pluginManager.UpdatePlugin(activePlugin, msg)
+		m.updatePluginState(updatedPlugin)
+	}
+	if msg.Err != nil {
+		zap.L().Error("Command failed.", zap.Strings("argv", msg.Command.Argv), zap.Error(msg.Err))
+		return cmd
+	}
+	m.quitting = true
+	return tea.Sequence(cmd, tea.Quit)
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// changeHighlightDuration is how long rows that changed or appeared in a
+// refresh stay highlighted.
+const changeHighlightDuration = time.Second
+
+// clearChangesMsg ends the highlight of the rows changed by a refresh, unless
+// a later refresh highlighted rows since.
+type clearChangesMsg struct {
+	seq uint64
+}
+
+// markChanges compares the results of a refresh with the shown ones by
+// identifier and marks the rows that appeared or whose text changed. It
+// returns a command clearing the marks, or nil if nothing changed.
+func (m *model) markChanges(items []list.Item) tea.Cmd {
+	previous := make(map[string]listItem, len(m.list.Items()))
+	for _, item := range m.list.Items() {
+		if li, ok := item.(listItem); ok {
+			previous[li.identifier] = li
+		}
+	}
+
+	changed := false
+	for i, item := range items {
+		li, ok := item.(listItem)
+		if !ok {
+			continue // Section headers.
+		}
+		old, seen := previous[li.identifier]
+		// Descriptions of lazy rows are only known once hydrated.
+		li.changed = !seen || old.title != li.title ||
+			(!old.lazy && !li.lazy && old.description != li.description)
+		changed = changed || li.changed
+		items[i] = li
+	}
+	if !changed {
+		return nil
+	}
+
+	m.changeSeq++
+	seq := m.changeSeq
+	return tea.Tick(changeHighlightDuration, func(time.Time) tea.Msg {
+		return clearChangesMsg{seq: seq}
+	})
+}
+
+// clearChanges removes the highlight of changed rows.
+func (m *model) clearChanges(msg clearChangesMsg) {
+	if msg.seq != m.changeSeq {
+		return
+	}
+	for i, item := range m.list.Items() {
+		if li, ok := item.(listItem); ok && li.changed {
+			li.changed = false
+			m.list.SetItem(i, li)
+		}
+	}
+}
+
+// refreshedIndex returns where the selection goes after a refresh: the row
+// with the identifier selected before, or the same position if it is gone.
+func (m *model) refreshedIndex(identifier string, index int) int {
+	if identifier != "" {
+		if i := m.findItemIndex(identifier); i >= 0 {
+			return i
+		}
+	}
+	return min(index, max(len(m.list.Items())-1, 0))
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listView renders the result list, or the grid in grid mode. In drop-up mode
+// the rows of the page are drawn bottom to top, so the first result sits right
+// above the input.
+func (m model) listView() string {
+	if m.grid {
+		return m.gridView()
+	}
+	if !m.dropUp {
+		return m.list.View()
+	}
+
+	items := m.list.VisibleItems()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	delegate := m.delegate()
+	rows := make([]string, 0, end-start+1)
+	if m.list.Paginator.TotalPages > 1 {
+		// Above the rows, where the list draws it below them.
+		rows = append(rows, m.list.Styles.PaginationStyle.MarginBottom(1).Render(m.list.Paginator.View()))
+	}
+	for i := end - 1; i >= start; i-- {
+		var row strings.Builder
+		delegate.Render(&row, m.list, i, items[i])
+		rows = append(rows, row.String())
+	}
+	if len(items) == 0 {
+		_, plural := m.list.StatusBarItemName()
+		rows = append(rows, m.list.Styles.NoItems.Render("No "+plural+"."))
+	}
+	return lipgloss.PlaceVertical(m.list.Height(), lipgloss.Bottom, lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
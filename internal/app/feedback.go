@@ -0,0 +1,46 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// feedbackDuration is how long the executed row flashes before the command
+// returned by the plugin runs, quitting or refreshing the launcher.
+const feedbackDuration = 150 * time.Millisecond
+
+// feedbackDoneMsg ends the flash of an executed row and runs its command.
+type feedbackDoneMsg struct {
+	cmd  tea.Cmd
+	quit bool // cmd quits the launcher.
+}
+
+// confirm flashes the selected row with a checkmark and the name of the
+// action run on it, then runs cmd. Keys are ignored meanwhile so the row is
+// not executed twice.
+func (m *model) confirm(action string, cmd tea.Cmd, quit bool) tea.Cmd {
+	m.collapsePeek()
+	m.confirmed = action
+	m.list.SetDelegate(m.delegate())
+	return tea.Tick(feedbackDuration, func(time.Time) tea.Msg {
+		return feedbackDoneMsg{cmd: cmd, quit: quit}
+	})
+}
+
+// handleFeedbackDone restores the row and runs the confirmed command.
+func (m *model) handleFeedbackDone(msg feedbackDoneMsg) tea.Cmd {
+	m.confirmed = ""
+	m.list.SetDelegate(m.delegate())
+	m.quitting = msg.quit
+	return msg.cmd
+}
+
+// renderConfirmed renders the executed row: a checkmark, its title and the
+// action run on it.
+func renderConfirmed(li listItem, action string) string {
+	return lipgloss.JoinHorizontal(lipgloss.Left,
+		confirmedItemStyle.Render("✓ "+li.Title()),
+		descStyle.Render(action))
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// gridCellWidth is the width of a result's cell in grid mode, including
+	// the gap to the next column.
+	gridCellWidth = 26
+	// gridCellGap separates the columns of the grid.
+	gridCellGap = 2
+	// gridCellHeight is the number of lines of a cell: title and description.
+	gridCellHeight = 2
+	// gridMargin is the number of blank lines between the input and the grid.
+	gridMargin = 1
+)
+
+// gridColumns returns the number of columns fitting the list's width.
+func (m model) gridColumns() int {
+	return max(1, m.list.Width()/gridCellWidth)
+}
+
+// gridRows returns the number of rows of cells fitting the list's height,
+// leaving the margin and a line for the page indicator.
+func (m model) gridRows() int {
+	return max(1, (m.listHeight-gridMargin-1)/gridCellHeight)
+}
+
+// fitGrid sizes the pages of the list to the cells of the grid. Without its
+// title and pagination, the list pages one result per line, so its height is
+// set to the number of cells; gridView draws them.
+func (m *model) fitGrid() {
+	if !m.grid {
+		return
+	}
+	selected := m.list.Index()
+	m.list.SetHeight(m.gridColumns() * m.gridRows())
+	m.list.Select(selected)
+}
+
+// moveInGrid handles the arrow keys in grid mode: left and right select the
+// previous and next result, up and down the result in the same column of the
+// row above or below, or the last result from the row above a shorter last
+// row. It reports whether msg was one of those keys. Printable keys, like j
+// and k, are left to the input so they can be typed.
+func (m *model) moveInGrid(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if !m.grid || !m.listShown() || msg.Type == tea.KeyRunes {
+		return nil, false
+	}
+
+	index, n := m.list.Index(), len(m.list.Items())
+	columns := m.gridColumns()
+	up, down := -columns, columns
+	if m.dropUp {
+		// The rows are drawn bottom to top; see gridView.
+		up, down = down, up
+	}
+	var step int
+	switch {
+	case key.Matches(msg, m.keys.Left):
+		step = -1
+	case key.Matches(msg, m.keys.Right):
+		step = 1
+	case key.Matches(msg, m.keys.Up):
+		step = up
+	case key.Matches(msg, m.keys.Down):
+		step = down
+	default:
+		return nil, false
+	}
+
+	target := index + step
+	if step > 1 && target >= n && (index/columns+1)*columns < n {
+		target = n - 1
+	}
+	if target < 0 || target >= n {
+		return nil, true
+	}
+	m.list.Select(target)
+	return tea.Batch(m.slideWindow(), m.hydrateVisibleItems()), true
+}
+
+// gridView renders the current page of results as a grid, filling rows left
+// to right, with the page indicator below. In drop-up mode the rows are drawn
+// bottom to top, so the first row sits right above the input.
+func (m model) gridView() string {
+	items := m.list.Items()
+	if len(items) == 0 {
+		_, plural := m.list.StatusBarItemName()
+		return lipgloss.PlaceVertical(m.listHeight, m.gridAnchor(), m.list.Styles.NoItems.Render("No "+plural+"."))
+	}
+
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	columns := m.gridColumns()
+	var rows []string
+	for i := start; i < end; i += columns {
+		cells := make([]string, 0, columns)
+		for j := i; j < min(i+columns, end); j++ {
+			cells = append(cells, m.renderCell(j, items[j]))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+	if m.list.Paginator.TotalPages > 1 {
+		rows = append(rows, m.list.Styles.PaginationStyle.Render(m.list.Paginator.View()))
+	}
+	rows = slices.Insert(rows, 0, strings.Repeat("\n", gridMargin-1)) // gridMargin blank lines.
+	if m.dropUp {
+		slices.Reverse(rows)
+	}
+	return lipgloss.PlaceVertical(m.listHeight, m.gridAnchor(), lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// gridAnchor returns where the grid sits in the list area.
+func (m model) gridAnchor() lipgloss.Position {
+	if m.dropUp {
+		return lipgloss.Bottom
+	}
+	return lipgloss.Top
+}
+
+// renderCell renders a result as a cell of the grid: its icon and title above
+// its description, cut to the cell's width. Like rows of the list, the
+// selected cell is pointed at and marked cells carry a checkmark.
+func (m model) renderCell(index int, item list.Item) string {
+	if s, ok := item.(sectionItem); ok {
+		return gridCell(ansi.Truncate(s.style().Render(s.title), gridCellWidth-gridCellGap, "…"))
+	}
+	li, ok := item.(listItem)
+	if !ok {
+		return ""
+	}
+
+	selected := index == m.list.Index()
+	prefix := "  "
+	titleStyle, icon := itemTitleStyle, iconStyle
+	switch {
+	case selected && m.confirmed != "":
+		return gridCell(
+			ansi.Truncate(confirmedItemStyle.Render(markSymbol+" "+li.Title()), gridCellWidth-gridCellGap, "…"),
+			ansi.Truncate(descStyle.UnsetPaddingLeft().Render(m.confirmed), gridCellWidth-gridCellGap, "…"))
+	case selected:
+		prefix = selectedItemStyle.Render()
+		if li.marked {
+			prefix = selectedItemStyle.SetString(markSymbol + " ").Render()
+		}
+		titleStyle, icon = selectedItemStyle.UnsetString(), selectedIconStyle
+	case li.marked:
+		prefix = markStyle.Render() + " "
+	case li.changed:
+		titleStyle = changedTitleStyle
+	}
+
+	title := highlightMatches(li.Title(), m.matchQuery, titleStyle)
+	if li.icon != "" {
+		title = icon.Render(li.icon) + " " + title
+	}
+	textWidth := gridCellWidth - gridCellGap - lipgloss.Width(prefix)
+	return gridCell(
+		prefix+ansi.Truncate(title, textWidth, "…"),
+		"  "+ansi.Truncate(highlightMatches(li.Description(), m.matchQuery, descStyle.UnsetPaddingLeft()), textWidth, "…"))
+}
+
+// gridCell stacks the lines of a cell and pads it to its full size, so the
+// columns line up.
+func gridCell(lines ...string) string {
+	return lipgloss.NewStyle().
+		Width(gridCellWidth).
+		Height(gridCellHeight).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// matchPositions returns the positions, in runes, of the characters of text
+// matching the words of query. Each word is fuzzy matched on its own, so "fi
+// brow" points at both words of "Firefox Web Browser"; words text does not
+// contain are skipped.
+func matchPositions(text, query string) []int {
+	var positions []int
+	for _, word := range strings.Fields(query) {
+		matches := fuzzy.Find(word, []string{text})
+		if len(matches) == 0 {
+			continue
+		}
+		for _, i := range matches[0].MatchedIndexes { // Byte offsets.
+			positions = append(positions, utf8.RuneCountInString(text[:i]))
+		}
+	}
+	return positions
+}
+
+// highlightMatches renders text with style, and its characters matching query
+// with matchStyle on top, showing why a result matched. style renders each run
+// of characters separately, so it must not pad.
+func highlightMatches(text, query string, style lipgloss.Style) string {
+	positions := matchPositions(text, query)
+	if len(positions) == 0 {
+		return style.Render(text)
+	}
+	return lipgloss.StyleRunes(text, positions, matchStyle.Inherit(style), style)
+}
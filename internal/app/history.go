@@ -0,0 +1,214 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const (
+	historyDir      = "incipio"
+	historyFileName = "history.json"
+
+	// maxHistoryEntries bounds the history file; the least recently used entries are dropped.
+	maxHistoryEntries = 500
+
+	// HistoryKeyword is the keyword of the history plugin. Its re-runs are
+	// recorded by the plugin itself rather than by the model.
+	HistoryKeyword = "!h"
+)
+
+// HistoryEntry is a selection executed from the launcher.
+type HistoryEntry struct {
+	Keyword     string    `json:"keyword"` // Keyword of the plugin that executed the selection.
+	Query       string    `json:"query"`   // Query typed when the selection was made.
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Identifier  string    `json:"identifier"`
+	Count       int       `json:"count"`
+	LastUsed    time.Time `json:"last_used"`
+}
+
+// History is the persistent query and selection history, stored as JSON in
+// the XDG data directory. It is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	path    string
+	entries []HistoryEntry // Most recently used first.
+}
+
+// LoadHistory reads the history file. A missing or unreadable file yields an empty history.
+func LoadHistory() *History {
+	h := &History{}
+
+	path, err := xdg.DataFile(filepath.Join(historyDir, historyFileName))
+	if err != nil {
+		zap.L().Warn("Could not determine history path, history will not be saved.", zap.Error(err))
+		return h
+	}
+	h.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h
+	}
+	if err != nil {
+		zap.L().Warn("Could not read history.", zap.String("path", path), zap.Error(err))
+		return h
+	}
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		zap.L().Warn("Could not parse history, starting fresh.", zap.String("path", path), zap.Error(err))
+		h.entries = nil
+	}
+	return h
+}
+
+// Record adds a selection to the history, or moves an existing one with the
+// same plugin and identifier to the front, and saves the history.
+func (h *History) Record(entry HistoryEntry, now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i := h.indexLocked(entry.Keyword, entry.Identifier); i >= 0 {
+		entry.Count = h.entries[i].Count
+		h.entries = slices.Delete(h.entries, i, i+1)
+	}
+	entry.Count++
+	entry.LastUsed = now
+	h.entries = slices.Insert(h.entries, 0, entry)
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[:maxHistoryEntries]
+	}
+	return h.saveLocked()
+}
+
+// Find returns the entry for the given plugin keyword and identifier.
+func (h *History) Find(keyword, identifier string) (HistoryEntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i := h.indexLocked(keyword, identifier); i >= 0 {
+		return h.entries[i], true
+	}
+	return HistoryEntry{}, false
+}
+
+// Entries returns all entries, most recently used first.
+func (h *History) Entries() []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.entries)
+}
+
+func (h *History) indexLocked(keyword, identifier string) int {
+	return slices.IndexFunc(h.entries, func(e HistoryEntry) bool {
+		return e.Keyword == keyword && e.Identifier == identifier
+	})
+}
+
+func (h *History) saveLocked() error {
+	if h.path == "" {
+		return fmt.Errorf("history path is unknown")
+	}
+
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write atomically so a crash never leaves a truncated history behind.
+	return atomicfile.WriteFile(h.path, data, 0o644)
+}
+
+// recordHistory records the executed item with the active plugin and query.
+// It is only called for items whose Execute returned a command, which leaves
+// out info and error rows.
+func (m *model) recordHistory(item listItem) {
+	history := m.pluginManager.History()
+	active := m.pluginManager.GetCurrentPlugin()
+	if history == nil || active == nil || active.Keyword() == HistoryKeyword {
+		return
+	}
+	if owner, _, ok := splitAggregateIdentifier(item.Identifier()); ok && owner == HistoryKeyword {
+		return // Re-running history, as with the history plugin itself.
+	}
+	err := history.Record(HistoryEntry{
+		Keyword:     active.Keyword(),
+		Query:       m.textInput.Value(),
+		Title:       item.Title(),
+		Description: item.Description(),
+		Identifier:  item.Identifier(),
+	}, time.Now())
+	if err != nil {
+		zap.L().Warn("Could not save history.", zap.Error(err))
+	}
+}
+
+// rerunMsg carries the outcome of re-running the query of a selection, which
+// the plugin then executes; see Rerun.
+type rerunMsg struct {
+	plugin     plugin.Plugin
+	identifier string
+	executed   func()
+	err        error
+}
+
+// Rerun returns a command executing a selection made earlier with the plugin
+// of the given keyword. The plugin first runs the original query again, off
+// the update loop, so plugins that resolve identifiers from their latest
+// results can execute it. executed, unless nil, is called once the plugin
+// executed the selection.
+func (pm *PluginManager) Rerun(keyword, query, identifier string, executed func()) tea.Cmd {
+	return func() tea.Msg {
+		p, identifier, err := pm.requery(keyword, query, identifier)
+		return rerunMsg{plugin: p, identifier: identifier, executed: executed, err: err}
+	}
+}
+
+// requery runs the query of a selection again with the plugin of the given
+// keyword, returning the plugin and the identifier it knows the selection by.
+func (pm *PluginManager) requery(keyword, query, identifier string) (plugin.Plugin, string, error) {
+	if owner, own, ok := splitAggregateIdentifier(identifier); ok {
+		keyword, identifier = owner, own // Selected among aggregated results.
+	}
+	pm.mu.RLock()
+	p, ok := pm.plugins[keyword]
+	isDefault := ok && pm.isDefault(p)
+	keywords := pm.keywordsOfLocked(p)
+	pm.mu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("plugin '%s' is not enabled", keyword)
+	}
+
+	_, err := func() (_ []plugin.Result, err error) {
+		defer pm.recoverPanic(p, "GetResults", &err)
+		return p.GetResults(pluginQuery(query, keywords, isDefault))
+	}()
+	if err != nil {
+		return nil, "", fmt.Errorf("plugin '%s' failed to repeat query '%s': %w", keyword, query, err)
+	}
+	return p, identifier, nil
+}
+
+// handleRerun executes a selection once its query ran again.
+func (m *model) handleRerun(msg rerunMsg) tea.Cmd {
+	if msg.err != nil {
+		zap.L().Error("Failed to re-run selection.", zap.Error(msg.err))
+		return m.handleCrash(msg.err)
+	}
+	p := msg.plugin
+	cmd := m.pluginManager.execute(p, "Execute", func() tea.Cmd { return p.Execute(msg.identifier) })
+	if cmd != nil && msg.executed != nil {
+		msg.executed()
+	}
+	return cmd
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// hydrationBudget is the maximum number of lazily loaded descriptions kept in memory.
+// Once exceeded, the oldest hydrated rows that are no longer visible are reset to lazy.
+const hydrationBudget = 512
+
+// hydratedMsg carries the result of a plugin.Hydrator call.
+type hydratedMsg struct {
+	identifier string
+	result     plugin.Result
+	err        error
+	seq        uint64 // Query whose results were hydrated; see model.querySeq.
+}
+
+// hydrateVisibleItems returns a command that hydrates all lazy rows on the current page.
+func (m *model) hydrateVisibleItems() tea.Cmd {
+	items := m.list.Items()
+	if len(items) == 0 {
+		return nil
+	}
+
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	var cmds []tea.Cmd
+	for _, item := range items[start:end] {
+		li, ok := item.(listItem)
+		if !ok || !li.lazy {
+			continue
+		}
+		if _, pending := m.hydrating[li.identifier]; pending {
+			continue
+		}
+		m.hydrating[li.identifier] = struct{}{}
+
+		identifier := li.identifier
+		seq := m.querySeq
+		cmds = append(cmds, func() tea.Msg {
+			result, err := m.pluginManager.Hydrate(identifier)
+			return hydratedMsg{identifier: identifier, result: result, err: err, seq: seq}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyHydration stores a hydrated result in the list and enforces the hydration budget.
+func (m *model) applyHydration(msg hydratedMsg) {
+	delete(m.hydrating, msg.identifier)
+	if msg.seq != m.querySeq {
+		return // Stale hydration, the list has been replaced since.
+	}
+	if msg.err != nil {
+		zap.L().Debug("Failed to hydrate result.", zap.String("identifier", msg.identifier), zap.Error(msg.err))
+		return
+	}
+
+	idx := m.findItemIndex(msg.identifier)
+	if idx < 0 {
+		return
+	}
+	li := m.list.Items()[idx].(listItem)
+	li.description = msg.result.Description
+	if msg.result.Title != "" {
+		li.title = msg.result.Title
+	}
+	if msg.result.Icon != "" {
+		li.icon = msg.result.Icon
+	}
+	li.lazy = false
+	m.list.SetItem(idx, li)
+
+	m.hydratedQueue = append(m.hydratedQueue, msg.identifier)
+	m.evictHydrated()
+}
+
+// evictHydrated drops descriptions of the oldest off-screen rows once the budget is exceeded.
+func (m *model) evictHydrated() {
+	items := m.list.Items()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+
+	// Bound the number of passes so a page larger than the budget cannot loop forever.
+	for attempts := len(m.hydratedQueue); attempts > 0 && len(m.hydratedQueue) > hydrationBudget; attempts-- {
+		identifier := m.hydratedQueue[0]
+		m.hydratedQueue = m.hydratedQueue[1:]
+
+		idx := m.findItemIndex(identifier)
+		if idx < 0 {
+			continue
+		}
+		if idx >= start && idx < end {
+			// Still visible, keep it and requeue.
+			m.hydratedQueue = append(m.hydratedQueue, identifier)
+			continue
+		}
+		li := items[idx].(listItem)
+		li.description = ""
+		li.lazy = true
+		m.list.SetItem(idx, li)
+	}
+}
+
+// resetHydration forgets hydration bookkeeping after the list has been replaced.
+func (m *model) resetHydration() {
+	clear(m.hydrating)
+	m.hydratedQueue = nil
+}
+
+// findItemIndex returns the index of the item with the given identifier, or -1.
+// The current page is checked first since hydration targets visible rows.
+func (m *model) findItemIndex(identifier string) int {
+	items := m.list.Items()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	for i := start; i < end; i++ {
+		if li, ok := items[i].(listItem); ok && li.identifier == identifier {
+			return i
+		}
+	}
+	for i, item := range items {
+		if li, ok := item.(listItem); ok && li.identifier == identifier {
+			return i
+		}
+	}
+	return -1
+}
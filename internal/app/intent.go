@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Intent is what a query without a keyword looks like it asks for, as guessed
+// by classifyIntent. With intent routing enabled, such queries go to the plugin
+// handling their intent instead of the default plugin.
+type Intent string
+
+const (
+	IntentNone       Intent = ""
+	IntentMath       Intent = "math"
+	IntentConversion Intent = "conversion"
+	IntentURL        Intent = "url"
+	IntentPath       Intent = "path"
+	IntentApp        Intent = "app"
+	// IntentMatch is the intent of queries claimed by a plugin.Matcher.
+	IntentMatch Intent = "match"
+)
+
+// intentKeywords maps intents to the keywords of the plugins handling them.
+// Intents whose plugin is not enabled are not routed.
+var intentKeywords = map[Intent]string{
+	IntentMath:       "=",
+	IntentConversion: "=",
+	IntentURL:        "!s",
+	IntentPath:       "!f",
+	IntentApp:        "!a",
+}
+
+var (
+	mathPattern       = regexp.MustCompile(`^(?:0[xX][\da-fA-F_]+|0[bB][01_]+|0[oO][0-7_]+|[\d\s.,+\-*/%^()])+$`)
+	mathOperator      = regexp.MustCompile(`[\da-fA-F]\s*[+\-*/%^]\s*[\d(]|^\(|\)$`)
+	conversionPattern = regexp.MustCompile(`(?i)^-?[\d.]+\s*[a-z°/]+\s+(to|in)\s+[a-z°/]+$`)
+	schemePattern     = regexp.MustCompile(`(?i)^https?://\S+$`)
+	domainPattern     = regexp.MustCompile(`(?i)^(www\.)?[a-z0-9-]+(\.[a-z0-9-]+)*\.(com|org|net|io|dev|app|edu|gov|de|uk|fr|eu)(:\d+)?(/\S*)?$`)
+	appPattern        = regexp.MustCompile(`^[\pL][\pL\d.+-]*$`)
+)
+
+// classifyIntent guesses the intent of a query typed without a keyword using
+// cheap heuristics. The first matching intent wins, so "1+2" is math rather
+// than an app name.
+func classifyIntent(query string) Intent {
+	query = strings.TrimSpace(query)
+	switch {
+	case query == "":
+		return IntentNone
+	case mathPattern.MatchString(query) && mathOperator.MatchString(query):
+		return IntentMath
+	case conversionPattern.MatchString(query):
+		return IntentConversion
+	case schemePattern.MatchString(query) || domainPattern.MatchString(query):
+		return IntentURL
+	case strings.HasPrefix(query, "/") || strings.HasPrefix(query, "~/") ||
+		strings.HasPrefix(query, "./") || strings.HasPrefix(query, "../"):
+		return IntentPath
+	case appPattern.MatchString(query):
+		return IntentApp
+	}
+	return IntentNone
+}
+
+// toggleIntentOverride re-runs the current query with intent routing skipped,
+// or applied again.
+func (m *model) toggleIntentOverride() tea.Cmd {
+	if m.debounceTimer != nil {
+		m.debounceTimer.Stop()
+		m.debounceTimer = nil
+	}
+	m.pluginManager.ToggleIntentOverride()
+	m.lastQuery = m.textInput.Value()
+	return m.handleQueryChange(m.lastQuery)
+}
+
+// intentStatus describes the intent the query was routed by, for the status
+// shown next to the input. It is empty when the query was not routed.
+func (m model) intentStatus() string {
+	intent := m.pluginManager.Intent()
+	active := m.pluginManager.GetCurrentPlugin()
+	if intent == IntentNone || active == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s → %s (%s to skip)", intent, active.Name(), m.keys.Intent.Help().Key)
+}
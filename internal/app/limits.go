@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// PluginLimits caps the results and the query time of a plugin, so a slow or
+// overly chatty one cannot freeze or flood the list.
+type PluginLimits struct {
+	MaxResults int           // Replaces the cap of SetMaxResults; zero keeps it.
+	Timeout    time.Duration // Zero means no timeout.
+}
+
+// TimeoutError reports a plugin that did not return its results in time. The
+// list shows it as a notice above the results returned before, if any.
+type TimeoutError struct {
+	Plugin  string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s did not answer within %s", e.Plugin, e.Timeout)
+}
+
+// SetPluginLimits sets the limits of plugins, by plugin flag or keyword.
+func (pm *PluginManager) SetPluginLimits(limits map[string]PluginLimits) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.limits = limits
+}
+
+// limitsLocked returns the limits of p, its results capped by SetMaxResults
+// unless set otherwise. pm.mu must be held.
+func (pm *PluginManager) limitsLocked(p plugin.Plugin) PluginLimits {
+	var limits PluginLimits
+	if p != nil {
+		if l, ok := pm.limits[p.Keyword()]; ok {
+			limits = l
+		} else if flag := p.Metadata().Flag; flag != "" {
+			limits = pm.limits[flag]
+		}
+	}
+	if limits.MaxResults <= 0 {
+		limits.MaxResults = pm.maxResults
+	}
+	return limits
+}
+
+// queryWithTimeout runs query, giving up once timeout passes with a
+// TimeoutError naming the plugin. Plugins honouring ctx stop then; the
+// results of others answering later are dropped. Zero means no timeout.
+func queryWithTimeout(ctx context.Context, name string, timeout time.Duration, query func(context.Context) ([]plugin.Result, error)) ([]plugin.Result, error) {
+	if timeout <= 0 {
+		return query(ctx)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, &TimeoutError{Plugin: name, Timeout: timeout})
+	defer cancel()
+
+	type answer struct {
+		results []plugin.Result
+		err     error
+	}
+	answered := make(chan answer, 1)
+	go func() {
+		results, err := query(ctx)
+		answered <- answer{results, err}
+	}()
+	select {
+	case a := <-answered:
+		if ctx.Err() != nil && a.err != nil {
+			return a.results, context.Cause(ctx)
+		}
+		return a.results, a.err
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// timeouts returns the TimeoutErrors of err, which may join several, and
+// whether err holds nothing else.
+func timeouts(err error) ([]*TimeoutError, bool) {
+	if err == nil {
+		return nil, true
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var all []*TimeoutError
+		for _, err := range joined.Unwrap() {
+			found, only := timeouts(err)
+			if !only {
+				return nil, false
+			}
+			all = append(all, found...)
+		}
+		return all, true
+	}
+	var timeout *TimeoutError
+	if errors.As(err, &timeout) {
+		return []*TimeoutError{timeout}, true
+	}
+	return nil, false
+}
+
+// timeoutNotices returns the list rows noting the plugins that timed out.
+func timeoutNotices(timedOut []*TimeoutError) []list.Item {
+	items := make([]list.Item, 0, len(timedOut))
+	for _, err := range timedOut {
+		items = append(items, sectionItem{title: err.Error() + "; results may be missing.", notice: true})
+	}
+	return items
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// markSymbol is drawn in front of marked results.
+const markSymbol = "✓"
+
+// toggleMark marks the selected result for batch execution, or unmarks it,
+// and moves on to the next result. Only plugins implementing
+// plugin.BatchExecutor accept marks.
+func (m *model) toggleMark() tea.Cmd {
+	if _, ok := optionalInterface[plugin.BatchExecutor](m.pluginManager.GetCurrentPlugin()); !ok {
+		return nil
+	}
+	li, ok := m.list.SelectedItem().(listItem)
+	if !ok {
+		return nil
+	}
+
+	if i := m.markIndex(li.identifier); i >= 0 {
+		m.marks = slices.Delete(m.marks, i, i+1)
+		li.marked = false
+	} else {
+		li.marked = true
+		m.marks = append(m.marks, li)
+	}
+	cmd := m.list.SetItem(m.list.Index(), li)
+	m.list.CursorDown()
+	return tea.Batch(cmd, m.slideWindow(), m.hydrateVisibleItems())
+}
+
+// markIndex returns the position of the result with the given identifier
+// among the marked results, or -1 if it is not marked.
+func (m model) markIndex(identifier string) int {
+	return slices.IndexFunc(m.marks, func(li listItem) bool { return li.identifier == identifier })
+}
+
+// applyMarks flags the items marked earlier, so results keep their marker when
+// a query or refresh recreates them.
+func (m model) applyMarks(items []list.Item) []list.Item {
+	if len(m.marks) == 0 {
+		return items
+	}
+	for i, item := range items {
+		if li, ok := item.(listItem); ok && m.markIndex(li.identifier) >= 0 {
+			li.marked = true
+			items[i] = li
+		}
+	}
+	return items
+}
+
+// clearMarks unmarks all results.
+func (m *model) clearMarks() {
+	if len(m.marks) == 0 {
+		return
+	}
+	m.marks = nil
+	items := m.list.Items()
+	for i, item := range items {
+		if li, ok := item.(listItem); ok && li.marked {
+			li.marked = false
+			items[i] = li
+		}
+	}
+	m.list.SetItems(items)
+}
+
+// executeMarked executes the marked results at once with the active plugin,
+// recording each of them like a single execution, and flashes the selected
+// row with the number of results executed.
+func (m *model) executeMarked() tea.Cmd {
+	marked := m.marks
+	identifiers := make([]string, len(marked))
+	for i, li := range marked {
+		identifiers[i] = li.identifier
+	}
+	m.clearMarks()
+
+	start := time.Now()
+	execCmd := m.pluginManager.ExecuteBatch(identifiers)
+	if execCmd == nil {
+		return nil
+	}
+	elapsed := time.Since(start) / time.Duration(len(marked))
+	for _, li := range marked {
+		m.recordHistory(li)
+		m.recordExecution(li, elapsed)
+	}
+	quit := execCmd() == tea.Quit()
+	return m.confirm(fmt.Sprintf("%s %d results", m.keys.Enter.Help().Desc, len(marked)), execCmd, quit)
+}
@@ -1,11 +1,15 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"time"
 
-	"github.com/barab-i/incipio/internal/theme"
+	"github.com/barab-i/incipio/internal/profile"
+	"github.com/barab-i/incipio/internal/trace"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
 	"go.uber.org/zap"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -16,77 +20,172 @@ import (
 )
 
 var (
-	appStyle          lipgloss.Style
-	listTitleStyle    lipgloss.Style
-	itemStyle         lipgloss.Style
-	selectedItemStyle lipgloss.Style
-	descStyle         lipgloss.Style
-	paginationStyle   lipgloss.Style
-	helpStyle         lipgloss.Style
-	inputPromptStyle  lipgloss.Style
-	inputTextStyle    lipgloss.Style
-	quitTextStyle     lipgloss.Style
+	appStyle           lipgloss.Style
+	listTitleStyle     lipgloss.Style
+	listHeaderStyle    lipgloss.Style
+	itemStyle          lipgloss.Style
+	selectedItemStyle  lipgloss.Style
+	changedTitleStyle  lipgloss.Style
+	markStyle          lipgloss.Style
+	matchStyle         lipgloss.Style
+	confirmedItemStyle lipgloss.Style
+	itemTitleStyle     lipgloss.Style
+	iconStyle          lipgloss.Style
+	selectedIconStyle  lipgloss.Style
+	descStyle          lipgloss.Style
+	paginationStyle    lipgloss.Style
+	helpStyle          lipgloss.Style
+	inputPromptStyle   lipgloss.Style
+	inputBadgeStyle    lipgloss.Style
+	inputTextStyle     lipgloss.Style
+	quitTextStyle      lipgloss.Style
+	streamStatusStyle  lipgloss.Style
+	streamErrorStyle   lipgloss.Style
+	errorBannerStyle   lipgloss.Style
+	previewStyle       lipgloss.Style
 )
 
-// InitStyles initializes styles using the current theme.
-func InitStyles() {
+// InitStyles initializes styles using the given theme.
+func InitStyles(t theme.Theme) {
 	appStyle = lipgloss.NewStyle().Padding(1, 2)
 	listTitleStyle = lipgloss.NewStyle().
 		MarginLeft(0).
 		Padding(0, 1).
-		Background(theme.CurrentTheme.Base0D).
-		Foreground(theme.CurrentTheme.Base00)
+		Background(t.Accent).
+		Foreground(t.Base00)
+
+	listHeaderStyle = lipgloss.NewStyle().
+		MarginLeft(0).
+		Padding(0, 1).
+		Foreground(t.Accent)
 
 	itemStyle = lipgloss.NewStyle().
 		PaddingLeft(2).
-		Foreground(theme.CurrentTheme.Base05)
+		Foreground(t.Base05)
 
 	selectedItemStyle = lipgloss.NewStyle().
 		PaddingLeft(0).
-		Foreground(theme.CurrentTheme.Base0E).
+		Foreground(t.Selection).
 		SetString("> ")
 
+	// Titles of rows that changed in a refresh, until the highlight fades.
+	changedTitleStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true)
+
+	// The marker in front of rows marked for batch execution.
+	markStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true).
+		SetString(markSymbol)
+
+	// Characters of titles and descriptions matching the query; see highlightMatches.
+	matchStyle = lipgloss.NewStyle().
+		Foreground(t.Match).
+		Bold(true)
+
+	confirmedItemStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(t.Selection).
+		Foreground(t.Base00).
+		Bold(true)
+
+	// Titles rendered after an icon need their own colour, as the icon's style resets it.
+	itemTitleStyle = lipgloss.NewStyle().
+		Foreground(t.Base05)
+
+	iconStyle = lipgloss.NewStyle().
+		Foreground(t.Base0C)
+
+	selectedIconStyle = lipgloss.NewStyle().
+		Foreground(t.Selection).
+		Bold(true)
+
 	descStyle = lipgloss.NewStyle().
 		PaddingLeft(2).
-		Foreground(theme.CurrentTheme.Base03)
+		Foreground(t.Muted)
 
 	paginationStyle = list.DefaultStyles().PaginationStyle.
 		PaddingLeft(2).
-		Foreground(theme.CurrentTheme.Base04)
+		Foreground(t.Base04)
 
 	helpStyle = list.DefaultStyles().HelpStyle.
 		PaddingLeft(2).
 		PaddingBottom(1).
-		Foreground(theme.CurrentTheme.Base04)
+		Foreground(t.Base04)
 
 	inputPromptStyle = lipgloss.NewStyle().
-		Foreground(theme.CurrentTheme.Base0A).
+		Foreground(t.Base0A).
+		Bold(true)
+
+	// Names the plugin a keyword activated, left of the prompt.
+	inputBadgeStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(t.Accent).
+		Foreground(t.Base00).
 		Bold(true)
 
 	inputTextStyle = lipgloss.NewStyle().
-		Foreground(theme.CurrentTheme.Base05)
+		Foreground(t.Base05)
 
 	quitTextStyle = lipgloss.NewStyle().
 		Margin(1, 0, 2, 4).
-		Foreground(theme.CurrentTheme.Base08)
+		Foreground(t.Error)
+
+	streamStatusStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	streamErrorStyle = lipgloss.NewStyle().
+		Foreground(t.Error)
+
+	errorBannerStyle = lipgloss.NewStyle().
+		PaddingLeft(2).
+		Foreground(t.Error)
+
+	previewStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(t.Muted).
+		PaddingLeft(1).
+		Foreground(t.Base05)
 }
 
 // KeyMap defines the keybindings for the application.
 type KeyMap struct {
-	Up    key.Binding
-	Down  key.Binding
-	Enter key.Binding
-	Quit  key.Binding
-	Esc   key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Enter   key.Binding
+	Quit    key.Binding
+	Esc     key.Binding
+	Peek    key.Binding
+	Intent  key.Binding
+	Help    key.Binding
+	Preview key.Binding
+	Mark    key.Binding
+	Left    key.Binding // Move between the columns of the grid layout.
+	Right   key.Binding
+
+	NextSection key.Binding // Jump between sections of results; see plugin.Result.Section.
+	PrevSection key.Binding
 }
 
 // DefaultKeyMap provides the default keybindings.
 var DefaultKeyMap = KeyMap{
-	Up:    key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "move up")),
-	Down:  key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "move down")),
-	Enter: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
-	Quit:  key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
-	Esc:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("escape", "clear/quit")),
+	Up:      key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "move up")),
+	Down:    key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "move down")),
+	Enter:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+	Quit:    key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+	Esc:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("escape", "clear/quit")),
+	Peek:    key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "peek")),
+	Intent:  key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "toggle intent routing")),
+	Help:    key.NewBinding(key.WithKeys("f1", "?"), key.WithHelp("f1/?", "toggle help")),
+	Preview: key.NewBinding(key.WithKeys("f2"), key.WithHelp("f2", "toggle preview")),
+	Mark:    key.NewBinding(key.WithKeys("ctrl+@"), key.WithHelp("ctrl+space", "mark result")),
+	Left:    key.NewBinding(key.WithKeys("left"), key.WithHelp("←", "move left")),
+	Right:   key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "move right")),
+
+	NextSection: key.NewBinding(key.WithKeys("shift+down"), key.WithHelp("shift+↓", "next section")),
+	PrevSection: key.NewBinding(key.WithKeys("shift+up"), key.WithHelp("shift+↑", "previous section")),
 }
 
 // listItem adapts plugin.Result to the list.Item interface.
@@ -94,6 +193,33 @@ type listItem struct {
 	title       string
 	description string
 	identifier  string
+	icon        string
+	lazy        bool // Description not loaded yet; see plugin.Hydrator.
+	changed     bool // Appeared or changed in the last refresh; see markChanges.
+	marked      bool // Marked for batch execution; see model.toggleMark.
+	section     string
+}
+
+// toListItems converts plugin results into list items, with a section header
+// before each result whose section differs from the one before it. previous
+// is the section of the result preceding the first, if any.
+func toListItems(results []plugin.Result, previous string) []list.Item {
+	items := make([]list.Item, 0, len(results))
+	for _, r := range results {
+		if r.Section != "" && r.Section != previous {
+			items = append(items, sectionItem{title: r.Section})
+		}
+		previous = r.Section
+		items = append(items, listItem{
+			title:       r.Title,
+			description: r.Description,
+			identifier:  r.Identifier,
+			icon:        r.Icon,
+			lazy:        r.Lazy,
+			section:     r.Section,
+		})
+	}
+	return items
 }
 
 func (i listItem) FilterValue() string { return i.title }
@@ -101,13 +227,26 @@ func (i listItem) Title() string       { return i.title }
 func (i listItem) Description() string { return i.description }
 func (i listItem) Identifier() string  { return i.identifier }
 
+// result converts the item back into the plugin result it was made from.
+func (i listItem) result() plugin.Result {
+	return plugin.Result{Title: i.title, Description: i.description, Identifier: i.identifier, Icon: i.icon, Lazy: i.lazy, Section: i.section}
+}
+
 // itemDelegate provides custom rendering for list items.
-type itemDelegate struct{}
+type itemDelegate struct {
+	peek      bool   // Render the selected item expanded; see model.togglePeek.
+	confirmed string // Action just run on the selected item, flashed; see model.confirm.
+	query     string // Query whose matches are highlighted; see model.matchQuery.
+}
 
 func (d itemDelegate) Height() int                               { return 1 }
 func (d itemDelegate) Spacing() int                              { return 0 }
 func (d itemDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if s, ok := item.(sectionItem); ok {
+		fmt.Fprint(w, s.style().Render(s.title))
+		return
+	}
 	li, ok := item.(listItem)
 	if !ok {
 		return
@@ -116,53 +255,120 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 	var titleRendered, descRendered, combined string
 	separator := " " // Separator between title and description
 
-	descRendered = descStyle.Render(li.Description())
+	descRendered = descStyle.Render(highlightMatches(li.Description(), d.query, descStyle.UnsetPaddingLeft()))
 
-	if index == m.Index() {
-		titleRendered = selectedItemStyle.Render(li.Title())
+	if index == m.Index() && d.confirmed != "" {
+		combined = renderConfirmed(li, d.confirmed)
+	} else if index == m.Index() && d.peek {
+		combined = renderExpanded(li, m.Width())
+	} else if index == m.Index() {
+		selectedStyle := selectedItemStyle
+		if li.marked {
+			selectedStyle = selectedItemStyle.SetString(markSymbol + " ")
+		}
+		if li.icon != "" {
+			titleRendered = selectedStyle.Render(selectedIconStyle.Render(li.icon), highlightMatches(li.Title(), d.query, selectedItemStyle.UnsetString()))
+		} else {
+			titleRendered = selectedStyle.Render(highlightMatches(li.Title(), d.query, selectedItemStyle.UnsetString()))
+		}
 		combined = lipgloss.JoinHorizontal(lipgloss.Left, titleRendered, descRendered)
 	} else {
-		titleRendered = itemStyle.Render(li.Title())
+		titleStyle := itemTitleStyle
+		if li.changed {
+			titleStyle = changedTitleStyle
+		}
+		if li.icon != "" {
+			titleRendered = itemStyle.Render(iconStyle.Render(li.icon) + " " + highlightMatches(li.Title(), d.query, titleStyle))
+		} else {
+			titleRendered = itemStyle.Render(highlightMatches(li.Title(), d.query, titleStyle))
+		}
 		combined = lipgloss.JoinHorizontal(lipgloss.Left, titleRendered, separator, descRendered)
-		combined = itemStyle.Render(combined)
+		if li.marked {
+			// The marker takes the place of the row's indentation.
+			combined = markStyle.Render() + " " + itemStyle.UnsetPaddingLeft().Render(combined)
+		} else {
+			combined = itemStyle.Render(combined)
+		}
 	}
 
 	fmt.Fprint(w, combined)
 }
 
+// delegate returns the item delegate rendering the list in the model's state.
+func (m model) delegate() itemDelegate {
+	return itemDelegate{peek: m.peeking, confirmed: m.confirmed, query: m.matchQuery}
+}
+
 // model holds the application's state.
 type model struct {
 	pluginManager *PluginManager
 	list          list.Model
 	textInput     textinput.Model
 	keys          KeyMap
-	width         int
+	pluginKeys    map[string]map[string][]string // Keys of plugin actions by plugin flag or keyword.
+	showHelp      bool                           // The help overlay replaces the content.
+	width         int                            // Terminal size.
 	height        int
-	err           error // err stores an error to be displayed in the UI.
+	maxWidth      int // Content size caps, zero for none.
+	maxHeight     int
+	dropUp        bool              // Input at the bottom, results growing upward; see listView.
+	grid          bool              // Results in several columns; see gridView.
+	anchor        lipgloss.Position // Vertical placement of the content; see placeVertically.
+	err           error             // err stores an error to be displayed in the UI.
 	quitting      bool
-
-	debounceTimer *time.Timer // For debouncing query processing.
-	lastQuery     string      // Stores the query for the debounced call.
+	confirmed     string     // Action run on the selected item while its row flashes; see confirm.
+	marks         []listItem // Results marked for batch execution, in marking order; see toggleMark.
+
+	debounce      time.Duration      // Pause in typing before a query runs.
+	debounceTimer *time.Timer        // For debouncing query processing.
+	lastQuery     string             // Stores the query for the debounced call.
+	querySeq      uint64             // Sequence number of the latest query; results of earlier ones are stale.
+	cancelQuery   context.CancelFunc // Cancels the latest query while it runs.
+	showStats     bool               // Show the result count and timing of the last query.
+	lastResults   queryStats
+	results       []plugin.Result // Results of the query; the list holds those from windowStart to windowEnd; see slideWindow.
+	windowStart   int
+	windowEnd     int
+	matchQuery    string // Query of the results shown, as the plugin received it; see highlightMatches.
+
+	hydrating     map[string]struct{} // Identifiers with an in-flight Hydrate call.
+	hydratedQueue []string            // Hydrated identifiers, oldest first, for budget eviction.
+
+	refreshPending bool   // A refreshMsg for a plugin.Refresher is scheduled.
+	changeSeq      uint64 // Sequence number of the last refresh that changed rows; see markChanges.
+
+	listHeight     int    // List height without a peeked row.
+	fullListHeight int    // List height without the error banner.
+	fullListWidth  int    // List width without the preview pane.
+	bannerLines    int    // Lines taken by the error banner; see fitErrorBanner.
+	dismissedError string // Errors of the banner dismissed with esc.
+	previewOn      bool   // The preview pane is toggled on; see previewShown.
+	preview        previewPane
+	peeking        bool // The selected row is expanded; see togglePeek.
+
+	stream *streamView // Stream shown in place of the plugin view, if any.
+
+	trace  *trace.Writer // Records the session when set; see traceUpdate.
+	dryRun bool          // Enter traces executions instead of running them.
+
+	profiler *profile.Profiler // Times frames when set; see --profile.
+
+	printResult func(keyword string, result plugin.Result) // Enter hands results to it instead of executing them; see Options.Print.
 }
 
 // InitialModel sets up the initial state of the application.
-func InitialModel(pm *PluginManager) model {
+func InitialModel(pm *PluginManager, opts Options) model {
 	ti := textinput.New()
 	ti.Placeholder = "Search..."
 	ti.Focus()
 	ti.CharLimit = 156
 	ti.Width = 50 // Initial width, will be updated.
 	ti.Prompt = "> "
-	ti.PromptStyle = inputPromptStyle
-	ti.TextStyle = inputTextStyle
 
 	delegate := itemDelegate{}
 
 	li := list.New([]list.Item{}, delegate, 0, 0)
 	li.Title = "" // No global title for the list itself.
-	li.Styles.Title = lipgloss.NewStyle().MarginLeft(0).Padding(0, 1).Foreground(theme.CurrentTheme.Base0D)
-	li.Styles.PaginationStyle = paginationStyle
-	li.Styles.HelpStyle = helpStyle
 
 	li.SetShowHelp(false)
 	li.SetShowStatusBar(false)
@@ -170,57 +376,97 @@ func InitialModel(pm *PluginManager) model {
 	li.SetShowFilter(false)
 
 	li.KeyMap = list.KeyMap{
-		CursorUp:   DefaultKeyMap.Up,
-		CursorDown: DefaultKeyMap.Down,
+		CursorUp:   opts.Keys.Up,
+		CursorDown: opts.Keys.Down,
 		GoToStart:  key.NewBinding(key.WithKeys("home")),
 		GoToEnd:    key.NewBinding(key.WithKeys("end")),
 	}
+	if opts.Grid {
+		// The grid draws the pages itself; see fitGrid.
+		li.SetShowTitle(false)
+		li.SetShowPagination(false)
+	}
+	if opts.DropUp {
+		// The list is drawn upside down, so the up key moves to the next result.
+		li.KeyMap.CursorUp, li.KeyMap.CursorDown = li.KeyMap.CursorDown, li.KeyMap.CursorUp
+	}
 
 	m := model{
 		pluginManager: pm,
 		textInput:     ti,
 		list:          li,
-		keys:          DefaultKeyMap,
+		keys:          opts.Keys,
+		pluginKeys:    opts.PluginKeys,
+		showStats:     opts.ShowQueryStats,
+		debounce:      opts.Debounce,
+		maxWidth:      opts.MaxWidth,
+		maxHeight:     opts.MaxHeight,
+		dropUp:        opts.DropUp,
+		grid:          opts.Grid,
+		anchor:        opts.Anchor,
 		err:           nil,
+		hydrating:     make(map[string]struct{}),
+		trace:         opts.Trace,
+		dryRun:        opts.DryRun,
+		profiler:      opts.Profile,
+		printResult:   opts.Print,
+	}
+	if m.dropUp && m.anchor == lipgloss.Top {
+		m.anchor = lipgloss.Bottom // Keep the input at the bottom of the terminal.
 	}
+	m.applyStyles()
 
 	// Fetch initial items from the default plugin.
 	// Note: The tea.Cmd returned by m.pluginManager.InitPlugins() is currently ignored here.
 	// Proper handling would involve returning it from model.Init() and processing it in the Bubble Tea runtime.
 	m.pluginManager.InitPlugins() // Synchronous part of plugin initialization.
+	m.warnUnknownPluginKeys()
 
 	defaultPlugin := m.pluginManager.GetCurrentPlugin()
 	if defaultPlugin != nil {
-		results, err := defaultPlugin.GetResults("") // Fetch initial results.
+		results, err := m.pluginManager.GetResults(context.Background(), "") // Fetch initial results.
 		if err != nil {
 			// Store error for UI display and log it.
 			m.err = fmt.Errorf("initial load failed for plugin '%s': %w", defaultPlugin.Name(), err)
 			zap.L().Error("Initial results load failed for default plugin",
 				zap.String("plugin", defaultPlugin.Name()),
 				zap.Error(err)) // Log the original error.
-			m.list.SetItems([]list.Item{}) // Keep the list empty on error.
+			m.clearResults() // Keep the list empty on error.
 		} else {
-			// Convert plugin results to list items.
-			items := make([]list.Item, len(results))
-			for i, r := range results {
-				items[i] = listItem{
-					title:       r.Title,
-					description: r.Description,
-					identifier:  r.Identifier,
-				}
-			}
-			m.list.SetItems(items) // Populate the list with initial items.
+			m.list.SetItems(m.windowResults(results, 0)) // Populate the list with initial items.
 		}
 	} else {
-		m.list.SetItems([]list.Item{}) // Ensure list is empty if no default plugin is available.
+		m.clearResults() // Ensure list is empty if no default plugin is available.
 		zap.L().Warn("No default plugin found during initial model setup.")
 	}
 
 	return m
 }
 
+// applyStyles copies the package styles into the text input and list, which
+// keep their own copies.
+func (m *model) applyStyles() {
+	m.textInput.PromptStyle = inputPromptStyle
+	m.textInput.TextStyle = inputTextStyle
+	m.list.Styles.Title = listHeaderStyle
+	m.list.Styles.PaginationStyle = paginationStyle
+	m.list.Styles.HelpStyle = helpStyle
+}
+
+// contentSize returns the terminal size limited by the configured caps.
+func (m model) contentSize() (int, int) {
+	width, height := m.width, m.height
+	if m.maxWidth > 0 {
+		width = min(width, m.maxWidth)
+	}
+	if m.maxHeight > 0 {
+		height = min(height, m.maxHeight)
+	}
+	return width, height
+}
+
 // Init performs initial setup for the model, like starting the text input blink.
 // Note: This should ideally also return commands from plugin initialization (see InitialModel).
 func (m model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.hydrateVisibleItems(), m.scheduleRefresh(), m.pluginManager.bus.listen())
 }
@@ -0,0 +1,105 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// handleMouse scrolls the result list with the wheel and selects the clicked
+// result, reporting whether the list handled msg. While a plugin's view or a
+// stream replaces the list, the message is left to them: their viewports
+// scroll with the wheel themselves.
+func (m *model) handleMouse(msg tea.MouseMsg) (tea.Cmd, bool) {
+	if !m.listShown() {
+		return nil, false
+	}
+	if msg.Action != tea.MouseActionPress {
+		return nil, true
+	}
+
+	// The list is drawn upside down in drop-up mode, so the wheel moves the other way.
+	up, down := m.list.CursorUp, m.list.CursorDown
+	if m.dropUp {
+		up, down = down, up
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		up()
+	case tea.MouseButtonWheelDown:
+		down()
+	case tea.MouseButtonLeft:
+		index, ok := m.itemAt(msg.X, msg.Y)
+		if !ok {
+			return nil, true
+		}
+		if _, header := m.list.Items()[index].(sectionItem); header {
+			return nil, true
+		}
+		m.list.Select(index)
+	default:
+		return nil, true
+	}
+	m.collapsePeek()
+	return tea.Batch(m.slideWindow(), m.hydrateVisibleItems()), true
+}
+
+// listShown reports whether the result list is on screen, next to the
+// preview pane or alone.
+func (m model) listShown() bool {
+	if m.showHelp || m.streamShown() {
+		return false
+	}
+	active := m.pluginManager.GetCurrentPlugin()
+	return active == nil || m.pluginManager.PluginView(active) == ""
+}
+
+// itemAt returns the index of the result drawn at the given screen cell.
+func (m model) itemAt(x, y int) (int, bool) {
+	left := appStyle.GetPaddingLeft()
+	if contentWidth, _ := m.contentSize(); contentWidth < m.width {
+		left += (m.width - contentWidth) / 2 // Centered; see View.
+	}
+	if x < left || x >= left+m.list.Width() {
+		return 0, false
+	}
+
+	start, end := m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+	rowHeight := itemDelegate{}.Height() + itemDelegate{}.Spacing()
+	columns, column := 1, 0
+	if m.grid {
+		rowHeight, columns = gridCellHeight, m.gridColumns()
+		if column = (x - left) / gridCellWidth; column >= columns {
+			return 0, false
+		}
+	}
+	frameTop, frameHeight := m.frameLines()
+	var row int
+	if m.dropUp {
+		// The first result sits right above the input, at the bottom.
+		bottom := frameTop + frameHeight - appStyle.GetPaddingBottom() - lipgloss.Height(m.inputView()) - 1 - m.bannerLines
+		if m.grid {
+			bottom -= gridMargin
+		}
+		if y > bottom {
+			return 0, false
+		}
+		row = (bottom - y) / rowHeight
+	} else {
+		top := frameTop + appStyle.GetPaddingTop() + lipgloss.Height(m.inputView()) + m.bannerLines
+		if m.grid {
+			top += gridMargin
+		} else {
+			top += lipgloss.Height(m.list.Styles.TitleBar.Render(m.list.Styles.Title.Render(m.list.Title)))
+		}
+		if y < top {
+			return 0, false
+		}
+		row = (y - top) / rowHeight
+	}
+
+	index := start + row*columns + column
+	if index >= end {
+		return 0, false
+	}
+	return index, true
+}
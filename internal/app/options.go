@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/internal/profile"
+	"github.com/barab-i/incipio/internal/trace"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Options configures the application model.
+type Options struct {
+	// Debounce is how long typing must pause before a query runs.
+	Debounce time.Duration
+	// Keys holds the keybindings.
+	Keys KeyMap
+	// PluginKeys binds keys to plugin actions: plugin flag or keyword, then
+	// action name, then keys. See plugin.Actor.
+	PluginKeys map[string]map[string][]string
+	// MaxWidth and MaxHeight cap the content size in terminal cells. Content
+	// narrower than the terminal is centered horizontally. Zero means no cap.
+	MaxWidth  int
+	MaxHeight int
+	// DropUp places the input at the bottom with the first result right above
+	// it and the others growing upward, like fzf's default layout.
+	DropUp bool
+	// Anchor places the content vertically in a taller terminal, e.g. with
+	// MaxHeight: lipgloss.Top, lipgloss.Center or lipgloss.Bottom. Drop-up
+	// mode anchors at the bottom instead of the top.
+	Anchor lipgloss.Position
+	// Grid arranges the results in several columns instead of one per line;
+	// see gridView.
+	Grid bool
+	// ShowQueryStats shows the number of results of each query and the time
+	// the plugin took to return them next to the input.
+	ShowQueryStats bool
+	// Trace, when set, receives an anonymized trace of the session; see --record.
+	Trace *trace.Writer
+	// Profile, when set, receives how long each frame took to render; see --profile.
+	Profile *profile.Profiler
+	// DryRun makes enter only trace the execution instead of running it. Replays use it.
+	DryRun bool
+	// Print, when set, receives the selected result, or each marked result,
+	// along with the active plugin's keyword when enter is pressed, and the
+	// launcher quits without executing them; see --print.
+	Print func(keyword string, result plugin.Result)
+}
+
+// DefaultOptions returns the options used without configuration.
+func DefaultOptions() Options {
+	return Options{
+		Debounce: 200 * time.Millisecond,
+		Keys:     DefaultKeyMap,
+	}
+}
+
+// WithOverrides returns a copy of the key map where the bindings of the given
+// actions (up, down, enter, quit, esc, peek, intent, help, preview, mark, left,
+// right, next_section, prev_section) are replaced by the given keys.
+func (k KeyMap) WithOverrides(overrides map[string][]string) (KeyMap, error) {
+	bindings := map[string]*key.Binding{
+		"up":      &k.Up,
+		"down":    &k.Down,
+		"enter":   &k.Enter,
+		"quit":    &k.Quit,
+		"esc":     &k.Esc,
+		"peek":    &k.Peek,
+		"intent":  &k.Intent,
+		"help":    &k.Help,
+		"preview": &k.Preview,
+		"mark":    &k.Mark,
+		"left":    &k.Left,
+		"right":   &k.Right,
+
+		"next_section": &k.NextSection,
+		"prev_section": &k.PrevSection,
+	}
+
+	for action, keys := range overrides {
+		binding, ok := bindings[strings.ToLower(action)]
+		if !ok {
+			known := make([]string, 0, len(bindings))
+			for name := range bindings {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return k, fmt.Errorf("unknown keybinding action '%s', expected one of %s", action, strings.Join(known, ", "))
+		}
+		if len(keys) == 0 {
+			return k, fmt.Errorf("keybinding action '%s' has no keys", action)
+		}
+		*binding = key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), binding.Help().Desc))
+	}
+	return k, nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// togglePeek expands the selected row to show its full title and description
+// wrapped over several lines, or collapses it again. The list shrinks by the
+// extra lines so the view keeps its height.
+func (m *model) togglePeek() {
+	if m.grid {
+		return // Cells have a fixed size.
+	}
+	if m.peeking {
+		m.collapsePeek()
+		return
+	}
+	li, ok := m.list.SelectedItem().(listItem)
+	if !ok {
+		return
+	}
+
+	// Changing the height repaginates the list, so restore the selection afterwards.
+	selected := m.list.Index()
+	extraLines := lipgloss.Height(renderExpanded(li, m.list.Width())) - 1
+	m.peeking = true
+	m.list.SetDelegate(m.delegate())
+	m.list.SetHeight(max(1, m.listHeight-extraLines))
+	m.list.Select(selected)
+}
+
+// collapsePeek returns a peeked row to its single-line form.
+func (m *model) collapsePeek() {
+	if !m.peeking {
+		return
+	}
+	selected := m.list.Index()
+	m.peeking = false
+	m.list.SetDelegate(m.delegate())
+	m.list.SetHeight(m.listHeight)
+	m.list.Select(selected)
+}
+
+// renderExpanded renders the item's title and description in full, wrapped to width.
+func renderExpanded(li listItem, width int) string {
+	textWidth := max(1, width-lipgloss.Width(selectedItemStyle.String()))
+
+	title := selectedItemStyle.UnsetString().Render(li.Title())
+	if li.icon != "" {
+		title = selectedIconStyle.Render(li.icon) + " " + title
+	}
+	block := lipgloss.NewStyle().Width(textWidth).Render(title)
+	if li.Description() != "" {
+		desc := descStyle.UnsetPaddingLeft().Width(textWidth).Render(li.Description())
+		block = lipgloss.JoinVertical(lipgloss.Left, block, desc)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, selectedItemStyle.Render(), block)
+}
@@ -1,22 +1,64 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/barab-i/incipio/internal/profile"
+	"github.com/barab-i/incipio/internal/stats"
 	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"go.uber.org/zap"
 )
 
-// PluginManager manages registered plugins.
+// ErrKeywordTaken is returned when registering a plugin typed with a keyword
+// of another plugin, such as one given to it by SetKeywords.
+var ErrKeywordTaken = errors.New("keyword already taken")
+
+// PluginManager manages registered plugins. It is safe for concurrent use, so
+// plugins can be registered and unregistered while queries run in commands.
 type PluginManager struct {
+	mu                      sync.RWMutex
 	plugins                 map[string]plugin.Plugin
 	disabledPluginsMetadata map[string]plugin.Metadata
+	disabledPlugins         map[string]plugin.Plugin // Instances of disabled plugins, to enable at runtime.
 	defaultPlugin           plugin.Plugin
 	activePlugin            plugin.Plugin
 	sortedKeywords          []string
+	maxResults              int               // Zero means no limit.
+	sources                 map[string]string // Keyword of the plugin loaded from each source file.
+	history                 *History          // Nil when history is not recorded.
+	stats                   *stats.Store      // Nil when usage is not recorded.
+	theme                   *theme.Handle     // Passed to plugins implementing plugin.Themed.
+	initialized             bool              // InitPlugins ran.
+
+	typedKeywords    map[string]string   // Keyword of the plugin each typed keyword activates; sortedKeywords lists them, longest first.
+	pluginKeywords   map[string][]string // Typed keywords of each plugin, longest first.
+	keywordOverrides map[string][]string // Typed keywords by plugin flag or keyword; see SetKeywords.
+
+	matchOrder []string    // Flags or keywords of the plugin.Matcher plugins consulted, in order; nil for all.
+	aggregate  *aggregator // Stands in for the default plugin; nil unless SetAggregate was called.
+
+	limits map[string]PluginLimits // Limits by plugin flag or keyword; see SetPluginLimits.
+
+	bus     *bus     // Passes events between plugins; see plugin.Publisher.
+	crashes *crashes // Panics of plugin calls; see recoverPanic.
+
+	profiler *profile.Profiler // Times queries when set; see SetProfiler.
+	focusing bool              // The launcher is shown; see Focus.
+	focused  plugin.Plugin     // Plugin whose OnFocus was called last, until its OnBlur.
+
+	intentRouting  bool   // Route queries without a keyword by their intent.
+	intent         Intent // Intent the active plugin was routed by, if any.
+	intentOverride bool   // Skip routing until the query is cleared.
 }
 
 // NewPluginManager creates a new PluginManager.
@@ -24,12 +66,82 @@ func NewPluginManager() *PluginManager {
 	return &PluginManager{
 		plugins:                 make(map[string]plugin.Plugin),
 		disabledPluginsMetadata: make(map[string]plugin.Metadata),
+		disabledPlugins:         make(map[string]plugin.Plugin),
 		sortedKeywords:          make([]string, 0),
+		typedKeywords:           make(map[string]string),
+		pluginKeywords:          make(map[string][]string),
+		sources:                 make(map[string]string),
+		theme:                   theme.Default(),
+		bus:                     newBus(),
+		crashes:                 newCrashes(),
+	}
+}
+
+// SetKeywords replaces the keywords typed to activate plugins, by plugin flag
+// or keyword, e.g. {"wikipedia": ["!wiki"]}. A plugin keeps its own keyword
+// only if listed. Plugins remain known by their own keyword elsewhere, such
+// as in plugin_keybindings and the history. It applies to plugins registered
+// afterwards.
+func (pm *PluginManager) SetKeywords(keywords map[string][]string) error {
+	owners := make(map[string]string)
+	for name, typed := range keywords {
+		if len(typed) == 0 {
+			return fmt.Errorf("no keywords given for plugin '%s'", name)
+		}
+		for _, keyword := range typed {
+			if keyword == "" || strings.ContainsAny(keyword, " \t") {
+				return fmt.Errorf("invalid keyword '%s' for plugin '%s': keywords cannot be empty or contain spaces", keyword, name)
+			}
+			if owner, taken := owners[keyword]; taken && owner != name {
+				return fmt.Errorf("keyword '%s' is given to both '%s' and '%s'", keyword, owner, name)
+			}
+			owners[keyword] = name
+		}
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.keywordOverrides = keywords
+	return nil
+}
+
+// keywordsFor returns the keywords typed to activate the plugin of metadata,
+// longest first.
+func (pm *PluginManager) keywordsFor(metadata plugin.Metadata) []string {
+	typed, ok := pm.keywordOverrides[metadata.Keyword]
+	if !ok && metadata.Flag != "" {
+		typed, ok = pm.keywordOverrides[metadata.Flag]
+	}
+	if !ok {
+		return []string{metadata.Keyword}
+	}
+	typed = slices.Clone(typed)
+	sort.SliceStable(typed, func(i, j int) bool { return len(typed[i]) > len(typed[j]) })
+	return slices.Compact(typed)
+}
+
+// Keywords returns the keywords typed to activate the enabled or disabled
+// plugin known by keyword, longest first.
+func (pm *PluginManager) Keywords(keyword string) []string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	if typed, ok := pm.pluginKeywords[keyword]; ok {
+		return slices.Clone(typed)
 	}
+	if metadata, ok := pm.disabledPluginsMetadata[keyword]; ok {
+		return pm.keywordsFor(metadata)
+	}
+	return []string{keyword}
 }
 
-// RegisterPlugin adds an enabled plugin.
+// RegisterPlugin adds an enabled plugin. It fails if the plugin's keyword, or
+// a keyword it is typed with, is taken by another plugin.
 func (pm *PluginManager) RegisterPlugin(p plugin.Plugin) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.registerPluginLocked(p)
+}
+
+func (pm *PluginManager) registerPluginLocked(p plugin.Plugin) error {
 	metadata := p.Metadata()
 	keyword := metadata.Keyword
 
@@ -39,19 +151,35 @@ func (pm *PluginManager) RegisterPlugin(p plugin.Plugin) error {
 	if _, exists := pm.plugins[keyword]; exists {
 		return fmt.Errorf("plugin keyword '%s' from plugin '%s' is already registered", keyword, metadata.Name)
 	}
+	typed := pm.keywordsFor(metadata)
+	for _, t := range typed {
+		if owner, taken := pm.typedKeywords[t]; taken {
+			return fmt.Errorf("%w: '%s' of plugin '%s' is used by plugin '%s'", ErrKeywordTaken, t, metadata.Name, pm.plugins[owner].Name())
+		}
+	}
 
 	pm.plugins[keyword] = p
+	pm.crashes.forget(keyword)
+	if themed, ok := optionalInterface[plugin.Themed](p); ok {
+		themed.SetTheme(pm.theme.For(keyword))
+	}
+	if publisher, ok := optionalInterface[plugin.Publisher](p); ok {
+		publisher.SetPublisher(pm.bus.publisher(keyword))
+	}
 	zap.L().Info("Registered plugin",
 		zap.String("name", metadata.Name),
 		zap.String("keyword", keyword),
+		zap.Strings("typedKeywords", typed),
 		zap.Bool("isDefault", metadata.IsDefault))
 
-	if keyword != "" { // Default plugin might have an empty keyword conceptually, but metadata should have it.
-		pm.sortedKeywords = append(pm.sortedKeywords, keyword)
-		sort.Slice(pm.sortedKeywords, func(i, j int) bool {
-			return len(pm.sortedKeywords[i]) > len(pm.sortedKeywords[j])
-		})
+	pm.pluginKeywords[keyword] = typed
+	for _, t := range typed {
+		pm.typedKeywords[t] = keyword
+		pm.sortedKeywords = append(pm.sortedKeywords, t)
 	}
+	sort.Slice(pm.sortedKeywords, func(i, j int) bool {
+		return len(pm.sortedKeywords[i]) > len(pm.sortedKeywords[j])
+	})
 
 	if metadata.IsDefault {
 		if pm.defaultPlugin != nil {
@@ -70,6 +198,12 @@ func (pm *PluginManager) RegisterPlugin(p plugin.Plugin) error {
 
 // RegisterMetadata stores metadata for disabled plugins.
 func (pm *PluginManager) RegisterMetadata(metadata plugin.Metadata) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.registerMetadataLocked(metadata)
+}
+
+func (pm *PluginManager) registerMetadataLocked(metadata plugin.Metadata) error {
 	if metadata.Keyword == "" {
 		return fmt.Errorf("plugin metadata '%s' has an empty keyword", metadata.Name)
 	}
@@ -92,8 +226,221 @@ func (pm *PluginManager) RegisterMetadata(metadata plugin.Metadata) error {
 	return nil
 }
 
-// DetermineActivePlugin selects the active plugin based on the query.
+// RegisterDisabledPlugin stores a disabled plugin, listed by its metadata
+// until EnablePlugin registers it.
+func (pm *PluginManager) RegisterDisabledPlugin(p plugin.Plugin) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.registerDisabledPluginLocked(p)
+}
+
+func (pm *PluginManager) registerDisabledPluginLocked(p plugin.Plugin) error {
+	metadata := p.Metadata()
+	if err := pm.registerMetadataLocked(metadata); err != nil {
+		return err
+	}
+	if _, enabled := pm.plugins[metadata.Keyword]; !enabled {
+		pm.disabledPlugins[metadata.Keyword] = p
+	}
+	return nil
+}
+
+// EnablePlugin registers the disabled plugin with the given keyword and
+// returns it. The caller is responsible for calling its Init.
+func (pm *PluginManager) EnablePlugin(keyword string) (plugin.Plugin, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	p, ok := pm.disabledPlugins[keyword]
+	if !ok {
+		if _, enabled := pm.plugins[keyword]; enabled {
+			return nil, fmt.Errorf("plugin '%s' is already enabled", keyword)
+		}
+		return nil, fmt.Errorf("no disabled plugin with keyword '%s'", keyword)
+	}
+	if err := pm.registerPluginLocked(p); err != nil {
+		return nil, err
+	}
+	delete(pm.disabledPlugins, keyword)
+	delete(pm.disabledPluginsMetadata, keyword)
+	if pm.activePlugin == nil {
+		pm.activePlugin = pm.defaultPlugin
+	}
+	return p, nil
+}
+
+// DisablePlugin unregisters the optional plugin with the given keyword and
+// keeps it, so that EnablePlugin can register it again. Mandatory plugins and
+// the default plugin cannot be disabled.
+func (pm *PluginManager) DisablePlugin(keyword string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	p, ok := pm.plugins[keyword]
+	if !ok {
+		return fmt.Errorf("no plugin registered with keyword '%s'", keyword)
+	}
+	if p.Metadata().IsMandatory {
+		return fmt.Errorf("plugin '%s' is mandatory", p.Name())
+	}
+	if pm.isDefault(p) {
+		return fmt.Errorf("plugin '%s' is the default plugin", p.Name())
+	}
+	if _, err := pm.unregisterPluginLocked(keyword); err != nil {
+		return err
+	}
+	return pm.registerDisabledPluginLocked(p)
+}
+
+// IsEnabled reports whether a plugin with the given keyword is registered.
+func (pm *PluginManager) IsEnabled(keyword string) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	_, ok := pm.plugins[keyword]
+	return ok
+}
+
+// UnregisterPlugin removes the enabled plugin with the given keyword. If it was
+// active, the default plugin becomes active. If it was the default plugin, no
+// plugin is the default until one marked as default is registered.
+func (pm *PluginManager) UnregisterPlugin(keyword string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	_, err := pm.unregisterPluginLocked(keyword)
+	return err
+}
+
+func (pm *PluginManager) unregisterPluginLocked(keyword string) (plugin.Plugin, error) {
+	p, exists := pm.plugins[keyword]
+	if !exists {
+		return nil, fmt.Errorf("no plugin registered with keyword '%s'", keyword)
+	}
+
+	delete(pm.plugins, keyword)
+	for _, t := range pm.pluginKeywords[keyword] {
+		delete(pm.typedKeywords, t)
+	}
+	delete(pm.pluginKeywords, keyword)
+	pm.sortedKeywords = slices.DeleteFunc(pm.sortedKeywords, func(k string) bool {
+		_, typed := pm.typedKeywords[k]
+		return !typed
+	})
+	if pm.defaultPlugin != nil && pm.defaultPlugin.Keyword() == keyword {
+		pm.defaultPlugin = nil
+	}
+	if pm.activePlugin != nil && pm.activePlugin.Keyword() == keyword {
+		pm.activePlugin = pm.defaultPlugin
+	}
+	zap.L().Info("Unregistered plugin", zap.String("name", p.Name()), zap.String("keyword", keyword))
+	return p, nil
+}
+
+// SetPluginSource records the file a registered plugin, or disabled plugin
+// metadata, was loaded from, so that ReloadPlugin can replace it later.
+func (pm *PluginManager) SetPluginSource(source, keyword string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.sources[source] = keyword
+}
+
+// ReloadPlugin replaces the plugin previously loaded from source with p. Enabled
+// plugins are registered, disabled ones only have their metadata recorded, and a
+// nil p removes the plugin of a deleted source. If p has the keyword of the
+// replaced plugin, it keeps that plugin's default and active status, and its
+// enabled status, which EnablePlugin or DisablePlugin may have changed since
+// it was loaded; use IsEnabled to learn the status p got. If p cannot be registered,
+// the previous plugin is restored and the error returned.
+// The caller is responsible for calling p.Init.
+func (pm *PluginManager) ReloadPlugin(source string, p plugin.Plugin, enabled bool) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	var previous, previousDisabled plugin.Plugin
+	var previousMetadata *plugin.Metadata
+	wasDefault, wasActive := false, false
+
+	if keyword, ok := pm.sources[source]; ok {
+		delete(pm.sources, source)
+		if _, registered := pm.plugins[keyword]; registered {
+			wasDefault = pm.defaultPlugin != nil && pm.defaultPlugin.Keyword() == keyword
+			wasActive = pm.activePlugin != nil && pm.activePlugin.Keyword() == keyword
+			previous, _ = pm.unregisterPluginLocked(keyword)
+		} else if metadata, disabled := pm.disabledPluginsMetadata[keyword]; disabled {
+			previousMetadata = &metadata
+			previousDisabled = pm.disabledPlugins[keyword]
+			delete(pm.disabledPluginsMetadata, keyword)
+			delete(pm.disabledPlugins, keyword)
+		}
+	}
+	if p == nil {
+		return nil
+	}
+
+	keyword := p.Keyword()
+	if previous != nil && previous.Keyword() == keyword {
+		enabled = true
+	} else if previousMetadata != nil && previousMetadata.Keyword == keyword {
+		enabled = p.Metadata().IsMandatory
+	}
+	var err error
+	if _, taken := pm.disabledPluginsMetadata[keyword]; taken {
+		err = fmt.Errorf("plugin keyword '%s' is already used by disabled plugin '%s'", keyword, pm.disabledPluginsMetadata[keyword].Name)
+	} else if enabled {
+		err = pm.registerPluginLocked(p)
+	} else {
+		err = pm.registerDisabledPluginLocked(p)
+	}
+	if err != nil {
+		// Restore what was loaded from this source before.
+		if previous != nil {
+			_ = pm.registerPluginLocked(previous)
+			pm.sources[source] = previous.Keyword()
+			if wasDefault {
+				pm.defaultPlugin = previous
+			}
+			if wasActive {
+				pm.activePlugin = previous
+			}
+		} else if previousMetadata != nil {
+			pm.disabledPluginsMetadata[previousMetadata.Keyword] = *previousMetadata
+			if previousDisabled != nil {
+				pm.disabledPlugins[previousMetadata.Keyword] = previousDisabled
+			}
+			pm.sources[source] = previousMetadata.Keyword
+		}
+		return err
+	}
+
+	pm.sources[source] = keyword
+	if enabled && previous != nil && previous.Keyword() == keyword {
+		if wasDefault {
+			pm.defaultPlugin = p
+		}
+		if wasActive {
+			pm.activePlugin = p
+		}
+	}
+	if pm.activePlugin == nil {
+		pm.activePlugin = pm.defaultPlugin
+	}
+	return nil
+}
+
+// DetermineActivePlugin selects the active plugin based on the query. While
+// the launcher is shown, the plugin it replaces gets OnBlur and the new one
+// OnFocus; see plugin.Focuser.
 func (pm *PluginManager) DetermineActivePlugin(query string) (plugin.Plugin, bool) {
+	active, switched := pm.determineActivePlugin(query)
+	if switched {
+		pm.refocus()
+	}
+	return active, switched
+}
+
+func (pm *PluginManager) determineActivePlugin(query string) (plugin.Plugin, bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
 	trimmedQuery := strings.TrimSpace(query)
 	currentActiveKeyword := ""
 	if pm.activePlugin != nil {
@@ -101,18 +448,37 @@ func (pm *PluginManager) DetermineActivePlugin(query string) (plugin.Plugin, boo
 	}
 
 	determinedPlugin := pm.defaultPlugin
+	matchedKeyword := false
 
 	for _, keyword := range pm.sortedKeywords {
 		if keyword != "" && strings.HasPrefix(trimmedQuery, keyword) {
 			if len(trimmedQuery) == len(keyword) || (len(trimmedQuery) > len(keyword) && trimmedQuery[len(keyword)] == ' ') {
-				if p, found := pm.plugins[keyword]; found {
+				if p, found := pm.plugins[pm.typedKeywords[keyword]]; found {
 					determinedPlugin = p
+					matchedKeyword = true
 					break
 				}
 			}
 		}
 	}
 
+	if trimmedQuery == "" {
+		pm.intentOverride = false
+	}
+	pm.intent = IntentNone
+	if !pm.intentOverride && !matchedKeyword && trimmedQuery != "" {
+		if p := pm.matchingPluginLocked(trimmedQuery); p != nil {
+			determinedPlugin = p
+			pm.intent = IntentMatch
+		} else if pm.intentRouting {
+			intent := classifyIntent(trimmedQuery)
+			if p, found := pm.plugins[intentKeywords[intent]]; found {
+				determinedPlugin = p
+				pm.intent = intent
+			}
+		}
+	}
+
 	determinedKeyword := ""
 	if determinedPlugin != nil {
 		determinedKeyword = determinedPlugin.Keyword()
@@ -129,54 +495,506 @@ func (pm *PluginManager) DetermineActivePlugin(query string) (plugin.Plugin, boo
 	return pm.activePlugin, switched
 }
 
-// GetCurrentPlugin returns the active plugin.
+// SetMatchOrder sets the plugins implementing plugin.Matcher that may claim
+// queries typed without a keyword, by flag or keyword, in order of priority.
+// Plugins not listed claim none. With a nil order, all do, by keyword.
+func (pm *PluginManager) SetMatchOrder(order []string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.matchOrder = order
+}
+
+// matchingPluginLocked returns the first enabled plugin claiming query as a
+// plugin.Matcher, or nil. pm.mu must be held.
+func (pm *PluginManager) matchingPluginLocked(query string) plugin.Plugin {
+	candidates := make([]plugin.Plugin, 0, len(pm.plugins))
+	if pm.matchOrder == nil {
+		for _, keyword := range slices.Sorted(maps.Keys(pm.plugins)) {
+			candidates = append(candidates, pm.plugins[keyword])
+		}
+	} else {
+		for _, name := range pm.matchOrder {
+			if owner, typed := pm.typedKeywords[name]; typed {
+				name = owner
+			}
+			for keyword, p := range pm.plugins {
+				if keyword == name || (name != "" && p.Metadata().Flag == name) {
+					candidates = append(candidates, p)
+				}
+			}
+		}
+	}
+	for _, p := range candidates {
+		if matcher, ok := optionalInterface[plugin.Matcher](p); ok && !pm.isDefault(p) && matcher.Matches(query) {
+			return p
+		}
+	}
+	return nil
+}
+
+// SetIntentRouting enables or disables routing queries typed without a keyword
+// to the plugin handling their intent; see classifyIntent.
+func (pm *PluginManager) SetIntentRouting(enabled bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.intentRouting = enabled
+}
+
+// Intent returns the intent the active plugin was routed by, or IntentNone
+// when it was selected by keyword or is the default plugin.
+func (pm *PluginManager) Intent() Intent {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.intent
+}
+
+// ToggleIntentOverride makes queries skip intent routing, or routes them again.
+// The override ends when the query is cleared. It reports whether routing is
+// now overridden; the caller re-runs DetermineActivePlugin.
+func (pm *PluginManager) ToggleIntentOverride() bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.intentOverride = !pm.intentOverride
+	return pm.intentOverride
+}
+
+// GetCurrentPlugin returns the active plugin. With aggregation set up, the
+// aggregator stands in for the default plugin.
 func (pm *PluginManager) GetCurrentPlugin() plugin.Plugin {
+	pm.mu.RLock()
+	active := pm.currentPluginLocked()
+	aggregate := pm.aggregateForLocked(active)
+	pm.mu.RUnlock()
+	if aggregate != nil {
+		return aggregate
+	}
+	return active
+}
+
+// SetAggregate makes queries typed without a keyword go to several plugins,
+// by flag or keyword, listing the first limit results of each under the
+// plugin's name. The default plugin comes first unless listed elsewhere. No
+// plugins turns aggregation off; limit defaults to DefaultAggregateLimit.
+func (pm *PluginManager) SetAggregate(plugins []string, limit int) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if len(plugins) == 0 {
+		pm.aggregate = nil
+		return
+	}
+	if limit <= 0 {
+		limit = DefaultAggregateLimit
+	}
+	pm.aggregate = &aggregator{pm: pm, members: plugins, limit: limit}
+}
+
+// aggregateForLocked returns the aggregator if it stands in for active, the
+// default plugin, or nil. Its methods lock pm.mu, so it must be used once
+// pm.mu is released.
+func (pm *PluginManager) aggregateForLocked(active plugin.Plugin) *aggregator {
+	if pm.aggregate == nil || active == nil || !pm.isDefault(active) {
+		return nil
+	}
+	return pm.aggregate
+}
+
+func (pm *PluginManager) currentPluginLocked() plugin.Plugin {
 	if pm.activePlugin == nil {
 		return pm.defaultPlugin
 	}
 	return pm.activePlugin
 }
 
-// GetResults retrieves results from the active plugin.
-func (pm *PluginManager) GetResults(query string) ([]plugin.Result, error) {
-	active := pm.GetCurrentPlugin()
+// GetResults retrieves results from the active plugin. ctx is passed to
+// plugins implementing plugin.ContextQuerier; other plugins run to completion.
+func (pm *PluginManager) GetResults(ctx context.Context, query string) ([]plugin.Result, error) {
+	pm.mu.RLock()
+	active := pm.currentPluginLocked()
+	isDefault := active != nil && pm.isDefault(active)
+	keywords := pm.keywordsOfLocked(active)
+	aggregate := pm.aggregateForLocked(active)
+	limits := pm.limitsLocked(active)
+	maxResults := pm.maxResults
+	pm.mu.RUnlock()
 	if active == nil {
 		return nil, fmt.Errorf("no active plugin available to handle query")
 	}
+	if aggregate != nil {
+		// The aggregated plugins are limited one by one.
+		active, limits = aggregate, PluginLimits{MaxResults: maxResults}
+	}
 
-	pluginQuery := query
-	trimmedQuery := strings.TrimSpace(query)
-	activeKeyword := active.Keyword()
+	results, err := queryWithTimeout(ctx, active.Name(), limits.Timeout, func(ctx context.Context) (_ []plugin.Result, err error) {
+		defer pm.recoverPanic(active, "GetResults", &err)
+		if aggregate == nil {
+			defer pm.observeQuery(active, "GetResults", time.Now())
+		}
+		if querier, ok := optionalInterface[plugin.ContextQuerier](active); ok {
+			return querier.GetResultsContext(ctx, pluginQuery(query, keywords, isDefault))
+		}
+		return active.GetResults(pluginQuery(query, keywords, isDefault))
+	})
+	if limits.MaxResults > 0 && len(results) > limits.MaxResults {
+		results = results[:limits.MaxResults]
+	}
+	return results, err
+}
+
+// StreamResults streams results from the active plugin if it implements
+// plugin.ResultStreamer, and reports false without calling send otherwise.
+// Batches beyond the results cap are dropped.
+func (pm *PluginManager) StreamResults(ctx context.Context, query string, send func([]plugin.Result)) (bool, error) {
+	pm.mu.RLock()
+	active := pm.currentPluginLocked()
+	isDefault := active != nil && pm.isDefault(active)
+	keywords := pm.keywordsOfLocked(active)
+	limits := pm.limitsLocked(active)
+	pm.mu.RUnlock()
+	streamer, ok := optionalInterface[plugin.ResultStreamer](active)
+	if !ok {
+		return false, nil
+	}
+
+	_, err := queryWithTimeout(ctx, active.Name(), limits.Timeout, func(ctx context.Context) (_ []plugin.Result, err error) {
+		defer pm.recoverPanic(active, "StreamResults", &err)
+		defer pm.observeQuery(active, "StreamResults", time.Now())
+		var mu sync.Mutex // Plugins may send from several goroutines.
+		sent := 0
+		return nil, streamer.StreamResults(ctx, pluginQuery(query, keywords, isDefault), func(results []plugin.Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			if limits.MaxResults > 0 {
+				results = results[:min(len(results), limits.MaxResults-sent)]
+			}
+			if len(results) == 0 || ctx.Err() != nil {
+				return
+			}
+			sent += len(results)
+			send(results)
+		})
+	})
+	return true, err
+}
+
+// PluginQuery returns the query as the active plugin receives it, without
+// its keyword.
+func (pm *PluginManager) PluginQuery(query string) string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	active := pm.currentPluginLocked()
+	if active == nil {
+		return query
+	}
+	return pluginQuery(query, pm.keywordsOfLocked(active), pm.isDefault(active))
+}
 
-	if !active.Metadata().IsDefault && activeKeyword != "" && strings.HasPrefix(trimmedQuery, activeKeyword) {
-		prefixLen := len(activeKeyword)
+// keywordsOfLocked returns the typed keywords of p, none if nil. pm.mu must be held.
+func (pm *PluginManager) keywordsOfLocked(p plugin.Plugin) []string {
+	if p == nil {
+		return nil
+	}
+	if typed, ok := pm.pluginKeywords[p.Keyword()]; ok {
+		return typed
+	}
+	return []string{p.Keyword()}
+}
+
+// pluginQuery strips the first of the plugin's keywords, longest first, that
+// starts the query. The default plugin receives the query unchanged.
+func pluginQuery(query string, keywords []string, isDefault bool) string {
+	if isDefault {
+		return query
+	}
+	trimmedQuery := strings.TrimSpace(query)
+	for _, keyword := range keywords {
+		if keyword == "" || !strings.HasPrefix(trimmedQuery, keyword) {
+			continue
+		}
+		prefixLen := len(keyword)
 		if len(trimmedQuery) > prefixLen && trimmedQuery[prefixLen] == ' ' {
-			pluginQuery = strings.TrimSpace(trimmedQuery[prefixLen+1:])
+			return strings.TrimSpace(trimmedQuery[prefixLen+1:])
 		} else if len(trimmedQuery) == prefixLen {
-			pluginQuery = ""
+			return ""
 		}
 	}
-	return active.GetResults(pluginQuery)
+	return query
 }
 
-// Execute delegates execution to the active plugin.
+// isDefault reports whether p is the current default plugin. pm.mu must be held.
+// Plugins are compared by keyword since yaegi plugin wrappers are not comparable.
+func (pm *PluginManager) isDefault(p plugin.Plugin) bool {
+	return pm.defaultPlugin != nil && p.Keyword() == pm.defaultPlugin.Keyword()
+}
+
+// SetProfiler makes the manager record how long plugins take to answer
+// queries, by call and keyword, e.g. "GetResults !a". Nil stops recording.
+func (pm *PluginManager) SetProfiler(p *profile.Profiler) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.profiler = p
+}
+
+// observeQuery records how long p took to answer a query from start, when profiling.
+func (pm *PluginManager) observeQuery(p plugin.Plugin, call string, start time.Time) {
+	pm.mu.RLock()
+	profiler := pm.profiler
+	pm.mu.RUnlock()
+	if profiler != nil {
+		profiler.Observe(call+" "+p.Keyword(), time.Since(start))
+	}
+}
+
+// SetMaxResults caps the number of results returned per query. Zero disables the cap.
+func (pm *PluginManager) SetMaxResults(n int) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.maxResults = max(n, 0)
+}
+
+// SetDefaultPlugin makes the registered plugin with the given keyword, typed
+// keyword or flag the default, overriding the IsDefault metadata of built-in plugins.
+func (pm *PluginManager) SetDefaultPlugin(keywordOrFlag string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if owner, typed := pm.typedKeywords[keywordOrFlag]; typed {
+		keywordOrFlag = owner
+	}
+	for keyword, p := range pm.plugins {
+		if keyword == keywordOrFlag || (keywordOrFlag != "" && p.Metadata().Flag == keywordOrFlag) {
+			wasActive := pm.activePlugin == nil || pm.isDefault(pm.activePlugin)
+			pm.defaultPlugin = p
+			if wasActive {
+				pm.activePlugin = p
+			}
+			zap.L().Info("Set default plugin", zap.String("name", p.Name()))
+			return nil
+		}
+	}
+	return fmt.Errorf("no enabled plugin with keyword or flag '%s'", keywordOrFlag)
+}
+
+// Execute delegates execution to the active plugin. A panic of the plugin,
+// or of the command it returns, is reported by a crashMsg.
 func (pm *PluginManager) Execute(identifier string) tea.Cmd {
 	active := pm.GetCurrentPlugin()
 	if active == nil {
 		zap.L().Warn("Execute called but no active plugin found", zap.String("identifier", identifier))
 		return nil
 	}
-	return active.Execute(identifier)
+	return pm.execute(active, "Execute", func() tea.Cmd { return active.Execute(identifier) })
+}
+
+// execute runs call of p, guarding it and the command it returns; see recoverPanic.
+func (pm *PluginManager) execute(p plugin.Plugin, name string, call func() tea.Cmd) (cmd tea.Cmd) {
+	var err error
+	defer func() {
+		var crash *PanicError
+		if errors.As(err, &crash) {
+			cmd = func() tea.Msg { return crashMsg{err: crash} }
+		}
+	}()
+	defer pm.recoverPanic(p, name, &err)
+	return pm.guardCmd(p, name, call())
+}
+
+// ExecuteBatch delegates executing several results at once to the active
+// plugin, which must implement plugin.BatchExecutor.
+func (pm *PluginManager) ExecuteBatch(identifiers []string) tea.Cmd {
+	active := pm.GetCurrentPlugin()
+	executor, ok := optionalInterface[plugin.BatchExecutor](active)
+	if !ok {
+		zap.L().Warn("ExecuteBatch called but the active plugin cannot execute batches", zap.Strings("identifiers", identifiers))
+		return nil
+	}
+	return pm.execute(active, "ExecuteBatch", func() tea.Cmd { return executor.ExecuteBatch(identifiers) })
+}
+
+// SetTheme sets the theme and passes it to every registered plugin implementing plugin.Themed.
+func (pm *PluginManager) SetTheme(h *theme.Handle) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.theme = h
+	for keyword, p := range pm.plugins {
+		if themed, ok := optionalInterface[plugin.Themed](p); ok {
+			themed.SetTheme(h.For(keyword))
+		}
+	}
+}
+
+// Theme returns the current theme.
+func (pm *PluginManager) Theme() *theme.Handle {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.theme
+}
+
+// SetHistory sets the history that executed selections are recorded in.
+func (pm *PluginManager) SetHistory(h *History) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.history = h
+}
+
+// History returns the selection history, or nil if none is set.
+func (pm *PluginManager) History() *History {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.history
+}
+
+// SetStats sets the store that usage statistics are recorded in.
+func (pm *PluginManager) SetStats(s *stats.Store) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.stats = s
+}
+
+// Stats returns the usage statistics, or nil if none are recorded.
+func (pm *PluginManager) Stats() *stats.Store {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.stats
+}
+
+// optionalInterface returns p as the optional interface T. Plugins loaded with
+// yaegi are wrapped in a type holding the optional interfaces they expose,
+// which are looked through, and wrappers are unwrapped until one implements T.
+func optionalInterface[T any](p plugin.Plugin) (T, bool) {
+	for p != nil {
+		if t, ok := p.(T); ok {
+			return t, true
+		}
+		if holder, ok := p.(interface{ OptionalInterfaces() []any }); ok {
+			for _, optional := range holder.OptionalInterfaces() {
+				if t, ok := optional.(T); ok {
+					return t, true
+				}
+			}
+		}
+		wrapper, ok := p.(interface{ Unwrap() plugin.Plugin })
+		if !ok {
+			break
+		}
+		p = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// Hydrate loads the full details of a lazy result from the active plugin.
+// Plugins that do not implement plugin.Hydrator return an error.
+func (pm *PluginManager) Hydrate(identifier string) (_ plugin.Result, err error) {
+	active := pm.GetCurrentPlugin()
+	if active == nil {
+		return plugin.Result{}, fmt.Errorf("no active plugin available to hydrate '%s'", identifier)
+	}
+	hydrator, ok := optionalInterface[plugin.Hydrator](active)
+	if !ok {
+		return plugin.Result{}, fmt.Errorf("plugin '%s' does not support lazy results", active.Name())
+	}
+	defer pm.recoverPanic(active, "Hydrate", &err)
+	return hydrator.Hydrate(identifier)
+}
+
+// Preview returns the preview of the result with the given identifier if p
+// implements plugin.Previewer, or "" if it does not or panics.
+func (pm *PluginManager) Preview(p plugin.Plugin, identifier string) string {
+	previewer, ok := optionalInterface[plugin.Previewer](p)
+	if !ok {
+		return ""
+	}
+	defer pm.recoverPanic(p, "Preview", nil)
+	return previewer.Preview(identifier)
+}
+
+// Focus calls OnFocus of the active plugin, once the launcher is shown. Until
+// Blur, plugins becoming active get OnFocus and those they replace OnBlur.
+func (pm *PluginManager) Focus() {
+	pm.mu.Lock()
+	pm.focusing = true
+	pm.mu.Unlock()
+	pm.refocus()
+}
+
+// Blur calls OnBlur of the focused plugin, once the launcher is hidden. Events
+// plugins publish meanwhile wait for the next model to run.
+func (pm *PluginManager) Blur() {
+	pm.mu.Lock()
+	pm.focusing = false
+	pm.mu.Unlock()
+	pm.refocus()
+	pm.bus.unlisten()
+}
+
+// refocus moves the focus to the active plugin, or the aggregator standing in
+// for it, while the launcher is shown and away from any plugin otherwise.
+func (pm *PluginManager) refocus() {
+	pm.mu.Lock()
+	previous := pm.focused
+	var next plugin.Plugin
+	if pm.focusing {
+		next = pm.currentPluginLocked()
+		if aggregate := pm.aggregateForLocked(next); aggregate != nil {
+			next = aggregate
+		}
+	}
+	if previous != nil && next != nil && previous.Keyword() == next.Keyword() {
+		pm.mu.Unlock()
+		return
+	}
+	pm.focused = next
+	pm.mu.Unlock()
+
+	pm.focus(previous, false)
+	pm.focus(next, true)
 }
 
-// InitPlugins initializes all registered plugins.
+// focus calls OnFocus of p, or OnBlur unless focused, if p implements
+// plugin.Focuser, recovering its panics.
+func (pm *PluginManager) focus(p plugin.Plugin, focused bool) {
+	focuser, ok := optionalInterface[plugin.Focuser](p)
+	if !ok {
+		return
+	}
+	if focused {
+		defer pm.recoverPanic(p, "OnFocus", nil)
+		focuser.OnFocus()
+		return
+	}
+	defer pm.recoverPanic(p, "OnBlur", nil)
+	focuser.OnBlur()
+}
+
+// Shutdown blurs the focused plugin and calls Shutdown of every enabled
+// plugin implementing plugin.Shutdowner, once the application quits.
+func (pm *PluginManager) Shutdown() {
+	pm.Blur()
+	plugins := pm.GetAllPlugins()
+	for _, keyword := range slices.Sorted(maps.Keys(plugins)) {
+		if shutdowner, ok := optionalInterface[plugin.Shutdowner](plugins[keyword]); ok {
+			shutdowner.Shutdown()
+		}
+	}
+}
+
+// InitPlugins initializes all registered plugins. Only the first call does, so
+// the models of a resident daemon share the state the plugins loaded.
 func (pm *PluginManager) InitPlugins() tea.Cmd {
+	pm.mu.Lock()
+	initialized := pm.initialized
+	pm.initialized = true
+	pm.mu.Unlock()
+	if initialized {
+		return nil
+	}
+
 	var cmds []tea.Cmd
 	initializedKeywords := make(map[string]bool)
 
-	if pm.defaultPlugin != nil {
-		keyword := pm.defaultPlugin.Keyword()
-		if cmd := pm.defaultPlugin.Init(); cmd != nil {
+	defaultPlugin := pm.GetDefaultPlugin()
+	if defaultPlugin != nil {
+		keyword := defaultPlugin.Keyword()
+		if cmd := pm.InitPlugin(defaultPlugin); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
 		if keyword != "" {
@@ -184,9 +1002,9 @@ func (pm *PluginManager) InitPlugins() tea.Cmd {
 		}
 	}
 
-	for keyword, p := range pm.plugins {
+	for keyword, p := range pm.GetAllPlugins() {
 		if _, alreadyInitialized := initializedKeywords[keyword]; !alreadyInitialized {
-			if cmd := p.Init(); cmd != nil {
+			if cmd := pm.InitPlugin(p); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
 		}
@@ -198,19 +1016,30 @@ func (pm *PluginManager) InitPlugins() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-// GetAllPlugins returns all enabled plugins.
+// InitPlugin calls Init of p, guarding it and the command it returns.
+func (pm *PluginManager) InitPlugin(p plugin.Plugin) tea.Cmd {
+	return pm.execute(p, "Init", p.Init)
+}
+
+// GetAllPlugins returns a snapshot of all enabled plugins by keyword.
 func (pm *PluginManager) GetAllPlugins() map[string]plugin.Plugin {
-	return pm.plugins
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return maps.Clone(pm.plugins)
 }
 
 // GetDefaultPlugin returns the default plugin.
 func (pm *PluginManager) GetDefaultPlugin() plugin.Plugin {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
 	return pm.defaultPlugin
 }
 
-// GetAllDisabledPluginsMetadatas returns metadata for all disabled plugins.
+// GetAllDisabledPluginsMetadatas returns a snapshot of the metadata of all disabled plugins.
 func (pm *PluginManager) GetAllDisabledPluginsMetadatas() map[string]plugin.Metadata {
-	return pm.disabledPluginsMetadata
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return maps.Clone(pm.disabledPluginsMetadata)
 }
 
 // UpdatePluginInstance updates a registered plugin instance.
@@ -220,6 +1049,9 @@ func (pm *PluginManager) UpdatePluginInstance(updatedPlugin plugin.Plugin) {
 		zap.L().Warn("Attempted to update with a nil plugin instance")
 		return
 	}
+	if _, ok := updatedPlugin.(*aggregator); ok {
+		return // The aggregator updates the plugins it passes messages to.
+	}
 
 	keyword := updatedPlugin.Keyword()
 	if keyword == "" {
@@ -227,6 +1059,9 @@ func (pm *PluginManager) UpdatePluginInstance(updatedPlugin plugin.Plugin) {
 		return
 	}
 
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
 	if _, exists := pm.plugins[keyword]; !exists {
 		// This typically means an attempt to update a plugin that isn't registered.
 		// Depending on desired behavior, this could be an error or a silent return.
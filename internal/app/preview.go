@@ -0,0 +1,118 @@
+package app
+
+import (
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// minPreviewWidth is the narrowest list area the preview pane splits.
+	minPreviewWidth = 60
+	// previewListShare is the percentage of the list area kept by the list.
+	previewListShare = 45
+)
+
+// previewMsg carries the result of a plugin.Previewer call.
+type previewMsg struct {
+	keyword    string
+	identifier string
+	content    string
+}
+
+// previewPane holds the preview of the highlighted result.
+type previewPane struct {
+	keyword    string // Keyword of the plugin previewing.
+	identifier string // Result previewed, or requested when loading.
+	content    string
+	loading    bool
+}
+
+// togglePreview shows or hides the preview pane. Update lays it out.
+func (m *model) togglePreview() {
+	m.previewOn = !m.previewOn
+	m.preview = previewPane{}
+}
+
+// previewer returns the active plugin if it implements plugin.Previewer and
+// the pane is shown.
+// Plugins rendering their own view and narrow terminals get no pane.
+func (m model) previewer() (plugin.Plugin, bool) {
+	if !m.previewOn || m.showHelp || m.streamShown() || m.fullListWidth < minPreviewWidth {
+		return nil, false
+	}
+	active := m.pluginManager.GetCurrentPlugin()
+	if active == nil || m.pluginManager.PluginView(active) != "" {
+		return nil, false
+	}
+	_, ok := optionalInterface[plugin.Previewer](active)
+	return active, ok
+}
+
+// previewShown reports whether the list shares its area with the preview pane.
+func (m model) previewShown() bool {
+	_, ok := m.previewer()
+	return ok
+}
+
+// syncPreview fits the list next to the preview pane, or to the whole area
+// without it, and requests the preview of the highlighted result if it changed.
+func (m *model) syncPreview() tea.Cmd {
+	previewer, shown := m.previewer()
+
+	width := m.fullListWidth
+	if shown {
+		width = m.fullListWidth * previewListShare / 100
+	}
+	if m.list.Width() != width {
+		m.list.SetWidth(width)
+		m.fitGrid()
+		m.resizeStream()
+	}
+	if !shown {
+		return nil
+	}
+
+	li, ok := m.list.SelectedItem().(listItem)
+	if !ok {
+		m.preview = previewPane{}
+		return nil
+	}
+	keyword := m.activeKeyword()
+	if m.preview.keyword == keyword && m.preview.identifier == li.identifier {
+		return nil
+	}
+
+	m.preview = previewPane{keyword: keyword, identifier: li.identifier, loading: true}
+	identifier := li.identifier
+	pm := m.pluginManager
+	return func() tea.Msg {
+		return previewMsg{keyword: keyword, identifier: identifier, content: pm.Preview(previewer, identifier)}
+	}
+}
+
+// applyPreview shows a preview unless another result was highlighted since.
+func (m *model) applyPreview(msg previewMsg) {
+	if msg.keyword != m.preview.keyword || msg.identifier != m.preview.identifier {
+		return
+	}
+	m.preview.content = msg.content
+	m.preview.loading = false
+}
+
+// renderPreview renders the list and the preview pane side by side.
+func (m model) renderPreview() string {
+	// Width sets the size inside the border, padding included.
+	width := max(1, m.fullListWidth-m.list.Width()-previewStyle.GetHorizontalBorderSize())
+	content := m.preview.content
+	if m.preview.loading {
+		content = descStyle.UnsetPaddingLeft().Render("Loading preview…")
+	}
+	pane := previewStyle.
+		Width(width).
+		Height(max(1, m.listHeight)).
+		MaxHeight(max(1, m.listHeight)).
+		Render(content)
+	list := lipgloss.NewStyle().Width(m.list.Width()).Render(m.listView())
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, pane)
+}
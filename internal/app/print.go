@@ -0,0 +1,20 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// printSelection hands the marked results, or the selected one without marks,
+// to the Print option and quits instead of executing them.
+func (m *model) printSelection(selected listItem) tea.Cmd {
+	items := m.marks
+	if len(items) == 0 {
+		items = []listItem{selected}
+	}
+	keyword := m.activeKeyword()
+	for _, li := range items {
+		m.printResult(keyword, li.result())
+	}
+	m.quitting = true
+	return tea.Quit
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inputView renders the text input. While a plugin is activated by its
+// keyword, a badge with the plugin's name is shown left of the prompt, and
+// the keyword is left out of the visible query once followed by a space.
+// The input's value keeps the keyword, so deleting back past the start of the
+// visible query brings the keyword back for editing.
+func (m model) inputView() string {
+	keyword, name, ok := m.keywordPlugin()
+	if !ok {
+		return m.textInput.View()
+	}
+
+	ti := m.textInput
+	value := ti.Value()
+	if rest, found := strings.CutPrefix(strings.TrimLeft(value, " "), keyword+" "); found {
+		hidden := utf8.RuneCountInString(value) - utf8.RuneCountInString(rest)
+		pos := ti.Position()
+		ti.SetValue(rest)
+		ti.SetCursor(max(0, pos-hidden))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, inputBadgeStyle.Render(name), " ", ti.View())
+}
+
+// keywordPlugin returns the keyword typed and the name of the active plugin
+// if the query activates it by one of its keywords, rather than as the
+// default plugin or by intent.
+func (m model) keywordPlugin() (keyword, name string, ok bool) {
+	active := m.pluginManager.GetCurrentPlugin()
+	if active == nil || active.Keyword() == "" {
+		return "", "", false
+	}
+	trimmed := strings.TrimSpace(m.textInput.Value())
+	for _, keyword := range m.pluginManager.Keywords(active.Keyword()) {
+		if trimmed == keyword || strings.HasPrefix(trimmed, keyword+" ") {
+			return keyword, active.Name(), true
+		}
+	}
+	return "", "", false
+}
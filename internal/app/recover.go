@@ -0,0 +1,202 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"runtime/debug"
+	"slices"
+	"sync"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxPanics is how many times in a row a plugin call may panic before
+// the plugin is disabled, unless SetMaxPanics says otherwise.
+const DefaultMaxPanics = 3
+
+// PanicError reports a plugin call that panicked. The launcher recovers from
+// it and carries on without what the call would have returned.
+type PanicError struct {
+	Plugin   string // Name of the plugin.
+	Call     string // Method that panicked, e.g. "GetResults".
+	Value    any    // Value passed to panic.
+	Disabled bool   // The plugin was disabled for panicking too often.
+}
+
+func (e *PanicError) Error() string {
+	msg := fmt.Sprintf("plugin '%s' crashed in %s: %v", e.Plugin, e.Call, e.Value)
+	if e.Disabled {
+		msg += " (disabled after crashing repeatedly)"
+	}
+	return msg
+}
+
+type crashKey struct {
+	keyword, call string
+}
+
+// crashes tracks the panics of plugins.
+type crashes struct {
+	mu     sync.Mutex
+	max    int                    // Panics in a row disabling a plugin; zero never does.
+	counts map[crashKey]int       // Panics in a row, by plugin keyword and call.
+	errs   map[string]*PanicError // Latest panic of each plugin, by keyword, until the call succeeds.
+}
+
+func newCrashes() *crashes {
+	return &crashes{max: DefaultMaxPanics, counts: make(map[crashKey]int), errs: make(map[string]*PanicError)}
+}
+
+// panicked counts a panic of the call and reports whether the plugin panicked
+// in it too often.
+func (c *crashes) panicked(keyword, call string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := crashKey{keyword, call}
+	c.counts[key]++
+	return c.max > 0 && c.counts[key] >= c.max
+}
+
+func (c *crashes) record(keyword string, err *PanicError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs[keyword] = err
+}
+
+// succeeded resets the panics of the call, dropping the plugin's error if the
+// same call panicked last.
+func (c *crashes) succeeded(keyword, call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := crashKey{keyword, call}
+	if c.counts[key] == 0 {
+		return
+	}
+	delete(c.counts, key)
+	if err, ok := c.errs[keyword]; ok && err.Call == call {
+		delete(c.errs, keyword)
+	}
+}
+
+// forget drops what is known of the panics of the plugin, such as once
+// another instance of it is registered.
+func (c *crashes) forget(keyword string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.counts {
+		if key.keyword == keyword {
+			delete(c.counts, key)
+		}
+	}
+	delete(c.errs, keyword)
+}
+
+// SetMaxPanics sets how many times in a row a call of an optional plugin may
+// panic before the plugin is disabled. Zero never disables plugins.
+func (pm *PluginManager) SetMaxPanics(n int) {
+	pm.crashes.mu.Lock()
+	defer pm.crashes.mu.Unlock()
+	pm.crashes.max = max(n, 0)
+}
+
+// PanicErrors returns the latest panic of each plugin whose call has not
+// succeeded since, ordered by plugin keyword.
+func (pm *PluginManager) PanicErrors() []error {
+	pm.crashes.mu.Lock()
+	defer pm.crashes.mu.Unlock()
+	var errs []error
+	for _, keyword := range slices.Sorted(maps.Keys(pm.crashes.errs)) {
+		errs = append(errs, pm.crashes.errs[keyword])
+	}
+	return errs
+}
+
+// recoverPanic, deferred around a call of p, recovers a panic of the call and
+// stores it as a PanicError in *err unless err is nil. Optional plugins
+// panicking too often are disabled.
+func (pm *PluginManager) recoverPanic(p plugin.Plugin, call string, err *error) {
+	v := recover()
+	if _, ok := p.(*aggregator); ok {
+		// The aggregated plugins are guarded one by one.
+		if v != nil && err != nil {
+			*err = &PanicError{Plugin: p.Name(), Call: call, Value: v}
+		}
+		return
+	}
+	keyword := p.Keyword()
+	if v == nil {
+		pm.crashes.succeeded(keyword, call)
+		return
+	}
+
+	zap.L().Error("Plugin panicked.",
+		zap.String("plugin", p.Name()),
+		zap.String("call", call),
+		zap.Any("panic", v),
+		zap.ByteString("stack", debug.Stack()))
+	crash := &PanicError{Plugin: p.Name(), Call: call, Value: v}
+	if pm.crashes.panicked(keyword, call) {
+		if disableErr := pm.DisablePlugin(keyword); disableErr != nil {
+			zap.L().Warn("Could not disable crashing plugin.", zap.String("plugin", p.Name()), zap.Error(disableErr))
+		} else {
+			crash.Disabled = true
+			zap.L().Warn("Disabled plugin after repeated crashes.", zap.String("plugin", p.Name()))
+		}
+	}
+	pm.crashes.record(keyword, crash)
+	if err != nil {
+		*err = crash
+	}
+}
+
+// crashMsg reports a command of a plugin that panicked.
+type crashMsg struct {
+	err *PanicError
+}
+
+// guardCmd returns cmd, which p returned from call, recovering its panics as a
+// crashMsg.
+func (pm *PluginManager) guardCmd(p plugin.Plugin, call string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		var err error
+		defer func() {
+			var crash *PanicError
+			if errors.As(err, &crash) {
+				msg = crashMsg{err: crash}
+			}
+		}()
+		defer pm.recoverPanic(p, call, &err)
+		return cmd()
+	}
+}
+
+// UpdatePlugin passes msg to the Update of p, recovering its panics, after
+// which p is kept unchanged.
+func (pm *PluginManager) UpdatePlugin(p plugin.Plugin, msg tea.Msg) (updated plugin.Plugin, cmd tea.Cmd) {
+	updated = p
+	defer pm.recoverPanic(p, "Update", nil)
+	updated, cmd = p.Update(msg)
+	return updated, pm.guardCmd(p, "Update", cmd)
+}
+
+// PluginView returns the View of p, or "" if it panics, so the list is shown.
+func (pm *PluginManager) PluginView(p plugin.Plugin) (view string) {
+	defer pm.recoverPanic(p, "View", nil)
+	return p.View()
+}
+
+// handleCrash re-runs the query once a plugin was disabled for crashing, so
+// the plugin now active answers it.
+func (m *model) handleCrash(err error) tea.Cmd {
+	var crash *PanicError
+	if !errors.As(err, &crash) || !crash.Disabled {
+		return nil
+	}
+	return m.handlePluginsChanged(PluginsChangedMsg{})
+}
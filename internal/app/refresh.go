@@ -0,0 +1,44 @@
+package app
+
+import (
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshMsg fires when a plugin.Refresher's interval has elapsed.
+type refreshMsg struct {
+	keyword string
+}
+
+// scheduleRefresh returns a command that fires a refreshMsg after the active
+// plugin's refresh interval. Only one refresh is scheduled at a time.
+func (m *model) scheduleRefresh() tea.Cmd {
+	if m.refreshPending {
+		return nil
+	}
+	active := m.pluginManager.GetCurrentPlugin()
+	refresher, ok := optionalInterface[plugin.Refresher](active)
+	if !ok || refresher.RefreshInterval() <= 0 {
+		return nil
+	}
+
+	m.refreshPending = true
+	keyword := active.Keyword()
+	return tea.Tick(refresher.RefreshInterval(), func(time.Time) tea.Msg {
+		return refreshMsg{keyword: keyword}
+	})
+}
+
+// handleRefresh re-runs the current query if the plugin that scheduled the
+// refresh is still active. The next refresh is scheduled once results arrive.
+func (m *model) handleRefresh(msg refreshMsg) tea.Cmd {
+	m.refreshPending = false
+	active := m.pluginManager.GetCurrentPlugin()
+	if active == nil || active.Keyword() != msg.keyword || m.debounceTimer != nil {
+		return m.scheduleRefresh()
+	}
+
+	return m.queryCmd(m.lastQuery, m.querySeq, false, true)
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// PluginReloadMsg asks the application to replace the plugin loaded from Source,
+// typically after the file changed on disk. Send it with tea.Program.Send.
+type PluginReloadMsg struct {
+	// Source is the file the plugin was loaded from.
+	Source string
+	// Plugin is the newly loaded plugin, or nil if Source was removed.
+	Plugin plugin.Plugin
+	// Enabled reports whether the plugin should be registered or only listed as
+	// disabled. A plugin replacing one of the same keyword keeps its status.
+	Enabled bool
+	// Err is set if the file could not be loaded. The previous plugin is kept in that case.
+	Err error
+}
+
+// PluginsChangedMsg asks the application to re-run the current query after
+// plugins were enabled or disabled at runtime, or their listing changed.
+type PluginsChangedMsg struct {
+	// Enabled is a plugin just enabled, which the application initializes.
+	Enabled plugin.Plugin
+}
+
+// handlePluginsChanged initializes an enabled plugin and refreshes the results.
+func (m *model) handlePluginsChanged(msg PluginsChangedMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	if msg.Enabled != nil {
+		cmds = append(cmds, m.pluginManager.InitPlugin(msg.Enabled))
+		if m.width > 0 {
+			width, height := m.contentSize()
+			updatedPlugin, cmd := m.pluginManager.UpdatePlugin(msg.Enabled, tea.WindowSizeMsg{Width: width, Height: height})
+			m.updatePluginState(updatedPlugin)
+			cmds = append(cmds, cmd)
+		}
+	}
+	if m.debounceTimer == nil {
+		cmds = append(cmds, m.handleQueryChange(m.textInput.Value()))
+	}
+	return tea.Batch(cmds...)
+}
+
+// handlePluginReload swaps the plugin in the manager, initializes the new
+// instance and re-runs the current query so results reflect the new code.
+func (m *model) handlePluginReload(msg PluginReloadMsg) tea.Cmd {
+	if msg.Err != nil {
+		zap.L().Warn("Could not reload plugin, keeping the previous version.", zap.String("source", msg.Source), zap.Error(msg.Err))
+		return nil
+	}
+	if err := m.pluginManager.ReloadPlugin(msg.Source, msg.Plugin, msg.Enabled); err != nil {
+		zap.L().Warn("Could not register reloaded plugin.", zap.String("source", msg.Source), zap.Error(err))
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	if msg.Plugin != nil && m.pluginManager.IsEnabled(msg.Plugin.Keyword()) {
+		zap.L().Info("Reloaded plugin.", zap.String("name", msg.Plugin.Name()), zap.String("source", msg.Source))
+		cmds = append(cmds, m.pluginManager.InitPlugin(msg.Plugin))
+		if m.width > 0 {
+			width, height := m.contentSize()
+			updatedPlugin, cmd := m.pluginManager.UpdatePlugin(msg.Plugin, tea.WindowSizeMsg{Width: width, Height: height})
+			m.updatePluginState(updatedPlugin)
+			cmds = append(cmds, cmd)
+		}
+	} else if msg.Plugin == nil {
+		zap.L().Info("Removed plugin of deleted file.", zap.String("source", msg.Source))
+	}
+
+	if m.debounceTimer == nil {
+		cmds = append(cmds, m.handleQueryChange(m.textInput.Value()))
+	}
+	return tea.Batch(cmds...)
+}
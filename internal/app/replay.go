@@ -0,0 +1,159 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/barab-i/incipio/internal/trace"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// maxReplayGap shortens idle pauses of a trace on replay. Pauses shorter
+	// than the debounce decide which queries run, so they are kept as recorded.
+	maxReplayGap = time.Second
+	// replaySettle is how long a replay waits for the last results after the
+	// final input event, on top of the debounce.
+	replaySettle = time.Second
+	// maxReportedDivergences bounds the replay report.
+	maxReportedDivergences = 10
+)
+
+// traceUpdate runs Update and writes the input message and its outcome to the trace.
+func (m model) traceUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.trace.Write(trace.KeyEvent(msg, m.textInput.Value()))
+	case tea.WindowSizeMsg:
+		m.trace.Write(trace.Event{Kind: trace.KindResize, Width: msg.Width, Height: msg.Height})
+	}
+
+	next, cmd := m.update(msg)
+
+	// Refreshes depend on timing rather than input, so only first results are
+	// traced, and streamed results once complete.
+	if msg, ok := msg.(resultsMsg); ok && !msg.refreshed && msg.stream == nil && msg.seq == m.querySeq {
+		updated := next.(model)
+		updated.traceEvent(trace.Event{
+			Kind:    trace.KindResults,
+			Plugin:  updated.activeKeyword(),
+			Query:   trace.Anonymize(msg.forQuery),
+			Results: len(updated.list.Items()),
+			Error:   msg.err != nil,
+		})
+	}
+	return next, cmd
+}
+
+// traceEvent writes an event to the trace, if the session is recorded.
+func (m *model) traceEvent(e trace.Event) {
+	if m.trace != nil {
+		m.trace.Write(e)
+	}
+}
+
+// traceExecute records that the selected item is executed.
+func (m *model) traceExecute() {
+	m.traceEvent(trace.Event{Kind: trace.KindExecute, Plugin: m.activeKeyword(), Selected: m.list.Index()})
+}
+
+func (m *model) activeKeyword() string {
+	if active := m.pluginManager.GetCurrentPlugin(); active != nil {
+		return active.Keyword()
+	}
+	return ""
+}
+
+// Replay re-drives a model built from pm and opts with the input events of a
+// recorded trace, without a terminal, and writes to out how the queries,
+// results and executions compare with the recorded ones. Selections are never
+// executed. When record is not nil, the trace of the replay is written to it
+// too. Replay reports whether the replay diverged from the recording.
+func Replay(pm *PluginManager, opts Options, events []trace.Event, record io.Writer, out io.Writer) (bool, error) {
+	var replayed bytes.Buffer
+	traceOut := io.Writer(&replayed)
+	if record != nil {
+		traceOut = io.MultiWriter(&replayed, record)
+	}
+	opts.Trace = trace.NewWriter(traceOut)
+	opts.DryRun = true
+
+	program := tea.NewProgram(InitialModel(pm, opts),
+		tea.WithInput(nil),
+		tea.WithOutput(io.Discard),
+		tea.WithoutRenderer(),
+		tea.WithoutSignalHandler())
+
+	go func() {
+		var last time.Duration
+		for _, e := range events {
+			if !e.IsInput() {
+				continue
+			}
+			time.Sleep(min(max(e.Offset-last, 0), maxReplayGap))
+			last = e.Offset
+			program.Send(e.Msg())
+		}
+		time.Sleep(opts.Debounce + replaySettle)
+		program.Quit()
+	}()
+
+	if _, err := program.Run(); err != nil {
+		return false, fmt.Errorf("replay failed: %w", err)
+	}
+	if err := opts.Trace.Err(); err != nil {
+		return false, fmt.Errorf("could not write replay trace: %w", err)
+	}
+	replayedEvents, err := trace.Read(&replayed)
+	if err != nil {
+		return false, fmt.Errorf("could not read replay trace: %w", err)
+	}
+
+	want, got := outcomes(events), outcomes(replayedEvents)
+	matched, diverged := 0, 0
+	for i := range max(len(want), len(got)) {
+		var w, g trace.Event
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if sameOutcome(w, g) {
+			matched++
+			continue
+		}
+		if diverged < maxReportedDivergences {
+			fmt.Fprintf(out, "#%d: recorded %s, replayed %s\n", i+1, describe(w), describe(g))
+		}
+		diverged++
+	}
+	fmt.Fprintf(out, "Replayed %d input events: %d of %d recorded outcomes matched.\n", len(events)-len(want), matched, len(want))
+	return diverged > 0, nil
+}
+
+// outcomes returns the outcome events of a trace, in order.
+func outcomes(events []trace.Event) []trace.Event {
+	var out []trace.Event
+	for _, e := range events {
+		if !e.IsInput() {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// sameOutcome compares two outcome events, ignoring when they happened.
+func sameOutcome(a, b trace.Event) bool {
+	a.Offset, b.Offset = 0, 0
+	return a == b
+}
+
+func describe(e trace.Event) string {
+	if e.Kind == "" {
+		return "nothing"
+	}
+	return e.String()
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resultStream collects the batches a plugin.ResultStreamer sends for a query
+// until the update loop picks them up.
+type resultStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	start  time.Time
+
+	mu      sync.Mutex
+	results []plugin.Result // All results sent so far.
+	done    bool
+	err     error
+	updated chan struct{} // Signalled, without blocking, on sends and when done.
+}
+
+// queryCmd returns a command running the query against the active plugin. The
+// query is cancelled once a new one starts; see cancelPreviousQuery. Results
+// of a plugin.ResultStreamer arrive in several resultsMsg: each one carrying
+// the stream holds the results so far, and the last one is complete. Refreshes
+// wait for all results, so the list does not shrink meanwhile.
+func (m *model) queryCmd(query string, seq uint64, pluginSwitched, refreshed bool) tea.Cmd {
+	msg := resultsMsg{pluginSwitched: pluginSwitched, forQuery: query, seq: seq, refreshed: refreshed}
+	pm := m.pluginManager
+	keyword := m.activeKeyword()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelQuery = cancel
+	if _, ok := optionalInterface[plugin.ResultStreamer](pm.GetCurrentPlugin()); ok {
+		s := &resultStream{ctx: ctx, cancel: cancel, start: time.Now(), updated: make(chan struct{}, 1)}
+		go s.run(pm, keyword, query, !refreshed)
+		return s.next(msg, refreshed)
+	}
+
+	return func() tea.Msg {
+		defer cancel()
+		start := time.Now()
+		msg.results, msg.err = pm.GetResults(ctx, query)
+		msg.elapsed = time.Since(start)
+		// Refreshes and superseded queries do not count in the statistics.
+		if !refreshed && ctx.Err() == nil {
+			recordQuery(pm, keyword, msg.elapsed, msg.err)
+		}
+		return msg
+	}
+}
+
+// cancelPreviousQuery cancels the previous query if it is still running.
+func (m *model) cancelPreviousQuery() {
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+		m.cancelQuery = nil
+	}
+}
+
+// run streams the results of the query. Should the active plugin have changed
+// since, its results are fetched at once instead. Unless superseded, the query
+// of the plugin with the given keyword is recorded in the statistics if record is set.
+func (s *resultStream) run(pm *PluginManager, keyword, query string, record bool) {
+	streamed, err := pm.StreamResults(s.ctx, query, s.send)
+	if !streamed {
+		var results []plugin.Result
+		results, err = pm.GetResults(s.ctx, query)
+		s.send(results)
+	}
+	if record && s.ctx.Err() == nil {
+		recordQuery(pm, keyword, time.Since(s.start), err)
+	}
+
+	s.mu.Lock()
+	s.done = true
+	s.err = err
+	s.mu.Unlock()
+	s.cancel()
+	s.signal()
+}
+
+// send appends a batch of results.
+func (s *resultStream) send(results []plugin.Result) {
+	s.mu.Lock()
+	s.results = append(s.results, results...)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *resultStream) signal() {
+	select {
+	case s.updated <- struct{}{}:
+	default:
+	}
+}
+
+// next returns a command waiting for more results, or for all of them when
+// untilDone is set, and yielding them as msg.
+func (s *resultStream) next(msg resultsMsg, untilDone bool) tea.Cmd {
+	return func() tea.Msg {
+		for {
+			<-s.updated
+			s.mu.Lock()
+			if s.done || !untilDone {
+				break
+			}
+			s.mu.Unlock()
+		}
+		defer s.mu.Unlock()
+
+		msg.results = slices.Clone(s.results)
+		msg.err = s.err
+		msg.elapsed = time.Since(s.start)
+		if !s.done {
+			msg.stream = s
+		}
+		return msg
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import "github.com/charmbracelet/lipgloss"
+
+// sectionItem is the header of a section of results; see plugin.Result.Section.
+// It is drawn in the list but cannot be selected. Notices, such as that of a
+// plugin timing out, are drawn the same way in the description style.
+type sectionItem struct {
+	title  string
+	notice bool
+}
+
+// style returns the style the header is drawn in.
+func (s sectionItem) style() lipgloss.Style {
+	if s.notice {
+		return descStyle
+	}
+	return listHeaderStyle
+}
+
+func (s sectionItem) FilterValue() string { return s.title }
+
+// skipSectionHeader moves the selection off a section header, on in the
+// direction it moved from before, or back if no result follows that way.
+func (m *model) skipSectionHeader(before int) {
+	items := m.list.Items()
+	index := m.list.Index()
+	if index >= len(items) {
+		return
+	}
+	if _, ok := items[index].(sectionItem); !ok {
+		return
+	}
+
+	step := 1
+	if index < before {
+		step = -1
+	}
+	for _, s := range []int{step, -step} {
+		for i := index + s; i >= 0 && i < len(items); i += s {
+			if _, ok := items[i].(sectionItem); !ok {
+				m.list.Select(i)
+				return
+			}
+		}
+	}
+}
+
+// jumpSection selects the first result of the next section, or of the
+// previous one, or of the current section if the selection is further down.
+// It reports whether there was such a section.
+func (m *model) jumpSection(forward bool) bool {
+	items := m.list.Items()
+	index := m.list.Index()
+	target := -1
+	for i, item := range items {
+		if _, ok := item.(sectionItem); !ok || i+1 >= len(items) {
+			continue
+		}
+		start := i + 1
+		if forward && start > index {
+			target = start
+			break
+		}
+		if !forward && start < index {
+			target = start
+		}
+	}
+	if target < 0 {
+		return false
+	}
+	m.list.Select(target)
+	return true
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// queryStats describes the results of the last query.
+type queryStats struct {
+	count   int
+	elapsed time.Duration
+	err     bool
+}
+
+// statsStatus describes the results of the last query, such as
+// "12 results in 3ms", for the status shown next to the input. It is empty
+// unless enabled or before the first results.
+func (m model) statsStatus() string {
+	s := m.lastResults
+	if !m.showStats || (s.elapsed == 0 && s.count == 0) {
+		return ""
+	}
+	if s.err {
+		return "failed in " + formatElapsed(s.elapsed)
+	}
+	noun := "results"
+	if s.count == 1 {
+		noun = "result"
+	}
+	return fmt.Sprintf("%d %s in %s", s.count, noun, formatElapsed(s.elapsed))
+}
+
+// recordQuery adds a query answered by the plugin with the given keyword to
+// the usage statistics, if they are recorded.
+func recordQuery(pm *PluginManager, keyword string, elapsed time.Duration, err error) {
+	if s := pm.Stats(); s != nil {
+		s.RecordQuery(keyword, elapsed, err != nil)
+	}
+}
+
+// recordExecution adds the executed item, at its position in the list, to
+// the usage statistics of the active plugin.
+func (m *model) recordExecution(item listItem, elapsed time.Duration) {
+	s := m.pluginManager.Stats()
+	if s == nil {
+		return
+	}
+	if err := s.RecordExecution(m.activeKeyword(), item.Title(), m.list.Index(), elapsed); err != nil {
+		zap.L().Warn("Could not save usage statistics.", zap.Error(err))
+	}
+}
+
+// formatElapsed rounds a duration to a precision readable at a glance.
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return "<1ms"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(10 * time.Millisecond).String()
+	}
+}
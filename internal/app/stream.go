@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// streamKeys scroll the stream view. Keys that type text are left to the input.
+var streamKeys = viewport.KeyMap{
+	PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdn", "page down")),
+	PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+}
+
+// streamView shows a plugin.Stream in place of the view of the plugin that
+// started it.
+type streamView struct {
+	stream   *plugin.Stream
+	owner    string // Keyword of the plugin that started the stream.
+	query    string // Query when the stream started; editing it closes the view.
+	viewport viewport.Model
+	follow   bool // Keep the end in view as chunks arrive.
+}
+
+// handleStreamStart shows the stream and starts waiting for its chunks. A
+// stream shown before is cancelled if it is still running.
+func (m *model) handleStreamStart(msg plugin.StreamStartMsg) tea.Cmd {
+	if msg.Stream == nil || (m.stream != nil && m.stream.stream == msg.Stream) {
+		return nil // The command of Execute may run twice; see the enter key.
+	}
+	if m.stream != nil {
+		m.stream.stream.Cancel()
+	}
+
+	vp := viewport.New(m.list.Width(), max(1, m.listHeight-2))
+	vp.KeyMap = streamKeys
+	m.stream = &streamView{
+		stream:   msg.Stream,
+		owner:    m.activeKeyword(),
+		query:    m.textInput.Value(),
+		viewport: vp,
+		follow:   true,
+	}
+	m.refreshStream()
+	return msg.Stream.Next()
+}
+
+// handleStreamUpdate renders new chunks of the shown stream and forwards the
+// message to the plugin that started it. Streams no longer shown are not
+// waited for.
+func (m *model) handleStreamUpdate(stream *plugin.Stream, msg tea.Msg) tea.Cmd {
+	if m.stream == nil || m.stream.stream != stream {
+		return nil
+	}
+	m.refreshStream()
+
+	var cmds []tea.Cmd
+	if owner, ok := m.pluginManager.GetAllPlugins()[m.stream.owner]; ok {
+		updatedPlugin, pluginCmd := m.pluginManager.UpdatePlugin(owner, msg)
+		m.updatePluginState(updatedPlugin)
+		cmds = append(cmds, pluginCmd)
+	}
+	if _, done := msg.(plugin.StreamDoneMsg); !done {
+		cmds = append(cmds, stream.Next())
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshStream sets the stream's text, wrapped to the view width, as the
+// viewport content.
+func (m *model) refreshStream() {
+	vp := &m.stream.viewport
+	vp.SetContent(lipgloss.NewStyle().Width(max(1, vp.Width)).Render(m.stream.stream.Text()))
+	if m.stream.follow {
+		vp.GotoBottom()
+	}
+}
+
+// resizeStream fits the stream view to the list area.
+func (m *model) resizeStream() {
+	if m.stream == nil {
+		return
+	}
+	m.stream.viewport.Width = m.list.Width()
+	m.stream.viewport.Height = max(1, m.listHeight-2) // Title and status lines.
+	m.refreshStream()
+}
+
+// updateStreamScroll passes a message to the stream view, scrolling it.
+// Scrolling away from the end stops following new chunks.
+func (m *model) updateStreamScroll(msg tea.Msg) tea.Cmd {
+	if m.stream == nil {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.stream.viewport, cmd = m.stream.viewport.Update(msg)
+	m.stream.follow = m.stream.viewport.AtBottom()
+	return cmd
+}
+
+// cancelStream cancels the shown stream if it is still running and reports
+// whether it did.
+func (m *model) cancelStream() bool {
+	if m.stream == nil || m.stream.stream.Done() {
+		return false
+	}
+	m.stream.stream.Cancel()
+	return true
+}
+
+// streamShown reports whether the stream view replaces the active plugin's view.
+func (m model) streamShown() bool {
+	return m.stream != nil && m.stream.owner == m.activeKeyword()
+}
+
+// renderStream renders the stream's title, text and status.
+func (m model) renderStream() string {
+	s := m.stream.stream
+	status := streamStatusStyle.Render("streaming… · esc: cancel · pgup/pgdn: scroll")
+	switch err := s.Err(); {
+	case errors.Is(err, context.Canceled):
+		status = streamStatusStyle.Render("cancelled")
+	case err != nil:
+		status = streamErrorStyle.Render("error: " + err.Error())
+	case s.Done():
+		status = streamStatusStyle.Render("done · pgup/pgdn: scroll")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		listHeaderStyle.Render(s.Title()),
+		m.stream.viewport.View(),
+		lipgloss.NewStyle().MaxWidth(max(1, m.list.Width())).Render(status))
+}
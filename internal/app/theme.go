@@ -0,0 +1,29 @@
+package app
+
+import (
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleThemeChange restyles the application with the new theme, passes it to
+// plugins implementing plugin.Themed and forwards the message to every plugin.
+func (m *model) handleThemeChange(msg plugin.ThemeChangedMsg) tea.Cmd {
+	if msg.Theme == nil {
+		return nil
+	}
+	theme.SetCurrent(msg.Theme)
+	InitStyles(msg.Theme.Base())
+	m.applyStyles()
+	m.pluginManager.SetTheme(msg.Theme)
+
+	var cmds []tea.Cmd
+	for _, p := range m.pluginManager.GetAllPlugins() {
+		updatedPlugin, pluginCmd := m.pluginManager.UpdatePlugin(p, msg)
+		m.updatePluginState(updatedPlugin)
+		if pluginCmd != nil {
+			cmds = append(cmds, pluginCmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
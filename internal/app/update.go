@@ -3,9 +3,9 @@ package app
 import (
 	"time"
 
+	"github.com/barab-i/incipio/internal/trace"
 	"github.com/barab-i/incipio/pkgs/plugin"
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -23,13 +23,42 @@ type resultsMsg struct {
 	err            error
 	pluginSwitched bool
 	forQuery       string
+	seq            uint64        // Sequence number of the query; see model.querySeq.
+	elapsed        time.Duration // Time the plugin took to return the results.
+	refreshed      bool          // Results of a periodic refresh; the selection is kept.
+	appended       bool          // More results of a streamed query; the selection is kept.
+	stream         *resultStream // Set while more results of a streamed query are coming.
 }
 
-const debounceDuration = 200 * time.Millisecond
-
-type processQueryMsg struct{}
+// processQueryMsg runs the query once typing paused, unless it was typed over since.
+type processQueryMsg struct {
+	seq uint64
+}
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	selected := m.list.Index()
+	var updated tea.Model
+	var cmd tea.Cmd
+	if m.trace != nil {
+		updated, cmd = m.traceUpdate(msg)
+	} else {
+		updated, cmd = m.update(msg)
+	}
+
+	// Any message may change the highlighted result or the active plugin.
+	next, ok := updated.(model)
+	if !ok {
+		return updated, cmd
+	}
+	next.skipSectionHeader(selected)
+	next.fitErrorBanner()
+	if previewCmd := next.syncPreview(); previewCmd != nil {
+		cmd = tea.Batch(cmd, previewCmd)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
 
@@ -39,17 +68,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		// Plugins lay out their views within the capped content size.
+		contentWidth, contentHeight := m.contentSize()
+		contentMsg := tea.WindowSizeMsg{Width: contentWidth, Height: contentHeight}
+
 		textInputHeight := lipgloss.Height(m.textInput.View()) + 1
-		listHeight := msg.Height - textInputHeight - appStyle.GetVerticalFrameSize()
+		listHeight := contentHeight - textInputHeight - appStyle.GetVerticalFrameSize()
 		listHeight = max(1, listHeight)
-		listWidth := msg.Width - appStyle.GetHorizontalFrameSize()
-		m.list.SetSize(listWidth, listHeight)
+		listWidth := contentWidth - appStyle.GetHorizontalFrameSize()
+		m.collapsePeek()
+		m.fullListHeight = listHeight
+		m.listHeight = max(1, listHeight-m.bannerLines)
+		m.fullListWidth = listWidth
+		m.list.SetSize(listWidth, m.listHeight)
+		m.fitGrid()
+		m.resizeStream()
+		cmds = append(cmds, m.hydrateVisibleItems())
 
-		for _, pluginInstance := range m.pluginManager.plugins {
+		for _, pluginInstance := range m.pluginManager.GetAllPlugins() {
 			if pluginInstance == nil {
 				continue
 			}
-			updatedPlugin, pluginCmd := pluginInstance.Update(msg)
+			updatedPlugin, pluginCmd := m.pluginManager.UpdatePlugin(pluginInstance, contentMsg)
 			m.updatePluginState(updatedPlugin)
 			if pluginCmd != nil {
 				cmds = append(cmds, pluginCmd)
@@ -58,7 +98,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 
 	case processQueryMsg:
-		if m.debounceTimer != nil {
+		if msg.seq == m.querySeq && m.debounceTimer != nil {
 			queryCmd := m.handleQueryChange(m.lastQuery)
 			if queryCmd != nil {
 				cmds = append(cmds, queryCmd)
@@ -68,41 +108,176 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 
 	case resultsMsg:
-		if msg.forQuery != m.lastQuery {
-			return m, nil // Stale results, ignore.
+		if msg.seq != m.querySeq {
+			if msg.stream != nil {
+				msg.stream.cancel()
+			}
+			return m, nil // Results of an older query or plugin, ignore.
 		}
 
-		if msg.err != nil {
+		m.collapsePeek()
+		selected := m.list.Index()
+		var selectedID string
+		if li, ok := m.list.SelectedItem().(listItem); ok {
+			selectedID = li.identifier
+		}
+		m.resetHydration()
+		keep := 0 // Results loaded by scrolling stay in the list.
+		if msg.refreshed || msg.appended {
+			keep = len(m.list.Items())
+		}
+		var highlightCmd tea.Cmd
+		if timedOut, only := timeouts(msg.err); !only {
 			m.err = msg.err
-			m.list.SetItems([]list.Item{})
+			m.list.SetItems(m.windowResults(msg.results, keep)) // Streamed results sent before the error.
 		} else {
 			m.err = nil
-			items := make([]list.Item, len(msg.results))
-			for i, r := range msg.results {
-				items[i] = listItem{
-					title:       r.Title,
-					description: r.Description,
-					identifier:  r.Identifier,
-				}
+			items := m.windowResults(msg.results, keep)
+			if msg.refreshed {
+				highlightCmd = m.markChanges(items)
 			}
-			m.list.SetItems(items)
+			m.list.SetItems(append(timeoutNotices(timedOut), items...))
 		}
+		m.lastResults = queryStats{count: len(msg.results), elapsed: msg.elapsed, err: msg.err != nil}
+		m.matchQuery = m.pluginManager.PluginQuery(msg.forQuery)
+		m.list.SetDelegate(m.delegate())
 
 		if msg.pluginSwitched {
 			m.list.Select(0)
 			m.list.ResetFilter()
+		} else if msg.refreshed || msg.appended {
+			m.list.Select(m.refreshedIndex(selectedID, selected))
 		} else if len(m.list.Items()) > 0 {
 			m.list.ResetSelected()
 		}
+		if msg.stream != nil {
+			more := resultsMsg{forQuery: msg.forQuery, seq: msg.seq, appended: true}
+			return m, tea.Batch(m.hydrateVisibleItems(), msg.stream.next(more, false))
+		}
+		crashCmd := m.handleCrash(msg.err)
+		return m, tea.Batch(m.hydrateVisibleItems(), m.scheduleRefresh(), highlightCmd, crashCmd)
+
+	case refreshMsg:
+		return m, m.handleRefresh(msg)
+
+	case eventMsg:
+		return m, m.deliverEvent(msg)
+
+	case crashMsg:
+		return m, m.handleCrash(msg.err)
+
+	case rerunMsg:
+		return m, m.handleRerun(msg)
+
+	case PluginReloadMsg:
+		return m, m.handlePluginReload(msg)
+
+	case PluginsChangedMsg:
+		return m, m.handlePluginsChanged(msg)
+
+	case plugin.RefreshMsg:
+		return m, m.handlePluginsChanged(PluginsChangedMsg{})
+
+	case plugin.StreamStartMsg:
+		return m, m.handleStreamStart(msg)
+
+	case plugin.StreamChunkMsg:
+		return m, m.handleStreamUpdate(msg.Stream, msg)
+
+	case plugin.StreamDoneMsg:
+		return m, m.handleStreamUpdate(msg.Stream, msg)
+
+	case plugin.RunCommandMsg:
+		return m, m.runCommand(msg.Command)
+
+	case plugin.CommandFinishedMsg:
+		return m, m.handleCommandFinished(msg)
+
+	case plugin.ThemeChangedMsg:
+		return m, m.handleThemeChange(msg)
+
+	case previewMsg:
+		m.applyPreview(msg)
+		return m, nil
+
+	case hydratedMsg:
+		m.applyHydration(msg)
 		return m, nil
 
+	case clearChangesMsg:
+		m.clearChanges(msg)
+		return m, nil
+
+	case feedbackDoneMsg:
+		return m, m.handleFeedbackDone(msg)
+
+	case tea.MouseMsg:
+		if m.tooSmall() {
+			return m, nil // Nothing to click or scroll.
+		}
+		if cmd, ok := m.handleMouse(msg); ok {
+			return m, cmd
+		}
+
 	case tea.KeyMsg:
+		if m.confirmed != "" {
+			return m, nil // The executed row is flashing; see confirm.
+		}
+		if cmd, ok := m.runPluginAction(msg); ok {
+			return m, cmd
+		}
+		if key.Matches(msg, m.keys.Preview) {
+			m.togglePreview()
+			return m, nil
+		}
+		// Printable keys, like ?, only toggle help on an empty query, so they can still be typed.
+		if key.Matches(msg, m.keys.Help) && (msg.Type != tea.KeyRunes || m.textInput.Value() == "") {
+			m.showHelp = !m.showHelp
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.Peek) {
+			m.togglePeek()
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.Mark) {
+			m.collapsePeek()
+			return m, m.toggleMark()
+		}
+		if cmd, ok := m.moveInGrid(msg); ok {
+			return m, cmd
+		}
+		if next, prev := key.Matches(msg, m.keys.NextSection), key.Matches(msg, m.keys.PrevSection); (next || prev) && m.listShown() {
+			m.collapsePeek()
+			// The list is drawn upside down in drop-up mode.
+			if !m.jumpSection(next != m.dropUp) {
+				return m, nil
+			}
+			return m, tea.Batch(m.slideWindow(), m.hydrateVisibleItems())
+		}
+		// Terminals report no key releases, so any other key ends a peek.
+		m.collapsePeek()
+
+		if key.Matches(msg, m.keys.Intent) {
+			return m, m.toggleIntentOverride()
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
 			m.quitting = true
 			return m, tea.Quit
 
 		case key.Matches(msg, m.keys.Esc):
+			if m.showHelp {
+				m.showHelp = false
+				return m, nil
+			}
+			if m.cancelStream() {
+				return m, nil
+			}
+			if m.errorBanner() != "" {
+				m.dismissedError = m.errorText()
+				return m, nil
+			}
 			if m.debounceTimer != nil {
 				m.debounceTimer.Stop()
 				m.debounceTimer = nil
@@ -116,7 +291,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if activePlugin := m.pluginManager.GetCurrentPlugin(); activePlugin != nil {
-					updatedPlugin, pluginCmd := activePlugin.Update(clearSummaryMsg{})
+					updatedPlugin, pluginCmd := m.pluginManager.UpdatePlugin(activePlugin, clearSummaryMsg{})
 					m.updatePluginState(updatedPlugin)
 					if pluginCmd != nil {
 						cmds = append(cmds, pluginCmd)
@@ -139,15 +314,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			if item := m.list.SelectedItem(); item != nil {
 				if selectedItem, ok := item.(listItem); ok {
+					m.traceExecute()
+					if m.dryRun {
+						return m, tea.Batch(cmds...)
+					}
+					if m.printResult != nil {
+						return m, m.printSelection(selectedItem)
+					}
+					if len(m.marks) > 0 {
+						return m, m.executeMarked()
+					}
+					start := time.Now()
 					execCmd := m.pluginManager.Execute(selectedItem.Identifier())
+					if execCmd != nil {
+						m.recordHistory(selectedItem)
+						m.recordExecution(selectedItem, time.Since(start))
+					}
+					if execCmd == nil {
+						return m, nil
+					}
 					// If Execute intends to quit, it should return tea.Quit.
 					// The model's quitting flag is set if the command itself is tea.Quit.
 					// This check is a basic way to see if the command is tea.Quit.
 					// A more robust solution might involve specific return types or signals from Execute.
-					if execCmd != nil && execCmd() == tea.Quit() {
-						m.quitting = true
-					}
-					return m, execCmd
+					quit := execCmd() == tea.Quit()
+					return m, m.confirm(m.keys.Enter.Help().Desc, execCmd, quit)
 				}
 			}
 			return m, tea.Batch(cmds...)
@@ -156,7 +347,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Plugin Update Handling (for messages not handled by specific key matches)
 	if activePlugin := m.pluginManager.GetCurrentPlugin(); activePlugin != nil {
-		updatedPlugin, pluginCmd := activePlugin.Update(msg)
+		updatedPlugin, pluginCmd := m.pluginManager.UpdatePlugin(activePlugin, msg)
 		m.updatePluginState(updatedPlugin)
 		if pluginCmd != nil {
 			cmds = append(cmds, pluginCmd)
@@ -171,14 +362,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	newQuery := m.textInput.Value()
 	if newQuery != queryBeforeInputUpdate {
 		m.lastQuery = newQuery
+		seq := m.nextQuerySeq() // Results still in flight are stale from now on.
 		if m.debounceTimer != nil {
 			m.debounceTimer.Stop()
 		}
-		m.debounceTimer = time.NewTimer(debounceDuration)
+		m.debounceTimer = time.NewTimer(m.debounce)
 		cmds = append(cmds, func() tea.Msg {
 			if m.debounceTimer != nil {
 				<-m.debounceTimer.C
-				return processQueryMsg{}
+				return processQueryMsg{seq: seq}
 			}
 			return nil
 		})
@@ -186,36 +378,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	m.list, cmd = m.list.Update(msg)
 	cmds = append(cmds, cmd)
+	cmds = append(cmds, m.slideWindow())
+	cmds = append(cmds, m.hydrateVisibleItems())
+	cmds = append(cmds, m.updateStreamScroll(msg))
 
 	return m, tea.Batch(cmds...)
 }
 
 func (m *model) handleQueryChange(newQuery string) tea.Cmd {
 	m.err = nil
+	seq := m.nextQuerySeq()
+
+	if m.stream != nil && newQuery != m.stream.query {
+		m.stream = nil // Editing the query goes back to the results.
+	}
 
 	activePlugin, pluginSwitched := m.pluginManager.DetermineActivePlugin(newQuery)
 
 	if pluginSwitched {
-		m.list.SetItems([]list.Item{})
+		m.clearMarks() // Identifiers are only meaningful to the plugin that marked them.
+		m.clearResults()
 		m.list.ResetFilter()
 	}
 
 	if activePlugin == nil {
 		if !pluginSwitched {
-			m.list.SetItems([]list.Item{})
+			m.clearResults()
 		}
 		return nil
 	}
 
-	return func() tea.Msg {
-		results, err := m.pluginManager.GetResults(newQuery)
-		return resultsMsg{
-			results:        results,
-			err:            err,
-			pluginSwitched: pluginSwitched,
-			forQuery:       newQuery,
-		}
-	}
+	m.traceEvent(trace.Event{Kind: trace.KindQuery, Plugin: activePlugin.Keyword(), Query: trace.Anonymize(newQuery)})
+	return m.queryCmd(newQuery, seq, pluginSwitched, false)
+}
+
+// nextQuerySeq starts a new query, making the results of all earlier ones
+// stale, and returns its sequence number.
+func (m *model) nextQuerySeq() uint64 {
+	m.cancelPreviousQuery()
+	m.querySeq++
+	return m.querySeq
 }
 
 // updatePluginState delegates updating the plugin instance to the PluginManager.
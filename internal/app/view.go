@@ -1,36 +1,197 @@
 package app
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/internal/profile"
 	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+)
+
+// maxBannerLines caps the height of the error banner.
+const maxBannerLines = 3
+
+// Smallest content size, in cells, the launcher is laid out in; smaller
+// terminals get tooSmallView instead.
+const (
+	minContentWidth  = 30
+	minContentHeight = 8
 )
 
 // View renders the application's UI.
 func (m model) View() string {
+	if m.profiler != nil {
+		defer m.observeRender(time.Now())
+	}
 	if m.quitting {
 		return quitTextStyle.Render("Exiting Incipio...")
 	}
+	if m.tooSmall() {
+		return m.tooSmallView()
+	}
+	return m.placeVertically(m.frame())
+}
 
+// observeRender records how long the frame started at start took to render.
+func (m model) observeRender(start time.Time) {
+	elapsed := time.Since(start)
+	m.profiler.Observe(profile.Render, elapsed)
+	zap.L().Debug("Rendered frame.", zap.Duration("elapsed", elapsed))
+}
+
+// tooSmall reports whether the content is too small to lay out the results,
+// which lipgloss would then draw garbled. The size is unknown until the first
+// tea.WindowSizeMsg.
+func (m model) tooSmall() bool {
+	width, height := m.contentSize()
+	return m.width > 0 && (width < minContentWidth || height < minContentHeight)
+}
+
+// tooSmallView renders the input above a note asking for a larger window, in
+// place of the results and plugin views, cut to the terminal size.
+func (m model) tooSmallView() string {
+	note := descStyle.UnsetPaddingLeft().Render(fmt.Sprintf("Too small, needs %d×%d.", minContentWidth, minContentHeight))
+	return lipgloss.NewStyle().
+		MaxWidth(m.width).
+		MaxHeight(m.height).
+		Render(lipgloss.JoinVertical(lipgloss.Left, m.inputView(), note))
+}
+
+// frame renders the input and the content below or above it, centered
+// horizontally when capped narrower than the terminal.
+func (m model) frame() string {
 	var viewContent string
 	activePlugin := m.pluginManager.GetCurrentPlugin()
 
-	// Check if the active plugin provides a custom view.
-	if activePlugin != nil {
-		viewContent = activePlugin.View()
+	// The help overlay or a stream started by the active plugin replaces its view; otherwise
+	// check if the active plugin provides a custom view.
+	if m.showHelp {
+		viewContent = m.renderHelp()
+	} else if m.streamShown() {
+		viewContent = m.renderStream()
+	} else if m.previewShown() {
+		viewContent = m.renderPreview()
+	} else if activePlugin != nil {
+		viewContent = m.pluginManager.PluginView(activePlugin)
 	}
 
 	// Use the default list view if no plugin-specific view is provided.
 	if viewContent == "" {
-		viewContent = m.list.View()
+		viewContent = m.listView()
+	}
+
+	// Show the intent a query was routed by and the query stats next to the input.
+	input := m.inputView()
+	for _, status := range []string{m.intentStatus(), m.statsStatus()} {
+		if status != "" {
+			input = lipgloss.JoinHorizontal(lipgloss.Top, input, descStyle.Render(status))
+		}
 	}
 
-	// Combine the text input and the main content area (list or plugin view).
+	// Combine the text input and the main content area (list or plugin view),
+	// with the input below it in drop-up mode.
+	// The error banner sits between the input and the content.
+	if banner := m.errorBanner(); banner != "" {
+		if m.dropUp {
+			viewContent = lipgloss.JoinVertical(lipgloss.Left, viewContent, banner)
+		} else {
+			viewContent = lipgloss.JoinVertical(lipgloss.Left, banner, viewContent)
+		}
+	}
 	mainContent := lipgloss.JoinVertical(lipgloss.Left,
-		m.textInput.View(),
+		input,
 		viewContent,
 	)
+	if m.dropUp {
+		mainContent = lipgloss.JoinVertical(lipgloss.Left, viewContent, input)
+	}
 
 	// Apply the main application style.
 	view := appStyle.Render(mainContent)
 
+	// Center content capped narrower than the terminal.
+	if contentWidth, _ := m.contentSize(); contentWidth < m.width {
+		view = lipgloss.PlaceHorizontal(m.width, lipgloss.Center, view)
+	}
 	return view
 }
+
+// placeVertically places the frame at the top, center or bottom of a taller
+// terminal; see Options.Anchor.
+func (m model) placeVertically(frame string) string {
+	if m.anchor == lipgloss.Top || m.height == 0 {
+		return frame
+	}
+	return lipgloss.PlaceVertical(m.height, m.anchor, frame)
+}
+
+// frameLines returns the terminal line the frame starts at once placed, and
+// its height.
+func (m model) frameLines() (top, height int) {
+	height = lipgloss.Height(m.frame())
+	gap := m.height - height
+	switch {
+	case gap <= 0 || m.anchor == lipgloss.Top:
+		return 0, height
+	case m.anchor == lipgloss.Bottom:
+		return gap, height
+	default:
+		return gap / 2, height // Rounded like lipgloss.PlaceVertical.
+	}
+}
+
+// errorText returns the error of the last query, that the active plugin
+// reports and the latest crashes of plugins, one per line, or "" if there are none.
+func (m model) errorText() string {
+	var errs []string
+	if m.err != nil {
+		errs = append(errs, m.err.Error())
+	}
+	others := m.pluginManager.PanicErrors()
+	if p := m.pluginManager.GetCurrentPlugin(); p != nil {
+		others = append([]error{p.GetError()}, others...)
+	}
+	for _, err := range others {
+		if err != nil && !slices.Contains(errs, err.Error()) {
+			errs = append(errs, err.Error())
+		}
+	}
+	return strings.Join(errs, "\n")
+}
+
+// errorBanner renders the errors of errorText in the theme's error color,
+// wrapped to the list width, unless dismissed with esc.
+func (m model) errorBanner() string {
+	text := m.errorText()
+	if text == "" || text == m.dismissedError {
+		return ""
+	}
+	return errorBannerStyle.Width(max(1, m.fullListWidth)).MaxHeight(maxBannerLines).Render(text)
+}
+
+// fitErrorBanner shrinks the list by the lines of the error banner, so the
+// view keeps its height, and forgets a dismissed banner once its errors are gone.
+func (m *model) fitErrorBanner() {
+	if m.errorText() == "" {
+		m.dismissedError = ""
+	}
+	lines := 0
+	if banner := m.errorBanner(); banner != "" {
+		lines = lipgloss.Height(banner)
+	}
+	if lines == m.bannerLines || m.fullListHeight == 0 {
+		return
+	}
+	m.bannerLines = lines
+	m.collapsePeek()
+	// Changing the height repaginates the list, so restore the selection afterwards.
+	selected := m.list.Index()
+	m.listHeight = max(1, m.fullListHeight-lines)
+	m.list.SetHeight(m.listHeight)
+	m.list.Select(selected)
+	m.fitGrid()
+	m.resizeStream()
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resultPageSize is how many results are put in the list at once. Results
+// beyond it are held back and added as the selection nears the end of the
+// list, so huge result sets are neither converted nor paginated up front.
+const resultPageSize = 200
+
+// maxWindowPages caps the pages of results in the list. Scrolling past them
+// drops the first page, and scrolling back up brings it back, so only the
+// results around the selection are ever list items, even for results as
+// numerous as the packages of nixpkgs.
+const maxWindowPages = 3
+
+// windowResults returns the list items for the first page of results, or for
+// at least keep of them from the window shown so far, and holds back the rest
+// for slideWindow.
+func (m *model) windowResults(results []plugin.Result, keep int) []list.Item {
+	start := 0
+	if keep > 0 {
+		start = min(m.windowStart, len(results)) // A refresh keeps the scroll position.
+	}
+	m.results = results
+	m.windowStart = start
+	m.windowEnd = min(len(results), start+max(resultPageSize, keep))
+	return m.applyMarks(toListItems(results[m.windowStart:m.windowEnd], ""))
+}
+
+// clearResults empties the list, dropping held back results.
+func (m *model) clearResults() {
+	m.results, m.windowStart, m.windowEnd = nil, 0, 0
+	m.list.SetItems([]list.Item{})
+}
+
+// slideWindow appends the next page of held back results once the selection
+// is within a page of the end of the list, dropping the first page beyond
+// maxWindowPages, and brings the previous page back once it is within a page
+// of the start.
+func (m *model) slideWindow() tea.Cmd {
+	items := m.list.Items()
+	index, perPage := m.list.Index(), m.list.Paginator.PerPage
+	switch {
+	case m.windowEnd < len(m.results) && index >= len(items)-perPage:
+		end := min(len(m.results), m.windowEnd+resultPageSize)
+		if end-m.windowStart <= maxWindowPages*resultPageSize {
+			var previous string
+			if last, ok := items[len(items)-1].(listItem); ok {
+				previous = last.section
+			}
+			m.list.SetItems(slices.Concat(items, m.applyMarks(toListItems(m.results[m.windowEnd:end], previous))))
+			m.windowEnd = end
+		} else {
+			m.moveWindow(end-maxWindowPages*resultPageSize, end)
+		}
+	case m.windowStart > 0 && index < perPage:
+		start := max(0, m.windowStart-resultPageSize)
+		m.moveWindow(start, min(m.windowEnd, start+maxWindowPages*resultPageSize))
+	default:
+		return nil
+	}
+	return m.hydrateVisibleItems()
+}
+
+// moveWindow makes the list hold the results from start to end, keeping the
+// selected result selected.
+func (m *model) moveWindow(start, end int) {
+	var selected string
+	if li, ok := m.list.SelectedItem().(listItem); ok {
+		selected = li.identifier
+	}
+	m.windowStart, m.windowEnd = start, end
+	m.list.SetItems(m.applyMarks(toListItems(m.results[start:end], "")))
+	m.list.Select(max(m.findItemIndex(selected), 0))
+}
@@ -0,0 +1,90 @@
+// Package assets lists the data files embedded in the binary, so that Incipio
+// works offline on first run without installing data files, and exports them
+// to be customized: exported into the config directory, themes and units are
+// loaded from there in place of the embedded ones.
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	incipio "github.com/barab-i/incipio"
+	"github.com/barab-i/incipio/internal/plugins/calculator"
+	"github.com/barab-i/incipio/pkgs/theme"
+)
+
+// Group is a set of embedded files exported together.
+type Group struct {
+	Name        string
+	Description string
+	// Dir is where the files are exported, relative to the export directory.
+	Dir   string
+	Files fs.FS
+}
+
+// Groups returns the embedded file groups.
+func Groups() []Group {
+	templates, err := fs.Sub(incipio.ExamplePlugins, "examples/plugins")
+	if err != nil {
+		panic(err) // The directory is embedded.
+	}
+	return []Group{
+		{
+			Name:        "themes",
+			Description: "Bundled Base16 themes, selectable with theme: <name>",
+			Dir:         "themes",
+			Files:       theme.Bundled(),
+		},
+		{
+			Name:        "units",
+			Description: "Unit table of the calculator's conversions",
+			Dir:         ".",
+			Files:       calculator.Units,
+		},
+		{
+			Name:        "plugin-templates",
+			Description: "Example Yaegi plugins, to copy into the plugins directory",
+			Dir:         "plugin-templates",
+			Files:       templates,
+		},
+	}
+}
+
+// Export writes the files of groups under dir and returns the paths written.
+// Existing files are kept unless overwrite is set, and returned in skipped.
+func Export(dir string, groups []Group, overwrite bool) (written, skipped []string, err error) {
+	for _, g := range groups {
+		err := fs.WalkDir(g.Files, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			path := filepath.Join(dir, g.Dir, filepath.FromSlash(name))
+			if _, err := os.Stat(path); err == nil && !overwrite {
+				skipped = append(skipped, path)
+				return nil
+			} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			data, err := fs.ReadFile(g.Files, name)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return err
+			}
+			written = append(written, path)
+			return nil
+		})
+		if err != nil {
+			return written, skipped, fmt.Errorf("could not export %s: %w", g.Name, err)
+		}
+	}
+	return written, skipped, nil
+}
@@ -0,0 +1,249 @@
+// Package binplugin runs plugins written in any language: executables in the
+// plugin directory's bin/ subdirectory, which answer JSON-RPC 2.0 requests
+// sent one per line on their stdin with one line on their stdout.
+package binplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const PluginDirName = "incipio/plugins/bin"
+
+const errorIdentifier = "bin_error"
+
+const (
+	// metadataTimeout bounds the start of a plugin, answering its metadata.
+	metadataTimeout = 5 * time.Second
+	callTimeout     = 5 * time.Second
+)
+
+// LoadedPlugin is a plugin started from an executable in the plugin directory.
+type LoadedPlugin struct {
+	Path   string
+	Plugin *Plugin
+}
+
+// PluginDir returns the directory executable plugins are started from.
+func PluginDir() string {
+	return filepath.Join(xdg.ConfigHome, PluginDirName)
+}
+
+// LoadPlugins starts the executables in the plugin directory and asks them
+// for their metadata. Plugins that fail to answer are logged and skipped.
+func LoadPlugins() ([]LoadedPlugin, error) {
+	dir := PluginDir()
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		zap.L().Debug("Executable plugin directory not found, skipping.", zap.String("path", dir))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read executable plugin directory '%s': %w", dir, err)
+	}
+
+	var loaded []LoadedPlugin
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") || !isExecutable(path) {
+			continue
+		}
+		p, err := Load(path)
+		if err != nil {
+			zap.L().Warn("Could not load executable plugin.", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		loaded = append(loaded, LoadedPlugin{Path: path, Plugin: p})
+	}
+	return loaded, nil
+}
+
+// isExecutable reports whether path is a regular file, or a link to one,
+// that may be executed.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
+
+// Load starts the executable at path and returns it as a plugin.
+func Load(path string) (*Plugin, error) {
+	proc, err := start(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not start: %w", err)
+	}
+	var meta metadataResult
+	if err := proc.call(context.Background(), methodMetadata, nil, &meta, metadataTimeout); err != nil {
+		proc.stop()
+		return nil, fmt.Errorf("no metadata: %w", err)
+	}
+	if meta.Keyword == "" {
+		proc.stop()
+		return nil, errors.New("metadata has an empty keyword")
+	}
+
+	zap.L().Info("Loaded executable plugin.", zap.String("name", meta.Name), zap.String("keyword", meta.Keyword), zap.String("path", path))
+	return &Plugin{
+		path: path,
+		metadata: plugin.Metadata{
+			Name:        meta.Name,
+			Description: meta.Description,
+			Keyword:     meta.Keyword,
+			Flag:        meta.Flag,
+			IsDefault:   meta.IsDefault,
+			// Executables are not sandboxed.
+			Capabilities: []plugin.Capability{plugin.CapabilityNetwork, plugin.CapabilityExec, plugin.CapabilityFilesystem},
+		},
+		proc: proc,
+	}, nil
+}
+
+// Plugin implements the plugin.Plugin interface for an executable. The
+// process is restarted on the next request if it exits or fails to answer.
+type Plugin struct {
+	path     string
+	metadata plugin.Metadata
+
+	mu   sync.Mutex // Serializes requests.
+	proc *process   // Nil after the process failed, until restarted.
+	err  error
+}
+
+// call sends a request to the process, starting it if needed.
+func (p *Plugin) call(ctx context.Context, method string, params, result any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.proc == nil {
+		proc, err := start(p.path)
+		if err != nil {
+			p.err = fmt.Errorf("could not restart %s: %w", p.path, err)
+			return p.err
+		}
+		p.proc = proc
+	}
+	err := p.proc.call(ctx, method, params, result, callTimeout)
+	var rpcErr *rpcError
+	if ctx.Err() != nil {
+		return err // Superseded; not an error of the plugin.
+	}
+	if err != nil && !errors.As(err, &rpcErr) {
+		// The process is gone or out of step with the requests.
+		p.proc.stop()
+		p.proc = nil
+	}
+	p.err = err
+	return err
+}
+
+// Metadata returns the plugin's metadata.
+func (p *Plugin) Metadata() plugin.Metadata {
+	return p.metadata
+}
+
+// Name returns the plugin's name.
+func (p *Plugin) Name() string {
+	return p.metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *Plugin) Keyword() string {
+	return p.metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *Plugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults asks the plugin for the results of the query.
+func (p *Plugin) GetResults(query string) ([]plugin.Result, error) {
+	return p.GetResultsContext(context.Background(), query)
+}
+
+// GetResultsContext asks the plugin for the results of the query, no longer
+// waiting for them once ctx is cancelled.
+func (p *Plugin) GetResultsContext(ctx context.Context, query string) ([]plugin.Result, error) {
+	var reply queryResult
+	err := p.call(ctx, methodQuery, queryParams{Query: query}, &reply)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		zap.L().Warn("Executable plugin query failed.", zap.String("path", p.path), zap.Error(err))
+		return []plugin.Result{{
+			Title:       "Plugin error",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+	results := make([]plugin.Result, 0, len(reply.Results))
+	for _, r := range reply.Results {
+		results = append(results, plugin.Result{Title: r.Title, Description: r.Description, Identifier: r.Identifier, Section: r.Section})
+	}
+	return results, nil
+}
+
+// Execute asks the plugin to execute the result, then copies, runs or quits
+// as the plugin replies.
+func (p *Plugin) Execute(identifier string) tea.Cmd {
+	if identifier == errorIdentifier {
+		return nil
+	}
+	var reply executeResult
+	if err := p.call(context.Background(), methodExecute, executeParams{Identifier: identifier}, &reply); err != nil {
+		zap.L().Error("Executable plugin failed to execute result.", zap.String("path", p.path), zap.String("identifier", identifier), zap.Error(err))
+		return nil
+	}
+	if reply.Copy != "" {
+		if err := clipboard.WriteAll(reply.Copy); err != nil {
+			zap.L().Error("Failed to copy to clipboard.", zap.Error(err))
+			return nil
+		}
+	}
+	if reply.Run != nil && len(reply.Run.Argv) > 0 {
+		return plugin.Run(*reply.Run)
+	}
+	if reply.Close {
+		return tea.Quit
+	}
+	return nil
+}
+
+// Update handles messages.
+func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *Plugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed request, if any.
+func (p *Plugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Stop stops the plugin's process.
+func (p *Plugin) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.proc != nil {
+		p.proc.stop()
+		p.proc = nil
+	}
+}
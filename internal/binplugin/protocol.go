@@ -0,0 +1,195 @@
+package binplugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zapio"
+)
+
+// Methods of the protocol. Each request is a JSON-RPC 2.0 object on one line
+// of the plugin's stdin, answered by one line on its stdout.
+const (
+	// methodMetadata returns metadataResult.
+	methodMetadata = "metadata"
+	// methodQuery takes queryParams and returns queryResult.
+	methodQuery = "query"
+	// methodExecute takes executeParams and returns executeResult.
+	methodExecute = "execute"
+)
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+type metadataResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Keyword     string `json:"keyword"`
+	Flag        string `json:"flag"`
+	IsDefault   bool   `json:"is_default"`
+}
+
+type queryParams struct {
+	Query string `json:"query"`
+}
+
+type queryResult struct {
+	Results []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Identifier  string `json:"identifier"`
+		Section     string `json:"section"`
+	} `json:"results"`
+}
+
+type executeParams struct {
+	Identifier string `json:"identifier"`
+}
+
+// executeResult tells the launcher what to do once a result was executed.
+type executeResult struct {
+	// Close quits the launcher.
+	Close bool `json:"close"`
+	// Copy is copied to the clipboard, if set.
+	Copy string `json:"copy"`
+	// Run is a command the launcher runs: argv, and optionally env, dir,
+	// detach and terminal.
+	Run *plugin.Command `json:"run"`
+}
+
+// errTimeout is returned when a plugin does not answer in time. The process
+// is stopped, since its answer would arrive out of turn.
+var errTimeout = errors.New("plugin did not answer in time")
+
+// process is a running plugin executable.
+type process struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan []byte   // Lines of stdout, closed when the process exits.
+	stopped  chan struct{} // Closed by stop.
+	stopOnce sync.Once
+	nextID   atomic.Int64
+}
+
+// start runs the executable at path.
+func start(path string) (*process, error) {
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = &zapio.Writer{Log: zap.L().With(zap.String("plugin", path)), Level: zapcore.DebugLevel}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{cmd: cmd, stdin: stdin, lines: make(chan []byte), stopped: make(chan struct{})}
+	go func() {
+		defer func() {
+			close(p.lines)
+			_ = cmd.Wait()
+		}()
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			select {
+			case p.lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-p.stopped:
+				return
+			}
+		}
+	}()
+	return p, nil
+}
+
+// maxLineSize caps the size of a response.
+const maxLineSize = 4 << 20
+
+// call sends a request and decodes the result of its response into result.
+// When ctx is cancelled first, call returns its error and the response is
+// skipped by the next call.
+func (p *process) call(ctx context.Context, method string, params, result any, timeout time.Duration) error {
+	id := p.nextID.Add(1)
+	data, err := json.Marshal(request{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not send %s: %w", method, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return errors.New("plugin exited")
+			}
+			var resp response
+			if err := json.Unmarshal(line, &resp); err != nil {
+				return fmt.Errorf("invalid response to %s: %w", method, err)
+			}
+			if resp.ID != id {
+				continue // Not an answer to this request.
+			}
+			if resp.Error != nil {
+				return resp.Error
+			}
+			if result == nil {
+				return nil
+			}
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("invalid result of %s: %w", method, err)
+			}
+			return nil
+		case <-timer.C:
+			return errTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// stop closes the plugin's stdin, which asks it to exit, and kills it.
+func (p *process) stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopped)
+		_ = p.stdin.Close()
+		_ = p.cmd.Process.Kill()
+	})
+}
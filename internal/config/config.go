@@ -0,0 +1,463 @@
+// Package config loads launcher settings from $XDG_CONFIG_HOME/incipio/config.yaml.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+const configDir = "incipio"
+
+// Defaults used when a setting is missing from the config file.
+const (
+	DefaultDebounce   = 200 * time.Millisecond
+	DefaultMaxResults = 0 // No limit.
+	DefaultMaxPanics  = 3
+)
+
+// Config holds the launcher settings. Command-line flags take precedence over these values.
+type Config struct {
+	// Plugins lists the flags of optional plugins to enable, like --plugins.
+	Plugins []string `yaml:"plugins"`
+	// DefaultPlugin is the keyword or flag of the plugin active when no keyword is typed.
+	// Empty keeps the plugin marked as default in its metadata.
+	DefaultPlugin string `yaml:"default_plugin"`
+	// Debounce is how long typing must pause before a query runs, e.g. "150ms".
+	Debounce time.Duration `yaml:"debounce"`
+	// MaxResults caps the number of results shown per query. Zero means no limit.
+	MaxResults int `yaml:"max_results"`
+	// IntentRouting sends queries typed without a keyword to the plugin matching
+	// what they look like, e.g. "2+2" to the calculator, instead of the default plugin.
+	IntentRouting bool `yaml:"intent_routing"`
+	// MatchOrder lists the plugins, by flag or keyword, that may claim queries
+	// typed without a keyword by what they look like, e.g. the calculator for
+	// "2+2", first listed first. Unset lets every such plugin claim queries;
+	// an empty list none.
+	MatchOrder []string `yaml:"match_order"`
+	// Aggregate combines the results of several plugins for queries typed
+	// without a keyword, instead of those of the default plugin alone.
+	Aggregate AggregateConfig `yaml:"aggregate"`
+	// ShowQueryStats shows "N results in 12ms" next to the input after each query.
+	ShowQueryStats bool `yaml:"show_query_stats"`
+	// Theme names a bundled theme or a file in the themes directory, e.g.
+	// "gruvbox". Keys set in theme.yaml apply on top of it. Empty uses theme.yaml alone.
+	Theme string `yaml:"theme"`
+	// Appearance picks the light or dark variants of the theme: "light", "dark",
+	// or "auto" (the default) to follow the terminal background.
+	Appearance string `yaml:"appearance"`
+	// Mouse enables scrolling with the wheel and selecting results by clicking.
+	// Terminals then only select text with shift held. On by default.
+	Mouse bool `yaml:"mouse"`
+	// Terminal is the terminal emulator programs needing one are started in,
+	// such as apps with Terminal=true, e.g. "foot" or "alacritty --class popup".
+	// Empty uses $TERMINAL, or else the first known emulator installed.
+	Terminal string `yaml:"terminal"`
+	// Keywords replaces the keywords typed to switch to plugins, by plugin
+	// flag or keyword, e.g. wikipedia: ["!wiki", "!w"]. A plugin keeps its own
+	// keyword only if listed; other settings still name it by its own.
+	Keywords map[string][]string `yaml:"keywords"`
+	// Keybindings maps actions (up, down, enter, quit, esc, peek, intent, help, preview) to the keys triggering them.
+	Keybindings map[string][]string `yaml:"keybindings"`
+	// PluginLimits caps the results and query time of plugins, by plugin flag
+	// or keyword, e.g. nixshell: {max_results: 200, timeout: 2s}.
+	PluginLimits map[string]PluginLimitsConfig `yaml:"plugin_limits"`
+	// MaxPluginPanics is how many times in a row a call of an optional plugin
+	// may crash before the plugin is disabled until restarted or enabled with
+	// !p. Zero never disables plugins.
+	MaxPluginPanics int `yaml:"max_plugin_panics"`
+	// PluginKeybindings binds keys to the actions plugins offer on the selected
+	// result, by plugin flag or keyword, e.g. wikipedia: {open in browser: [ctrl+o]}.
+	// The help overlay lists the actions of the active plugin.
+	PluginKeybindings map[string]map[string][]string `yaml:"plugin_keybindings"`
+	// Layout caps the size of the launcher content and places the input.
+	Layout LayoutConfig `yaml:"layout"`
+	// AppLauncher configures the application launcher.
+	AppLauncher AppLauncherConfig `yaml:"applauncher"`
+	// Grep configures the ripgrep content search plugin.
+	Grep GrepConfig `yaml:"grep"`
+	// WebSearch configures the search engines of the web search plugin.
+	WebSearch WebSearchConfig `yaml:"websearch"`
+	// Calculator configures the exchange rates of currency conversions.
+	Calculator CalculatorConfig `yaml:"calculator"`
+	// Run configures where the run plugin starts programs.
+	Run RunConfig `yaml:"run"`
+	// AI configures the endpoint of the assistant plugin.
+	AI AIConfig `yaml:"ai"`
+	// Todo configures the file of the to-do plugin.
+	Todo TodoConfig `yaml:"todo"`
+	// Elevation picks how plugins run privileged actions as root.
+	Elevation ElevationConfig `yaml:"elevation"`
+}
+
+// LayoutConfig holds the content size caps. Caps are in terminal cells; zero
+// keeps the cap suggested for the focused monitor and -1 removes the cap.
+type LayoutConfig struct {
+	SizeConfig `yaml:",inline"`
+	// Monitors overrides the caps per output name, e.g. "DP-1".
+	Monitors map[string]SizeConfig `yaml:"monitors"`
+	// DropUp anchors the input at the bottom of the terminal with the results
+	// growing upward, for terminals docked at the bottom of the screen.
+	DropUp bool `yaml:"drop_up"`
+	// Mode arranges the results: "list", the default, or "grid" for several
+	// columns, like rofi's grid mode.
+	Mode string `yaml:"mode"`
+	// Anchor places content shorter than the terminal at its "top", the
+	// default, "center" or "bottom", e.g. in a popup terminal.
+	Anchor string `yaml:"anchor"`
+}
+
+// OverrideSize replaces the caps set in size for all outputs, including those
+// of Monitors, as the --width and --height flags do. Zero fields are kept.
+func (l *LayoutConfig) OverrideSize(size SizeConfig) {
+	for name, monitor := range l.Monitors {
+		if size.MaxWidth != 0 {
+			monitor.MaxWidth = 0
+		}
+		if size.MaxHeight != 0 {
+			monitor.MaxHeight = 0
+		}
+		l.Monitors[name] = monitor
+	}
+	if size.MaxWidth != 0 {
+		l.MaxWidth = size.MaxWidth
+	}
+	if size.MaxHeight != 0 {
+		l.MaxHeight = size.MaxHeight
+	}
+}
+
+// SizeConfig caps the content width and height.
+type SizeConfig struct {
+	MaxWidth  int `yaml:"max_width"`
+	MaxHeight int `yaml:"max_height"`
+}
+
+// Resolve applies the configured caps for the named output on top of the
+// suggested ones and returns the caps to use, zero meaning no cap.
+func (l LayoutConfig) Resolve(output string, suggestedWidth, suggestedHeight int) (int, int) {
+	width, height := suggestedWidth, suggestedHeight
+	for _, override := range []SizeConfig{l.SizeConfig, l.Monitors[output]} {
+		if override.MaxWidth != 0 {
+			width = override.MaxWidth
+		}
+		if override.MaxHeight != 0 {
+			height = override.MaxHeight
+		}
+	}
+	return max(width, 0), max(height, 0)
+}
+
+// PluginLimitsConfig holds the limits of one plugin.
+type PluginLimitsConfig struct {
+	// MaxResults caps the results of the plugin in place of max_results, which
+	// applies if zero.
+	MaxResults int `yaml:"max_results"`
+	// Timeout is how long the plugin may take to answer a query, e.g. "2s",
+	// before its results are given up and a notice shown. Zero means no timeout.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// AggregateConfig lists the plugins whose results are combined for queries
+// typed without a keyword.
+type AggregateConfig struct {
+	// Plugins are queried at once, by flag or keyword, e.g. ["!a", "!h",
+	// snippets], and listed in this order under their names. The default
+	// plugin comes first unless listed. Empty lists the default plugin alone.
+	Plugins []string `yaml:"plugins"`
+	// Limit is the number of results listed of each plugin. Zero means 5.
+	Limit int `yaml:"limit"`
+}
+
+// AppLauncherConfig holds the settings of the !a plugin.
+type AppLauncherConfig struct {
+	// IgnoreShowIn lists apps whatever their OnlyShowIn and NotShowIn keys,
+	// which otherwise hide apps meant for other desktops than those of
+	// $XDG_CURRENT_DESKTOP, like GNOME's Settings panels under KDE.
+	IgnoreShowIn bool `yaml:"ignore_show_in"`
+}
+
+// GrepConfig holds the settings of the !grep plugin.
+type GrepConfig struct {
+	// Directory is searched by default. Empty means the home directory.
+	Directory string `yaml:"directory"`
+	// Args are extra arguments passed to rg, e.g. ["--hidden", "--glob=!.git"].
+	Args []string `yaml:"args"`
+}
+
+// WebSearchConfig holds the settings of the !s plugin.
+type WebSearchConfig struct {
+	// Default is the bang of the engine used when the query starts with none.
+	// Empty means DuckDuckGo.
+	Default string `yaml:"default"`
+	// Engines maps bangs to URL templates, adding to or replacing the built-in
+	// engines. "{query}" in the template is replaced by the escaped query, e.g.
+	// gh: https://github.com/search?q={query}.
+	Engines map[string]string `yaml:"engines"`
+}
+
+// CalculatorConfig holds the settings of the = plugin.
+type CalculatorConfig struct {
+	// RatesProvider is where exchange rates come from: "ecb" (the default)
+	// for the European Central Bank, or the URL of a JSON API answering
+	// {"base": ..., "rates": {...}}, like https://api.frankfurter.app/latest.
+	RatesProvider string `yaml:"rates_provider"`
+	// RatesTTL is how long fetched rates are used before fetching them again.
+	// Zero means 12h. Cached rates are still used while offline.
+	RatesTTL time.Duration `yaml:"rates_ttl"`
+}
+
+// RunConfig holds the settings of the !run plugin.
+type RunConfig struct {
+	// Terminal names programs to run in a terminal, in addition to known
+	// text interface programs like htop or vim.
+	Terminal []string `yaml:"terminal"`
+	// Detached names programs to run without a terminal, overriding the
+	// known text interface programs.
+	Detached []string `yaml:"detached"`
+}
+
+// TodoConfig holds the settings of the !td plugin.
+type TodoConfig struct {
+	// File keeps the tasks: a markdown checklist if it ends in .md, todo.txt
+	// otherwise. Empty means ~/.local/share/incipio/todo.txt.
+	File string `yaml:"file"`
+}
+
+// AIConfig holds the settings of the !ai plugin.
+type AIConfig struct {
+	// Provider is the API spoken by the endpoint: "ollama" (the default) or
+	// "openai" for OpenAI-compatible chat completion servers.
+	Provider string `yaml:"provider"`
+	// Endpoint is the base URL of the API, e.g. "http://localhost:11434" for
+	// Ollama or "https://api.openai.com/v1". Empty means a local Ollama.
+	Endpoint string `yaml:"endpoint"`
+	// Model is the model answering, e.g. "llama3.2".
+	Model string `yaml:"model"`
+	// APIKeyEnv names the environment variable holding the API key, if the
+	// endpoint needs one, e.g. "OPENAI_API_KEY". Keys are not read from the file.
+	APIKeyEnv string `yaml:"api_key_env"`
+	// SystemPrompt is sent before every conversation.
+	SystemPrompt string `yaml:"system_prompt"`
+}
+
+// ElevationConfig holds the methods plugins elevate privileged actions with,
+// like starting a system timer: "pkexec", "sudo" (asking through $SUDO_ASKPASS)
+// or "auto", the default, for pkexec when installed and sudo otherwise.
+type ElevationConfig struct {
+	// Method is used by plugins without a method of their own.
+	Method string `yaml:"method"`
+	// Plugins overrides the method by plugin flag, e.g. cron: sudo.
+	Plugins map[string]string `yaml:"plugins"`
+}
+
+// Default returns the settings used without a config file.
+func Default() Config {
+	return Config{
+		Debounce:        DefaultDebounce,
+		MaxResults:      DefaultMaxResults,
+		MaxPluginPanics: DefaultMaxPanics,
+		Mouse:           true,
+	}
+}
+
+// Path returns the location of the config file.
+func Path() (string, error) {
+	return xdg.ConfigFile(filepath.Join(configDir, configFileName))
+}
+
+// Load reads the config file. A missing file is not an error and yields Default().
+// On error, Default() is returned along with the error.
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err != nil {
+		return cfg, fmt.Errorf("could not determine config path: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		zap.L().Debug("Config file not found, using defaults.", zap.String("path", path))
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return Default(), fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	zap.L().Debug("Loaded config file.", zap.String("path", path))
+	return cfg, nil
+}
+
+// SetPluginEnabled adds flag to the plugins of the config file, or removes it,
+// keeping the other settings and their comments. A missing file is created.
+func SetPluginEnabled(flag string, enabled bool) error {
+	path, err := Path()
+	if err != nil {
+		return fmt.Errorf("could not determine config path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 { // Empty or missing file.
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("invalid %s: not a mapping", path)
+	}
+
+	var plugins *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "plugins" {
+			plugins = root.Content[i+1]
+		}
+	}
+	if plugins == nil {
+		plugins = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "plugins"}, plugins)
+	} else if plugins.Tag == "!!null" {
+		*plugins = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	if plugins.Kind != yaml.SequenceNode {
+		return fmt.Errorf("invalid %s: plugins is not a list", path)
+	}
+
+	kept := plugins.Content[:0]
+	for _, item := range plugins.Content {
+		if strings.TrimSpace(item.Value) != flag {
+			kept = append(kept, item)
+		}
+	}
+	plugins.Content = kept
+	if enabled {
+		plugins.Content = append(plugins.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: flag})
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("could not encode %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("could not encode %s: %w", path, err)
+	}
+	// Replace the file atomically so a crash never truncates it, writing
+	// through a symlink, as dotfile managers make, and keeping its mode.
+	target, perm := path, os.FileMode(0o644)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		target = resolved
+	}
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := atomicfile.WriteFile(target, []byte(buf.String()), perm); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	zap.L().Debug("Saved plugin status to config file.", zap.String("flag", flag), zap.Bool("enabled", enabled), zap.String("path", path))
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.Debounce < 0 {
+		return fmt.Errorf("debounce must not be negative, got %s", c.Debounce)
+	}
+	if c.MaxResults < 0 {
+		return fmt.Errorf("max_results must not be negative, got %d", c.MaxResults)
+	}
+	if c.MaxPluginPanics < 0 {
+		return fmt.Errorf("max_plugin_panics must not be negative, got %d", c.MaxPluginPanics)
+	}
+	if c.Aggregate.Limit < 0 {
+		return fmt.Errorf("aggregate.limit must not be negative, got %d", c.Aggregate.Limit)
+	}
+	switch c.Appearance {
+	case "", "auto", "light", "dark":
+	default:
+		return fmt.Errorf("appearance must be auto, light or dark, got %q", c.Appearance)
+	}
+	switch c.Layout.Mode {
+	case "", "list", "grid":
+	default:
+		return fmt.Errorf("layout.mode must be list or grid, got %q", c.Layout.Mode)
+	}
+	switch c.Layout.Anchor {
+	case "", "top", "center", "bottom":
+	default:
+		return fmt.Errorf("layout.anchor must be top, center or bottom, got %q", c.Layout.Anchor)
+	}
+	if c.Calculator.RatesTTL < 0 {
+		return fmt.Errorf("calculator.rates_ttl must not be negative, got %s", c.Calculator.RatesTTL)
+	}
+	if p := c.Calculator.RatesProvider; p != "" && p != "ecb" && !strings.HasPrefix(p, "https://") && !strings.HasPrefix(p, "http://") {
+		return fmt.Errorf("calculator.rates_provider must be ecb or an http(s) URL, got %q", p)
+	}
+	for name, method := range c.Elevation.Plugins {
+		if err := validateElevationMethod(method); err != nil {
+			return fmt.Errorf("elevation.plugins.%s: %w", name, err)
+		}
+	}
+	if err := validateElevationMethod(c.Elevation.Method); err != nil {
+		return fmt.Errorf("elevation.method: %w", err)
+	}
+	switch c.AI.Provider {
+	case "", "ollama", "openai":
+	default:
+		return fmt.Errorf("ai provider must be ollama or openai, got %q", c.AI.Provider)
+	}
+	for plugin, limits := range c.PluginLimits {
+		if limits.MaxResults < 0 {
+			return fmt.Errorf("plugin_limits.%s.max_results must not be negative, got %d", plugin, limits.MaxResults)
+		}
+		if limits.Timeout < 0 {
+			return fmt.Errorf("plugin_limits.%s.timeout must not be negative, got %s", plugin, limits.Timeout)
+		}
+	}
+	for plugin, actions := range c.PluginKeybindings {
+		for action, keys := range actions {
+			if len(keys) == 0 {
+				return fmt.Errorf("plugin_keybindings action '%s' of %s has no keys", action, plugin)
+			}
+		}
+	}
+	for bang, template := range c.WebSearch.Engines {
+		if bang == "" || strings.ContainsAny(bang, " \t") {
+			return fmt.Errorf("websearch engine bang %q must be a single word", bang)
+		}
+		if !strings.HasPrefix(template, "https://") && !strings.HasPrefix(template, "http://") {
+			return fmt.Errorf("websearch engine %q must be an http(s) URL, got %q", bang, template)
+		}
+	}
+	return nil
+}
+
+func validateElevationMethod(method string) error {
+	switch method {
+	case "", "auto", "pkexec", "sudo":
+		return nil
+	default:
+		return fmt.Errorf("must be auto, pkexec or sudo, got %q", method)
+	}
+}
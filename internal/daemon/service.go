@@ -0,0 +1,226 @@
+// Package daemon installs and manages the services that start the incipio
+// daemon at login: a systemd user service, or an XDG autostart entry on
+// systems without a systemd user instance.
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	// ServiceName is the systemd user unit running the daemon.
+	ServiceName = "incipio.service"
+	// autostartFileName is the XDG autostart entry used without systemd.
+	autostartFileName = "incipio-daemon.desktop"
+)
+
+// Method is how the daemon is started at login.
+type Method string
+
+const (
+	MethodSystemd   Method = "systemd"
+	MethodAutostart Method = "autostart"
+)
+
+// Status describes what is installed for the daemon.
+type Status struct {
+	// ServicePath is the location of the systemd user unit.
+	ServicePath string
+	// ServiceInstalled reports whether the unit file exists.
+	ServiceInstalled bool
+	// ServiceEnabled and ServiceActive are the states reported by systemctl,
+	// e.g. "enabled" and "active". They are empty when systemd is unavailable.
+	ServiceEnabled string
+	ServiceActive  string
+	// AutostartPath is the location of the XDG autostart entry.
+	AutostartPath string
+	// AutostartInstalled reports whether the autostart entry exists.
+	AutostartInstalled bool
+	// Executable is the command the installed files run, if any is installed.
+	Executable string
+}
+
+// servicePath returns the path of the systemd user unit.
+func servicePath() string {
+	return filepath.Join(xdg.ConfigHome, "systemd", "user", ServiceName)
+}
+
+// autostartPath returns the path of the XDG autostart entry.
+func autostartPath() string {
+	return filepath.Join(xdg.ConfigHome, "autostart", autostartFileName)
+}
+
+// SystemdAvailable reports whether a systemd user instance is reachable.
+func SystemdAvailable() bool {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	return exec.Command("systemctl", "--user", "show-environment").Run() == nil
+}
+
+// Install writes a unit starting "executable daemon run" with the graphical
+// session and enables it, or an XDG autostart entry when method is
+// MethodAutostart. An existing installation is replaced.
+func Install(executable string, method Method) error {
+	switch method {
+	case MethodSystemd:
+		if err := writeFile(servicePath(), serviceUnit(executable)); err != nil {
+			return err
+		}
+		if err := systemctl("daemon-reload"); err != nil {
+			return err
+		}
+		return systemctl("enable", "--now", ServiceName)
+	case MethodAutostart:
+		return writeFile(autostartPath(), autostartEntry(executable))
+	default:
+		return fmt.Errorf("unknown install method %q", method)
+	}
+}
+
+// Uninstall stops and removes the systemd user unit and the autostart entry.
+// Missing files are not an error.
+func Uninstall() error {
+	var errs []error
+	if _, err := os.Stat(servicePath()); err == nil {
+		if SystemdAvailable() {
+			if err := systemctl("disable", "--now", ServiceName); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := os.Remove(servicePath()); err != nil {
+			errs = append(errs, err)
+		} else if SystemdAvailable() {
+			if err := systemctl("daemon-reload"); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := os.Remove(autostartPath()); err != nil && !os.IsNotExist(err) {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// GetStatus inspects the installed files and the state of the systemd unit.
+func GetStatus() Status {
+	s := Status{ServicePath: servicePath(), AutostartPath: autostartPath()}
+
+	if data, err := os.ReadFile(s.ServicePath); err == nil {
+		s.ServiceInstalled = true
+		s.Executable = execLine(string(data), "ExecStart=")
+		if SystemdAvailable() {
+			s.ServiceEnabled = systemctlState("is-enabled")
+			s.ServiceActive = systemctlState("is-active")
+		}
+	}
+	if data, err := os.ReadFile(s.AutostartPath); err == nil {
+		s.AutostartInstalled = true
+		if s.Executable == "" {
+			s.Executable = execLine(string(data), "Exec=")
+		}
+	}
+	return s
+}
+
+func serviceUnit(executable string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Incipio launcher daemon
+Documentation=https://github.com/barab-i/incipio
+PartOf=graphical-session.target
+After=graphical-session.target
+
+[Service]
+Type=simple
+ExecStart=%s daemon run
+Restart=on-failure
+RestartSec=2
+
+[Install]
+WantedBy=graphical-session.target
+`, quoteSystemdExec(executable))
+}
+
+func autostartEntry(executable string) string {
+	return fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Incipio Daemon
+Comment=Keeps the incipio launcher resident
+Exec=%s daemon run
+NoDisplay=true
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, quoteDesktopExec(executable))
+}
+
+// quoteSystemdExec quotes a path for an ExecStart= line. systemd expands
+// specifiers starting with % and variables starting with $ even in quotes, so
+// both are doubled, and reads C-style escapes in double quotes.
+func quoteSystemdExec(path string) string {
+	path = strings.NewReplacer("%", "%%", "$", "$$").Replace(path)
+	if !strings.ContainsAny(path, " \t\n\"'\\;") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\t", `\t`, "\n", `\n`)
+	return `"` + r.Replace(path) + `"`
+}
+
+// quoteDesktopExec quotes a path for the Exec= line of a desktop entry. Field
+// codes start with %, so it is doubled. Arguments holding reserved characters
+// are double quoted with ", `, $ and \ escaped by a backslash, and as the value
+// is a string, whose escapes are read first, every backslash is then doubled
+// and newlines escaped.
+func quoteDesktopExec(path string) string {
+	path = strings.ReplaceAll(path, "%", "%%")
+	if !strings.ContainsAny(path, " \t\n\"'\\><~|&;$*?#()`") {
+		return path
+	}
+	quoted := strings.NewReplacer(`"`, `\"`, "`", "\\`", "$", `\$`, `\`, `\\`).Replace(path)
+	quoted = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(quoted)
+	return `"` + quoted + `"`
+}
+
+// execLine returns the value of the first line starting with prefix.
+func execLine(content, prefix string) string {
+	for line := range strings.SplitSeq(content, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("could not create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return nil
+}
+
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("systemctl --user %s: %v %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// systemctlState runs a systemctl query like is-active, which prints the state
+// and exits non-zero for inactive units.
+func systemctlState(query string) string {
+	out, _ := exec.Command("systemctl", "--user", query, ServiceName).Output()
+	return strings.TrimSpace(string(out))
+}
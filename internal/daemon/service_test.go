@@ -0,0 +1,39 @@
+package daemon
+
+import "testing"
+
+func TestQuoteSystemdExec(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{`/usr/bin/incipio`, `/usr/bin/incipio`},
+		{`/opt/100%/incipio`, `/opt/100%%/incipio`},
+		{`/home/$USER/incipio`, `/home/$$USER/incipio`},
+		{`/my apps/incipio`, `"/my apps/incipio"`},
+		{`/my apps/50% $off/incipio`, `"/my apps/50%% $$off/incipio"`},
+		{`/a"b\c/incipio`, `"/a\"b\\c/incipio"`},
+		{"/a\tb/incipio", `"/a\tb/incipio"`},
+	}
+	for _, tt := range tests {
+		if got := quoteSystemdExec(tt.path); got != tt.want {
+			t.Errorf("quoteSystemdExec(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteDesktopExec(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{`/usr/bin/incipio`, `/usr/bin/incipio`},
+		{`/opt/100%/incipio`, `/opt/100%%/incipio`},
+		{`/my apps/incipio`, `"/my apps/incipio"`},
+		{`/home/$USER/incipio`, `"/home/\\$USER/incipio"`},
+		{`/a"b/incipio`, `"/a\\"b/incipio"`},
+		{`/a\b/incipio`, `"/a\\\\b/incipio"`},
+		{"/a`b`/incipio", "\"/a\\\\`b\\\\`/incipio\""},
+		{"/a\nb/incipio", `"/a\nb/incipio"`},
+		{`/my apps/50%/incipio`, `"/my apps/50%%/incipio"`},
+	}
+	for _, tt := range tests {
+		if got := quoteDesktopExec(tt.path); got != tt.want {
+			t.Errorf("quoteDesktopExec(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package desktop
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+)
+
+// chooserIdentifier prefixes the desktop entry IDs of the chooser's results.
+const chooserIdentifier = "open_with:"
+
+// OpenWithAction names the action of plugins opening the chooser, bindable in
+// plugin_keybindings.
+const OpenWithAction = "open with…"
+
+// Chooser lists the applications able to open a file as results, for plugins
+// offering an "open with" action on the files they list. Once opened, the
+// plugin shows its results in place of its own until the query changes.
+type Chooser struct {
+	mu        sync.Mutex
+	target    string // Path or URI of the file to open, empty while closed.
+	name      string
+	apps      []Entry
+	defaultID string
+	query     string // The query the chooser was opened at.
+	last      string // The latest query seen by Results.
+}
+
+// Open lists the applications for target, a path or a URI, of mimeType. It
+// fails if none is registered.
+func (c *Chooser) Open(target, mimeType string) error {
+	apps, defaultID := Applications(mimeType)
+	if len(apps) == 0 {
+		return fmt.Errorf("no application is registered for %s", mimeType)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.target, c.name, c.apps, c.defaultID, c.query = target, path.Base(target), apps, defaultID, c.last
+	return nil
+}
+
+// Results returns the applications to choose from, and false once the query
+// changed since Open, closing the chooser. Plugins call it for every query.
+func (c *Chooser) Results(query string) ([]plugin.Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = query
+	if c.target == "" {
+		return nil, false
+	}
+	if query != c.query {
+		c.target, c.apps = "", nil
+		return nil, false
+	}
+
+	results := make([]plugin.Result, 0, len(c.apps))
+	for _, app := range c.apps {
+		description := app.Exec
+		if app.ID == c.defaultID {
+			description = "default | " + description
+		}
+		results = append(results, plugin.Result{
+			Title:       app.Name,
+			Description: description,
+			Identifier:  chooserIdentifier + app.ID,
+			Section:     fmt.Sprintf("Open %s with", c.name),
+		})
+	}
+	return results, true
+}
+
+// Execute opens the file with the application of identifier and closes the
+// chooser. handled is false for identifiers that are not the chooser's.
+func (c *Chooser) Execute(identifier string) (handled bool, err error) {
+	id, ok := strings.CutPrefix(identifier, chooserIdentifier)
+	if !ok {
+		return false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, app := range c.apps {
+		if app.ID != id {
+			continue
+		}
+		command, err := app.Command(c.target)
+		if err != nil {
+			return true, err
+		}
+		if err := launch.Run(command); err != nil {
+			return true, fmt.Errorf("could not start %s: %w", app.Name, err)
+		}
+		c.target, c.apps = "", nil
+		return true, nil
+	}
+	return true, fmt.Errorf("the application chooser was closed")
+}
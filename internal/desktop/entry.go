@@ -0,0 +1,230 @@
+// Package desktop reads freedesktop.org desktop entries and the MIME type
+// associations of mimeapps.list, to open files with the applications
+// registered for them.
+package desktop
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/go-ini/ini"
+)
+
+// Entry is an application described by a .desktop file.
+type Entry struct {
+	// ID names the entry in mimeapps.list: its path below an applications
+	// directory with slashes turned into dashes, e.g. "org.gnome.Evince.desktop".
+	ID        string
+	Path      string
+	Name      string
+	Exec      string
+	Icon      string
+	Terminal  bool
+	MimeTypes []string
+	Hidden    bool
+}
+
+// ReadEntry reads the desktop entry at path, known by id. Semicolons separate
+// list items in desktop entries rather than start comments.
+func ReadEntry(path, id string) (Entry, error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{SkipUnrecognizableLines: true, IgnoreInlineComment: true}, path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to load desktop entry '%s': %w", path, err)
+	}
+	section := cfg.Section("Desktop Entry")
+	terminal, _ := section.Key("Terminal").Bool()
+	hidden, _ := section.Key("Hidden").Bool()
+	entry := Entry{
+		ID:        id,
+		Path:      path,
+		Name:      Localized(section, "Name"),
+		Exec:      section.Key("Exec").String(),
+		Icon:      section.Key("Icon").String(),
+		Terminal:  terminal,
+		MimeTypes: splitList(section.Key("MimeType").String()),
+		Hidden:    hidden,
+	}
+	if entry.Name == "" || entry.Exec == "" {
+		return Entry{}, fmt.Errorf("missing required field Name or Exec in '%s'", path)
+	}
+	return entry, nil
+}
+
+// Entries reads the desktop entries of all applications directories by ID.
+// Entries in directories listed first, such as ~/.local/share/applications,
+// override those of the same ID in later ones.
+func Entries() map[string]Entry {
+	entries := make(map[string]Entry)
+	for _, dir := range xdg.ApplicationDirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".desktop") {
+				return nil // Skip unreadable directories and other files.
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+			id := strings.ReplaceAll(rel, string(filepath.Separator), "-")
+			if _, found := entries[id]; found {
+				return nil
+			}
+			if entry, err := ReadEntry(path, id); err == nil {
+				entries[id] = entry
+			}
+			return nil
+		})
+	}
+	return entries
+}
+
+// Command returns the command opening target, a path or a URI, with the
+// application, or starting it without a file if target is empty. The field
+// codes of Exec are expanded as the Desktop Entry Specification describes;
+// target is appended if Exec takes no file. Applications with Terminal set
+// get a command run in a terminal.
+func (e Entry) Command(target string) (plugin.Command, error) {
+	path, uri := target, target
+	if u, err := url.Parse(target); err == nil && u.Scheme == "file" {
+		path = u.Path
+	} else if filepath.IsAbs(target) {
+		uri = (&url.URL{Scheme: "file", Path: target}).String()
+	}
+
+	argv, expanded, err := e.expandExec(path, uri)
+	if err != nil {
+		return plugin.Command{}, fmt.Errorf("invalid Exec in '%s': %w", e.Path, err)
+	}
+	if len(argv) == 0 {
+		return plugin.Command{}, fmt.Errorf("empty Exec in '%s'", e.Path)
+	}
+	if target != "" && !expanded {
+		argv = append(argv, path)
+	}
+	return plugin.Command{Argv: argv, Terminal: e.Terminal}, nil
+}
+
+// unescapeString undoes the escapes of desktop entry string values, \s, \n,
+// \t, \r and \\, which apply before the quoting of Exec.
+var unescapeString = strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`)
+
+// expandExec splits Exec into arguments and expands its field codes, with
+// path and uri standing for the file to open, none if empty. expanded reports
+// whether Exec takes a file.
+//
+// Arguments are separated by spaces and may be quoted in double quotes,
+// inside which a backslash escapes ", `, $ and \. Field codes may be part of
+// an argument, like --file=%f; %i expands to two arguments, --icon and the
+// icon, and %% is a literal percent sign. Files are never split or quoted,
+// so names with spaces stay single arguments.
+func (e Entry) expandExec(path, uri string) (argv []string, expanded bool, err error) {
+	exec := unescapeString.Replace(e.Exec)
+	var word strings.Builder
+	inWord, quoted := false, false
+	endWord := func() {
+		if inWord {
+			argv = append(argv, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(exec) && strings.IndexByte("\"`$\\", exec[i+1]) >= 0:
+			i++
+			word.WriteByte(exec[i])
+		case c == '"':
+			quoted, inWord = !quoted, true
+		case !quoted && (c == ' ' || c == '\t' || c == '\n'):
+			endWord()
+		case c == '%' && i+1 < len(exec):
+			i++
+			switch exec[i] {
+			case '%':
+				word.WriteByte('%')
+				inWord = true
+			case 'f', 'F':
+				if path != "" {
+					word.WriteString(path)
+					inWord, expanded = true, true
+				}
+			case 'u', 'U':
+				if uri != "" {
+					word.WriteString(uri)
+					inWord, expanded = true, true
+				}
+			case 'i':
+				if e.Icon != "" {
+					endWord()
+					argv = append(argv, "--icon", e.Icon)
+				}
+			case 'c':
+				word.WriteString(e.Name)
+				inWord = true
+			case 'k':
+				word.WriteString(e.Path)
+				inWord = true
+			case 'd', 'D', 'n', 'N', 'v', 'm':
+				// Deprecated field codes, removed.
+			default:
+				return nil, false, fmt.Errorf("unknown field code %%%c", exec[i])
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, false, fmt.Errorf("unterminated quote in %q", e.Exec)
+	}
+	endWord()
+	return argv, expanded, nil
+}
+
+// CurrentDesktops returns the desktop environments of $XDG_CURRENT_DESKTOP,
+// most specific first, e.g. ["ubuntu", "GNOME"].
+func CurrentDesktops() []string {
+	var desktops []string
+	for _, d := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if d != "" {
+			desktops = append(desktops, d)
+		}
+	}
+	return desktops
+}
+
+// ShownIn reports whether an entry with the OnlyShowIn and NotShowIn lists
+// given is shown in desktops, as CurrentDesktops returns them. The first
+// desktop named by either list decides; an entry naming none is shown
+// unless OnlyShowIn is set. Names are compared ignoring case.
+func ShownIn(onlyShowIn, notShowIn string, desktops []string) bool {
+	only, not := splitList(onlyShowIn), splitList(notShowIn)
+	for _, d := range desktops {
+		if slices.ContainsFunc(only, func(name string) bool { return strings.EqualFold(name, d) }) {
+			return true
+		}
+		if slices.ContainsFunc(not, func(name string) bool { return strings.EqualFold(name, d) }) {
+			return false
+		}
+	}
+	return len(only) == 0
+}
+
+// splitList splits a semicolon-separated list value, like MimeType.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,71 @@
+package desktop
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+// localeSuffixes are the suffixes of the localized keys matching the user's
+// locale, most specific first, e.g. [de_DE@euro], [de_DE], [de@euro], [de].
+var localeSuffixes = messagesLocaleSuffixes()
+
+// messagesLocaleSuffixes derives the localized key suffixes from the locale
+// of messages: $LC_ALL, $LC_MESSAGES or $LANG, the first one set.
+func messagesLocaleSuffixes() []string {
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = os.Getenv(name); locale != "" {
+			break
+		}
+	}
+	return localeSuffixesFor(locale)
+}
+
+// localeSuffixesFor returns the suffixes matching a locale of the form
+// lang_COUNTRY.ENCODING@MODIFIER, in the order the Desktop Entry
+// Specification looks them up. The encoding is ignored.
+func localeSuffixesFor(locale string) []string {
+	if locale == "" || locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C.") {
+		return nil
+	}
+	rest, modifier, _ := strings.Cut(locale, "@")
+	rest, _, _ = strings.Cut(rest, ".")
+	lang, country, _ := strings.Cut(rest, "_")
+
+	var suffixes []string
+	if country != "" && modifier != "" {
+		suffixes = append(suffixes, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		suffixes = append(suffixes, lang+"_"+country)
+	}
+	if modifier != "" {
+		suffixes = append(suffixes, lang+"@"+modifier)
+	}
+	suffixes = append(suffixes, lang)
+	for i, s := range suffixes {
+		suffixes[i] = "[" + s + "]"
+	}
+	return suffixes
+}
+
+// Localized returns the value of key translated for the user's locale, or
+// the untranslated value if there is no translation.
+func Localized(section *ini.Section, key string) string {
+	for _, suffix := range localeSuffixes {
+		if section.HasKey(key + suffix) {
+			if value := section.Key(key + suffix).String(); value != "" {
+				return value
+			}
+		}
+	}
+	return section.Key(key).String()
+}
+
+// Locale identifies the translations Localized picks, e.g. "[de_DE][de]", so
+// caches of localized values can tell when the locale changed.
+func Locale() string {
+	return strings.Join(localeSuffixes, "")
+}
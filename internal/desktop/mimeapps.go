@@ -0,0 +1,129 @@
+package desktop
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/go-ini/ini"
+)
+
+// mimeappsLists returns the mimeapps.list files in order of precedence, the
+// desktop-specific ones of $XDG_CURRENT_DESKTOP before the generic one of
+// each directory.
+func mimeappsLists() []string {
+	dirs := append([]string{xdg.ConfigHome}, xdg.ConfigDirs...)
+	dirs = append(dirs, filepath.Join(xdg.DataHome, "applications"))
+	for _, dir := range xdg.DataDirs {
+		dirs = append(dirs, filepath.Join(dir, "applications"))
+	}
+
+	var lists []string
+	for _, dir := range dirs {
+		for _, d := range CurrentDesktops() {
+			lists = append(lists, filepath.Join(dir, strings.ToLower(d)+"-mimeapps.list"))
+		}
+		lists = append(lists, filepath.Join(dir, "mimeapps.list"))
+	}
+	return lists
+}
+
+// Applications returns the installed applications able to open files of
+// mimeType: the default application first, then those associated in
+// mimeapps.list, then the others declaring the type in their desktop entry.
+// Text types also offer the applications for text/plain, last. defaultID is
+// the ID of the default application, if one is set and installed.
+func Applications(mimeType string) (apps []Entry, defaultID string) {
+	entries := Entries()
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if entry, ok := entries[id]; ok && !entry.Hidden && !seen[id] {
+			seen[id] = true
+			apps = append(apps, entry)
+		}
+	}
+
+	types := []string{mimeType}
+	if strings.HasPrefix(mimeType, "text/") && mimeType != "text/plain" {
+		types = append(types, "text/plain")
+	}
+	for _, t := range types {
+		defaults, added, removed := associations(t)
+		for _, id := range defaults {
+			if _, ok := entries[id]; ok {
+				if defaultID == "" {
+					defaultID = id
+				}
+				add(id)
+				break // Only the first installed default counts.
+			}
+		}
+		for _, id := range added {
+			add(id)
+		}
+
+		var declared []Entry
+		for id, entry := range entries {
+			if !removed[id] && !seen[id] && slices.Contains(entry.MimeTypes, t) {
+				declared = append(declared, entry)
+			}
+		}
+		sort.Slice(declared, func(i, j int) bool {
+			return strings.ToLower(declared[i].Name) < strings.ToLower(declared[j].Name)
+		})
+		for _, entry := range declared {
+			add(entry.ID)
+		}
+	}
+	return apps, defaultID
+}
+
+// associations reads the default, added and removed applications of
+// mimeType from the mimeapps.list files. Removals hide the associations of
+// less important lists and of desktop entries, not those of more important ones.
+func associations(mimeType string) (defaults, added []string, removed map[string]bool) {
+	removed = make(map[string]bool)
+	for _, path := range mimeappsLists() {
+		cfg, err := ini.LoadSources(ini.LoadOptions{SkipUnrecognizableLines: true, IgnoreInlineComment: true, Loose: true}, path)
+		if err != nil {
+			continue // Missing or unreadable lists have no associations.
+		}
+		defaults = append(defaults, splitList(cfg.Section("Default Applications").Key(mimeType).String())...)
+		for _, id := range splitList(cfg.Section("Added Associations").Key(mimeType).String()) {
+			if !removed[id] {
+				added = append(added, id)
+			}
+		}
+		for _, id := range splitList(cfg.Section("Removed Associations").Key(mimeType).String()) {
+			removed[id] = true
+		}
+	}
+	return defaults, added, removed
+}
+
+// MimeType guesses the MIME type of the file at path from its extension,
+// using the shared MIME database, or else from its first bytes.
+func MimeType(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "inode/directory"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		t, _, _ = strings.Cut(t, ";")
+		return t
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	t, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return t
+}
@@ -0,0 +1,328 @@
+package index
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"go.uber.org/zap"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver with FTS5 support.
+)
+
+const dataDir = "incipio/index"
+
+// DefaultSearchLimit caps the number of rows returned by Search when no limit is given.
+const DefaultSearchLimit = 200
+
+var tableNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Entry is a single row stored in an index table.
+type Entry struct {
+	Identifier  string
+	Title       string
+	Description string
+}
+
+// Result converts the entry into a plugin.Result.
+func (e Entry) Result() plugin.Result {
+	return plugin.Result{
+		Title:       e.Title,
+		Description: e.Description,
+		Identifier:  e.Identifier,
+	}
+}
+
+// LazyResult converts the entry into a plugin.Result whose description is
+// loaded on demand through plugin.Hydrator.
+func (e Entry) LazyResult() plugin.Result {
+	return plugin.Result{
+		Title:      e.Title,
+		Identifier: e.Identifier,
+		Lazy:       true,
+	}
+}
+
+// Store is a handle to a shared SQLite database holding one or more FTS5 tables.
+// Handles returned by Open for the same name share a single connection pool.
+type Store struct {
+	name string
+	db   *sql.DB
+}
+
+type sharedDB struct {
+	db       *sql.DB
+	refCount int
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]*sharedDB)
+)
+
+// Open returns a Store backed by $XDG_DATA_HOME/incipio/index/<name>.db.
+// The underlying connection is shared between all open handles of the same name
+// and is closed once every handle has been closed.
+func Open(name string) (*Store, error) {
+	if !tableNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid index name '%s'", name)
+	}
+
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	if shared, ok := stores[name]; ok {
+		shared.refCount++
+		return &Store{name: name, db: shared.db}, nil
+	}
+
+	dbPath, err := xdg.DataFile(filepath.Join(dataDir, name+".db"))
+	if err != nil {
+		return nil, fmt.Errorf("could not determine index path for '%s': %w", name, err)
+	}
+
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stores[name] = &sharedDB{db: db, refCount: 1}
+	zap.L().Debug("Opened index store.", zap.String("name", name), zap.String("path", dbPath))
+	return &Store{name: name, db: db}, nil
+}
+
+func openDB(dbPath string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return nil, fmt.Errorf("could not create index directory for '%s': %w", dbPath, err)
+	}
+
+	dsn := "file:" + dbPath + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open index database '%s': %w", dbPath, err)
+	}
+	// SQLite serialises writers anyway; a small pool avoids lock contention between plugins.
+	db.SetMaxOpenConns(4)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to index database '%s': %w", dbPath, err)
+	}
+	return db, nil
+}
+
+// Close releases the handle. The database is closed when the last handle is released.
+func (s *Store) Close() error {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	shared, ok := stores[s.name]
+	if !ok || shared.db != s.db {
+		return nil // Already closed.
+	}
+	shared.refCount--
+	if shared.refCount > 0 {
+		return nil
+	}
+	delete(stores, s.name)
+	return shared.db.Close()
+}
+
+// CloseAll closes every open store regardless of outstanding handles.
+// It is intended to be called once during application shutdown.
+func CloseAll() {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	for name, shared := range stores {
+		if err := shared.db.Close(); err != nil {
+			zap.L().Warn("Error closing index store.", zap.String("name", name), zap.Error(err))
+		}
+		delete(stores, name)
+	}
+}
+
+// EnsureTable creates the FTS5 table if it does not exist yet.
+func (s *Store) EnsureTable(table string) error {
+	if !tableNamePattern.MatchString(table) {
+		return fmt.Errorf("invalid index table name '%s'", table)
+	}
+	stmt := fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(
+		identifier UNINDEXED,
+		title,
+		description,
+		tokenize = 'unicode61 remove_diacritics 2',
+		prefix = '2 3'
+	)`, table)
+	if _, err := s.db.Exec(stmt); err != nil {
+		return fmt.Errorf("could not create index table '%s': %w", table, err)
+	}
+	return nil
+}
+
+// Replace atomically swaps the contents of the table with the given entries.
+func (s *Store) Replace(table string, entries []Entry) error {
+	if err := s.EnsureTable(table); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction on '%s': %w", table, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+		return fmt.Errorf("could not clear index table '%s': %w", table, err)
+	}
+	if err := insertEntries(tx, table, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Upsert inserts the entries, replacing any existing rows with the same identifier.
+func (s *Store) Upsert(table string, entries ...Entry) error {
+	if err := s.EnsureTable(table); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction on '%s': %w", table, err)
+	}
+	defer tx.Rollback()
+
+	deleteStmt, err := tx.Prepare(fmt.Sprintf("DELETE FROM %s WHERE identifier = ?", table))
+	if err != nil {
+		return fmt.Errorf("could not prepare delete on '%s': %w", table, err)
+	}
+	defer deleteStmt.Close()
+
+	for _, e := range entries {
+		if _, err := deleteStmt.Exec(e.Identifier); err != nil {
+			return fmt.Errorf("could not delete '%s' from '%s': %w", e.Identifier, table, err)
+		}
+	}
+	if err := insertEntries(tx, table, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertEntries(tx *sql.Tx, table string, entries []Entry) error {
+	insertStmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s (identifier, title, description) VALUES (?, ?, ?)", table))
+	if err != nil {
+		return fmt.Errorf("could not prepare insert on '%s': %w", table, err)
+	}
+	defer insertStmt.Close()
+
+	for _, e := range entries {
+		if _, err := insertStmt.Exec(e.Identifier, e.Title, e.Description); err != nil {
+			return fmt.Errorf("could not insert '%s' into '%s': %w", e.Identifier, table, err)
+		}
+	}
+	return nil
+}
+
+// Delete removes the row with the given identifier.
+func (s *Store) Delete(table, identifier string) error {
+	if err := s.EnsureTable(table); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE identifier = ?", table), identifier); err != nil {
+		return fmt.Errorf("could not delete '%s' from '%s': %w", identifier, table, err)
+	}
+	return nil
+}
+
+// Count returns the number of rows in the table.
+func (s *Store) Count(table string) (int, error) {
+	if err := s.EnsureTable(table); err != nil {
+		return 0, err
+	}
+	var count int
+	if err := s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s", table)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count rows in '%s': %w", table, err)
+	}
+	return count, nil
+}
+
+// Get returns the row with the given identifier.
+func (s *Store) Get(table, identifier string) (Entry, error) {
+	if err := s.EnsureTable(table); err != nil {
+		return Entry{}, err
+	}
+	e := Entry{Identifier: identifier}
+	err := s.db.QueryRow(fmt.Sprintf("SELECT title, description FROM %s WHERE identifier = ? LIMIT 1", table), identifier).
+		Scan(&e.Title, &e.Description)
+	if err != nil {
+		return Entry{}, fmt.Errorf("could not get '%s' from '%s': %w", identifier, table, err)
+	}
+	return e, nil
+}
+
+// Search runs a full-text prefix search over title and description, best matches first.
+// An empty query returns the first rows in insertion order. A limit <= 0 uses DefaultSearchLimit.
+func (s *Store) Search(table, query string, limit int) ([]Entry, error) {
+	return s.search(table, query, limit, "description")
+}
+
+// SearchTitles behaves like Search but leaves Description empty, so that large
+// result sets only keep titles in memory until rows are hydrated with Get.
+func (s *Store) SearchTitles(table, query string, limit int) ([]Entry, error) {
+	return s.search(table, query, limit, "''")
+}
+
+func (s *Store) search(table, query string, limit int, descriptionColumn string) ([]Entry, error) {
+	if err := s.EnsureTable(table); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	var rows *sql.Rows
+	var err error
+	matchExpr := buildMatchExpression(query)
+	if matchExpr == "" {
+		rows, err = s.db.Query(fmt.Sprintf("SELECT identifier, title, %s FROM %s LIMIT ?", descriptionColumn, table), limit)
+	} else {
+		// Title matches weigh considerably more than description matches.
+		rows, err = s.db.Query(fmt.Sprintf(
+			"SELECT identifier, title, %[2]s FROM %[1]s WHERE %[1]s MATCH ? ORDER BY bm25(%[1]s, 0.0, 10.0, 1.0) LIMIT ?",
+			table, descriptionColumn), matchExpr, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not search '%s': %w", table, err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Identifier, &e.Title, &e.Description); err != nil {
+			return nil, fmt.Errorf("could not read search row from '%s': %w", table, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// buildMatchExpression turns free-form user input into an FTS5 query where every
+// whitespace-separated token is matched as a quoted prefix.
+func buildMatchExpression(query string) string {
+	tokens := strings.Fields(query)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		escaped := strings.ReplaceAll(token, `"`, `""`)
+		terms = append(terms, `"`+escaped+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,277 @@
+// Package ipc connects "incipio --toggle" to the resident daemon over a unix
+// socket. The client passes its terminal to the daemon, which shows the
+// launcher on it with its plugins already loaded, so the UI appears without
+// scanning desktop files or loading indexes again.
+//
+// The socket is a SOCK_SEQPACKET socket, so every request and reply is one
+// packet holding a JSON object. Terminals travel as SCM_RIGHTS control messages.
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	socketName = "incipio.sock"
+	network    = "unixpacket"
+	// maxPacketSize bounds requests and replies, which are small JSON objects.
+	maxPacketSize = 4096
+)
+
+var (
+	// ErrDaemonRunning is returned by Listen when another daemon serves the socket.
+	ErrDaemonRunning = errors.New("daemon already running")
+	// ErrNotRunning is returned by Toggle when no daemon serves the socket.
+	ErrNotRunning = errors.New("daemon not running")
+)
+
+// Command is a request sent to the daemon.
+type Command string
+
+const (
+	// CommandToggle shows the launcher on the terminal passed with the request, or
+	// hides the launcher shown on another terminal.
+	CommandToggle Command = "toggle"
+	// CommandResize tells the daemon that the terminal of the shown launcher was resized.
+	CommandResize Command = "resize"
+)
+
+// Status is a reply of the daemon.
+type Status string
+
+const (
+	// StatusShown means the launcher runs on the client's terminal until StatusClosed is sent.
+	StatusShown Status = "shown"
+	// StatusHidden means the launcher shown on another terminal was closed.
+	StatusHidden Status = "hidden"
+	// StatusClosed means the launcher on the client's terminal exited.
+	StatusClosed Status = "closed"
+	// StatusFailed carries an error instead of the requested change.
+	StatusFailed Status = "failed"
+)
+
+// terminalEnv lists the variables describing the client's terminal, which the
+// daemon needs to pick colors for it.
+var terminalEnv = []string{"TERM", "COLORTERM", "NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE"}
+
+// Request is a packet sent by a client.
+type Request struct {
+	Command Command `json:"command"`
+	// Env holds the variables of terminalEnv set in the client's environment,
+	// as "key=value" pairs. It is only sent with CommandToggle.
+	Env []string `json:"env,omitempty"`
+	// Background is "light" or "dark", as detected by the client for its
+	// terminal. It is only sent with CommandToggle.
+	Background string `json:"background,omitempty"`
+}
+
+type reply struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SocketPath returns the location of the daemon socket in $XDG_RUNTIME_DIR.
+func SocketPath() (string, error) {
+	return xdg.RuntimeFile(socketName)
+}
+
+// Listener accepts clients on the daemon socket.
+type Listener struct {
+	l *net.UnixListener
+}
+
+// Listen serves the socket at path, replacing a stale socket left behind by a
+// daemon that did not exit cleanly.
+func Listen(path string) (*Listener, error) {
+	if conn, err := net.Dial(network, path); err == nil {
+		conn.Close()
+		return nil, ErrDaemonRunning
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("could not remove stale socket: %w", err)
+	}
+
+	l, err := net.ListenUnix(network, &net.UnixAddr{Name: path, Net: network})
+	if err != nil {
+		return nil, err
+	}
+	l.SetUnlinkOnClose(true)
+	return &Listener{l: l}, nil
+}
+
+// Accept waits for the next client.
+func (l *Listener) Accept() (*Conn, error) {
+	c, err := l.l.AcceptUnix()
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{c: c}, nil
+}
+
+// Close stops listening and removes the socket.
+func (l *Listener) Close() error {
+	return l.l.Close()
+}
+
+// Conn is a client connection, as seen by the daemon.
+type Conn struct {
+	c *net.UnixConn
+}
+
+// Receive reads the next request along with the files passed with it: the
+// client's input and output terminals for CommandToggle. The caller owns the files.
+func (c *Conn) Receive() (Request, []*os.File, error) {
+	buf := make([]byte, maxPacketSize)
+	oob := make([]byte, syscall.CmsgSpace(2*4)) // Two file descriptors.
+	n, oobn, _, _, err := c.c.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return Request{}, nil, err
+	}
+	if n == 0 {
+		return Request{}, nil, net.ErrClosed // The client hung up.
+	}
+
+	files, err := parseRights(oob[:oobn])
+	if err != nil {
+		return Request{}, nil, err
+	}
+	var req Request
+	if err := json.Unmarshal(buf[:n], &req); err != nil {
+		CloseFiles(files)
+		return Request{}, nil, fmt.Errorf("invalid request: %w", err)
+	}
+	return req, files, nil
+}
+
+// Reply sends a status to the client. err is sent along with StatusFailed.
+func (c *Conn) Reply(status Status, err error) error {
+	r := reply{Status: status}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return writeJSON(c.c, r, nil)
+}
+
+// Close hangs up.
+func (c *Conn) Close() error {
+	return c.c.Close()
+}
+
+// Toggle asks the daemon at path to show the launcher on the terminal in and
+// out, described by the client's environment and its background, or to hide
+// the launcher shown on another terminal. When shown, it forwards a
+// CommandResize for every value received from resized and returns once the
+// launcher exits. It returns ErrNotRunning if no daemon serves path.
+func Toggle(path string, in, out *os.File, background string, resized <-chan os.Signal) (Status, error) {
+	conn, err := net.DialUnix(network, nil, &net.UnixAddr{Name: path, Net: network})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNotRunning, err)
+	}
+	defer conn.Close()
+
+	req := Request{Command: CommandToggle, Background: background}
+	for _, key := range terminalEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			req.Env = append(req.Env, key+"="+value)
+		}
+	}
+	rights := syscall.UnixRights(int(in.Fd()), int(out.Fd()))
+	if err := writeJSON(conn, req, rights); err != nil {
+		return "", err
+	}
+
+	// done stops the reader once Toggle returned, as the daemon may still
+	// send replies nobody receives.
+	done := make(chan struct{})
+	defer close(done)
+	replies := make(chan reply)
+	errs := make(chan error, 1)
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, err := conn.Read(buf)
+			if err == nil && n == 0 {
+				err = errors.New("daemon hung up")
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			var r reply
+			if err := json.Unmarshal(buf[:n], &r); err != nil {
+				errs <- fmt.Errorf("invalid reply: %w", err)
+				return
+			}
+			select {
+			case replies <- r:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case r := <-replies:
+			switch r.Status {
+			case StatusFailed:
+				return StatusFailed, errors.New(r.Error)
+			case StatusHidden, StatusClosed:
+				return r.Status, nil
+			}
+		case <-resized:
+			if err := writeJSON(conn, Request{Command: CommandResize}, nil); err != nil {
+				return "", err
+			}
+		case err := <-errs:
+			return "", err
+		}
+	}
+}
+
+// writeJSON sends v as one packet, with the given control message.
+func writeJSON(c *net.UnixConn, v any, oob []byte) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.WriteMsgUnix(data, oob, nil)
+	return err
+}
+
+// parseRights returns the files passed in SCM_RIGHTS control messages.
+func parseRights(oob []byte) ([]*os.File, error) {
+	if len(oob) == 0 {
+		return nil, nil
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+	var files []*os.File
+	for _, msg := range msgs {
+		fds, err := syscall.ParseUnixRights(&msg)
+		if err != nil {
+			continue // Not SCM_RIGHTS.
+		}
+		for _, fd := range fds {
+			files = append(files, os.NewFile(uintptr(fd), fmt.Sprintf("client-fd-%d", fd)))
+		}
+	}
+	return files, nil
+}
+
+// CloseFiles closes files received from a client.
+func CloseFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
@@ -0,0 +1,40 @@
+package launch
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+)
+
+// Command builds the process described by c, without starting it.
+func Command(c plugin.Command) (*exec.Cmd, error) {
+	if len(c.Argv) == 0 {
+		return nil, errors.New("command has no program")
+	}
+	cmd := exec.Command(c.Argv[0], c.Argv[1:]...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	return cmd, nil
+}
+
+// Run starts a detached command, in a new terminal window if c.Terminal is set.
+// Commands that run in the launcher's terminal are started by the application
+// with tea.ExecProcess instead.
+func Run(c plugin.Command) error {
+	if c.Terminal {
+		argv, err := TerminalCommand(c.Argv...)
+		if err != nil {
+			return err
+		}
+		c.Argv = argv
+	}
+	cmd, err := Command(c)
+	if err != nil {
+		return err
+	}
+	return Detached(cmd)
+}
@@ -0,0 +1,174 @@
+package launch
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sahilm/fuzzy"
+	"go.uber.org/zap"
+)
+
+// TerminalPrograms have a text interface and run in a terminal unless
+// configured otherwise, as they would have nowhere to draw when detached.
+var TerminalPrograms = []string{
+	"aerc", "alsamixer", "atop", "bash", "bluetuith", "btop", "calcurse", "cmus",
+	"fish", "glances", "htop", "iotop", "ipython", "irssi", "k9s", "lazygit", "less",
+	"lf", "lynx", "man", "mc", "micro", "mosh", "mutt", "nano", "ncdu", "ncmpcpp",
+	"neomutt", "newsboat", "nmtui", "nnn", "node", "nvim", "nvtop", "pulsemixer",
+	"python", "python3", "ranger", "screen", "sh", "ssh", "tig", "tmux", "top",
+	"vi", "vim", "w3m", "weechat", "yazi", "zsh",
+}
+
+// Executable is a program found on $PATH.
+type Executable struct {
+	Name string
+	Path string
+}
+
+// Executables indexes the programs on $PATH. The index is built on first use
+// and again whenever a $PATH directory changed. The zero value is ready to use.
+type Executables struct {
+	mu          sync.Mutex
+	executables []Executable         // Sorted by name; the first of each name on $PATH.
+	names       []string             // Names of executables, for fuzzy matching.
+	dirModTimes map[string]time.Time // Modification time of each $PATH directory at the last scan.
+}
+
+// Len returns the number of executables on $PATH.
+func (x *Executables) Len() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refresh()
+	return len(x.executables)
+}
+
+// Find fuzzy matches pattern against the names of the executables, best
+// first. An exact name beats longer names scoring the same.
+func (x *Executables) Find(pattern string) []Executable {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refresh()
+
+	matches := fuzzy.Find(pattern, x.names)
+	slices.SortStableFunc(matches, func(a, b fuzzy.Match) int {
+		return boolRank(b.Str == pattern) - boolRank(a.Str == pattern)
+	})
+	found := make([]Executable, len(matches))
+	for i, match := range matches {
+		found[i] = x.executables[match.Index]
+	}
+	return found
+}
+
+// Lookup returns the path of the named executable, which may also be a path.
+func (x *Executables) Lookup(name string) (string, bool) {
+	if strings.Contains(name, "/") {
+		return name, IsExecutable(name)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refresh()
+
+	i, found := slices.BinarySearchFunc(x.executables, name, func(e Executable, name string) int {
+		return strings.Compare(e.Name, name)
+	})
+	if !found {
+		return "", false
+	}
+	return x.executables[i].Path, true
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// pathDirs returns the directories of $PATH, without duplicates.
+func pathDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// refresh indexes the executables again if a $PATH directory changed since
+// the last scan.
+func (x *Executables) refresh() {
+	if x.dirModTimes != nil && !x.stale() {
+		return
+	}
+	x.rescan()
+}
+
+// stale reports whether a $PATH directory changed since the last scan.
+func (x *Executables) stale() bool {
+	dirs := pathDirs()
+	if len(dirs) != len(x.dirModTimes) {
+		return true
+	}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		modTime, scanned := x.dirModTimes[dir]
+		if !scanned || (err == nil && !info.ModTime().Equal(modTime)) {
+			return true
+		}
+	}
+	return false
+}
+
+// rescan indexes the executables on $PATH. Earlier directories shadow later
+// ones, as in the shell.
+func (x *Executables) rescan() {
+	seen := make(map[string]bool)
+	x.executables = x.executables[:0]
+	x.dirModTimes = make(map[string]time.Time)
+
+	for _, dir := range pathDirs() {
+		info, err := os.Stat(dir)
+		if err != nil {
+			x.dirModTimes[dir] = time.Time{}
+			continue
+		}
+		x.dirModTimes[dir] = info.ModTime()
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			zap.L().Debug("Could not read $PATH directory.", zap.String("dir", dir), zap.Error(err))
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			path := filepath.Join(dir, name)
+			if seen[name] || !IsExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			x.executables = append(x.executables, Executable{Name: name, Path: path})
+		}
+	}
+
+	slices.SortFunc(x.executables, func(a, b Executable) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	x.names = make([]string, len(x.executables))
+	for i, e := range x.executables {
+		x.names[i] = e.Name
+	}
+	zap.L().Debug("Indexed executables on $PATH.", zap.Int("count", len(x.executables)))
+}
+
+// IsExecutable reports whether path is a regular file, or a link to one, with
+// an execute permission bit set.
+func IsExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
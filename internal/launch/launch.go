@@ -0,0 +1,169 @@
+// Package launch starts external programs detached from the launcher's terminal.
+package launch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"syscall"
+
+	"github.com/barab-i/incipio/pkgs/execute"
+	"go.uber.org/zap"
+)
+
+// List of known terminal emulators to try, in order of preference.
+var knownTerminalEmulators = []string{
+	// Common distribution abstractions/defaults
+	"x-terminal-emulator", // Debian/Ubuntu specific abstraction
+	"gnome-terminal",      // GNOME default
+	"konsole",             // KDE default
+	"xfce4-terminal",      // XFCE default
+	"mate-terminal",       // MATE default
+	"lxterminal",          // LXDE/LXQt default
+	"deepin-terminal",     // Deepin DE default
+
+	// Popular standalone terminals
+	"alacritty",
+	"kitty",
+	"wezterm",
+	"foot",
+	"ghostty",
+	"st",
+	"terminator",
+	"tilix",
+	"urxvt",
+
+	// Other known terminals / Fallbacks
+	"qterminal",
+	"terminology",
+	"roxterm",
+	"xterm",
+	"uxterm",
+	"rxvt",
+	"aterm",
+	"Eterm",
+
+	// Wrappers (less ideal to call directly if the base exists, but good for completeness)
+	"xfce4-terminal.wrapper",
+}
+
+// terminal is the terminal emulator set with SetTerminal, as a program and
+// its arguments.
+var terminal []string
+
+// execFlags are the arguments known terminal emulators take before the
+// program to run in them. Others take -e.
+var execFlags = map[string][]string{
+	"gnome-terminal": {"--"},
+	"kitty":          nil,
+	"foot":           nil,
+	"wezterm":        {"start", "--"},
+	"xfce4-terminal": {"-x"},
+	"mate-terminal":  {"-x"},
+	"terminator":     {"-x"},
+}
+
+// SetTerminal sets the terminal emulator programs needing one are started in,
+// a command line like "foot" or "alacritty --class popup". An empty command
+// keeps $TERMINAL or the first known emulator installed.
+func SetTerminal(command string) error {
+	fields, err := execute.Split(command)
+	if err != nil {
+		return fmt.Errorf("invalid terminal %q: %w", command, err)
+	}
+	terminal = fields
+	return nil
+}
+
+// FindTerminal tries to find a suitable terminal emulator.
+// It checks the terminal set with SetTerminal first, then $TERMINAL, then a
+// list of known emulators.
+func FindTerminal() string {
+	if len(terminal) > 0 {
+		return terminal[0]
+	}
+
+	// Try $TERMINAL environment variable
+	envTerminal := os.Getenv("TERMINAL")
+	if envTerminal != "" {
+		if path, err := exec.LookPath(envTerminal); err == nil {
+			zap.L().Debug("Using terminal from $TERMINAL.", zap.String("terminal", path))
+			return path
+		}
+		zap.L().Debug("$TERMINAL is set but command not found.", zap.String("terminal", envTerminal))
+	}
+
+	// Try a list of known terminal emulators
+	for _, t := range knownTerminalEmulators {
+		if path, err := exec.LookPath(t); err == nil {
+			zap.L().Debug("Found suitable terminal from known list.", zap.String("terminal", path))
+			return path
+		}
+	}
+
+	zap.L().Error("Failed to find any terminal emulator.")
+	return ""
+}
+
+// TerminalCommand returns the command line opening a new terminal window
+// running argv, or just the window if argv is empty. See FindTerminal.
+func TerminalCommand(argv ...string) ([]string, error) {
+	fields := terminal
+	if len(fields) == 0 {
+		found := FindTerminal()
+		if found == "" {
+			return nil, errors.New("no terminal emulator found, set terminal in config.yaml or $TERMINAL")
+		}
+		fields = []string{found}
+	}
+	return TerminalArgs(fields, argv...), nil
+}
+
+// TerminalArgs appends argv to the terminal command line fields, after the
+// arguments the emulator expects before a program, unless fields already end
+// with them.
+func TerminalArgs(fields []string, argv ...string) []string {
+	command := slices.Clone(fields)
+	if len(argv) == 0 {
+		return command
+	}
+	flags, known := execFlags[filepath.Base(fields[0])]
+	if !known {
+		flags = []string{"-e"}
+	}
+	if last := command[len(command)-1]; len(command) == 1 || last != "-e" && last != "-x" && last != "--" {
+		command = append(command, flags...)
+	}
+	return append(command, argv...)
+}
+
+// Detached starts cmd in a new session so it outlives the launcher.
+// Standard streams are not inherited.
+func Detached(cmd *exec.Cmd) error {
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	cmd.Stdin = nil
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid: true, // Create a new session to detach from the terminal.
+	}
+	return cmd.Start()
+}
+
+// InTerminal starts the command line in a new terminal emulator window, in dir if set.
+func InTerminal(dir string, command ...string) error {
+	argv, err := TerminalCommand(command...)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	return Detached(cmd)
+}
+
+// OpenURL opens a URL or path with the desktop's default handler.
+func OpenURL(url string) error {
+	return Detached(exec.Command("xdg-open", url))
+}
@@ -0,0 +1,140 @@
+// Package logbuffer keeps the most recent log entries in memory so they can be
+// shown inside the TUI, where output to the terminal would be hidden.
+package logbuffer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultCapacity is the number of entries kept by New when no capacity is given.
+const DefaultCapacity = 1000
+
+// Entry is a captured log entry.
+type Entry struct {
+	// Seq numbers the entries in the order they were logged, from 1, and
+	// identifies an entry as long as it is buffered.
+	Seq     uint64
+	Time    time.Time
+	Level   zapcore.Level
+	Message string
+	// Source is the package that logged the entry, e.g. "grep" for the grep
+	// plugin, or the value of a "plugin" string field when one is present.
+	Source string
+	// Fields holds the entry's fields rendered as key=value pairs, sorted by key.
+	Fields string
+}
+
+// Buffer is a fixed-size ring of the most recent log entries. It is safe for
+// concurrent use.
+type Buffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int // Index the next entry is written to once the ring is full.
+	full    bool
+	seq     uint64 // Seq of the last entry added.
+}
+
+// New returns a buffer keeping the last capacity entries.
+func New(capacity int) *Buffer {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Buffer{entries: make([]Entry, 0, capacity)}
+}
+
+func (b *Buffer) add(e Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	e.Seq = b.seq
+	if !b.full {
+		b.entries = append(b.entries, e)
+		b.full = len(b.entries) == cap(b.entries)
+		return
+	}
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % len(b.entries)
+}
+
+// Entries returns the buffered entries, newest first.
+func (b *Buffer) Entries() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]Entry, 0, len(b.entries))
+	for i := len(b.entries) - 1; i >= 0; i-- {
+		entries = append(entries, b.entries[(b.next+i)%len(b.entries)])
+	}
+	return entries
+}
+
+// Core returns a zapcore.Core recording entries at or above level into the
+// buffer, to be combined with the regular core using zapcore.NewTee.
+func (b *Buffer) Core(level zapcore.LevelEnabler) zapcore.Core {
+	return &core{LevelEnabler: level, buffer: b}
+}
+
+type core struct {
+	zapcore.LevelEnabler
+	buffer *Buffer
+	fields []zapcore.Field // Added with With.
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, f := range append(c.fields[:len(c.fields):len(c.fields)], fields...) {
+		f.AddTo(encoder)
+	}
+
+	source := ""
+	if entry.Caller.Defined {
+		source = filepath.Base(filepath.Dir(entry.Caller.File))
+	}
+	if name, ok := encoder.Fields["plugin"].(string); ok && name != "" {
+		source = name
+	}
+
+	keys := make([]string, 0, len(encoder.Fields))
+	for k := range encoder.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, encoder.Fields[k])
+	}
+
+	c.buffer.add(Entry{
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+		Source:  source,
+		Fields:  strings.Join(pairs, " "),
+	})
+	return nil
+}
+
+func (c *core) Sync() error {
+	return nil
+}
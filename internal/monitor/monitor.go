@@ -0,0 +1,183 @@
+// Package monitor detects the focused output of the running Wayland compositor
+// and derives sensible size caps for the launcher content from it.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// queryTimeout bounds a compositor IPC query so startup is never delayed noticeably.
+const queryTimeout = 500 * time.Millisecond
+
+// ErrUnsupported is returned when no supported compositor is detected.
+var ErrUnsupported = errors.New("no supported compositor IPC (sway, Hyprland, niri) found")
+
+// Output is a compositor output (monitor).
+type Output struct {
+	// Name is the connector name, e.g. "DP-1" or "eDP-1".
+	Name string
+	// Width and Height are the current mode in physical pixels.
+	Width  int
+	Height int
+	// Scale is the output's scale factor, 1 when unknown.
+	Scale float64
+}
+
+// LogicalSize returns the output size in logical pixels, after scaling.
+func (o Output) LogicalSize() (int, int) {
+	scale := o.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	return int(float64(o.Width) / scale), int(float64(o.Height) / scale)
+}
+
+// Class is a rough category of outputs sharing the same size caps.
+type Class string
+
+const (
+	ClassLaptop    Class = "laptop"
+	ClassStandard  Class = "standard"
+	ClassLarge     Class = "large"
+	ClassUltrawide Class = "ultrawide"
+)
+
+// Classify categorizes the output by aspect ratio and logical width.
+func (o Output) Classify() Class {
+	width, height := o.LogicalSize()
+	switch {
+	case height > 0 && float64(width)/float64(height) >= 2.1:
+		return ClassUltrawide
+	case width >= 2560:
+		return ClassLarge
+	case width >= 1600:
+		return ClassStandard
+	default:
+		return ClassLaptop
+	}
+}
+
+// sizeHints holds the content caps in terminal cells for each class. Laptop
+// screens are small enough that the launcher uses the whole terminal.
+var sizeHints = map[Class]struct{ width, height int }{
+	ClassLaptop:    {0, 0},
+	ClassStandard:  {100, 32},
+	ClassLarge:     {120, 40},
+	ClassUltrawide: {110, 40},
+}
+
+// SizeHint returns the maximum content width and height in terminal cells
+// suggested for the output. Zero means no cap.
+func (o Output) SizeHint() (maxWidth, maxHeight int) {
+	hint := sizeHints[o.Classify()]
+	return hint.width, hint.height
+}
+
+// Focused returns the focused output of the running compositor.
+func Focused() (Output, error) {
+	switch {
+	case os.Getenv("SWAYSOCK") != "":
+		return swayFocused()
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return hyprlandFocused()
+	case os.Getenv("NIRI_SOCKET") != "":
+		return niriFocused()
+	}
+	return Output{}, ErrUnsupported
+}
+
+// query runs a compositor IPC command and decodes its JSON output into v.
+func query(v any, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("unexpected %s output: %w", name, err)
+	}
+	return nil
+}
+
+func swayFocused() (Output, error) {
+	var outputs []struct {
+		Name    string  `json:"name"`
+		Focused bool    `json:"focused"`
+		Scale   float64 `json:"scale"`
+		// Rect is in logical pixels and already accounts for rotation.
+		Rect struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"rect"`
+	}
+	if err := query(&outputs, "swaymsg", "--raw", "--type", "get_outputs"); err != nil {
+		return Output{}, err
+	}
+	for _, o := range outputs {
+		if o.Focused {
+			scale := o.Scale
+			if scale <= 0 {
+				scale = 1
+			}
+			return Output{Name: o.Name, Width: int(float64(o.Rect.Width) * scale), Height: int(float64(o.Rect.Height) * scale), Scale: scale}, nil
+		}
+	}
+	return Output{}, errors.New("sway reports no focused output")
+}
+
+func hyprlandFocused() (Output, error) {
+	var monitors []struct {
+		Name      string  `json:"name"`
+		Focused   bool    `json:"focused"`
+		Width     int     `json:"width"`
+		Height    int     `json:"height"`
+		Scale     float64 `json:"scale"`
+		Transform int     `json:"transform"`
+	}
+	if err := query(&monitors, "hyprctl", "monitors", "-j"); err != nil {
+		return Output{}, err
+	}
+	for _, m := range monitors {
+		if m.Focused {
+			o := Output{Name: m.Name, Width: m.Width, Height: m.Height, Scale: m.Scale}
+			if m.Transform%2 == 1 { // Rotated by 90 or 270 degrees.
+				o.Width, o.Height = o.Height, o.Width
+			}
+			return o, nil
+		}
+	}
+	return Output{}, errors.New("hyprland reports no focused monitor")
+}
+
+func niriFocused() (Output, error) {
+	var output struct {
+		Name    string `json:"name"`
+		Logical *struct {
+			Width  int     `json:"width"`
+			Height int     `json:"height"`
+			Scale  float64 `json:"scale"`
+		} `json:"logical"`
+	}
+	if err := query(&output, "niri", "msg", "--json", "focused-output"); err != nil {
+		return Output{}, err
+	}
+	if output.Logical == nil {
+		return Output{}, errors.New("niri reports no focused output")
+	}
+	// niri reports the logical size; convert back to physical pixels.
+	l := output.Logical
+	return Output{
+		Name:   output.Name,
+		Width:  int(float64(l.Width) * l.Scale),
+		Height: int(float64(l.Height) * l.Scale),
+		Scale:  l.Scale,
+	}, nil
+}
@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/barab-i/incipio/internal/config"
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!ai"
+
+const (
+	askIdentifier      = "ai_ask:"      // Start a new conversation, followed by the query.
+	continueIdentifier = "ai_continue:" // Follow up in the current conversation, followed by the query.
+	showIdentifier     = "ai_show"      // Reopen the current conversation.
+	infoIdentifier     = "ai_info"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "AI Assistant",
+	Description: "Ask a local Ollama or OpenAI-compatible model and stream its answer.",
+	Keyword:     Keyword,
+	Flag:        "ai",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// AIPlugin asks a language model and streams the answers of a conversation.
+type AIPlugin struct {
+	client       client
+	systemPrompt string
+
+	mu           sync.Mutex
+	askedQuery   string         // Query of the last question.
+	conversation []message      // Without the system prompt.
+	current      *plugin.Stream // Answer being streamed, nil when idle.
+
+	userStyle  lipgloss.Style
+	modelStyle lipgloss.Style
+}
+
+// New creates a new instance of the AIPlugin talking to the configured endpoint.
+func New(cfg config.AIConfig) *AIPlugin {
+	c := client{
+		provider: cfg.Provider,
+		endpoint: strings.TrimRight(cfg.Endpoint, "/"),
+		model:    cfg.Model,
+	}
+	if c.provider == "" {
+		c.provider = providerOllama
+	}
+	if c.endpoint == "" {
+		c.endpoint = defaultOllamaEndpoint
+	}
+	if c.model == "" {
+		c.model = defaultModel
+	}
+	if cfg.APIKeyEnv != "" {
+		c.apiKey = os.Getenv(cfg.APIKeyEnv)
+	}
+
+	p := &AIPlugin{
+		client:       c,
+		systemPrompt: cfg.SystemPrompt,
+	}
+	p.SetTheme(theme.DefaultTheme)
+	return p
+}
+
+// SetTheme styles the speaker labels of the conversation with the given theme.
+func (p *AIPlugin) SetTheme(t theme.Theme) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.userStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Selection)
+	p.modelStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+}
+
+// Metadata returns the plugin's metadata.
+func (p *AIPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *AIPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *AIPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *AIPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults offers to ask the query in a new conversation or as a follow-up
+// in the current one.
+func (p *AIPlugin) GetResults(query string) ([]plugin.Result, error) {
+	query = strings.TrimSpace(query)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if query == "" {
+		if len(p.conversation) > 0 {
+			return []plugin.Result{{
+				Title:       "Show conversation",
+				Description: fmt.Sprintf("%d messages with %s", len(p.conversation), p.client.model),
+				Identifier:  showIdentifier,
+			}}, nil
+		}
+		return []plugin.Result{{
+			Title:       fmt.Sprintf("Ask %s anything", p.client.model),
+			Description: "Answers stream in from " + p.client.endpoint,
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	ask := plugin.Result{
+		Title:       "Ask: " + query,
+		Description: "New conversation with " + p.client.model,
+		Identifier:  askIdentifier + query,
+	}
+	if len(p.conversation) == 0 {
+		return []plugin.Result{ask}, nil
+	}
+	return []plugin.Result{{
+		Title:       "Follow up: " + query,
+		Description: fmt.Sprintf("Continue the conversation of %d messages", len(p.conversation)),
+		Identifier:  continueIdentifier + query,
+	}, ask}, nil
+}
+
+// Execute asks the query of the selected result and streams the
+// conversation. Executing again before editing the query copies the finished
+// answer to the clipboard and quits.
+func (p *AIPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if identifier == showIdentifier {
+		s := plugin.NewStream(p.titleLocked())
+		s.Write(p.transcriptLocked())
+		s.Close(nil)
+		return s.Start()
+	}
+	query, ask := strings.CutPrefix(identifier, askIdentifier)
+	if !ask {
+		var ok bool
+		if query, ok = strings.CutPrefix(identifier, continueIdentifier); !ok {
+			return nil // Do nothing for info items.
+		}
+	}
+
+	if query == "" {
+		return nil
+	}
+	if query == p.askedQuery && len(p.conversation) > 0 {
+		if p.current != nil {
+			return nil // Still answering.
+		}
+		if last := p.lastAnswerLocked(); last != "" {
+			if err := clipboard.WriteAll(last); err != nil {
+				zap.L().Error("Failed to copy answer to clipboard.", zap.Error(err))
+				return nil
+			}
+			return tea.Quit
+		}
+	}
+
+	if ask {
+		p.conversation = nil
+	}
+	p.askedQuery = query
+	p.conversation = append(p.conversation, message{Role: "user", Content: query})
+	return p.startAnswerLocked().Start()
+}
+
+func (p *AIPlugin) titleLocked() string {
+	return fmt.Sprintf("%s · %s", p.client.model, p.client.endpoint)
+}
+
+// lastAnswerLocked returns the last answer of the conversation.
+func (p *AIPlugin) lastAnswerLocked() string {
+	if n := len(p.conversation); n > 0 && p.conversation[n-1].Role == "assistant" {
+		return p.conversation[n-1].Content
+	}
+	return ""
+}
+
+// speakerLocked renders the label introducing a message.
+func (p *AIPlugin) speakerLocked(role string) string {
+	if role == "user" {
+		return p.userStyle.Render("You")
+	}
+	return p.modelStyle.Render(p.client.model)
+}
+
+// transcriptLocked renders the conversation.
+func (p *AIPlugin) transcriptLocked() string {
+	var b strings.Builder
+	for i, m := range p.conversation {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(p.speakerLocked(m.Role) + "\n" + m.Content)
+	}
+	return b.String()
+}
+
+// startAnswerLocked cancels the answer being streamed, if any, and requests an
+// answer to the conversation. The stream starts with the conversation so far,
+// followed by the answer's chunks as they arrive.
+func (p *AIPlugin) startAnswerLocked() *plugin.Stream {
+	if p.current != nil {
+		p.current.Cancel()
+	}
+
+	messages := make([]message, 0, len(p.conversation)+1)
+	if p.systemPrompt != "" {
+		messages = append(messages, message{Role: "system", Content: p.systemPrompt})
+	}
+	messages = append(messages, p.conversation...)
+
+	s := plugin.NewStream(p.titleLocked())
+	s.Write(p.transcriptLocked() + "\n\n" + p.speakerLocked("assistant") + "\n")
+	p.current = s
+	p.conversation = append(p.conversation, message{Role: "assistant"})
+	index := len(p.conversation) - 1
+
+	go func() {
+		err := p.client.stream(s.Context(), messages, func(chunk string) {
+			p.mu.Lock()
+			if p.current == s {
+				p.conversation[index].Content += chunk
+			}
+			p.mu.Unlock()
+			s.Write(chunk)
+		})
+		if err != nil && s.Context().Err() == nil {
+			zap.L().Warn("AI request failed.", zap.String("endpoint", p.client.endpoint), zap.Error(err))
+		}
+		s.Close(err)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.current == s {
+			p.current = nil
+		}
+	}()
+	return s
+}
+
+// Update handles messages.
+func (p *AIPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as answers are shown through plugin.Stream.
+func (p *AIPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as errors are shown in the streamed answer.
+func (p *AIPlugin) GetError() error {
+	return nil
+}
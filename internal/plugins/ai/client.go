@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	providerOllama = "ollama"
+	providerOpenAI = "openai"
+
+	defaultOllamaEndpoint = "http://localhost:11434"
+	defaultModel          = "llama3.2"
+)
+
+// message is a chat message of a conversation.
+type message struct {
+	Role    string `json:"role"` // "system", "user" or "assistant".
+	Content string `json:"content"`
+}
+
+// client streams chat answers from an Ollama or OpenAI-compatible endpoint.
+type client struct {
+	provider string
+	endpoint string // Base URL without a trailing slash.
+	model    string
+	apiKey   string
+}
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+// ollamaChunk is a line of Ollama's streamed /api/chat response.
+type ollamaChunk struct {
+	Message message `json:"message"`
+	Done    bool    `json:"done"`
+	Error   string  `json:"error"`
+}
+
+// openAIChunk is a server-sent event of a streamed /chat/completions response.
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// stream sends the conversation and calls onChunk with each piece of the
+// answer as it arrives. It returns once the answer is complete, the endpoint
+// fails or ctx is cancelled.
+func (c client) stream(ctx context.Context, messages []message, onChunk func(string)) error {
+	path := "/api/chat"
+	if c.provider == providerOpenAI {
+		path = "/chat/completions"
+	}
+	body, err := json.Marshal(chatRequest{Model: c.model, Messages: messages, Stream: true})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach %s: %w", c.endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", c.endpoint, resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		done, err := c.parseLine(scanner.Bytes(), onChunk)
+		if err != nil || done {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("answer interrupted: %w", err)
+	}
+	return nil
+}
+
+// parseLine handles a line of the streamed response and reports whether the
+// answer is complete.
+func (c client) parseLine(line []byte, onChunk func(string)) (bool, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return false, nil
+	}
+
+	if c.provider == providerOpenAI {
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			return false, nil // Comments and other event fields.
+		}
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			return true, nil
+		}
+		var chunk openAIChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return false, fmt.Errorf("invalid response: %w", err)
+		}
+		if chunk.Error != nil {
+			return false, errors.New(chunk.Error.Message)
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			onChunk(chunk.Choices[0].Delta.Content)
+		}
+		return false, nil
+	}
+
+	var chunk ollamaChunk
+	if err := json.Unmarshal(line, &chunk); err != nil {
+		return false, fmt.Errorf("invalid response: %w", err)
+	}
+	if chunk.Error != "" {
+		return false, errors.New(chunk.Error)
+	}
+	if chunk.Message.Content != "" {
+		onChunk(chunk.Message.Content)
+	}
+	return chunk.Done, nil
+}
@@ -0,0 +1,88 @@
+package applauncher
+
+import (
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/internal/desktop"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/go-ini/ini"
+)
+
+// actionSeparator joins the file path of an app and the ID of one of its
+// desktop actions in the identifiers of action results.
+const actionSeparator = "#action="
+
+// DesktopAction is an additional way to start an application, declared in a
+// [Desktop Action <id>] section of its .desktop file, such as Firefox's "New
+// Private Window".
+type DesktopAction struct {
+	ID   string
+	Name string
+	Exec string
+}
+
+// parseDesktopActions reads the actions listed by the Actions key of the
+// [Desktop Entry] section. Actions without a Name or Exec are skipped.
+func parseDesktopActions(cfg *ini.File, entry *ini.Section) []DesktopAction {
+	var actions []DesktopAction
+	for _, id := range strings.Split(entry.Key("Actions").String(), ";") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		section, err := cfg.GetSection("Desktop Action " + id)
+		if err != nil {
+			continue
+		}
+		action := DesktopAction{
+			ID:   id,
+			Name: desktop.Localized(section, "Name"),
+			Exec: section.Key("Exec").String(),
+		}
+		if action.Name != "" && action.Exec != "" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// actionResults returns the desktop actions of app matching the query. Every
+// word of the query must appear in the app's or the action's name, and at
+// least one in the action's, so "private" and "firefox priv" find Firefox's
+// "New Private Window" while "firefox" alone lists just the app.
+func (p *AppLauncherPlugin) actionResults(app DesktopEntry, lowerQuery string, now time.Time) []scoredResult {
+	var results []scoredResult
+	lowerApp := strings.ToLower(app.Name)
+	for _, action := range app.Actions {
+		lowerAction := strings.ToLower(action.Name)
+		matched, inAction := true, false
+		for _, word := range strings.Fields(lowerQuery) {
+			switch {
+			case strings.Contains(lowerAction, word):
+				inAction = true
+			case !strings.Contains(lowerApp, word):
+				matched = false
+			}
+		}
+		if !matched || !inAction {
+			continue
+		}
+
+		identifier := app.FilePath + actionSeparator + action.ID
+		score := scoreComment
+		if strings.HasPrefix(lowerAction, lowerQuery) {
+			score = scoreNameMatch
+		}
+		results = append(results, scoredResult{
+			Result: plugin.Result{
+				Title:       app.Name + ": " + action.Name,
+				Description: app.Comment,
+				Identifier:  identifier,
+				Icon:        app.glyph,
+			},
+			Score: score + p.history.bonus(identifier, now),
+		})
+	}
+	return results
+}
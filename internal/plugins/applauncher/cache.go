@@ -0,0 +1,110 @@
+package applauncher
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/desktop"
+	"go.uber.org/zap"
+)
+
+const (
+	cacheDir      = "incipio"
+	cacheFileName = "desktop_entries.json"
+	// cacheVersion changes when DesktopEntry does, discarding older caches.
+	cacheVersion = 1
+)
+
+// cachedFile is a .desktop file as parsed by the last scan.
+type cachedFile struct {
+	ModTime int64 `json:"mod_time"` // Unix nanoseconds.
+	// Entry is nil for files that failed to parse or are not displayed.
+	Entry *DesktopEntry `json:"entry,omitempty"`
+	// Glyph and Untranslated keep the unexported fields of Entry.
+	Glyph        string `json:"glyph,omitempty"`
+	Untranslated string `json:"untranslated,omitempty"`
+}
+
+// desktopCache is the content of the cache file.
+type desktopCache struct {
+	Version int `json:"version"`
+	// Locale the entries were translated for, see desktop.Locale.
+	Locale string `json:"locale"`
+	// Desktops the entries were filtered for, see shownDesktops.
+	Desktops string                `json:"desktops"`
+	Files    map[string]cachedFile `json:"files"` // By absolute path.
+}
+
+func cachePath() (string, error) {
+	return xdg.CacheFile(filepath.Join(cacheDir, cacheFileName))
+}
+
+// readCache returns the files of the previous scan, or nil if there is no
+// usable cache, such as after the locale or desktops changed.
+func readCache(desktops string) map[string]cachedFile {
+	path, err := cachePath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			zap.L().Warn("Could not read the desktop entry cache.", zap.String("path", path), zap.Error(err))
+		}
+		return nil
+	}
+	var cache desktopCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		zap.L().Warn("Ignoring invalid desktop entry cache.", zap.String("path", path), zap.Error(err))
+		return nil
+	}
+	if cache.Version != cacheVersion || cache.Locale != desktop.Locale() || cache.Desktops != desktops || cache.Files == nil {
+		return nil
+	}
+	for path, file := range cache.Files {
+		if file.Entry != nil {
+			file.Entry.glyph, file.Entry.untranslated = file.Glyph, file.Untranslated
+			cache.Files[path] = file
+		}
+	}
+	return cache.Files
+}
+
+// writeCache saves the files of a scan, filtered for desktops, for the next start.
+func writeCache(files map[string]cachedFile, desktops string) {
+	path, err := cachePath()
+	if err != nil {
+		zap.L().Warn("Could not determine the desktop entry cache path.", zap.Error(err))
+		return
+	}
+	for p, file := range files {
+		if file.Entry != nil {
+			file.Glyph, file.Untranslated = file.Entry.glyph, file.Entry.untranslated
+			files[p] = file
+		}
+	}
+	data, err := json.Marshal(desktopCache{Version: cacheVersion, Locale: desktop.Locale(), Desktops: desktops, Files: files})
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		zap.L().Warn("Could not write the desktop entry cache.", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// appsOf returns the displayed apps among files, by path.
+func appsOf(files map[string]cachedFile) []DesktopEntry {
+	apps := make([]DesktopEntry, 0, len(files))
+	for _, file := range files {
+		if file.Entry != nil {
+			apps = append(apps, *file.Entry)
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].FilePath < apps[j].FilePath })
+	return apps
+}
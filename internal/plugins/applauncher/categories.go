@@ -0,0 +1,75 @@
+package applauncher
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+)
+
+// Sections of the app list shown for an empty query, besides those of
+// mainCategories.
+const (
+	recentSection = "Recent"
+	otherSection  = "Other"
+)
+
+// mainCategories names the sections of the main categories of the freedesktop
+// menu specification.
+var mainCategories = map[string]string{
+	"AudioVideo":  "Multimedia",
+	"Audio":       "Multimedia",
+	"Video":       "Multimedia",
+	"Development": "Development",
+	"Education":   "Education",
+	"Game":        "Games",
+	"Graphics":    "Graphics",
+	"Network":     "Internet",
+	"Office":      "Office",
+	"Science":     "Science",
+	"Settings":    "Settings",
+	"System":      "System",
+	"Utility":     "Accessories",
+}
+
+// section returns the section the app is listed in: that of the first main
+// category among its Categories, or otherSection.
+func (app DesktopEntry) section() string {
+	for _, category := range strings.Split(app.Categories, ";") {
+		if section, ok := mainCategories[strings.TrimSpace(category)]; ok {
+			return section
+		}
+	}
+	return otherSection
+}
+
+// browseResults lists all apps for an empty query: frequently and recently
+// used ones first, the rest alphabetically within sections by category.
+func (p *AppLauncherPlugin) browseResults(now time.Time) []plugin.Result {
+	var recent []scoredResult
+	var rest []plugin.Result
+	for _, app := range p.snapshot() {
+		result := app.result()
+		if bonus := p.history.bonus(app.FilePath, now); bonus > 0 {
+			result.Section = recentSection
+			recent = append(recent, scoredResult{Result: result, Score: bonus})
+			continue
+		}
+		result.Section = app.section()
+		rest = append(rest, result)
+	}
+
+	sort.SliceStable(rest, func(i, j int) bool {
+		a, b := rest[i], rest[j]
+		if a.Section != b.Section {
+			// The other apps come last.
+			if a.Section == otherSection || b.Section == otherSection {
+				return b.Section == otherSection
+			}
+			return a.Section < b.Section
+		}
+		return a.Title < b.Title
+	})
+	return append(sortScoredResults(recent), rest...)
+}
@@ -0,0 +1,178 @@
+package applauncher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"go.uber.org/zap"
+)
+
+const (
+	historyDir      = "incipio"
+	historyFileName = "launch_history.json"
+
+	// frecencyPointsPerLaunch converts weighted launches into relevance points.
+	frecencyPointsPerLaunch = 4
+	// maxFrecencyBonus keeps usage from outranking a better textual match by too much:
+	// a frequently used app matching by name still ranks below a name prefix match.
+	maxFrecencyBonus = 40
+)
+
+// recencyWeights discount launches by age, most recent first.
+var recencyWeights = []struct {
+	maxAge time.Duration
+	weight float64
+}{
+	{24 * time.Hour, 1.0},
+	{7 * 24 * time.Hour, 0.7},
+	{30 * 24 * time.Hour, 0.5},
+	{90 * 24 * time.Hour, 0.3},
+}
+
+const staleWeight = 0.1
+
+// LaunchStat records how often and when an application was launched.
+type LaunchStat struct {
+	Identifier string    `json:"identifier"`
+	Count      int       `json:"count"`
+	LastLaunch time.Time `json:"last_launch"`
+}
+
+// launchHistory holds launch statistics persisted in an XDG data file.
+type launchHistory struct {
+	mu    sync.Mutex
+	path  string
+	stats map[string]LaunchStat
+}
+
+// loadLaunchHistory reads the history file. A missing or unreadable file yields an empty history.
+func loadLaunchHistory() *launchHistory {
+	h := &launchHistory{stats: make(map[string]LaunchStat)}
+
+	path, err := xdg.DataFile(filepath.Join(historyDir, historyFileName))
+	if err != nil {
+		zap.L().Warn("Could not determine launch history path, history will not be saved.", zap.Error(err))
+		return h
+	}
+	h.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h
+	}
+	if err != nil {
+		zap.L().Warn("Could not read launch history.", zap.String("path", path), zap.Error(err))
+		return h
+	}
+
+	var stats []LaunchStat
+	if err := json.Unmarshal(data, &stats); err != nil {
+		zap.L().Warn("Could not parse launch history, starting fresh.", zap.String("path", path), zap.Error(err))
+		return h
+	}
+	for _, s := range stats {
+		h.stats[s.Identifier] = s
+	}
+	return h
+}
+
+// record counts a launch and saves the history.
+func (h *launchHistory) record(identifier string, now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.stats[identifier]
+	s.Identifier = identifier
+	s.Count++
+	s.LastLaunch = now
+	h.stats[identifier] = s
+	return h.saveLocked()
+}
+
+// bonus returns the relevance points earned by launch frequency and recency.
+func (h *launchHistory) bonus(identifier string, now time.Time) int {
+	h.mu.Lock()
+	s, ok := h.stats[identifier]
+	h.mu.Unlock()
+	if !ok {
+		return 0
+	}
+
+	weight := staleWeight
+	age := now.Sub(s.LastLaunch)
+	for _, w := range recencyWeights {
+		if age <= w.maxAge {
+			weight = w.weight
+			break
+		}
+	}
+	return min(int(float64(s.Count)*weight*frecencyPointsPerLaunch), maxFrecencyBonus)
+}
+
+// list returns all statistics, most launched first.
+func (h *launchHistory) list() []LaunchStat {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make([]LaunchStat, 0, len(h.stats))
+	for _, s := range h.stats {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].LastLaunch.After(stats[j].LastLaunch)
+	})
+	return stats
+}
+
+// reset forgets one identifier, or everything when identifier is empty.
+func (h *launchHistory) reset(identifier string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if identifier == "" {
+		clear(h.stats)
+	} else {
+		delete(h.stats, identifier)
+	}
+	return h.saveLocked()
+}
+
+func (h *launchHistory) saveLocked() error {
+	if h.path == "" {
+		return fmt.Errorf("launch history path is unknown")
+	}
+
+	stats := make([]LaunchStat, 0, len(h.stats))
+	for _, s := range h.stats {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Identifier < stats[j].Identifier })
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write atomically so a crash never leaves a truncated history behind.
+	return atomicfile.WriteFile(h.path, data, 0o644)
+}
+
+// LaunchHistory returns the recorded launch statistics, most launched first.
+func (p *AppLauncherPlugin) LaunchHistory() []LaunchStat {
+	return p.history.list()
+}
+
+// ResetLaunchHistory forgets the launches of the application with the given
+// identifier (its .desktop file path), or of all applications when empty.
+func (p *AppLauncherPlugin) ResetLaunchHistory(identifier string) error {
+	return p.history.reset(identifier)
+}
@@ -0,0 +1,64 @@
+package applauncher
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// defaultIcon is shown for applications without a more specific glyph.
+const defaultIcon = "" // nf-fa-window_maximize
+
+// iconGlyphs maps substrings of .desktop Icon names to Nerd Font (Font Awesome) glyphs.
+// Icon names are usually an application or reverse-DNS name
+// ("firefox", "org.gnome.Nautilus") or a freedesktop icon name
+// ("utilities-terminal"). The first matching rule wins, so specific
+// applications come before generic categories.
+var iconGlyphs = []struct {
+	substrings []string
+	glyph      string
+}{
+	{[]string{"firefox", "librewolf", "waterfox"}, ""}, // nf-fa-firefox
+	{[]string{"chrome", "chromium"}, ""},               // nf-fa-chrome
+	{[]string{"steam"}, ""},                            // nf-fa-steam
+	{[]string{"spotify"}, ""},                          // nf-fa-spotify
+	{[]string{"terminal", "kitty", "alacritty", "konsole", "foot", "wezterm", "xterm"}, ""},                     // nf-fa-terminal
+	{[]string{"code", "vim", "emacs", "gedit", "kate", "editor", "jetbrains", "idea", "sublime", "zed"}, ""},    // nf-fa-code
+	{[]string{"nautilus", "dolphin", "thunar", "nemo", "pcmanfm", "file-manager", "system-file", "folder"}, ""}, // nf-fa-folder
+	{[]string{"thunderbird", "evolution", "geary", "mail"}, ""},                                                 // nf-fa-envelope
+	{[]string{"discord", "telegram", "signal", "slack", "element", "chat", "irc"}, ""},                          // nf-fa-comments
+	{[]string{"browser", "brave", "epiphany", "web", "vivaldi", "opera", "qutebrowser"}, ""},                    // nf-fa-globe
+	{[]string{"vlc", "mpv", "totem", "celluloid", "video", "movie"}, ""},                                        // nf-fa-film
+	{[]string{"music", "audio", "rhythmbox", "lollypop", "elisa", "amarok"}, ""},                                // nf-fa-music
+	{[]string{"gimp", "inkscape", "krita", "image", "photo", "eog", "loupe", "gwenview"}, ""},                   // nf-fa-picture_o
+	{[]string{"pdf", "evince", "okular", "zathura", "reader", "book"}, ""},                                      // nf-fa-book
+	{[]string{"writer", "document", "text"}, ""},                                                                // nf-fa-file_text
+	{[]string{"calc", "spreadsheet"}, ""},                                                                       // nf-fa-calculator
+	{[]string{"password", "keepass", "seahorse", "keyring"}, ""},                                                // nf-fa-lock
+	{[]string{"archive", "file-roller", "org.kde.ark", "engrampa"}, ""},                                         // nf-fa-archive
+	{[]string{"settings", "preferences", "control", "tweaks", "config"}, ""},                                    // nf-fa-cog
+	{[]string{"game", "lutris", "heroic"}, ""},                                                                  // nf-fa-gamepad
+	{[]string{"monitor", "system", "htop", "btop"}, ""},                                                         // nf-fa-desktop
+	{[]string{"help", "manual"}, ""},                                                                            // nf-fa-question_circle
+}
+
+// iconGlyph maps a .desktop Icon value, which may also be an absolute path
+// to an image, to a Nerd Font glyph. The application name is used when the
+// icon name does not match any rule.
+func iconGlyph(icon, name string) string {
+	if filepath.IsAbs(icon) {
+		icon = strings.TrimSuffix(filepath.Base(icon), filepath.Ext(icon))
+	}
+	for _, candidate := range []string{strings.ToLower(icon), strings.ToLower(name)} {
+		if candidate == "" {
+			continue
+		}
+		for _, rule := range iconGlyphs {
+			for _, s := range rule.substrings {
+				if strings.Contains(candidate, s) {
+					return rule.glyph
+				}
+			}
+		}
+	}
+	return defaultIcon
+}
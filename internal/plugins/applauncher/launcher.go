@@ -3,13 +3,15 @@ package applauncher
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
-	"syscall"
+	"sync"
+	"time"
 
 	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/desktop"
+	"github.com/barab-i/incipio/internal/launch"
 	"github.com/barab-i/incipio/pkgs/plugin"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/go-ini/ini"
@@ -26,42 +28,6 @@ var metadata = plugin.Metadata{
 	IsDefault:   true,
 }
 
-// List of known terminal emulators to try, in order of preference.
-var knownTerminalEmulators = []string{
-	// Common distribution abstractions/defaults
-	"x-terminal-emulator", // Debian/Ubuntu specific abstraction
-	"gnome-terminal",      // GNOME default
-	"konsole",             // KDE default
-	"xfce4-terminal",      // XFCE default
-	"mate-terminal",       // MATE default
-	"lxterminal",          // LXDE/LXQt default
-	"deepin-terminal",     // Deepin DE default
-
-	// Popular standalone terminals
-	"alacritty",
-	"kitty",
-	"wezterm",
-	"foot",
-	"ghostty",
-	"st",
-	"terminator",
-	"tilix",
-	"urxvt",
-
-	// Other known terminals / Fallbacks
-	"qterminal",
-	"terminology",
-	"roxterm",
-	"xterm",
-	"uxterm",
-	"rxvt",
-	"aterm",
-	"Eterm",
-
-	// Wrappers (less ideal to call directly if the base exists, but good for completeness)
-	"xfce4-terminal.wrapper",
-}
-
 // DesktopEntry represents information parsed from a .desktop file.
 type DesktopEntry struct {
 	Name        string
@@ -71,17 +37,39 @@ type DesktopEntry struct {
 	FilePath    string
 	GenericName string
 	Keywords    string
+	Categories  string
 	Terminal    bool
+	Actions     []DesktopAction
+
+	glyph string // Nerd Font glyph derived from Icon, see iconGlyph.
+	// untranslated holds Name and GenericName as written in English when
+	// they are shown translated, so apps are found by either.
+	untranslated string
 }
 
 // AppLauncherPlugin implements the plugin.Plugin interface for launching apps.
 type AppLauncherPlugin struct {
-	apps []DesktopEntry
+	history *launchHistory
+	// executables are offered when no app matches, see pathResults.
+	executables launch.Executables
+	// ignoreShowIn lists apps whatever their OnlyShowIn and NotShowIn keys.
+	ignoreShowIn bool
+
+	mu sync.Mutex
+	// apps is replaced as a whole by scans, never modified in place.
+	apps  []DesktopEntry
+	files map[string]cachedFile // Of the last scan, by absolute path.
+	// scanning is set while a background scan runs; rescan asks it to scan
+	// again once done, for changes made meanwhile.
+	scanning bool
+	rescan   bool
+	watch    sync.Once
 }
 
-// New creates a new instance of the AppLauncherPlugin.
-func New() *AppLauncherPlugin {
-	return &AppLauncherPlugin{}
+// New creates a new instance of the AppLauncherPlugin. Apps meant for other
+// desktop environments are listed too if ignoreShowIn is set.
+func New(ignoreShowIn bool) *AppLauncherPlugin {
+	return &AppLauncherPlugin{history: loadLaunchHistory(), ignoreShowIn: ignoreShowIn}
 }
 
 // Metadata returns the plugin's metadata.
@@ -99,12 +87,41 @@ func (p *AppLauncherPlugin) Keyword() string {
 	return metadata.Keyword
 }
 
-// Init scans for .desktop files during initialization.
+// Init lists the apps cached by the previous session at once and scans the
+// application directories for changes in the background. Without a cache,
+// the first scan runs before Init returns. The directories are then watched
+// so apps installed later are listed too.
 func (p *AppLauncherPlugin) Init() tea.Cmd {
-	p.scanDesktopFiles()
+	if cached := readCache(p.shownDesktops()); cached != nil {
+		p.mu.Lock()
+		p.apps, p.files = appsOf(cached), cached
+		p.mu.Unlock()
+		p.scanInBackground()
+	} else {
+		p.scan()
+	}
+	p.watch.Do(p.watchApplicationDirs)
 	return nil
 }
 
+// RefreshInterval re-runs the query while the application directories are
+// scanned in the background, so new apps show up as soon as it finishes.
+func (p *AppLauncherPlugin) RefreshInterval() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.scanning {
+		return time.Second
+	}
+	return 0
+}
+
+// snapshot returns the apps of the latest scan.
+func (p *AppLauncherPlugin) snapshot() []DesktopEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.apps
+}
+
 const (
 	scoreNamePrefix = 100
 	scoreNameMatch  = 50
@@ -119,40 +136,43 @@ type scoredResult struct {
 	Score  int
 }
 
-// GetResults filters and sorts applications based on query relevance.
+// GetResults filters and sorts applications based on query relevance. When
+// none matches, executables on $PATH are offered instead.
 func (p *AppLauncherPlugin) GetResults(query string) ([]plugin.Result, error) {
 	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	now := time.Now()
 
 	if lowerQuery == "" {
-		results := make([]plugin.Result, len(p.apps))
-		for i, app := range p.apps {
-			results[i] = plugin.Result{
-				Title:       app.Name,
-				Description: app.Comment,
-				Identifier:  app.FilePath,
-			}
-		}
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].Title < results[j].Title
-		})
-		return results, nil
+		return p.browseResults(now), nil
 	}
 
 	scoredResults := []scoredResult{}
-	for _, app := range p.apps {
-		score := calculateRelevanceScore(app, lowerQuery)
+	for _, app := range p.snapshot() {
+		score := calculateRelevanceScore(app, lowerQuery, p.history.bonus(app.FilePath, now))
 		if score > 0 {
-			scoredResults = append(scoredResults, scoredResult{
-				Result: plugin.Result{
-					Title:       app.Name,
-					Description: app.Comment,
-					Identifier:  app.FilePath,
-				},
-				Score: score,
-			})
+			scoredResults = append(scoredResults, scoredResult{Result: app.result(), Score: score})
 		}
+		scoredResults = append(scoredResults, p.actionResults(app, lowerQuery, now)...)
 	}
+	if len(scoredResults) == 0 {
+		return p.pathResults(query), nil
+	}
+
+	return sortScoredResults(scoredResults), nil
+}
+
+// result returns the app's row in the list.
+func (app DesktopEntry) result() plugin.Result {
+	return plugin.Result{
+		Title:       app.Name,
+		Description: app.Comment,
+		Identifier:  app.FilePath,
+		Icon:        app.glyph,
+	}
+}
 
+// sortScoredResults orders results by descending score, then by title.
+func sortScoredResults(scoredResults []scoredResult) []plugin.Result {
 	sort.SliceStable(scoredResults, func(i, j int) bool {
 		if scoredResults[i].Score != scoredResults[j].Score {
 			return scoredResults[i].Score > scoredResults[j].Score
@@ -164,17 +184,19 @@ func (p *AppLauncherPlugin) GetResults(query string) ([]plugin.Result, error) {
 	for i, sr := range scoredResults {
 		finalResults[i] = sr.Result
 	}
-
-	return finalResults, nil
+	return finalResults
 }
 
-func calculateRelevanceScore(app DesktopEntry, lowerQuery string) int {
+// calculateRelevanceScore scores how well an app matches the query. Apps that
+// match at all additionally earn the frecency bonus from their launch history.
+func calculateRelevanceScore(app DesktopEntry, lowerQuery string, frecencyBonus int) int {
 	score := 0
 	lowerName := strings.ToLower(app.Name)
 	lowerGeneric := strings.ToLower(app.GenericName)
 	lowerKeywords := strings.ToLower(app.Keywords)
 	lowerComment := strings.ToLower(app.Comment)
 	lowerExec := strings.ToLower(app.Exec)
+	lowerUntranslated := strings.ToLower(app.untranslated)
 
 	if strings.HasPrefix(lowerName, lowerQuery) {
 		score = max(score, scoreNamePrefix)
@@ -182,7 +204,7 @@ func calculateRelevanceScore(app DesktopEntry, lowerQuery string) int {
 		score = max(score, scoreNameMatch)
 	}
 
-	if strings.Contains(lowerGeneric, lowerQuery) {
+	if strings.Contains(lowerGeneric, lowerQuery) || strings.Contains(lowerUntranslated, lowerQuery) {
 		score = max(score, scoreGeneric)
 	}
 	if strings.Contains(lowerKeywords, lowerQuery) {
@@ -198,80 +220,142 @@ func calculateRelevanceScore(app DesktopEntry, lowerQuery string) int {
 	// Ensure at least one field matched if score > 0
 	if score > 0 && !(strings.Contains(lowerName, lowerQuery) ||
 		strings.Contains(lowerGeneric, lowerQuery) ||
+		strings.Contains(lowerUntranslated, lowerQuery) ||
 		strings.Contains(lowerKeywords, lowerQuery) ||
 		strings.Contains(lowerComment, lowerQuery) ||
 		strings.Contains(lowerExec, lowerQuery)) {
 		return 0
 	}
+	if score > 0 {
+		score += frecencyBonus
+	}
 
 	return score
 }
 
-// Execute launches the application corresponding to the identifier (file path).
-func (p *AppLauncherPlugin) Execute(identifier string) tea.Cmd {
-	var targetApp *DesktopEntry
-	for i := range p.apps {
-		if p.apps[i].FilePath == identifier {
-			targetApp = &p.apps[i]
-			break
+// app returns the application with the given identifier (file path), or nil.
+// Identifiers of desktop actions return their application and action.
+func (p *AppLauncherPlugin) app(identifier string) (*DesktopEntry, *DesktopAction) {
+	path, actionID, isAction := strings.Cut(identifier, actionSeparator)
+	apps := p.snapshot()
+	for i := range apps {
+		if apps[i].FilePath != path {
+			continue
+		}
+		if !isAction {
+			return &apps[i], nil
+		}
+		for j := range apps[i].Actions {
+			if apps[i].Actions[j].ID == actionID {
+				return &apps[i], &apps[i].Actions[j]
+			}
 		}
 	}
+	return nil, nil
+}
 
-	if targetApp == nil {
-		zap.L().Warn("Could not find app for execution.", zap.String("identifier", identifier))
-		return nil
+// Preview describes the application, or the desktop action, and the command it runs.
+func (p *AppLauncherPlugin) Preview(identifier string) string {
+	app, action := p.app(identifier)
+	if app == nil {
+		return ""
+	}
+	if action != nil {
+		return fmt.Sprintf("%s\n%s action\n\nExec: %s\nFile: %s", action.Name, app.Name, action.Exec, app.FilePath)
 	}
 
-	execParts := strings.Fields(targetApp.Exec)
-	cleanedExec := []string{}
-	for _, part := range execParts {
-		if !strings.HasPrefix(part, "%") {
-			cleanedExec = append(cleanedExec, part)
-		}
+	var b strings.Builder
+	b.WriteString(app.Name + "\n")
+	if app.GenericName != "" {
+		b.WriteString(app.GenericName + "\n")
 	}
-	if len(cleanedExec) == 0 {
-		zap.L().Warn("Could not determine command from Exec field.",
-			zap.String("execField", targetApp.Exec),
-			zap.String("filePath", targetApp.FilePath))
-		return nil
+	if app.Comment != "" {
+		b.WriteString("\n" + app.Comment + "\n")
 	}
+	b.WriteString("\nExec: " + app.Exec + "\n")
+	if app.Terminal {
+		b.WriteString("Runs in a terminal\n")
+	}
+	if app.Keywords != "" {
+		b.WriteString("Keywords: " + strings.ReplaceAll(strings.TrimSuffix(app.Keywords, ";"), ";", ", ") + "\n")
+	}
+	if len(app.Actions) > 0 {
+		names := make([]string, len(app.Actions))
+		for i, action := range app.Actions {
+			names[i] = action.Name
+		}
+		b.WriteString("Actions: " + strings.Join(names, ", ") + "\n")
+	}
+	b.WriteString("File: " + app.FilePath)
+	return b.String()
+}
 
-	var command string
-	var args []string
-
-	if targetApp.Terminal {
-		terminalCmd := findTerminalEmulator()
-		if terminalCmd == "" {
-			zap.L().Error("Failed to find any suitable terminal emulator. Cannot launch terminal application.",
-				zap.String("application", targetApp.Name))
+// Execute launches the application corresponding to the identifier (file
+// path), or runs the command of a desktop action of it or of an executable
+// offered by pathResults.
+func (p *AppLauncherPlugin) Execute(identifier string) tea.Cmd {
+	if command, ok := plugin.DecodeCommand(identifier); ok {
+		if err := launch.Run(command); err != nil {
+			zap.L().Error("Error starting command.", zap.Strings("executedArgv", command.Argv), zap.Error(err))
 			return nil
 		}
-		command = terminalCmd
-		args = append([]string{"-e"}, cleanedExec...)
-	} else {
-		command = cleanedExec[0]
-		args = cleanedExec[1:]
+		return tea.Quit
 	}
-
-	cmd := exec.Command(command, args...)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	cmd.Stdin = nil
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true, // Create a new session to detach from the terminal.
+	targetApp, action := p.app(identifier)
+	if targetApp == nil {
+		zap.L().Warn("Could not find app for execution.", zap.String("identifier", identifier))
+		return nil
+	}
+	execField := targetApp.Exec
+	if action != nil {
+		execField = action.Exec
 	}
-	err := cmd.Start()
 
+	// Apps with Terminal=true run in the configured terminal emulator.
+	entry := desktop.Entry{
+		Name:     targetApp.Name,
+		Path:     targetApp.FilePath,
+		Exec:     execField,
+		Icon:     targetApp.Icon,
+		Terminal: targetApp.Terminal,
+	}
+	command, err := entry.Command("")
 	if err != nil {
+		zap.L().Warn("Could not determine command from Exec field.",
+			zap.String("execField", execField),
+			zap.String("filePath", targetApp.FilePath),
+			zap.Error(err))
+		return nil
+	}
+
+	if err := launch.Run(command); err != nil {
 		zap.L().Error("Error starting command.",
-			zap.String("originalExec", targetApp.Exec),
-			zap.String("executedCommand", command),
-			zap.Strings("executedArgs", args),
+			zap.String("originalExec", execField),
+			zap.Strings("executedArgv", command.Argv),
+			zap.Bool("terminal", command.Terminal),
 			zap.String("filePath", targetApp.FilePath),
 			zap.Error(err))
 		return nil
 	}
 
+	if err := p.history.record(identifier, time.Now()); err != nil {
+		zap.L().Warn("Could not save launch history.", zap.String("identifier", identifier), zap.Error(err))
+	}
+	return tea.Quit
+}
+
+// ExecuteBatch launches the applications of all identifiers, quitting once
+// any of them started.
+func (p *AppLauncherPlugin) ExecuteBatch(identifiers []string) tea.Cmd {
+	launched := false
+	for _, identifier := range identifiers {
+		if p.Execute(identifier) != nil {
+			launched = true
+		}
+	}
+	if !launched {
+		return nil
+	}
 	return tea.Quit
 }
 
@@ -290,12 +374,15 @@ func (p *AppLauncherPlugin) GetError() error {
 	return nil
 }
 
-func (p *AppLauncherPlugin) scanDesktopFiles() {
-	p.apps = []DesktopEntry{}
-	appDirs := xdg.ApplicationDirs
-	foundPaths := make(map[string]struct{})
+// scanDesktopFiles lists the displayable apps of the application
+// directories. Files unchanged since cached, a previous scan's files, are not
+// parsed again. It returns the files seen, for the next scan, and whether any
+// was added, changed or removed since.
+func (p *AppLauncherPlugin) scanDesktopFiles(cached map[string]cachedFile) (apps []DesktopEntry, files map[string]cachedFile, changed bool) {
+	apps = []DesktopEntry{}
+	files = make(map[string]cachedFile)
 
-	for _, dir := range appDirs {
+	for _, dir := range xdg.ApplicationDirs {
 		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
 				if d != nil && d.IsDir() {
@@ -312,15 +399,29 @@ func (p *AppLauncherPlugin) scanDesktopFiles() {
 					zap.L().Debug("Could not get absolute path, using original.", zap.String("path", path), zap.Error(absErr))
 					absPath = path
 				}
+				if _, found := files[absPath]; found {
+					return nil
+				}
+				info, infoErr := d.Info()
+				if infoErr != nil {
+					zap.L().Debug("Skipping file due to error during desktop file scan.", zap.String("path", path), zap.Error(infoErr))
+					return nil
+				}
 
-				if _, found := foundPaths[absPath]; !found {
+				file, found := cached[absPath]
+				if !found || file.ModTime != info.ModTime().UnixNano() {
+					file = cachedFile{ModTime: info.ModTime().UnixNano()}
 					entry, parseErr := parseDesktopFile(path)
-					if parseErr == nil && entry != nil && shouldDisplayEntry(entry) {
-						p.apps = append(p.apps, *entry)
-						foundPaths[absPath] = struct{}{}
+					if parseErr == nil && entry != nil && p.shouldDisplayEntry(entry) {
+						file.Entry = entry
 					} else if parseErr != nil {
 						zap.L().Debug("Failed to parse .desktop file or entry not displayable.", zap.String("path", path), zap.Error(parseErr))
 					}
+					changed = true
+				}
+				files[absPath] = file
+				if file.Entry != nil {
+					apps = append(apps, *file.Entry)
 				}
 			}
 			return nil
@@ -330,10 +431,11 @@ func (p *AppLauncherPlugin) scanDesktopFiles() {
 			zap.L().Warn("Error walking application directory for .desktop files.", zap.String("directory", dir), zap.Error(err))
 		}
 	}
+	return apps, files, changed || len(files) != len(cached)
 }
 
 func parseDesktopFile(filePath string) (*DesktopEntry, error) {
-	cfg, err := ini.LoadSources(ini.LoadOptions{SkipUnrecognizableLines: true}, filePath)
+	cfg, err := ini.LoadSources(ini.LoadOptions{SkipUnrecognizableLines: true, IgnoreInlineComment: true}, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load INI file '%s': %w", filePath, err)
 	}
@@ -349,15 +451,22 @@ func parseDesktopFile(filePath string) (*DesktopEntry, error) {
 	}
 
 	entry := &DesktopEntry{
-		Name:        section.Key("Name").String(),
+		Name:        desktop.Localized(section, "Name"),
 		Exec:        section.Key("Exec").String(),
 		Icon:        section.Key("Icon").String(),
-		Comment:     section.Key("Comment").String(),
-		GenericName: section.Key("GenericName").String(),
-		Keywords:    section.Key("Keywords").String(),
+		Comment:     desktop.Localized(section, "Comment"),
+		GenericName: desktop.Localized(section, "GenericName"),
+		Keywords:    desktop.Localized(section, "Keywords"),
+		Categories:  section.Key("Categories").String(),
 		FilePath:    filePath,
 		Terminal:    terminal,
 	}
+	untranslatedName := section.Key("Name").String()
+	entry.glyph = iconGlyph(entry.Icon, untranslatedName)
+	if untranslatedName != entry.Name {
+		entry.untranslated = untranslatedName + "\n" + section.Key("GenericName").String()
+	}
+	entry.Actions = parseDesktopActions(cfg, section)
 
 	if entry.Name == "" || entry.Exec == "" {
 		return nil, fmt.Errorf("missing required field Name or Exec in '%s'", filePath)
@@ -366,8 +475,10 @@ func parseDesktopFile(filePath string) (*DesktopEntry, error) {
 	return entry, nil
 }
 
-func shouldDisplayEntry(entry *DesktopEntry) bool {
-	cfg, err := ini.LoadSources(ini.LoadOptions{SkipUnrecognizableLines: true}, entry.FilePath)
+// shouldDisplayEntry reports whether the app is listed: not if NoDisplay or
+// Hidden is set, or if OnlyShowIn or NotShowIn exclude the current desktop.
+func (p *AppLauncherPlugin) shouldDisplayEntry(entry *DesktopEntry) bool {
+	cfg, err := ini.LoadSources(ini.LoadOptions{SkipUnrecognizableLines: true, IgnoreInlineComment: true}, entry.FilePath)
 	if err != nil {
 		zap.L().Debug("Could not reload .desktop file for display check, assuming displayable.", zap.String("path", entry.FilePath), zap.Error(err))
 		return true // Default to displayable if re-parsing fails.
@@ -380,31 +491,18 @@ func shouldDisplayEntry(entry *DesktopEntry) bool {
 	if hidden, _ := section.Key("Hidden").Bool(); hidden {
 		return false
 	}
+	if !p.ignoreShowIn && !desktop.ShownIn(section.Key("OnlyShowIn").String(), section.Key("NotShowIn").String(), desktop.CurrentDesktops()) {
+		return false
+	}
 
 	return true
 }
 
-// findTerminalEmulator tries to find a suitable terminal emulator.
-// It checks $TERMINAL first, then a list of known emulators.
-func findTerminalEmulator() string {
-	// Try $TERMINAL environment variable
-	envTerminal := os.Getenv("TERMINAL")
-	if envTerminal != "" {
-		if path, err := exec.LookPath(envTerminal); err == nil {
-			zap.L().Debug("Using terminal from $TERMINAL.", zap.String("terminal", path))
-			return path
-		}
-		zap.L().Debug("$TERMINAL is set but command not found.", zap.String("terminal", envTerminal))
+// shownDesktops identifies the desktops apps are filtered for, so cached
+// apps are scanned again when they change.
+func (p *AppLauncherPlugin) shownDesktops() string {
+	if p.ignoreShowIn {
+		return "*"
 	}
-
-	// Try a list of known terminal emulators
-	for _, t := range knownTerminalEmulators {
-		if path, err := exec.LookPath(t); err == nil {
-			zap.L().Debug("Found suitable terminal from known list.", zap.String("terminal", path))
-			return path
-		}
-	}
-
-	zap.L().Error("Failed to find any terminal emulator.")
-	return ""
+	return strings.Join(desktop.CurrentDesktops(), ":")
 }
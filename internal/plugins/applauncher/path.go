@@ -0,0 +1,46 @@
+package applauncher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+)
+
+const (
+	// pathSection lists the executables offered when no app matches.
+	pathSection = "Executables on $PATH"
+	// maxPathResults caps the executables offered.
+	maxPathResults = 10
+)
+
+// pathResults offers the executables on $PATH matching the query when no app
+// does, so programs without a desktop file, like htop, can still be started.
+// Programs with a text interface run in a terminal. Queries with arguments
+// are left to the !run plugin.
+func (p *AppLauncherPlugin) pathResults(query string) []plugin.Result {
+	name := strings.TrimSpace(query)
+	if name == "" || strings.ContainsAny(name, " \t/") {
+		return nil
+	}
+
+	found := p.executables.Find(name)
+	results := make([]plugin.Result, 0, min(len(found), maxPathResults))
+	for _, e := range found[:min(len(found), maxPathResults)] {
+		command := plugin.Command{Argv: []string{e.Path}, Detach: true, Terminal: slices.Contains(launch.TerminalPrograms, e.Name)}
+		where := "detached"
+		if command.Terminal {
+			where = "in a terminal"
+		}
+		results = append(results, plugin.Result{
+			Title:       e.Name,
+			Description: fmt.Sprintf("%s | enter runs %s", e.Path, where),
+			Identifier:  command.Encode(),
+			Icon:        iconGlyph("terminal", ""),
+			Section:     pathSection,
+		})
+	}
+	return results
+}
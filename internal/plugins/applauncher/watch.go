@@ -0,0 +1,119 @@
+package applauncher
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// rescanDelay coalesces the burst of events package managers produce when
+// installing or removing an application.
+const rescanDelay = time.Second
+
+// scan scans the application directories, re-parsing the files changed since
+// the last scan, and saves the result to the cache if anything changed.
+func (p *AppLauncherPlugin) scan() {
+	p.mu.Lock()
+	cached := p.files
+	p.mu.Unlock()
+
+	apps, files, changed := p.scanDesktopFiles(cached)
+	if !changed {
+		return
+	}
+	p.mu.Lock()
+	p.apps, p.files = apps, files
+	p.mu.Unlock()
+	writeCache(files, p.shownDesktops())
+	zap.L().Debug("Application list updated.", zap.Int("apps", len(apps)))
+}
+
+// scanInBackground scans on its own goroutine. A call while a scan runs
+// makes it scan once more when done rather than starting another.
+func (p *AppLauncherPlugin) scanInBackground() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.scanning {
+		p.rescan = true
+		return
+	}
+	p.scanning = true
+
+	go func() {
+		for {
+			p.scan()
+			p.mu.Lock()
+			if !p.rescan {
+				p.scanning = false
+				p.mu.Unlock()
+				return
+			}
+			p.rescan = false
+			p.mu.Unlock()
+		}
+	}()
+}
+
+// watchApplicationDirs scans again in the background whenever desktop files
+// change in the application directories, once quiet for rescanDelay. Their
+// subdirectories are watched as well, including those created later; an
+// applications directory missing at startup is picked up on restart.
+func (p *AppLauncherPlugin) watchApplicationDirs() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		zap.L().Warn("Could not watch application directories, new apps are listed on restart.", zap.Error(err))
+		return
+	}
+	addTree := func(root string) {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if err := watcher.Add(path); err != nil {
+				zap.L().Debug("Not watching application directory.", zap.String("path", path), zap.Error(err))
+			}
+			return nil
+		})
+	}
+	for _, dir := range xdg.ApplicationDirs {
+		addTree(dir)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(rescanDelay)
+		timer.Stop()
+		defer timer.Stop()
+
+		for {
+			select {
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zap.L().Warn("Application directory watcher error.", zap.Error(err))
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if event.Op.Has(fsnotify.Create) {
+					addTree(event.Name) // Does nothing unless a directory was created.
+				}
+				if strings.HasSuffix(event.Name, ".desktop") || event.Op.Has(fsnotify.Create) || event.Op.Has(fsnotify.Remove) || event.Op.Has(fsnotify.Rename) {
+					timer.Reset(rescanDelay)
+				}
+			case <-timer.C:
+				p.scanInBackground()
+			}
+		}
+	}()
+}
@@ -0,0 +1,314 @@
+package battery
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!bat"
+
+const powerSupplyDir = "/sys/class/power_supply"
+
+const (
+	noBatteryIdentifier   = "bat_none"
+	powerSourceIdentifier = "bat_power_source"
+	errorIdentifier       = "bat_error"
+	levelIdentifier       = "bat_level:"  // Followed by the battery's name.
+	healthIdentifier      = "bat_health:" // Followed by the battery's name.
+	profileIdentifier     = "bat_profile:"
+)
+
+// powerProfiles lists the profiles known to power-profiles-daemon in display order.
+var powerProfiles = []string{"power-saver", "balanced", "performance"}
+
+var metadata = plugin.Metadata{
+	Name:        "Battery",
+	Description: "Show battery level, health and time estimates, and switch power profiles.",
+	Keyword:     Keyword,
+	Flag:        "bat",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// supply holds the attributes of a power supply read from sysfs.
+type supply struct {
+	name  string
+	attrs map[string]string
+}
+
+// profileSetMsg is sent once powerprofilesctl switched the power profile, or failed to.
+type profileSetMsg struct {
+	err error
+}
+
+// BatteryPlugin implements the plugin.Plugin interface for battery status and power profiles.
+type BatteryPlugin struct {
+	mu  sync.Mutex
+	err error // Of the last profile switch; guarded by mu.
+}
+
+// New creates a new instance of the BatteryPlugin.
+func New() *BatteryPlugin {
+	return &BatteryPlugin{}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *BatteryPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *BatteryPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *BatteryPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *BatteryPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists batteries, the AC adapter state and the available power profiles.
+func (p *BatteryPlugin) GetResults(query string) ([]plugin.Result, error) {
+	supplies, err := readSupplies()
+	if err != nil {
+		return []plugin.Result{{
+			Title:       "Could not read power supplies",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+
+	results := []plugin.Result{}
+	onAC := false
+	for _, s := range supplies {
+		switch s.attrs["type"] {
+		case "Battery":
+			results = append(results, batteryResults(s)...)
+		case "Mains", "USB":
+			if s.attrs["online"] == "1" {
+				onAC = true
+			}
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, plugin.Result{
+			Title:       "No battery found",
+			Description: fmt.Sprintf("No batteries listed in %s", powerSupplyDir),
+			Identifier:  noBatteryIdentifier,
+		})
+	}
+
+	acState := "On battery power"
+	if onAC {
+		acState = "Connected to AC power"
+	}
+	results = append(results, plugin.Result{Title: acState, Description: "Power source", Identifier: powerSourceIdentifier})
+	results = append(results, profileResults()...)
+
+	if err := p.GetError(); err != nil {
+		results = append(results, plugin.Result{
+			Title:       "Could not switch power profile",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		})
+	}
+
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		filtered := results[:0]
+		for _, r := range results {
+			if strings.Contains(strings.ToLower(r.Title+" "+r.Description), query) {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
+	}
+	return results, nil
+}
+
+// readSupplies reads the uevent-style attributes of every power supply.
+func readSupplies() ([]supply, error) {
+	entries, err := os.ReadDir(powerSupplyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	supplies := make([]supply, 0, len(entries))
+	for _, entry := range entries {
+		dir := filepath.Join(powerSupplyDir, entry.Name())
+		attrs := make(map[string]string)
+		for _, attr := range []string{
+			"type", "online", "status", "capacity", "capacity_level", "model_name", "cycle_count",
+			"energy_now", "energy_full", "energy_full_design", "power_now",
+			"charge_now", "charge_full", "charge_full_design", "current_now",
+		} {
+			data, err := os.ReadFile(filepath.Join(dir, attr))
+			if err != nil {
+				continue // Not every driver exposes every attribute.
+			}
+			attrs[attr] = strings.TrimSpace(string(data))
+		}
+		supplies = append(supplies, supply{name: entry.Name(), attrs: attrs})
+	}
+	return supplies, nil
+}
+
+// batteryResults describes the level, health and time estimate of one battery.
+func batteryResults(s supply) []plugin.Result {
+	status := s.attrs["status"]
+	title := fmt.Sprintf("%s: %s%% %s", s.name, s.attrs["capacity"], strings.ToLower(status))
+	if s.attrs["capacity"] == "" {
+		title = fmt.Sprintf("%s: %s %s", s.name, s.attrs["capacity_level"], strings.ToLower(status))
+	}
+
+	details := []string{}
+	if model := s.attrs["model_name"]; model != "" {
+		details = append(details, model)
+	}
+	if estimate := timeEstimate(s); estimate != "" {
+		details = append(details, estimate)
+	}
+	results := []plugin.Result{{
+		Title:       strings.TrimSpace(title),
+		Description: strings.Join(append(details, "Battery level"), " | "),
+		Identifier:  levelIdentifier + s.name,
+	}}
+
+	// Batteries report either energy (µWh) or charge (µAh) counters depending on the driver.
+	full, design := readInt(s, "energy_full"), readInt(s, "energy_full_design")
+	if full == 0 || design == 0 {
+		full, design = readInt(s, "charge_full"), readInt(s, "charge_full_design")
+	}
+	if full > 0 && design > 0 {
+		health := fmt.Sprintf("Health: %.0f%% of design capacity", float64(full)/float64(design)*100)
+		description := fmt.Sprintf("%s battery wear", s.name)
+		if cycles := s.attrs["cycle_count"]; cycles != "" && cycles != "0" {
+			description = fmt.Sprintf("%s | %s charge cycles", description, cycles)
+		}
+		results = append(results, plugin.Result{Title: health, Description: description, Identifier: healthIdentifier + s.name})
+	}
+	return results
+}
+
+// timeEstimate returns the time until the battery is empty or full at the current rate.
+func timeEstimate(s supply) string {
+	now, full, rate := readInt(s, "energy_now"), readInt(s, "energy_full"), readInt(s, "power_now")
+	if rate == 0 {
+		now, full, rate = readInt(s, "charge_now"), readInt(s, "charge_full"), readInt(s, "current_now")
+	}
+	if rate <= 0 {
+		return ""
+	}
+
+	switch s.attrs["status"] {
+	case "Discharging":
+		return formatHours(float64(now)/float64(rate)) + " remaining"
+	case "Charging":
+		if full > now {
+			return formatHours(float64(full-now)/float64(rate)) + " until full"
+		}
+	}
+	return ""
+}
+
+func readInt(s supply, attr string) int64 {
+	n, err := strconv.ParseInt(s.attrs[attr], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func formatHours(hours float64) string {
+	d := time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// profileResults lists power profiles, marking the active one.
+// Nothing is listed when powerprofilesctl is unavailable.
+func profileResults() []plugin.Result {
+	if _, err := exec.LookPath("powerprofilesctl"); err != nil {
+		return nil
+	}
+	out, err := exec.Command("powerprofilesctl", "get").Output()
+	if err != nil {
+		zap.L().Debug("Failed to get active power profile.", zap.Error(err))
+		return nil
+	}
+	active := strings.TrimSpace(string(out))
+
+	results := make([]plugin.Result, 0, len(powerProfiles))
+	for _, profile := range powerProfiles {
+		title := "Switch to " + profile
+		description := "Power profile | enter applies it"
+		if profile == active {
+			title = profile + " (active)"
+			description = "Current power profile"
+		}
+		results = append(results, plugin.Result{
+			Title:       title,
+			Description: description,
+			Identifier:  profileIdentifier + profile,
+		})
+	}
+	return results
+}
+
+// Execute switches to the selected power profile off the update loop, and
+// quits once it is active.
+func (p *BatteryPlugin) Execute(identifier string) tea.Cmd {
+	profile, ok := strings.CutPrefix(identifier, profileIdentifier)
+	if !ok {
+		return nil // Do nothing for info/error items.
+	}
+	return func() tea.Msg {
+		if out, err := exec.Command("powerprofilesctl", "set", profile).CombinedOutput(); err != nil {
+			zap.L().Error("Failed to set power profile.", zap.String("profile", profile), zap.Error(err))
+			return profileSetMsg{err: fmt.Errorf("%v %s", err, strings.TrimSpace(string(out)))}
+		}
+		return profileSetMsg{}
+	}
+}
+
+// Update handles the power profile being switched.
+func (p *BatteryPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	if msg, ok := msg.(profileSetMsg); ok {
+		p.setError(msg.err)
+		if msg.err == nil {
+			return p, tea.Quit
+		}
+	}
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *BatteryPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed profile switch, if any.
+func (p *BatteryPlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *BatteryPlugin) setError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
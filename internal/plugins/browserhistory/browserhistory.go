@@ -0,0 +1,281 @@
+// Package browserhistory implements the !bh plugin, which searches the history
+// of Firefox and Chromium-based browsers and opens the selected pages.
+package browserhistory
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!bh"
+
+const (
+	infoIdentifier = "bh_info"
+
+	// cacheTTL bounds how often history databases are checked for changes while typing.
+	cacheTTL = 30 * time.Second
+	// maxResults caps the pages listed for a query.
+	maxResults = 100
+)
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionCopyLink = "copy link"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Browser History",
+	Description: "Search the history of Firefox and Chromium-based browsers.",
+	Keyword:     Keyword,
+	Flag:        "bh",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// loaded holds the pages read from a history database.
+type loaded struct {
+	modTime time.Time
+	pages   []page
+}
+
+// BrowserHistoryPlugin implements the plugin.Plugin interface for browser history search.
+type BrowserHistoryPlugin struct {
+	mu        sync.Mutex
+	databases map[string]loaded // By path.
+	pages     []page            // Merged by URL.
+	checkedAt time.Time
+	loadErrs  []string
+	err       error
+}
+
+// New creates a new instance of the BrowserHistoryPlugin.
+func New() *BrowserHistoryPlugin {
+	return &BrowserHistoryPlugin{databases: make(map[string]loaded)}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *BrowserHistoryPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *BrowserHistoryPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *BrowserHistoryPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *BrowserHistoryPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists the visited pages whose title or URL contains every word of
+// the query, ranked by how often and how recently they were visited.
+func (p *BrowserHistoryPlugin) GetResults(query string) ([]plugin.Result, error) {
+	pages, loadErrs := p.load()
+	words := strings.Fields(strings.ToLower(query))
+	now := time.Now()
+
+	type scored struct {
+		page
+		score float64
+	}
+	var matches []scored
+	for _, pg := range pages {
+		text := strings.ToLower(pg.title + " " + pg.url)
+		matched := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, scored{page: pg, score: score(pg, now)})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	results := make([]plugin.Result, 0, min(len(matches), maxResults)+1)
+	for _, m := range matches[:min(len(matches), maxResults)] {
+		title := m.title
+		if title == "" {
+			title = m.url
+		}
+		results = append(results, plugin.Result{
+			Title:       title,
+			Description: fmt.Sprintf("%s | %s, last %s (%s)", m.url, visits(m.visits), m.lastVisit.Format("Jan 2 2006"), m.browser),
+			Identifier:  m.url,
+		})
+	}
+
+	if len(pages) == 0 && len(loadErrs) == 0 {
+		results = append(results, plugin.Result{
+			Title:       "No browser history found",
+			Description: "Firefox and Chromium-based browsers (Chrome, Brave, Vivaldi, Edge) are supported",
+			Identifier:  infoIdentifier,
+		})
+	}
+	for _, e := range loadErrs {
+		results = append(results, plugin.Result{Title: "Could not read history", Description: e, Identifier: infoIdentifier})
+	}
+	if p.err != nil {
+		results = append(results, plugin.Result{Title: "Last action failed", Description: p.err.Error(), Identifier: infoIdentifier})
+	}
+	return results, nil
+}
+
+// score ranks a page by its visits, counted logarithmically so that a few
+// heavily used pages do not bury everything else, plus a bonus fading over
+// about a month since the last visit.
+func score(pg page, now time.Time) float64 {
+	ageDays := now.Sub(pg.lastVisit).Hours() / 24
+	return math.Log1p(float64(pg.visits)) + 3*math.Exp(-max(ageDays, 0)/30)
+}
+
+func visits(n int) string {
+	if n == 1 {
+		return "1 visit"
+	}
+	return fmt.Sprintf("%d visits", n)
+}
+
+// load returns the pages of all history databases, re-reading those that
+// changed once the cache has expired.
+func (p *BrowserHistoryPlugin) load() ([]page, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.checkedAt) < cacheTTL {
+		return p.pages, p.loadErrs
+	}
+	p.checkedAt = time.Now()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		p.loadErrs = []string{err.Error()}
+		return p.pages, p.loadErrs
+	}
+
+	changed := false
+	found := make(map[string]bool)
+	var loadErrs []string
+	for _, db := range findDatabases(home) {
+		found[db.path] = true
+		modTime := db.modTime()
+		if cached, ok := p.databases[db.path]; ok && cached.modTime.Equal(modTime) {
+			continue
+		}
+		pages, err := db.read()
+		if err != nil {
+			zap.L().Warn("Could not read browser history.", zap.String("path", db.path), zap.Error(err))
+			loadErrs = append(loadErrs, err.Error())
+			continue
+		}
+		p.databases[db.path] = loaded{modTime: modTime, pages: pages}
+		changed = true
+	}
+	for path := range p.databases {
+		if !found[path] {
+			delete(p.databases, path) // The profile was removed.
+			changed = true
+		}
+	}
+
+	if changed {
+		p.pages = mergePages(p.databases)
+	}
+	p.loadErrs = loadErrs
+	return p.pages, p.loadErrs
+}
+
+// mergePages combines the pages of all databases, adding up the visits of
+// URLs opened in several browsers or profiles.
+func mergePages(databases map[string]loaded) []page {
+	byURL := make(map[string]int)
+	var pages []page
+	for _, db := range databases {
+		for _, pg := range db.pages {
+			i, seen := byURL[pg.url]
+			if !seen {
+				byURL[pg.url] = len(pages)
+				pages = append(pages, pg)
+				continue
+			}
+			merged := &pages[i]
+			merged.visits += pg.visits
+			if pg.lastVisit.After(merged.lastVisit) {
+				merged.lastVisit = pg.lastVisit
+			}
+			if merged.title == "" {
+				merged.title = pg.title
+			}
+		}
+	}
+	return pages
+}
+
+// Execute opens the selected page in the default browser and quits.
+func (p *BrowserHistoryPlugin) Execute(identifier string) tea.Cmd {
+	if identifier == infoIdentifier {
+		return nil // Do nothing for info items.
+	}
+	if err := launch.OpenURL(identifier); err != nil {
+		p.err = err
+		zap.L().Error("Failed to open URL.", zap.String("url", identifier), zap.Error(err))
+		return nil
+	}
+	p.err = nil
+	return tea.Quit
+}
+
+// Actions lists the actions on the selected page.
+func (p *BrowserHistoryPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionCopyLink, Keys: []string{"ctrl+y"}},
+	}
+}
+
+// RunAction copies the URL of the selected page and quits.
+func (p *BrowserHistoryPlugin) RunAction(name, identifier string) tea.Cmd {
+	if name != actionCopyLink || identifier == infoIdentifier {
+		return nil
+	}
+	if err := clipboard.WriteAll(identifier); err != nil {
+		p.err = err
+		zap.L().Error("Failed to copy URL to clipboard.", zap.Error(err))
+		return nil
+	}
+	p.err = nil
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *BrowserHistoryPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *BrowserHistoryPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed action, if any.
+func (p *BrowserHistoryPlugin) GetError() error {
+	return p.err
+}
@@ -0,0 +1,164 @@
+package browserhistory
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver.
+)
+
+// maxVisitsPerDatabase caps the pages read from a history database, the most
+// recently visited first, bounding memory with years of history.
+const maxVisitsPerDatabase = 20000
+
+// source is a browser family sharing a history database schema.
+type source struct {
+	browser string
+	// globs find the databases of the browser's profiles, relative to the home directory.
+	globs []string
+	query string
+	// lastVisit converts the stored time of the last visit.
+	lastVisit func(int64) time.Time
+}
+
+// chromeEpoch is where Chromium's timestamps, in microseconds, start.
+var chromeEpoch = time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var sources = []source{
+	{
+		browser: "Firefox",
+		globs: []string{
+			".mozilla/firefox/*/places.sqlite",
+			".var/app/org.mozilla.firefox/.mozilla/firefox/*/places.sqlite",
+			"snap/firefox/common/.mozilla/firefox/*/places.sqlite",
+			".librewolf/*/places.sqlite",
+		},
+		query: `SELECT url, COALESCE(title, ''), visit_count, COALESCE(last_visit_date, 0)
+			FROM moz_places WHERE visit_count > 0 AND hidden = 0
+			ORDER BY last_visit_date DESC LIMIT ?`,
+		lastVisit: time.UnixMicro,
+	},
+	{
+		browser: "Chromium",
+		globs: []string{
+			".config/chromium/*/History",
+			".config/google-chrome/*/History",
+			".config/BraveSoftware/Brave-Browser/*/History",
+			".config/vivaldi/*/History",
+			".config/microsoft-edge/*/History",
+			".var/app/com.google.Chrome/config/google-chrome/*/History",
+			".var/app/org.chromium.Chromium/config/chromium/*/History",
+		},
+		query: `SELECT url, title, visit_count, last_visit_time
+			FROM urls WHERE visit_count > 0 AND hidden = 0
+			ORDER BY last_visit_time DESC LIMIT ?`,
+		lastVisit: func(us int64) time.Time {
+			return chromeEpoch.Add(time.Duration(us) * time.Microsecond)
+		},
+	},
+}
+
+// page is a visited URL.
+type page struct {
+	url       string
+	title     string
+	visits    int
+	lastVisit time.Time
+	browser   string
+}
+
+// database is a history database of a browser profile.
+type database struct {
+	path   string
+	source source
+}
+
+// findDatabases returns the history databases of all profiles under home.
+func findDatabases(home string) []database {
+	var found []database
+	for _, s := range sources {
+		for _, glob := range s.globs {
+			paths, _ := filepath.Glob(filepath.Join(home, glob)) // Only fails on malformed patterns.
+			for _, path := range paths {
+				found = append(found, database{path: path, source: s})
+			}
+		}
+	}
+	return found
+}
+
+// modTime returns when the database or its write-ahead log last changed.
+func (d database) modTime() time.Time {
+	var latest time.Time
+	for _, path := range []string{d.path, d.path + "-wal"} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// read returns the pages of the database. Browsers keep their history locked
+// while running, so it reads a copy, along with the write-ahead log holding
+// the latest visits.
+func (d database) read() ([]page, error) {
+	dir, err := os.MkdirTemp("", "incipio-history-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	copyPath := filepath.Join(dir, "history.sqlite")
+	if err := copyFile(d.path, copyPath); err != nil {
+		return nil, err
+	}
+	if err := copyFile(d.path+"-wal", copyPath+"-wal"); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+copyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(d.source.query, maxVisitsPerDatabase)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", d.path, err)
+	}
+	defer rows.Close()
+
+	var pages []page
+	for rows.Next() {
+		var p page
+		var lastVisit int64
+		if err := rows.Scan(&p.url, &p.title, &p.visits, &lastVisit); err != nil {
+			return nil, err
+		}
+		p.lastVisit, p.browser = d.source.lastVisit(lastVisit), d.source.browser
+		pages = append(pages, p)
+	}
+	return pages, rows.Err()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
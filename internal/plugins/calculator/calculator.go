@@ -2,15 +2,36 @@ package calculator
 
 import (
 	"fmt"
+	"maps"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/barab-i/incipio/pkgs/clipboard"
 	"github.com/barab-i/incipio/pkgs/plugin"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/expr-lang/expr"
+	"go.uber.org/zap"
 )
 
 const Keyword = "="
 
+// ansVariable holds the last result selected, or the last value assigned.
+const ansVariable = "ans"
+
+// assignPrefix starts the identifier of an assignment result, followed by
+// "name=value".
+const assignPrefix = "calc_set:"
+
+// assignmentPattern matches "name = expression", but not comparisons like "x == 5".
+var assignmentPattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*=([^=].*)$`)
+
+// arithmeticPattern matches numbers joined by operators, like "2+2" or
+// "(3 * 4) / 2", which the calculator claims without its keyword.
+var arithmeticPattern = regexp.MustCompile(`^[\s(]*-?[\d.]+[\s)]*(?:[+\-*/%^][\s(]*-?[\d.]+[\s)]*)+$`)
+
 var metadata = plugin.Metadata{
 	Name:        "Calculator",
 	Keyword:     Keyword,
@@ -20,11 +41,22 @@ var metadata = plugin.Metadata{
 }
 
 // CalculatorPlugin implements the plugin.Plugin interface for calculations.
-type CalculatorPlugin struct{}
+// Variables assigned with "= x = 5" and ans live as long as the instance, so
+// in the daemon they are kept between launches.
+type CalculatorPlugin struct {
+	mu         sync.Mutex
+	variables  map[string]any // Values by name, including ans once set.
+	currencies *currencies
+}
 
-// New creates a new instance of the CalculatorPlugin.
-func New() *CalculatorPlugin {
-	return &CalculatorPlugin{}
+// New creates a new instance of the CalculatorPlugin. Exchange rates come from
+// ratesProvider, ProviderECB or the URL of a JSON rates API, and are fetched
+// again once older than ratesTTL.
+func New(ratesProvider string, ratesTTL time.Duration) *CalculatorPlugin {
+	return &CalculatorPlugin{
+		variables:  make(map[string]any),
+		currencies: newCurrencies(ratesProvider, ratesTTL),
+	}
 }
 
 // Metadata returns the metadata for the plugin.
@@ -47,20 +79,65 @@ func (p *CalculatorPlugin) Init() tea.Cmd {
 	return nil
 }
 
+// RefreshInterval re-runs the query while exchange rates are being fetched,
+// so that currency conversions fill in once they arrive.
+func (p *CalculatorPlugin) RefreshInterval() time.Duration {
+	if p.currencies.isFetching() {
+		return 250 * time.Millisecond
+	}
+	return 0
+}
+
+// Matches claims arithmetic and unit conversions typed without the keyword,
+// such as "2+2" or "10 km to mi".
+func (p *CalculatorPlugin) Matches(query string) bool {
+	if arithmeticPattern.MatchString(query) {
+		return true
+	}
+	_, ok := convert(query)
+	return ok
+}
+
 // GetResults evaluates the mathematical expression in the query.
 func (p *CalculatorPlugin) GetResults(query string) ([]plugin.Result, error) {
 	if query == "" {
 		return []plugin.Result{
 			{
 				Title:       "Calculator",
-				Description: "Enter a mathematical expression after '=' (e.g., = 2 * (3 + 4))",
+				Description: "Enter an expression or a conversion after '=' (e.g., = 2 * (3 + 4), = 10 km to miles, = 100 usd to eur, = x = ans / 2)",
 				Identifier:  "calc_info",
 			},
 		}, nil
 	}
 
-	program, err := expr.Compile(query)
+	// Conversions are listed alongside the plain evaluation, which usually fails for them.
+	var conversions []plugin.Result
+	if conversion, ok := convert(query); ok {
+		conversions = append(conversions, conversion)
+	} else if conversion, ok := p.currencies.convert(query); ok {
+		conversions = append(conversions, conversion)
+	}
+
+	name, expression := "", query
+	if match := assignmentPattern.FindStringSubmatch(query); match != nil {
+		name, expression = match[1], match[2]
+		if name == ansVariable {
+			return []plugin.Result{
+				{
+					Title:       fmt.Sprintf("Error: %s cannot be assigned", ansVariable),
+					Description: "ans holds the last result",
+					Identifier:  "calc_error",
+				},
+			}, nil
+		}
+	}
+
+	env := p.env()
+	program, err := expr.Compile(expression, expr.Env(env))
 	if err != nil {
+		if len(conversions) > 0 {
+			return conversions, nil
+		}
 		return []plugin.Result{
 			{
 				Title:       fmt.Sprintf("Error: %v", err),
@@ -70,8 +147,11 @@ func (p *CalculatorPlugin) GetResults(query string) ([]plugin.Result, error) {
 		}, nil
 	}
 
-	result, err := expr.Run(program, nil)
+	result, err := expr.Run(program, env)
 	if err != nil {
+		if len(conversions) > 0 {
+			return conversions, nil
+		}
 		return []plugin.Result{
 			{
 				Title:       fmt.Sprintf("Error: %v", err),
@@ -82,14 +162,76 @@ func (p *CalculatorPlugin) GetResults(query string) ([]plugin.Result, error) {
 	}
 
 	resultStr := formatResult(result)
+	if name != "" {
+		return []plugin.Result{
+			{
+				Title:       fmt.Sprintf("%s = %s", name, resultStr),
+				Description: fmt.Sprintf("Enter stores %s for later expressions", name),
+				Identifier:  assignPrefix + name + "=" + resultStr,
+			},
+		}, nil
+	}
+
+	results := append(conversions, plugin.Result{
+		Title:       resultStr,
+		Description: fmt.Sprintf("Result of: %s", query),
+		Identifier:  resultStr,
+	})
+	if n, ok := asInteger(result); ok {
+		results = append(results, baseResults(n, query)...)
+	}
+	return results, nil
+}
+
+// asInteger returns the result as an integer if it is a whole number.
+func asInteger(result any) (int64, bool) {
+	switch v := result.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
 
+// baseResults lists an integer result in hexadecimal and binary, each copied
+// on its own. Binary digits are grouped by four in the title.
+func baseResults(n int64, query string) []plugin.Result {
+	hex := strconv.FormatInt(n, 16)
+	binary := strconv.FormatInt(n, 2)
+	sign := ""
+	if n < 0 {
+		sign, hex, binary = "-", hex[1:], binary[1:]
+	}
 	return []plugin.Result{
 		{
-			Title:       resultStr,
-			Description: fmt.Sprintf("Result of: %s", query),
-			Identifier:  resultStr,
+			Title:       sign + "0x" + hex,
+			Description: fmt.Sprintf("Hexadecimal of: %s", query),
+			Identifier:  sign + "0x" + hex,
+		},
+		{
+			Title:       sign + "0b" + groupDigits(binary, 4),
+			Description: fmt.Sprintf("Binary of: %s", query),
+			Identifier:  sign + "0b" + binary,
 		},
-	}, nil
+	}
+}
+
+// groupDigits separates digits into groups of size from the right with "_",
+// as in Go and expr literals.
+func groupDigits(digits string, size int) string {
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%size == 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
 }
 
 // formatResult converts the evaluation result into a string representation.
@@ -112,13 +254,57 @@ func formatResult(result any) string {
 	}
 }
 
-// Execute handles the action for a selected result.
-// For the calculator, it quits unless it's an info or error message.
+// env returns a copy of the variables for evaluating an expression.
+func (p *CalculatorPlugin) env() map[string]any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.variables)
+}
+
+// store sets the variable name, and ans, to the formatted value.
+func (p *CalculatorPlugin) store(name, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	parsed := parseResult(value)
+	if name != "" {
+		p.variables[name] = parsed
+	}
+	p.variables[ansVariable] = parsed
+}
+
+// parseResult converts a result formatted by formatResult or baseResults back
+// into a value.
+func parseResult(s string) any {
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return int(i)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
+// Execute copies the selected result to the clipboard and quits, keeping it
+// as ans. An assignment stores the variable and leaves the launcher open for
+// the next expression. Info and error messages are ignored.
 func (p *CalculatorPlugin) Execute(identifier string) tea.Cmd {
 	if identifier == "calc_info" || identifier == "calc_error" {
 		return nil // Do nothing for info/error items.
 	}
-	return tea.Quit // Quit on selecting a valid result.
+	if assignment, ok := strings.CutPrefix(identifier, assignPrefix); ok {
+		name, value, _ := strings.Cut(assignment, "=")
+		p.store(name, value)
+		return func() tea.Msg { return nil }
+	}
+	p.store("", identifier)
+	if err := clipboard.WriteAll(identifier); err != nil {
+		zap.L().Error("Failed to copy calculator result to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
 }
 
 // Update handles messages.
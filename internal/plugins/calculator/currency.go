@@ -0,0 +1,341 @@
+package calculator
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"go.uber.org/zap"
+)
+
+const (
+	// ProviderECB fetches the daily reference rates of the European Central Bank.
+	ProviderECB = "ecb"
+	ecbRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+	// DefaultRatesTTL is how long fetched rates are used before fetching them again.
+	DefaultRatesTTL = 12 * time.Hour
+
+	ratesCacheFile = "rates.json"
+	fetchTimeout   = 10 * time.Second
+	// retryDelay spaces out fetches while the provider cannot be reached.
+	retryDelay = time.Minute
+)
+
+// currencyPattern matches "<value> <code> to|in <code>", e.g. "100 usd to eur".
+var currencyPattern = regexp.MustCompile(`^(.*?)\s*([a-z]{3})\s+(?:to|in)\s+([a-z]{3})$`)
+
+// knownCurrencies are the codes recognized before any rates are loaded, those
+// published by the ECB. Rates loaded later add their own codes.
+var knownCurrencies = []string{
+	"AUD", "BGN", "BRL", "CAD", "CHF", "CNY", "CZK", "DKK", "EUR", "GBP", "HKD",
+	"HUF", "IDR", "ILS", "INR", "ISK", "JPY", "KRW", "MXN", "MYR", "NOK", "NZD",
+	"PHP", "PLN", "RON", "SEK", "SGD", "THB", "TRY", "USD", "ZAR",
+}
+
+// exchangeRates are the rates of a provider, as cached on disk.
+type exchangeRates struct {
+	Base    string             `json:"base"`
+	Rates   map[string]float64 `json:"rates"` // Units of each currency per unit of Base.
+	Date    string             `json:"date"`  // Day the provider published the rates, if known.
+	Source  string             `json:"source"`
+	Fetched time.Time          `json:"fetched"`
+}
+
+// rate returns the units of currency per unit of the base currency.
+func (r *exchangeRates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok && rate > 0
+}
+
+// currencies fetches exchange rates in the background and caches them on disk.
+// When the provider cannot be reached, the last cached rates are used.
+type currencies struct {
+	provider string // ProviderECB or the URL of a JSON rates API.
+	ttl      time.Duration
+
+	mu          sync.Mutex
+	rates       *exchangeRates // Nil until loaded from the cache or fetched.
+	cacheRead   bool
+	fetching    bool
+	lastAttempt time.Time
+	fetchErr    error // Error of the last fetch, if it failed.
+}
+
+// newCurrencies returns a converter using provider, ProviderECB if empty, and
+// refetching rates older than ttl, DefaultRatesTTL if zero.
+func newCurrencies(provider string, ttl time.Duration) *currencies {
+	if provider == "" {
+		provider = ProviderECB
+	}
+	if ttl <= 0 {
+		ttl = DefaultRatesTTL
+	}
+	return &currencies{provider: provider, ttl: ttl}
+}
+
+// isFetching reports whether rates are being fetched.
+func (c *currencies) isFetching() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetching
+}
+
+// current returns the rates known so far, starting a fetch in the background
+// if they are missing or older than the TTL.
+func (c *currencies) current() (rates *exchangeRates, fetching bool, fetchErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.cacheRead {
+		c.cacheRead = true
+		c.rates = readRatesCache()
+	}
+	stale := c.rates == nil || time.Since(c.rates.Fetched) > c.ttl
+	if stale && !c.fetching && time.Since(c.lastAttempt) > retryDelay {
+		c.fetching = true
+		c.lastAttempt = time.Now()
+		go c.fetch()
+	}
+	return c.rates, c.fetching, c.fetchErr
+}
+
+// fetch downloads the rates and caches them.
+func (c *currencies) fetch() {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	rates, err := fetchRates(ctx, c.provider)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetching = false
+	c.fetchErr = err
+	if err != nil {
+		zap.L().Warn("Could not fetch exchange rates.", zap.String("provider", c.provider), zap.Error(err))
+		return
+	}
+	c.rates = rates
+	writeRatesCache(rates)
+}
+
+// isCurrency reports whether code names a currency with known rates.
+func (c *currencies) isCurrency(code string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rates != nil {
+		if _, ok := c.rates.rate(code); ok {
+			return true
+		}
+	}
+	return slices.Contains(knownCurrencies, code)
+}
+
+// convert recognizes a currency conversion query and returns its result. ok
+// is false if the query is not a currency conversion.
+func (c *currencies) convert(query string) (result plugin.Result, ok bool) {
+	match := currencyPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(query)))
+	if match == nil {
+		return plugin.Result{}, false
+	}
+	from, to := strings.ToUpper(match[2]), strings.ToUpper(match[3])
+	if !c.isCurrency(from) || !c.isCurrency(to) {
+		return plugin.Result{}, false
+	}
+
+	value := 1.0
+	if valueExpr := strings.TrimSpace(match[1]); valueExpr != "" {
+		v, err := evalNumber(valueExpr)
+		if err != nil {
+			return plugin.Result{}, false
+		}
+		value = v
+	}
+
+	rates, fetching, fetchErr := c.current()
+	if rates == nil {
+		if fetching {
+			return plugin.Result{
+				Title:       "Fetching exchange rates...",
+				Description: fmt.Sprintf("Converting %s %s to %s", formatNumber(value), from, to),
+				Identifier:  "calc_info",
+			}, true
+		}
+		return plugin.Result{
+			Title:       "Error: no exchange rates",
+			Description: fmt.Sprintf("Could not fetch them: %v", fetchErr),
+			Identifier:  "calc_error",
+		}, true
+	}
+
+	fromRate, fromOK := rates.rate(from)
+	toRate, toOK := rates.rate(to)
+	if !fromOK || !toOK {
+		missing := from
+		if fromOK {
+			missing = to
+		}
+		return plugin.Result{
+			Title:       fmt.Sprintf("Error: no exchange rate for %s", missing),
+			Description: fmt.Sprintf("Not published by %s", rates.Source),
+			Identifier:  "calc_error",
+		}, true
+	}
+
+	converted := value / fromRate * toRate
+	convertedStr := formatNumber(converted)
+	description := fmt.Sprintf("Conversion of: %s %s at %s rates of %s", formatNumber(value), from, rates.Source, rates.Date)
+	if fetchErr != nil && time.Since(rates.Fetched) > c.ttl {
+		description += " (offline, last cached)"
+	}
+	return plugin.Result{
+		Title:       fmt.Sprintf("%s %s", convertedStr, to),
+		Description: description,
+		Identifier:  convertedStr,
+	}, true
+}
+
+// fetchRates downloads the rates of provider: the ECB's daily XML, or a JSON
+// API answering {"base": "EUR", "date": "...", "rates": {"USD": 1.08, ...}},
+// as frankfurter.app and exchangerate.host do.
+func fetchRates(ctx context.Context, provider string) (*exchangeRates, error) {
+	url := provider
+	if provider == ProviderECB {
+		url = ecbRatesURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var rates *exchangeRates
+	if provider == ProviderECB {
+		rates, err = parseECBRates(body)
+	} else {
+		rates, err = parseJSONRates(body, req.URL.Host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates from %s: %w", url, err)
+	}
+	rates.Fetched = time.Now()
+	return rates, nil
+}
+
+// parseECBRates parses the ECB's eurofxref-daily.xml.
+func parseECBRates(body []byte) (*exchangeRates, error) {
+	var envelope struct {
+		Cube struct {
+			Day struct {
+				Time  string `xml:"time,attr"`
+				Rates []struct {
+					Currency string  `xml:"currency,attr"`
+					Rate     float64 `xml:"rate,attr"`
+				} `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	rates := &exchangeRates{Base: "EUR", Rates: make(map[string]float64), Date: envelope.Cube.Day.Time, Source: "ECB"}
+	for _, r := range envelope.Cube.Day.Rates {
+		rates.Rates[r.Currency] = r.Rate
+	}
+	if len(rates.Rates) == 0 {
+		return nil, errors.New("no rates")
+	}
+	return rates, nil
+}
+
+// parseJSONRates parses the rates of a JSON API.
+func parseJSONRates(body []byte, source string) (*exchangeRates, error) {
+	var response struct {
+		Base     string             `json:"base"`
+		BaseCode string             `json:"base_code"` // open.er-api.com
+		Date     string             `json:"date"`
+		Rates    map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	base := cmp.Or(response.Base, response.BaseCode)
+	if base == "" || len(response.Rates) == 0 {
+		return nil, errors.New("no base currency or rates")
+	}
+	return &exchangeRates{
+		Base:   strings.ToUpper(base),
+		Rates:  response.Rates,
+		Date:   cmp.Or(response.Date, time.Now().Format(time.DateOnly)),
+		Source: source,
+	}, nil
+}
+
+// ratesCachePath returns the location of the cached rates.
+func ratesCachePath() (string, error) {
+	return xdg.CacheFile(filepath.Join("incipio", ratesCacheFile))
+}
+
+// readRatesCache returns the cached rates, or nil if there are none.
+func readRatesCache() *exchangeRates {
+	path, err := ratesCachePath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			zap.L().Warn("Could not read cached exchange rates.", zap.String("path", path), zap.Error(err))
+		}
+		return nil
+	}
+	var rates exchangeRates
+	if err := json.Unmarshal(data, &rates); err != nil || rates.Base == "" {
+		zap.L().Warn("Ignoring invalid cached exchange rates.", zap.String("path", path), zap.Error(err))
+		return nil
+	}
+	return &rates
+}
+
+// writeRatesCache caches rates on disk for later sessions and offline use.
+func writeRatesCache(rates *exchangeRates) {
+	path, err := ratesCachePath()
+	if err == nil {
+		var data []byte
+		data, err = json.Marshal(rates)
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+	}
+	if err != nil {
+		zap.L().Warn("Could not cache exchange rates.", zap.Error(err))
+	}
+}
@@ -0,0 +1,153 @@
+package calculator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/expr-lang/expr"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// unit converts values of a dimension to and from its base unit:
+// base = value*factor + offset.
+type unit struct {
+	symbol    string
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+// UnitsFile is the name of the unit table, embedded and in the config directory.
+const UnitsFile = "units.yaml"
+
+// Units holds the built-in unit table, UnitsFile, for "incipio assets export".
+//
+//go:embed units.yaml
+var Units embed.FS
+
+// unitEntry is a unit of the table with its accepted names.
+type unitEntry struct {
+	Symbol    string   `yaml:"symbol"`
+	Dimension string   `yaml:"dimension"`
+	Factor    float64  `yaml:"factor"`
+	Offset    float64  `yaml:"offset"`
+	Names     []string `yaml:"names"`
+}
+
+// units indexes the built-in units, then those of the user's units.yaml, by
+// lowercase name. It is loaded on the first conversion.
+var units = sync.OnceValue(func() map[string]unit {
+	m := make(map[string]unit)
+	add := func(data []byte, source string) {
+		var entries []unitEntry
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			zap.L().Warn("Could not parse unit table, ignoring it.", zap.String("source", source), zap.Error(err))
+			return
+		}
+		for _, e := range entries {
+			if e.Symbol == "" || e.Factor == 0 {
+				zap.L().Warn("Ignoring unit without a symbol or factor.", zap.String("source", source), zap.String("symbol", e.Symbol))
+				continue
+			}
+			for _, name := range e.Names {
+				m[strings.ToLower(name)] = unit{symbol: e.Symbol, dimension: e.Dimension, factor: e.Factor, offset: e.Offset}
+			}
+		}
+	}
+	builtIn, err := Units.ReadFile(UnitsFile)
+	if err != nil {
+		panic(err) // The file is embedded.
+	}
+	add(builtIn, "built-in")
+
+	path, err := xdg.ConfigFile(filepath.Join("incipio", UnitsFile))
+	if err != nil {
+		return m
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		add(data, path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		zap.L().Warn("Could not read unit table.", zap.String("path", path), zap.Error(err))
+	}
+	return m
+})
+
+// conversionPattern matches "<value> <unit> to|in <unit>", e.g. "10 km to miles".
+// The value may be any expression and defaults to 1 when omitted.
+var conversionPattern = regexp.MustCompile(`^(.*?)\s*([a-z°][a-z°/ ]*?)\s+(?:to|in)\s+([a-z°][a-z°/ ]*?)$`)
+
+// convert recognizes a unit conversion query and returns its result. ok is
+// false if the query is not a conversion.
+func convert(query string) (result plugin.Result, ok bool) {
+	match := conversionPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(query)))
+	if match == nil {
+		return plugin.Result{}, false
+	}
+	from, fromOK := units()[match[2]]
+	to, toOK := units()[match[3]]
+	if !fromOK || !toOK {
+		return plugin.Result{}, false
+	}
+
+	value := 1.0
+	if valueExpr := strings.TrimSpace(match[1]); valueExpr != "" {
+		v, err := evalNumber(valueExpr)
+		if err != nil {
+			return plugin.Result{}, false
+		}
+		value = v
+	}
+
+	if from.dimension != to.dimension {
+		return plugin.Result{
+			Title:       fmt.Sprintf("Error: cannot convert %s to %s", from.dimension, to.dimension),
+			Description: "Incompatible units",
+			Identifier:  "calc_error",
+		}, true
+	}
+
+	converted := ((value*from.factor + from.offset) - to.offset) / to.factor
+	convertedStr := formatNumber(converted)
+	return plugin.Result{
+		Title:       fmt.Sprintf("%s %s", convertedStr, to.symbol),
+		Description: fmt.Sprintf("Conversion of: %s %s (%s)", formatNumber(value), from.symbol, from.dimension),
+		Identifier:  convertedStr,
+	}, true
+}
+
+// evalNumber evaluates a numeric expression.
+func evalNumber(input string) (float64, error) {
+	result, err := expr.Eval(input, nil)
+	if err != nil {
+		return 0, err
+	}
+	switch v := result.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("not a number: %v", result)
+	}
+}
+
+// formatNumber formats a converted value with up to 10 significant digits.
+func formatNumber(v float64) string {
+	if v == float64(int64(v)) {
+		return formatResult(v)
+	}
+	return strconv.FormatFloat(v, 'g', 10, 64)
+}
@@ -0,0 +1,395 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!cal"
+
+const (
+	infoIdentifier  = "cal_info"
+	errorIdentifier = "cal_error"
+)
+
+const usage = "e.g. next friday, days until 2025-12-25, now + 3 weeks, 3pm PST in CET — enter copies the value"
+
+var metadata = plugin.Metadata{
+	Name:        "Calendar",
+	Description: "Calculate dates, durations between them and times in other time zones.",
+	Keyword:     Keyword,
+	Flag:        "cal",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// answer is a value a query evaluates to, copied when selected.
+type answer struct {
+	value string
+	note  string
+}
+
+// CalendarPlugin implements the plugin.Plugin interface for date calculations.
+type CalendarPlugin struct {
+	now func() time.Time
+}
+
+// New creates a new instance of the CalendarPlugin.
+func New() *CalendarPlugin {
+	return &CalendarPlugin{now: time.Now}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *CalendarPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *CalendarPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *CalendarPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *CalendarPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults evaluates the query, showing the current date and time when it
+// is empty.
+func (p *CalendarPlugin) GetResults(query string) ([]plugin.Result, error) {
+	answers, err := evaluate(query, p.now())
+	if err != nil {
+		return []plugin.Result{{Title: "Could not read the date", Description: err.Error() + " | " + usage, Identifier: errorIdentifier}}, nil
+	}
+
+	results := make([]plugin.Result, 0, len(answers)+1)
+	for _, a := range answers {
+		results = append(results, plugin.Result{Title: a.value, Description: a.note, Identifier: a.value})
+	}
+	if strings.TrimSpace(query) == "" {
+		results = append(results, plugin.Result{Title: "Calendar", Description: usage, Identifier: infoIdentifier})
+	}
+	return results, nil
+}
+
+// evaluate computes the answers to a query relative to now:
+//
+//   - a date or time: "next friday", "tomorrow 9am", "2025-12-25"
+//   - date arithmetic: "now + 3 weeks", "in 2 days", "3 days ago", "2 weeks after dec 1"
+//   - a duration: "days until 2025-12-25", "weeks since jan 1", "days between X and Y"
+//   - any of these in another time zone: "3pm PST in CET", "now in Asia/Tokyo"
+func evaluate(query string, now time.Time) ([]answer, error) {
+	fields := splitSigns(strings.Fields(query))
+
+	var target *time.Location // Zone the result is shown in, if converted.
+	if n := len(fields); n >= 2 && (strings.EqualFold(fields[n-2], "in") || strings.EqualFold(fields[n-2], "to")) {
+		if loc, ok := zone(fields[n-1]); ok {
+			target, fields = loc, fields[:n-2]
+		}
+	}
+
+	if answers, ok, err := evaluateSpan(fields, now); ok {
+		return answers, err
+	}
+
+	m, err := evaluateMoment(fields, now, false)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil {
+		return convertAnswers(m, target), nil
+	}
+	return momentAnswers(m, now), nil
+}
+
+// splitSigns separates signs written against amounts, as in "now +3 weeks".
+func splitSigns(fields []string) []string {
+	split := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) > 1 && (f[0] == '+' || f[0] == '-') && f[1] >= '0' && f[1] <= '9' {
+			split = append(split, f[:1], f[1:])
+			continue
+		}
+		split = append(split, f)
+	}
+	return split
+}
+
+// evaluateMoment reads a point in time, optionally in a time zone and moved
+// by amounts of units. See parseMoment for past.
+func evaluateMoment(fields []string, now time.Time, past bool) (moment, error) {
+	loc := time.Local
+	if n := len(fields); n > 0 {
+		if l, ok := zone(fields[n-1]); ok {
+			loc, fields = l, fields[:n-1]
+		}
+	}
+
+	lower := make([]string, len(fields))
+	for i, f := range fields {
+		lower[i] = strings.ToLower(f)
+	}
+	switch {
+	case len(lower) > 1 && lower[0] == "in":
+		t, err := parseOffsets(fields[1:], now.In(loc), 1)
+		return moment{t: t, hasClock: true}, err
+	case len(lower) > 1 && lower[len(lower)-1] == "ago":
+		t, err := parseOffsets(fields[:len(fields)-1], now.In(loc), -1)
+		return moment{t: t, hasClock: true}, err
+	}
+	for i, word := range lower {
+		sign := 0
+		switch word {
+		case "from", "after":
+			sign = 1
+		case "before":
+			sign = -1
+		}
+		if sign != 0 && i > 0 {
+			base, err := parseMoment(fields[i+1:], now, loc, past)
+			if err != nil {
+				return moment{}, err
+			}
+			base.t, err = parseOffsets(fields[:i], base.t, sign)
+			return base, err
+		}
+	}
+
+	if i := slices.IndexFunc(fields, func(f string) bool { return f == "+" || f == "-" }); i >= 0 {
+		base, err := parseMoment(fields[:i], now, loc, past)
+		if err != nil {
+			return moment{}, err
+		}
+		base.t, err = parseOffsets(fields[i:], base.t, 1)
+		return base, err
+	}
+	return parseMoment(fields, now, loc, past)
+}
+
+// spanWords start queries asking for the time between two moments, after an
+// optional unit, as in "weeks until".
+var spanWords = []string{"until", "till", "to", "since", "between"}
+
+// evaluateSpan computes the time between two moments, and reports whether the
+// query asked for it.
+func evaluateSpan(fields []string, now time.Time) ([]answer, bool, error) {
+	for len(fields) > 0 && slices.Contains([]string{"how", "long", "time"}, strings.ToLower(fields[0])) {
+		fields = fields[1:]
+	}
+	u := day
+	if len(fields) > 0 {
+		if parsed, ok := units[strings.ToLower(fields[0])]; ok && len(fields[0]) > 1 {
+			u, fields = parsed, fields[1:]
+		}
+	}
+	if len(fields) < 2 || !slices.Contains(spanWords, strings.ToLower(fields[0])) {
+		return nil, false, nil
+	}
+	word, fields := strings.ToLower(fields[0]), fields[1:]
+
+	from, to := moment{t: now, hasClock: true}, moment{}
+	var err error
+	switch word {
+	case "between":
+		i := slices.IndexFunc(fields, func(f string) bool { return strings.EqualFold(f, "and") })
+		if i < 0 {
+			return nil, true, fmt.Errorf("expected 'between <date> and <date>'")
+		}
+		if from, err = evaluateMoment(fields[:i], now, false); err != nil {
+			return nil, true, err
+		}
+		to, err = evaluateMoment(fields[i+1:], now, false)
+	case "since":
+		from, to = moment{}, from
+		from, err = evaluateMoment(fields, now, true)
+	default:
+		to, err = evaluateMoment(fields, now, false)
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return spanAnswers(from, to, u), true, nil
+}
+
+// spanAnswers describes the time from one moment to another in the unit
+// asked for, then broken down in weeks and months.
+func spanAnswers(from, to moment, u unit) []answer {
+	note := fmt.Sprintf("from %s to %s", formatMoment(from), formatMoment(to))
+	past := to.t.Before(from.t)
+	if !from.hasClock || !to.hasClock {
+		past = dayDiff(from.t, to.t) < 0 // Today is not past for a date.
+	}
+	if past {
+		from, to = to, from
+		note += " (in the past)"
+	}
+
+	if u.fixed != 0 {
+		d := to.t.Sub(from.t)
+		return []answer{
+			{value: plural(int(d/u.fixed), u.name), note: note},
+			{value: formatDuration(d), note: note},
+		}
+	}
+
+	days := dayDiff(from.t, to.t)
+	months, monthDays := monthDiff(from.t, to.t)
+	var first answer
+	switch u {
+	case week:
+		first = answer{value: joinAmounts(days/7, "week", days%7, "day"), note: note}
+	case month:
+		first = answer{value: joinAmounts(months, "month", monthDays, "day"), note: note}
+	case year:
+		first = answer{value: joinAmounts(months/12, "year", months%12, "month"), note: note}
+	default:
+		first = answer{value: plural(days, "day"), note: note}
+	}
+	answers := []answer{first}
+	for _, a := range []answer{
+		{value: plural(days, "day"), note: note},
+		{value: joinAmounts(days/7, "week", days%7, "day"), note: note},
+		{value: joinAmounts(months, "month", monthDays, "day"), note: note},
+	} {
+		if !slices.Contains(answers, a) {
+			answers = append(answers, a)
+		}
+	}
+	return answers
+}
+
+// momentAnswers formats a moment: readable, then in machine formats.
+func momentAnswers(m moment, now time.Time) []answer {
+	note := relative(m, now)
+	if !m.hasClock {
+		year, week := m.t.ISOWeek()
+		return []answer{
+			{value: m.t.Format("Monday, January 2, 2006"), note: note},
+			{value: m.t.Format(time.DateOnly), note: "ISO 8601 | " + note},
+			{value: fmt.Sprintf("%d-W%02d", year, week), note: "ISO week | " + note},
+		}
+	}
+	return []answer{
+		{value: m.t.Format("Mon, Jan 2 2006 15:04 MST"), note: note},
+		{value: m.t.Format(time.RFC3339), note: "RFC 3339 | " + note},
+		{value: strconv.FormatInt(m.t.Unix(), 10), note: "Unix timestamp | " + note},
+	}
+}
+
+// convertAnswers shows a moment in another time zone.
+func convertAnswers(m moment, loc *time.Location) []answer {
+	converted := m.t.In(loc)
+	note := fmt.Sprintf("%s = %s", m.t.Format("Mon 15:04 MST"), converted.Format("Mon 15:04 MST"))
+	if shift := dayDiff(m.t, converted); shift != 0 {
+		note += fmt.Sprintf(" (%+d day)", shift)
+	}
+	return []answer{
+		{value: converted.Format("15:04 MST"), note: note},
+		{value: converted.Format("Mon, Jan 2 2006 15:04 MST"), note: note},
+		{value: converted.Format(time.RFC3339), note: "RFC 3339 | " + note},
+	}
+}
+
+// relative describes when a moment is from now, e.g. "in 3 days".
+func relative(m moment, now time.Time) string {
+	if m.hasClock {
+		d := m.t.Sub(now).Round(time.Minute)
+		switch {
+		case d == 0:
+			return "now"
+		case d > 0 && d < 24*time.Hour:
+			return "in " + formatDuration(d)
+		case d < 0 && d > -24*time.Hour:
+			return formatDuration(-d) + " ago"
+		}
+	}
+	switch days := dayDiff(now.In(m.t.Location()), m.t); {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days == -1:
+		return "yesterday"
+	case days > 0:
+		return "in " + plural(days, "day")
+	default:
+		return plural(-days, "day") + " ago"
+	}
+}
+
+// formatMoment formats a moment for notes, with its time if it has one.
+func formatMoment(m moment) string {
+	if m.hasClock {
+		return m.t.Format("Mon Jan 2 2006 15:04")
+	}
+	return m.t.Format("Mon Jan 2 2006")
+}
+
+// formatDuration renders a duration in hours and minutes, e.g. "26h 5m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// plural formats an amount of a unit, e.g. "1 day" or "3 days".
+func plural(n int, name string) string {
+	if n == 1 {
+		return "1 " + name
+	}
+	return fmt.Sprintf("%d %ss", n, name)
+}
+
+// joinAmounts formats two amounts, leaving out either if zero.
+func joinAmounts(n int, name string, rest int, restName string) string {
+	switch {
+	case rest == 0:
+		return plural(n, name)
+	case n == 0:
+		return plural(rest, restName)
+	}
+	return plural(n, name) + " " + plural(rest, restName)
+}
+
+// Execute copies the selected value to the clipboard and quits.
+func (p *CalendarPlugin) Execute(identifier string) tea.Cmd {
+	if identifier == infoIdentifier || identifier == errorIdentifier {
+		return nil // Do nothing for info/error items.
+	}
+	if err := clipboard.WriteAll(identifier); err != nil {
+		zap.L().Error("Failed to copy date to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *CalendarPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *CalendarPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil; invalid queries are shown as results.
+func (p *CalendarPlugin) GetError() error {
+	return nil
+}
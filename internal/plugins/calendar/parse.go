@@ -0,0 +1,328 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// moment is a point in time a query refers to. Dates without a time of day,
+// like "next friday", have hasClock unset and are shown as dates only.
+type moment struct {
+	t        time.Time
+	hasClock bool
+}
+
+// unit is a step of date arithmetic, like "3 weeks".
+type unit struct {
+	name  string // Singular, for display.
+	fixed time.Duration
+	days  int // Calendar units keep the time of day across DST.
+	month int
+}
+
+var (
+	second = unit{name: "second", fixed: time.Second}
+	minute = unit{name: "minute", fixed: time.Minute}
+	hour   = unit{name: "hour", fixed: time.Hour}
+	day    = unit{name: "day", days: 1}
+	week   = unit{name: "week", days: 7}
+	month  = unit{name: "month", month: 1}
+	year   = unit{name: "year", month: 12}
+)
+
+// units maps the spellings of units to them.
+var units = map[string]unit{
+	"s": second, "sec": second, "secs": second, "second": second, "seconds": second,
+	"m": minute, "min": minute, "mins": minute, "minute": minute, "minutes": minute,
+	"h": hour, "hr": hour, "hrs": hour, "hour": hour, "hours": hour,
+	"d": day, "day": day, "days": day,
+	"w": week, "wk": week, "wks": week, "week": week, "weeks": week,
+	"mo": month, "month": month, "months": month,
+	"y": year, "yr": year, "yrs": year, "year": year, "years": year,
+}
+
+// add moves t by n units.
+func (u unit) add(t time.Time, n int) time.Time {
+	if u.fixed != 0 {
+		return t.Add(time.Duration(n) * u.fixed)
+	}
+	return addMonths(t, n*u.month).AddDate(0, 0, n*u.days)
+}
+
+// addMonths moves t by n months. Unlike AddDate, which would carry Jan 31
+// over into March, it ends at the last day of months too short for the day.
+func addMonths(t time.Time, n int) time.Time {
+	if n == 0 {
+		return t
+	}
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(t.Day(), last), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// zoneOffsets holds common time zone abbreviations. They name fixed offsets:
+// "3pm PST" is 3pm at UTC-8 even while California observes PDT.
+var zoneOffsets = map[string]time.Duration{
+	"utc": 0, "gmt": 0, "z": 0,
+	"hst": -10 * time.Hour, "akst": -9 * time.Hour, "akdt": -8 * time.Hour,
+	"pst": -8 * time.Hour, "pdt": -7 * time.Hour, "mst": -7 * time.Hour, "mdt": -6 * time.Hour,
+	"cst": -6 * time.Hour, "cdt": -5 * time.Hour, "est": -5 * time.Hour, "edt": -4 * time.Hour,
+	"wet": 0, "west": time.Hour, "bst": time.Hour,
+	"cet": time.Hour, "cest": 2 * time.Hour, "eet": 2 * time.Hour, "eest": 3 * time.Hour, "msk": 3 * time.Hour,
+	"pkt": 5 * time.Hour, "ist": 5*time.Hour + 30*time.Minute, "ict": 7 * time.Hour,
+	"sgt": 8 * time.Hour, "hkt": 8 * time.Hour, "awst": 8 * time.Hour, "jst": 9 * time.Hour, "kst": 9 * time.Hour,
+	"acst": 9*time.Hour + 30*time.Minute, "aest": 10 * time.Hour, "aedt": 11 * time.Hour,
+	"nzst": 12 * time.Hour, "nzdt": 13 * time.Hour,
+}
+
+// zone resolves a time zone abbreviation, an IANA name like Europe/Berlin,
+// or "local".
+func zone(name string) (*time.Location, bool) {
+	lower := strings.ToLower(name)
+	if lower == "local" {
+		return time.Local, true
+	}
+	if offset, ok := zoneOffsets[lower]; ok {
+		return time.FixedZone(strings.ToUpper(lower), int(offset.Seconds())), true
+	}
+	if !strings.Contains(name, "/") {
+		return nil, false // Keeps words like "in" from reading as zone files.
+	}
+	loc, err := time.LoadLocation(name)
+	return loc, err == nil
+}
+
+// dateLayouts are the absolute dates understood, after commas are removed.
+// Layouts without a year refer to the next such day; see parseMoment.
+var dateLayouts = []string{
+	"2006-01-02", "2006/01/02", "02.01.2006",
+	"Jan 2 2006", "January 2 2006", "2 Jan 2006", "2 January 2006",
+	"Mon Jan 2 2006", "Monday January 2 2006",
+	"Jan 2", "January 2", "2 Jan", "2 January",
+}
+
+// clockLayouts are the times of day understood, lowercased with spaces removed.
+var clockLayouts = []string{"3pm", "3:04pm", "15:04", "15:04:05"}
+
+// parseMoment reads a point in time relative to now in loc: a day like
+// "today", "next friday" or "2025-12-25", optionally followed by a time of
+// day, a time of day alone, "now", "@<unix seconds>" or an RFC 3339 timestamp.
+// Days like "friday" or "dec 25" are the next such day, or the last one if
+// past is set.
+func parseMoment(fields []string, now time.Time, loc *time.Location, past bool) (moment, error) {
+	now = now.In(loc)
+	if len(fields) == 0 || len(fields) == 1 && strings.EqualFold(fields[0], "now") {
+		return moment{t: now, hasClock: true}, nil
+	}
+	if len(fields) == 1 {
+		if seconds, ok := strings.CutPrefix(fields[0], "@"); ok {
+			n, err := strconv.ParseInt(seconds, 10, 64)
+			if err != nil {
+				return moment{}, fmt.Errorf("invalid timestamp '%s'", fields[0])
+			}
+			return moment{t: time.Unix(n, 0).In(loc), hasClock: true}, nil
+		}
+		if t, err := time.Parse(time.RFC3339, strings.ToUpper(fields[0])); err == nil {
+			return moment{t: t.In(loc), hasClock: true}, nil
+		}
+	}
+
+	date, n, ok := parseDay(fields, now, past)
+	if !ok {
+		date, n = midnight(now), 0 // A time of day alone is today's.
+	}
+	rest := fields[n:]
+	if len(rest) == 0 {
+		return moment{t: date}, nil
+	}
+	hour, minute, second, ok := parseClock(rest)
+	if !ok {
+		return moment{}, fmt.Errorf("unknown date or time '%s'", strings.Join(fields, " "))
+	}
+	return moment{t: time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, 0, loc), hasClock: true}, nil
+}
+
+// parseDay reads the day the fields start with, as midnight in now's
+// location, and returns how many fields it took. See parseMoment for past.
+func parseDay(fields []string, now time.Time, past bool) (time.Time, int, bool) {
+	today := midnight(now)
+	first := strings.ToLower(fields[0])
+	switch first {
+	case "today":
+		return today, 1, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), 1, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), 1, true
+	}
+
+	// Absolute dates span up to four fields, e.g. "Mon Jan 2 2006".
+	for n := min(len(fields), 4); n > 0; n-- {
+		text := strings.ReplaceAll(strings.Join(fields[:n], " "), ",", "")
+		for _, layout := range dateLayouts {
+			t, err := time.ParseInLocation(layout, text, now.Location())
+			if err != nil {
+				continue
+			}
+			if !strings.Contains(layout, "2006") {
+				t = t.AddDate(today.Year(), 0, 0)
+				switch {
+				case past && t.After(today):
+					t = t.AddDate(-1, 0, 0)
+				case !past && t.Before(today):
+					t = t.AddDate(1, 0, 0)
+				}
+			}
+			return t, n, true
+		}
+	}
+
+	if wd, ok := weekdays[first]; ok {
+		if past {
+			return previousWeekday(today.AddDate(0, 0, 1), wd), 1, true
+		}
+		return nextWeekday(today, wd, false), 1, true
+	}
+	if len(fields) >= 2 && (first == "next" || first == "last" || first == "this") {
+		second := strings.ToLower(fields[1])
+		if wd, ok := weekdays[second]; ok {
+			switch first {
+			case "next":
+				return nextWeekday(today, wd, true), 2, true
+			case "last":
+				return previousWeekday(today, wd), 2, true
+			default:
+				return nextWeekday(today, wd, false), 2, true
+			}
+		}
+		if u, ok := units[second]; ok && u.fixed == 0 && first != "this" {
+			step := 1
+			if first == "last" {
+				step = -1
+			}
+			return u.add(today, step), 2, true
+		}
+	}
+
+	return time.Time{}, 0, false
+}
+
+// parseClock reads a time of day such as "3pm", "3:30 pm", "15:00", "noon"
+// or "midnight".
+func parseClock(fields []string) (hour, minute, second int, ok bool) {
+	text := strings.ToLower(strings.Join(fields, ""))
+	switch text {
+	case "noon":
+		return 12, 0, 0, true
+	case "midnight":
+		return 0, 0, 0, true
+	}
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t.Hour(), t.Minute(), t.Second(), true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// midnight returns the start of t's day.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextWeekday returns the next day falling on wd from today, today included
+// unless after is set.
+func nextWeekday(today time.Time, wd time.Weekday, after bool) time.Time {
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+	if days == 0 && after {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// previousWeekday returns the last day before today falling on wd.
+func previousWeekday(today time.Time, wd time.Weekday) time.Time {
+	days := (int(today.Weekday()) - int(wd) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, -days)
+}
+
+// parseOffsets reads amounts of units like "3 weeks 2 days" or "1h30m" and
+// applies them to t, subtracting them if sign is negative.
+func parseOffsets(fields []string, t time.Time, sign int) (time.Time, error) {
+	if len(fields) == 0 {
+		return t, fmt.Errorf("missing amount after the sign")
+	}
+	for i := 0; i < len(fields); i++ {
+		field := strings.ToLower(fields[i])
+		switch field {
+		case "+":
+			sign = 1
+			continue
+		case "-":
+			sign = -1
+			continue
+		case "and":
+			continue
+		}
+		// Amounts and units may be written apart ("3 weeks") or together ("3w", "1h30m").
+		if i+1 < len(fields) && isNumber(field) {
+			field += strings.ToLower(fields[i+1])
+			i++
+		}
+		for field != "" {
+			digits := len(field) - len(strings.TrimLeft(field, "0123456789"))
+			letters := len(field[digits:]) - len(strings.TrimLeft(field[digits:], "abcdefghijklmnopqrstuvwxyz"))
+			if digits == 0 || letters == 0 {
+				return t, fmt.Errorf("expected an amount like '3 days', got '%s'", fields[i])
+			}
+			n, err := strconv.Atoi(field[:digits])
+			if err != nil {
+				return t, err
+			}
+			u, ok := units[field[digits:digits+letters]]
+			if !ok {
+				return t, fmt.Errorf("unknown unit '%s'", field[digits:digits+letters])
+			}
+			t = u.add(t, sign*n)
+			field = field[digits+letters:]
+		}
+	}
+	return t, nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// dayDiff returns the number of calendar days from a to b.
+func dayDiff(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// monthDiff returns the number of whole months from a to b, a before b, and
+// the days left over.
+func monthDiff(a, b time.Time) (months, days int) {
+	months = (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+	if addMonths(a, months).After(b) {
+		months--
+	}
+	return months, dayDiff(addMonths(a, months), b)
+}
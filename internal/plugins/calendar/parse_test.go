@@ -0,0 +1,49 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonthArithmeticClampsDay(t *testing.T) {
+	now := time.Date(2026, time.January, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct{ query, want string }{
+		{"2026-01-31 + 1 month", "2026-02-28"},
+		{"2024-01-31 + 1 month", "2024-02-29"},
+		{"2026-03-31 - 1 month", "2026-02-28"},
+		{"2026-01-31 + 3 months", "2026-04-30"},
+		{"2026-01-31 + 2 months", "2026-03-31"},
+		{"2024-02-29 + 1 year", "2025-02-28"},
+		{"2026-01-31 + 1 month + 1 day", "2026-03-01"},
+	}
+	for _, tt := range tests {
+		m, err := evaluateMoment(strings.Fields(tt.query), now, false)
+		if err != nil {
+			t.Errorf("evaluateMoment(%q) error = %v", tt.query, err)
+			continue
+		}
+		if got := m.t.Format(time.DateOnly); got != tt.want {
+			t.Errorf("evaluateMoment(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMonthDiff(t *testing.T) {
+	tests := []struct {
+		a, b         string
+		months, days int
+	}{
+		{"2026-01-31", "2026-02-28", 1, 0},
+		{"2026-01-31", "2026-03-01", 1, 1},
+		{"2026-01-15", "2026-03-14", 1, 27},
+	}
+	for _, tt := range tests {
+		a, _ := time.Parse(time.DateOnly, tt.a)
+		b, _ := time.Parse(time.DateOnly, tt.b)
+		months, days := monthDiff(a, b)
+		if months != tt.months || days != tt.days {
+			t.Errorf("monthDiff(%s, %s) = %d months %d days, want %d months %d days", tt.a, tt.b, months, days, tt.months, tt.days)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package cron
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/elevate"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!cron"
+
+// cacheTTL bounds how often crontab and systemctl are queried while typing.
+const cacheTTL = 5 * time.Second
+
+const (
+	editIdentifier     = "cron_edit"
+	errorIdentifier    = "cron_error"
+	identifierSep      = ":"
+	timerIdentifierPre = "cron_timer"
+	jobIdentifierPre   = "cron_job" // Followed by the separator and the crontab line number.
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Scheduled Jobs",
+	Description: "List crontab entries and systemd timers with their next run times.",
+	Keyword:     Keyword,
+	Flag:        "cron",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// job is a crontab entry or systemd timer.
+type job struct {
+	title      string
+	detail     string
+	next       time.Time
+	identifier string
+}
+
+// systemdTimer mirrors the JSON output of 'systemctl list-timers -o json'.
+type systemdTimer struct {
+	Next      *int64 `json:"next"` // Microseconds since the epoch.
+	Last      *int64 `json:"last"`
+	Unit      string `json:"unit"`
+	Activates string `json:"activates"`
+}
+
+// editorFinishedMsg is sent once the crontab editor exits.
+type editorFinishedMsg struct {
+	err error
+}
+
+// timerStartedMsg is sent once the unit of a timer has been started, or failed to.
+type timerStartedMsg struct {
+	err error
+}
+
+// CronPlugin lists scheduled jobs and lets the user run timers or edit the crontab.
+type CronPlugin struct {
+	mu       sync.Mutex
+	jobs     []job
+	loadErrs []string
+	loadedAt time.Time
+	elevator *elevate.Elevator // Starts system timers as root.
+	err      error             // Guarded by mu.
+}
+
+// New creates a new instance of the CronPlugin, which starts the units of
+// system timers as root with elevator.
+func New(elevator *elevate.Elevator) *CronPlugin {
+	return &CronPlugin{elevator: elevator}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *CronPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *CronPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *CronPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *CronPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists crontab entries and timers matching the query, soonest first.
+func (p *CronPlugin) GetResults(query string) ([]plugin.Result, error) {
+	jobs, loadErrs := p.loadJobs()
+	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	now := time.Now()
+
+	results := []plugin.Result{}
+	for _, j := range jobs {
+		if lowerQuery != "" && !strings.Contains(strings.ToLower(j.title+" "+j.detail), lowerQuery) {
+			continue
+		}
+		results = append(results, plugin.Result{
+			Title:       j.title,
+			Description: fmt.Sprintf("%s | %s", describeNext(j.next, now), j.detail),
+			Identifier:  j.identifier,
+		})
+	}
+
+	results = append(results, plugin.Result{
+		Title:       "Edit crontab",
+		Description: "Open the user crontab in $EDITOR (crontab -e)",
+		Identifier:  editIdentifier,
+	})
+	for _, e := range loadErrs {
+		results = append(results, plugin.Result{Title: "Could not list jobs", Description: e, Identifier: errorIdentifier})
+	}
+	if err := p.GetError(); err != nil {
+		results = append(results, plugin.Result{Title: "Last action failed", Description: err.Error(), Identifier: errorIdentifier})
+	}
+	return results, nil
+}
+
+// loadJobs returns cached jobs, refreshing them once the cache has expired.
+func (p *CronPlugin) loadJobs() ([]job, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.loadedAt) < cacheTTL {
+		return p.jobs, p.loadErrs
+	}
+
+	now := time.Now()
+	var jobs []job
+	var loadErrs []string
+
+	cronJobs, err := loadCrontab(now)
+	if err != nil {
+		loadErrs = append(loadErrs, err.Error())
+	}
+	jobs = append(jobs, cronJobs...)
+
+	for _, userScope := range []bool{true, false} {
+		timers, err := loadTimers(userScope)
+		if err != nil {
+			loadErrs = append(loadErrs, err.Error())
+		}
+		jobs = append(jobs, timers...)
+	}
+
+	// Soonest first; jobs without a next run (e.g. @reboot) go last.
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].next.IsZero() != jobs[j].next.IsZero() {
+			return !jobs[i].next.IsZero()
+		}
+		return jobs[i].next.Before(jobs[j].next)
+	})
+
+	p.jobs, p.loadErrs, p.loadedAt = jobs, loadErrs, now
+	return jobs, loadErrs
+}
+
+// loadCrontab parses the output of 'crontab -l'.
+func loadCrontab(now time.Time) ([]job, error) {
+	if _, err := exec.LookPath("crontab"); err != nil {
+		return nil, nil // No cron daemon installed, nothing to list.
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("crontab", "-l")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "no crontab") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("crontab -l failed: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var jobs []job
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || isEnvAssignment(line) {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		scheduleFields, command := fields, ""
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields, command = fields[:1], strings.Join(fields[1:], " ")
+		} else if len(fields) > 5 {
+			scheduleFields, command = fields[:5], strings.Join(fields[5:], " ")
+		}
+
+		s, err := parseSchedule(scheduleFields)
+		if err != nil {
+			zap.L().Debug("Skipping unparsable crontab line.", zap.String("line", line), zap.Error(err))
+			continue
+		}
+
+		next := s.next(now)
+		schedule := strings.Join(scheduleFields, " ")
+		if s.reboot {
+			schedule = "@reboot"
+		}
+		jobs = append(jobs, job{
+			title:      command,
+			detail:     fmt.Sprintf("cron: %s", schedule),
+			next:       next,
+			identifier: jobIdentifierPre + identifierSep + strconv.Itoa(lineNumber),
+		})
+	}
+	return jobs, scanner.Err()
+}
+
+// isEnvAssignment reports whether a crontab line sets an environment variable.
+func isEnvAssignment(line string) bool {
+	name, _, found := strings.Cut(line, "=")
+	return found && !strings.ContainsAny(strings.TrimSpace(name), " \t*@")
+}
+
+// loadTimers lists systemd timers of the user or system manager.
+func loadTimers(userScope bool) ([]job, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return nil, nil
+	}
+
+	args := []string{"list-timers", "--all", "--output=json", "--no-pager"}
+	scope := "system"
+	if userScope {
+		args = append([]string{"--user"}, args...)
+		scope = "user"
+	}
+	out, err := exec.Command("systemctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl %s list-timers failed: %w", scope, err)
+	}
+
+	var timers []systemdTimer
+	if err := json.Unmarshal(out, &timers); err != nil {
+		return nil, fmt.Errorf("could not parse %s timers: %w", scope, err)
+	}
+
+	jobs := make([]job, 0, len(timers))
+	for _, t := range timers {
+		var next time.Time
+		if t.Next != nil && *t.Next > 0 {
+			next = time.UnixMicro(*t.Next)
+		}
+		lastRun := "never ran"
+		if t.Last != nil && *t.Last > 0 {
+			lastRun = "last " + time.UnixMicro(*t.Last).Format("Jan 2 15:04")
+		}
+		jobs = append(jobs, job{
+			title:      t.Unit,
+			detail:     fmt.Sprintf("%s timer → %s, %s | enter runs now", scope, t.Activates, lastRun),
+			next:       next,
+			identifier: strings.Join([]string{timerIdentifierPre, scope, t.Activates}, identifierSep),
+		})
+	}
+	return jobs, nil
+}
+
+// describeNext formats the next run time relative to now.
+func describeNext(next, now time.Time) string {
+	if next.IsZero() {
+		return "next: n/a"
+	}
+	left := next.Sub(now).Round(time.Minute)
+	if left < time.Minute {
+		return fmt.Sprintf("next: %s (now)", next.Format("Mon Jan 2 15:04"))
+	}
+	return fmt.Sprintf("next: %s (in %s)", next.Format("Mon Jan 2 15:04"), formatDuration(left))
+}
+
+// formatDuration renders a duration coarsely, e.g. "3d 4h" or "2h 15m".
+func formatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// Execute opens the crontab editor for crontab jobs and the edit row, or starts
+// the service activated by a timer.
+func (p *CronPlugin) Execute(identifier string) tea.Cmd {
+	switch {
+	case identifier == editIdentifier, strings.HasPrefix(identifier, jobIdentifierPre+identifierSep):
+		return tea.ExecProcess(exec.Command("crontab", "-e"), func(err error) tea.Msg {
+			return editorFinishedMsg{err: err}
+		})
+	case strings.HasPrefix(identifier, timerIdentifierPre+identifierSep):
+		parts := strings.SplitN(identifier, identifierSep, 3)
+		if len(parts) != 3 || parts[2] == "" {
+			return nil
+		}
+		return p.startTimer(parts[1] != "user", parts[2])
+	}
+	return nil // Do nothing for info/error items.
+}
+
+// startTimer returns a command starting unit, as root for system timers,
+// which may wait on a password prompt and so runs off the update loop.
+func (p *CronPlugin) startTimer(system bool, unit string) tea.Cmd {
+	return func() tea.Msg {
+		if system {
+			if err := p.elevator.Run("systemctl", "start", unit); err != nil {
+				zap.L().Error("Failed to start system timer unit.", zap.String("unit", unit), zap.Error(err))
+				return timerStartedMsg{err: fmt.Errorf("could not start %s: %w", unit, err)}
+			}
+			return timerStartedMsg{}
+		}
+		args := []string{"--user", "start", unit}
+		if out, err := exec.Command("systemctl", args...).CombinedOutput(); err != nil {
+			zap.L().Error("Failed to start timer unit.", zap.Strings("args", args), zap.Error(err))
+			return timerStartedMsg{err: fmt.Errorf("systemctl %s: %v %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))}
+		}
+		return timerStartedMsg{}
+	}
+}
+
+// Update handles the crontab editor exiting and timer units starting.
+func (p *CronPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	switch msg := msg.(type) {
+	case editorFinishedMsg:
+		if msg.err != nil {
+			p.setError(fmt.Errorf("crontab -e: %w", msg.err))
+			return p, nil
+		}
+		return p, tea.Quit
+	case timerStartedMsg:
+		if msg.err != nil {
+			p.setError(msg.err)
+			return p, nil
+		}
+		return p, tea.Quit
+	}
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *CronPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed action, if any.
+func (p *CronPlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *CronPlugin) setError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
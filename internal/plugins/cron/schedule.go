@@ -0,0 +1,167 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a parsed five-field cron expression.
+type schedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// Per crontab(5), when both day-of-month and day-of-week are restricted,
+	// a time matches if either field matches.
+	daysRestricted, weekdaysRestricted bool
+	reboot                             bool
+}
+
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseSchedule parses the schedule part of a crontab line.
+func parseSchedule(fields []string) (*schedule, error) {
+	if len(fields) == 1 {
+		if fields[0] == "@reboot" {
+			return &schedule{reboot: true}, nil
+		}
+		expanded, ok := scheduleMacros[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown schedule macro '%s'", fields[0])
+		}
+		fields = strings.Fields(expanded)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 schedule fields, got %d", len(fields))
+	}
+
+	var s schedule
+	var err error
+	if s.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if s.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if s.days, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if s.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if s.weekdays, err = parseField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if s.weekdays[7] { // Both 0 and 7 mean Sunday.
+		s.weekdays[0] = true
+	}
+	s.daysRestricted = fields[2] != "*"
+	s.weekdaysRestricted = fields[4] != "*"
+	return &s, nil
+}
+
+// parseField expands a comma-separated list of values, ranges and steps.
+func parseField(field string, minValue, maxValue int, names map[string]int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step '%s'", stepPart)
+			}
+		}
+
+		start, end := minValue, maxValue
+		if rangePart != "*" {
+			lo, hi, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(lo, names); err != nil {
+				return nil, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(hi, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				end = maxValue
+			}
+		}
+		if start < minValue || end > maxValue || start > end {
+			return nil, fmt.Errorf("value out of range in '%s'", part)
+		}
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func parseValue(value string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", value)
+	}
+	return n, nil
+}
+
+func (s *schedule) matchesDay(t time.Time) bool {
+	dayMatch := s.days[t.Day()]
+	weekdayMatch := s.weekdays[int(t.Weekday())]
+	if s.daysRestricted && s.weekdaysRestricted {
+		return dayMatch || weekdayMatch
+	}
+	return dayMatch && weekdayMatch
+}
+
+// next returns the first time strictly after 'from' matching the schedule,
+// or the zero time if none exists within the next five years.
+func (s *schedule) next(from time.Time) time.Time {
+	if s.reboot {
+		return time.Time{}
+	}
+
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	limit := from.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,154 @@
+package debuglog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/internal/logbuffer"
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!debug"
+
+const (
+	refreshInterval = 500 * time.Millisecond
+	maxEntries      = 200
+
+	infoIdentifier     = "debug_info"
+	copyIdentifierBase = "debug_copy_"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Debug Log",
+	Description: "Tail recent log entries, filtered by plugin, level or text.",
+	Keyword:     Keyword,
+	Flag:        "", // Registered in debug mode only.
+	IsMandatory: true,
+	IsDefault:   false,
+}
+
+// DebugLogPlugin lists the entries captured by a logbuffer.Buffer, newest first.
+type DebugLogPlugin struct {
+	buffer *logbuffer.Buffer
+
+	mu          sync.Mutex
+	copyTargets map[string]string // Maps result identifiers to the text copied on Execute.
+}
+
+// New creates a new instance of the DebugLogPlugin showing the entries of buffer.
+func New(buffer *logbuffer.Buffer) *DebugLogPlugin {
+	return &DebugLogPlugin{
+		buffer:      buffer,
+		copyTargets: make(map[string]string),
+	}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *DebugLogPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *DebugLogPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *DebugLogPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *DebugLogPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// RefreshInterval makes the application tail the log while the plugin is active.
+func (p *DebugLogPlugin) RefreshInterval() time.Duration {
+	return refreshInterval
+}
+
+// GetResults lists the newest log entries whose source, level, message or
+// fields contain every word of the query, e.g. "!debug grep warn".
+func (p *DebugLogPlugin) GetResults(query string) ([]plugin.Result, error) {
+	words := strings.Fields(strings.ToLower(query))
+	results := []plugin.Result{}
+	copyTargets := make(map[string]string)
+
+	for _, e := range p.buffer.Entries() {
+		if len(results) == maxEntries {
+			break
+		}
+		line := fmt.Sprintf("%s %s [%s] %s", e.Time.Format("15:04:05.000"), e.Level.CapitalString(), e.Source, e.Message)
+		if !containsAll(strings.ToLower(line+" "+e.Fields), words) {
+			continue
+		}
+
+		// Keyed on the entry, so the selection stays on it as new entries arrive.
+		identifier := fmt.Sprintf("%s%d", copyIdentifierBase, e.Seq)
+		copyTargets[identifier] = strings.TrimSpace(line + " " + e.Fields)
+		results = append(results, plugin.Result{
+			Title:       line,
+			Description: e.Fields,
+			Identifier:  identifier,
+		})
+	}
+
+	p.mu.Lock()
+	p.copyTargets = copyTargets
+	p.mu.Unlock()
+
+	if len(results) == 0 {
+		return []plugin.Result{{
+			Title:       "No log entries",
+			Description: "Entries logged by incipio and its plugins appear here as they happen.",
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+	return results, nil
+}
+
+// containsAll reports whether s contains every word.
+func containsAll(s string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(s, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute copies the selected entry to the clipboard and quits.
+func (p *DebugLogPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	text, ok := p.copyTargets[identifier]
+	p.mu.Unlock()
+	if !ok {
+		return nil // Do nothing for info items.
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		zap.L().Error("Failed to copy log entry to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *DebugLogPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *DebugLogPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as this plugin does not maintain an error state.
+func (p *DebugLogPlugin) GetError() error {
+	return nil
+}
@@ -0,0 +1,416 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/internal/desktop"
+	"github.com/barab-i/incipio/internal/index"
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!f"
+
+const (
+	indexName  = "files"
+	indexTable = "home"
+
+	// maxIndexedFiles bounds the live index so huge home directories stay fast to scan.
+	maxIndexedFiles = 200000
+	// maxResults is the number of merged results shown per query.
+	maxResults = 100
+
+	infoIdentifier  = "files_info"
+	errorIdentifier = "files_error"
+)
+
+// skippedDirs are never descended into while building the live index.
+// Hidden directories are skipped as well.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"__pycache__":  true,
+	"target":       true,
+	"venv":         true,
+}
+
+var metadata = plugin.Metadata{
+	Name:        "File Search",
+	Description: "Find files in your home directory, and system-wide via plocate/locate.",
+	Keyword:     Keyword,
+	Flag:        "files",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// FileSearchPlugin finds files using a live index of the home directory, merged
+// with results from the locate database when one is available.
+type FileSearchPlugin struct {
+	mu        sync.Mutex
+	store     *index.Store
+	root      string
+	indexing  bool
+	indexed   bool // The live index was rebuilt during this session.
+	indexErr  error
+	locate    locateBackend
+	locateErr error
+	chooser   desktop.Chooser
+	openErr   error // Of the last file opened.
+}
+
+// New creates a new instance of the FileSearchPlugin.
+func New() *FileSearchPlugin {
+	root, _ := os.UserHomeDir()
+	return &FileSearchPlugin{root: root}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *FileSearchPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *FileSearchPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *FileSearchPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *FileSearchPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// RefreshInterval re-runs the query while the live index is being rebuilt,
+// so that results fill in as soon as indexing finishes.
+func (p *FileSearchPlugin) RefreshInterval() time.Duration {
+	if p.isIndexing() {
+		return time.Second
+	}
+	return 0
+}
+
+// GetResults searches the live index and the locate database; see StreamResults.
+func (p *FileSearchPlugin) GetResults(query string) ([]plugin.Result, error) {
+	return p.GetResultsContext(context.Background(), query)
+}
+
+// GetResultsContext returns all results of StreamResults at once, stopping
+// locate once ctx is cancelled.
+func (p *FileSearchPlugin) GetResultsContext(ctx context.Context, query string) ([]plugin.Result, error) {
+	var results []plugin.Result
+	err := p.StreamResults(ctx, query, func(batch []plugin.Result) {
+		results = append(results, batch...)
+	})
+	return results, err
+}
+
+// StreamResults searches the live index and the locate database. Index matches
+// come first; locate adds files outside the home directory or not indexed
+// yet, streamed as it finds them since it may take a while on large
+// databases. Entries whose file no longer exists are marked as stale.
+func (p *FileSearchPlugin) StreamResults(ctx context.Context, query string, send func([]plugin.Result)) error {
+	if apps, ok := p.chooser.Results(query); ok {
+		send(apps)
+		return nil
+	}
+	p.ensureIndex()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		send([]plugin.Result{p.statusResult()})
+		return nil
+	}
+
+	found := newFoundFiles()
+	// A path typed out, e.g. "~/notes.md", comes first when it exists.
+	if path, ok := existingPath(query); ok {
+		found.add(path, "path")
+	}
+	if store := p.indexStore(); store != nil {
+		entries, err := store.Search(indexTable, query, maxResults)
+		if err != nil {
+			zap.L().Warn("File index search failed.", zap.Error(err))
+		}
+		for _, e := range entries {
+			found.add(e.Identifier, "index")
+		}
+	}
+	if batch := found.take(); len(batch) > 0 {
+		send(batch)
+	}
+
+	err := p.locate.stream(ctx, query, maxResults, func(paths []string) {
+		for _, path := range paths {
+			found.add(path, "locate")
+		}
+		if batch := found.take(); len(batch) > 0 {
+			send(batch)
+		}
+	})
+	if ctx.Err() != nil {
+		return ctx.Err() // Superseded; locate was stopped, not failing.
+	}
+	p.mu.Lock()
+	p.locateErr = err
+	p.mu.Unlock()
+
+	var last []plugin.Result
+	if found.count == 0 {
+		last = append(last, plugin.Result{
+			Title:       "No files found",
+			Description: fmt.Sprintf("Nothing matches '%s'", query),
+			Identifier:  infoIdentifier,
+		})
+	}
+	if status := p.statusResult(); status.Identifier == errorIdentifier || p.isIndexing() {
+		last = append(last, status)
+	}
+	if len(last) > 0 {
+		send(last)
+	}
+	return nil
+}
+
+// foundFiles collects the results of a query, each path once and up to
+// maxResults, for StreamResults to send in batches.
+type foundFiles struct {
+	seen  map[string]bool
+	count int
+	batch []plugin.Result // Added since the last take.
+}
+
+func newFoundFiles() *foundFiles {
+	return &foundFiles{seen: make(map[string]bool)}
+}
+
+// add adds the result for path unless it was found already, marking it as
+// stale if the file no longer exists.
+func (f *foundFiles) add(path, source string) {
+	if f.seen[path] || f.count >= maxResults {
+		return
+	}
+	f.seen[path] = true
+	f.count++
+
+	description := fmt.Sprintf("%s | %s", filepath.Dir(path), source)
+	if _, err := os.Lstat(path); err != nil {
+		description += " | stale, no longer exists"
+	}
+	f.batch = append(f.batch, plugin.Result{
+		Title:       filepath.Base(path),
+		Description: description,
+		Identifier:  path,
+	})
+}
+
+// take returns the results added since the last call.
+func (f *foundFiles) take() []plugin.Result {
+	batch := f.batch
+	f.batch = nil
+	return batch
+}
+
+// statusResult describes the state of the live index and the locate backend.
+func (p *FileSearchPlugin) statusResult() plugin.Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.openErr != nil:
+		return plugin.Result{Title: "Could not open file", Description: p.openErr.Error(), Identifier: errorIdentifier}
+	case p.indexErr != nil:
+		return plugin.Result{Title: "File index unavailable", Description: p.indexErr.Error(), Identifier: errorIdentifier}
+	case p.locateErr != nil:
+		return plugin.Result{Title: "locate failed", Description: p.locateErr.Error(), Identifier: errorIdentifier}
+	case p.indexing:
+		return plugin.Result{Title: "Indexing home directory...", Description: "Results may be incomplete until indexing finishes", Identifier: infoIdentifier}
+	}
+
+	backend := "live index only (install plocate for system-wide search)"
+	if p.locate.available() {
+		backend = "live index + " + filepath.Base(p.locate.command)
+	}
+	return plugin.Result{
+		Title:       "File Search",
+		Description: fmt.Sprintf("Type part of a file name | %s", backend),
+		Identifier:  infoIdentifier,
+	}
+}
+
+func (p *FileSearchPlugin) isIndexing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.indexing
+}
+
+func (p *FileSearchPlugin) indexStore() *index.Store {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.store
+}
+
+// ensureIndex opens the index store and rebuilds it in the background once per session.
+// The previous session's index is searched while the rebuild runs.
+func (p *FileSearchPlugin) ensureIndex() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.store == nil && p.indexErr == nil {
+		store, err := index.Open(indexName)
+		if err != nil {
+			p.indexErr = err
+			zap.L().Error("Failed to open file index.", zap.Error(err))
+			return
+		}
+		p.store = store
+	}
+	if p.store == nil || p.indexed || p.indexing || p.root == "" {
+		return
+	}
+
+	p.indexing = true
+	go p.rebuildIndex()
+}
+
+// rebuildIndex walks the home directory and replaces the live index.
+func (p *FileSearchPlugin) rebuildIndex() {
+	start := time.Now()
+	entries := make([]index.Entry, 0, 1024)
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries.
+		}
+		if d.IsDir() {
+			if path != p.root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		entries = append(entries, index.Entry{
+			Identifier:  path,
+			Title:       d.Name(),
+			Description: filepath.Dir(path),
+		})
+		if len(entries) >= maxIndexedFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err == nil {
+		err = p.store.Replace(indexTable, entries)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.indexing = false
+	p.indexed = true
+	if err != nil {
+		p.indexErr = fmt.Errorf("could not build file index: %w", err)
+		zap.L().Error("Failed to build file index.", zap.Error(err))
+		return
+	}
+	zap.L().Debug("Rebuilt file index.", zap.Int("files", len(entries)), zap.Duration("took", time.Since(start)))
+}
+
+// existingPath resolves a query that is a path, absolute or relative to the
+// home or working directory, to the absolute path of an existing file.
+func existingPath(query string) (string, bool) {
+	path := query
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		path = filepath.Join(home, rest)
+	} else if !filepath.IsAbs(path) && !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "../") {
+		return "", false
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// Execute opens the selected file with the default application, or with the
+// application chosen after the open with action, and quits.
+func (p *FileSearchPlugin) Execute(identifier string) tea.Cmd {
+	if handled, err := p.chooser.Execute(identifier); handled {
+		p.setOpenErr(err)
+		if err != nil {
+			zap.L().Error("Failed to open file with the chosen application.", zap.Error(err))
+			return nil
+		}
+		return tea.Quit
+	}
+	if identifier == infoIdentifier || identifier == errorIdentifier {
+		return nil // Do nothing for info/error items.
+	}
+	if _, err := os.Lstat(identifier); err != nil {
+		zap.L().Warn("Selected file no longer exists.", zap.String("path", identifier))
+		return nil
+	}
+	if err := launch.OpenURL(identifier); err != nil {
+		zap.L().Error("Failed to open file.", zap.String("path", identifier), zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Actions lists the actions on the selected file.
+func (p *FileSearchPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: desktop.OpenWithAction, Keys: []string{"alt+o"}},
+	}
+}
+
+// RunAction lists the applications able to open the selected file, to open
+// it with the one selected next.
+func (p *FileSearchPlugin) RunAction(name, identifier string) tea.Cmd {
+	if name != desktop.OpenWithAction || identifier == infoIdentifier || identifier == errorIdentifier {
+		return nil
+	}
+	err := p.chooser.Open(identifier, desktop.MimeType(identifier))
+	p.setOpenErr(err)
+	return plugin.Refresh()
+}
+
+func (p *FileSearchPlugin) setOpenErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.openErr = err
+}
+
+// Update handles messages.
+func (p *FileSearchPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *FileSearchPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as errors are reported through results.
+func (p *FileSearchPlugin) GetError() error {
+	return nil
+}
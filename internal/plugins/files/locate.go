@@ -0,0 +1,101 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// locateTimeout bounds a single locate query so a slow database never blocks typing.
+const locateTimeout = 2 * time.Second
+
+// locateCommands lists supported locate implementations in order of preference.
+var locateCommands = []string{"plocate", "locate"}
+
+// locateBackend queries a plocate/mlocate database. These are updated
+// periodically by updatedb, so results may point to files removed since.
+type locateBackend struct {
+	once    sync.Once
+	command string // Empty when no locate implementation is usable.
+}
+
+// available reports whether a locate command was found.
+func (l *locateBackend) available() bool {
+	l.once.Do(func() {
+		for _, c := range locateCommands {
+			if path, err := exec.LookPath(c); err == nil {
+				l.command = path
+				zap.L().Debug("Using locate backend for file search.", zap.String("command", path))
+				return
+			}
+		}
+	})
+	return l.command != ""
+}
+
+// locateBatchSize and locateBatchDelay bound how many paths, and for how long,
+// stream collects before passing them on.
+const (
+	locateBatchSize  = 20
+	locateBatchDelay = 100 * time.Millisecond
+)
+
+// stream passes the paths matching all query terms, case-insensitively, to
+// send in batches as locate prints them.
+func (l *locateBackend) stream(ctx context.Context, query string, limit int, send func([]string)) error {
+	if !l.available() {
+		return nil
+	}
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, locateTimeout)
+	defer cancel()
+
+	args := append([]string{"--ignore-case", "--all", "--limit", strconv.Itoa(limit), "--"}, terms...)
+	cmd := exec.CommandContext(ctx, l.command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", l.command, err)
+	}
+
+	var batch []string
+	sent := time.Now()
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			batch = append(batch, line)
+		}
+		if len(batch) >= locateBatchSize || (len(batch) > 0 && time.Since(sent) >= locateBatchDelay) {
+			send(batch)
+			batch, sent = nil, time.Now()
+		}
+	}
+	if len(batch) > 0 {
+		send(batch)
+	}
+	scanErr := scanner.Err()
+
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return nil // No matches.
+		}
+		return fmt.Errorf("%s: %v %s", l.command, err, strings.TrimSpace(stderr.String()))
+	}
+	return scanErr
+}
@@ -0,0 +1,444 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const Keyword = "!fmt"
+
+const (
+	copyIdentifier  = "fmt_copy:" // Followed by the query, formatted again on Execute.
+	infoIdentifier  = "fmt_info"
+	errorIdentifier = "fmt_error"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Formatter",
+	Description: "Pretty-print, minify or convert JSON, YAML and TOML from the clipboard or input.",
+	Keyword:     Keyword,
+	Flag:        "fmt",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// format identifies a supported data format.
+type format string
+
+const (
+	formatJSON format = "JSON"
+	formatYAML format = "YAML"
+	formatTOML format = "TOML"
+)
+
+// mode selects what to do with the detected document.
+type mode string
+
+const (
+	modePretty mode = "pretty"
+	modeMinify mode = "min"
+	modeToJSON mode = "json"
+	modeToYAML mode = "yaml"
+	modeToTOML mode = "toml"
+)
+
+var viewportKeys = viewport.KeyMap{
+	PageDown:     key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdn", "page down")),
+	PageUp:       key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+	HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "½ page up")),
+	HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "½ page down")),
+}
+
+// FormatterPlugin detects and reformats structured text.
+type FormatterPlugin struct {
+	// mu guards the output and the view, as GetResults runs concurrently
+	// with the other methods.
+	mu          sync.Mutex
+	output      string // Formatted output of the last query, previewed in View.
+	outputLabel string // Describes the output, e.g. "JSON → YAML".
+	viewport    viewport.Model
+	viewWidth   int
+	viewHeight  int
+	ready       bool
+
+	headerStyle lipgloss.Style
+	footerStyle lipgloss.Style
+}
+
+// New creates a new instance of the FormatterPlugin.
+func New() *FormatterPlugin {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewportKeys
+	p := &FormatterPlugin{
+		viewport: vp,
+	}
+	p.SetTheme(theme.DefaultTheme)
+	return p
+}
+
+// SetTheme styles the plugin's view with the given theme.
+func (p *FormatterPlugin) SetTheme(t theme.Theme) {
+	p.headerStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+	p.footerStyle = lipgloss.NewStyle().Foreground(t.Muted)
+}
+
+// Metadata returns the plugin's metadata.
+func (p *FormatterPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *FormatterPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *FormatterPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *FormatterPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults formats the typed content, or the clipboard if nothing was typed.
+// The query may start with a mode: min, json, yaml or toml.
+func (p *FormatterPlugin) GetResults(query string) ([]plugin.Result, error) {
+	p.setOutput("", "")
+
+	f, failed := formatQuery(query)
+	if failed != nil {
+		return []plugin.Result{*failed}, nil
+	}
+	p.setOutput(f.output, f.label)
+
+	return []plugin.Result{{
+		Title:       fmt.Sprintf("Copy %s", f.label),
+		Description: fmt.Sprintf("Formatted from %s, %d lines", f.source, strings.Count(f.output, "\n")+1),
+		Identifier:  copyIdentifier + query,
+	}}, nil
+}
+
+// formatted is the output of formatting a query.
+type formatted struct {
+	output string
+	label  string // Describes the output, e.g. "JSON → YAML".
+	source string // "input" or "clipboard".
+}
+
+// formatQuery formats the content of query, or the clipboard if it has none.
+// When there is nothing to format, it returns the info or error result to
+// show instead.
+func formatQuery(query string) (formatted, *plugin.Result) {
+	m, content := parseQuery(query)
+	source := "input"
+	if content == "" {
+		clip, err := clipboard.ReadAll()
+		if err != nil {
+			return formatted{}, &plugin.Result{
+				Title:       "Could not read clipboard",
+				Description: err.Error(),
+				Identifier:  errorIdentifier,
+			}
+		}
+		content = strings.TrimSpace(clip)
+		source = "clipboard"
+	}
+	if content == "" {
+		return formatted{}, &plugin.Result{
+			Title:       "Formatter",
+			Description: "Copy or type JSON/YAML/TOML (e.g., !fmt min {\"a\": 1}); modes: min, json, yaml, toml",
+			Identifier:  infoIdentifier,
+		}
+	}
+
+	detected, value, err := detect(content)
+	if err != nil {
+		return formatted{}, &plugin.Result{
+			Title:       fmt.Sprintf("Unrecognised %s content", source),
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}
+	}
+
+	output, target, err := render(content, detected, value, m)
+	if err != nil {
+		return formatted{}, &plugin.Result{
+			Title:       fmt.Sprintf("Cannot %s %s", describeMode(m, target), detected),
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}
+	}
+
+	label := fmt.Sprintf("%s (%s)", detected, m)
+	if target != detected {
+		label = fmt.Sprintf("%s → %s", detected, target)
+	}
+	return formatted{output: output, label: label, source: source}, nil
+}
+
+// parseQuery splits an optional leading mode from the content.
+func parseQuery(query string) (mode, string) {
+	query = strings.TrimSpace(query)
+	first, rest, _ := strings.Cut(query, " ")
+	switch m := mode(strings.ToLower(first)); m {
+	case modeMinify, modeToJSON, modeToYAML, modeToTOML, modePretty:
+		return m, strings.TrimSpace(rest)
+	}
+	return modePretty, query
+}
+
+func describeMode(m mode, target format) string {
+	switch m {
+	case modeMinify:
+		return "minify"
+	case modeToJSON, modeToYAML, modeToTOML:
+		return fmt.Sprintf("convert to %s", target)
+	}
+	return "format"
+}
+
+// detect identifies the format of content and returns its decoded value.
+// YAML is only accepted for mappings and sequences since any plain text is a valid YAML scalar.
+func detect(content string) (format, any, error) {
+	var value any
+	if json.Valid([]byte(content)) {
+		if err := json.Unmarshal([]byte(content), &value); err != nil {
+			return "", nil, err
+		}
+		return formatJSON, normalizeNumbers(value), nil
+	}
+
+	if err := yaml.Unmarshal([]byte(content), &value); err == nil {
+		switch value.(type) {
+		case map[string]any, []any:
+			return formatYAML, value, nil
+		}
+	}
+
+	var table map[string]any
+	if _, err := toml.Decode(content, &table); err == nil && len(table) > 0 {
+		return formatTOML, table, nil
+	}
+
+	return "", nil, errors.New("content is not valid JSON, YAML or TOML")
+}
+
+// normalizeNumbers turns whole JSON numbers into integers so they are not
+// rendered as floats (e.g. "2.0") when converting to YAML or TOML.
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case map[string]any:
+		for k, child := range v {
+			v[k] = normalizeNumbers(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = normalizeNumbers(child)
+		}
+	}
+	return value
+}
+
+// render produces the output for the given mode along with its format.
+func render(content string, detected format, value any, m mode) (string, format, error) {
+	switch m {
+	case modeToJSON:
+		return encodeJSON(value)
+	case modeToYAML:
+		return encodeYAML(value)
+	case modeToTOML:
+		return encodeTOML(value)
+	case modeMinify:
+		switch detected {
+		case formatJSON:
+			var buf bytes.Buffer
+			err := json.Compact(&buf, []byte(content))
+			return buf.String(), formatJSON, err
+		case formatYAML:
+			return minifyYAML(content)
+		default:
+			return "", detected, errors.New("TOML has no compact form")
+		}
+	default:
+		switch detected {
+		case formatJSON:
+			// Indent the original text so key order is preserved.
+			var buf bytes.Buffer
+			err := json.Indent(&buf, []byte(content), "", "  ")
+			return buf.String(), formatJSON, err
+		case formatYAML:
+			return prettyYAML(content)
+		default:
+			return encodeTOML(value)
+		}
+	}
+}
+
+func encodeJSON(value any) (string, format, error) {
+	out, err := json.MarshalIndent(value, "", "  ")
+	return string(out), formatJSON, err
+}
+
+func encodeYAML(value any) (string, format, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(value); err != nil {
+		return "", formatYAML, err
+	}
+	return strings.TrimRight(buf.String(), "\n"), formatYAML, enc.Close()
+}
+
+func encodeTOML(value any) (string, format, error) {
+	if _, ok := value.(map[string]any); !ok {
+		return "", formatTOML, errors.New("TOML documents must be tables at the top level")
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(value); err != nil {
+		return "", formatTOML, err
+	}
+	return strings.TrimRight(buf.String(), "\n"), formatTOML, nil
+}
+
+// prettyYAML re-indents YAML through its node tree, keeping key order and comments.
+func prettyYAML(content string) (string, format, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		return "", formatYAML, err
+	}
+	return encodeYAML(&node)
+}
+
+// minifyYAML renders YAML in single-line flow style.
+func minifyYAML(content string) (string, format, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		return "", formatYAML, err
+	}
+	setFlowStyle(&node)
+	return encodeYAML(&node)
+}
+
+func setFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+	node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
+
+// setOutput stores the formatted output and refreshes the preview.
+func (p *FormatterPlugin) setOutput(output, label string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.output = output
+	p.outputLabel = label
+	if p.ready {
+		p.viewport.SetContent(output)
+		p.viewport.GotoTop()
+	}
+}
+
+// Execute copies the output formatted from the query of the selected result
+// to the clipboard and quits. The output is formatted again, as the last query
+// may not be the selected result's.
+func (p *FormatterPlugin) Execute(identifier string) tea.Cmd {
+	query, ok := strings.CutPrefix(identifier, copyIdentifier)
+	if !ok {
+		return nil // Do nothing for info/error items.
+	}
+	f, failed := formatQuery(query)
+	if failed != nil || f.output == "" {
+		return nil
+	}
+	if err := clipboard.WriteAll(f.output); err != nil {
+		zap.L().Error("Failed to copy formatted output to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Help lists the keys scrolling the formatted output while it is shown.
+func (p *FormatterPlugin) Help() []key.Binding {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.output == "" {
+		return nil
+	}
+	return []key.Binding{viewportKeys.PageUp, viewportKeys.PageDown, viewportKeys.HalfPageUp, viewportKeys.HalfPageDown}
+}
+
+// Update handles window sizing and viewport scrolling.
+func (p *FormatterPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Constants for main app layout estimation, matching the app's padding and input line.
+		const mainAppHorizontalPadding = 4
+		const mainAppVerticalPadding = 2
+		const textInputHeight = 1
+
+		p.viewWidth = msg.Width - mainAppHorizontalPadding
+		p.viewHeight = max(1, msg.Height-textInputHeight-mainAppVerticalPadding)
+		p.viewport.Width = max(1, p.viewWidth)
+		p.viewport.Height = max(1, p.viewHeight-lipgloss.Height(p.headerView())-lipgloss.Height(p.footerView()))
+		p.ready = true
+		p.viewport.SetContent(p.output)
+		return p, nil
+	}
+
+	if !p.ready || p.output == "" {
+		return p, nil
+	}
+	var cmd tea.Cmd
+	p.viewport, cmd = p.viewport.Update(msg)
+	return p, cmd
+}
+
+func (p *FormatterPlugin) headerView() string {
+	return p.headerStyle.Render(p.outputLabel)
+}
+
+func (p *FormatterPlugin) footerView() string {
+	return p.footerStyle.Render(fmt.Sprintf("enter: copy · pgup/pgdn: scroll · %3.f%%", p.viewport.ScrollPercent()*100))
+}
+
+// View renders a preview of the formatted output. It is empty when there is
+// nothing to preview, so info and error results are shown in the main list.
+func (p *FormatterPlugin) View() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.ready || p.output == "" {
+		return ""
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, p.headerView(), p.viewport.View(), p.footerView())
+}
+
+// GetError returns nil as errors are reported through results.
+func (p *FormatterPlugin) GetError() error {
+	return nil
+}
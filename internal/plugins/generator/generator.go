@@ -0,0 +1,294 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!gen"
+
+const (
+	defaultCount = 5
+	maxCount     = 50
+
+	infoIdentifier  = "gen_info"
+	errorIdentifier = "gen_error"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Generator",
+	Description: "Generate fake names, emails, addresses and JSON for quick test data.",
+	Keyword:     Keyword,
+	Flag:        "gen",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// generatorFunc produces a single random value.
+type generatorFunc func() string
+
+type kind struct {
+	name        string
+	description string
+	generate    generatorFunc
+}
+
+// kinds lists the top-level generators in display order.
+var kinds = []kind{
+	{"name", "Random full name", randomName},
+	{"email", "Random email address (reserved example domains)", randomEmail},
+	{"word", "Random adjective-noun pair", randomWord},
+	{"ipv4", "Random IPv4 address", randomIPv4},
+	{"ipv6", "Random IPv6 address", randomIPv6},
+	{"mac", "Random locally administered MAC address", randomMAC},
+	{"json", "Random JSON object (e.g., !gen json id:int name:name tags:[word])", nil},
+}
+
+// fieldGenerators produce JSON values for 'key:type' field specifications.
+var fieldGenerators = map[string]func() any{
+	"name":  func() any { return randomName() },
+	"first": func() any { return pick(firstNames) },
+	"last":  func() any { return pick(lastNames) },
+	"email": func() any { return randomEmail() },
+	"word":  func() any { return randomWord() },
+	"ipv4":  func() any { return randomIPv4() },
+	"ipv6":  func() any { return randomIPv6() },
+	"mac":   func() any { return randomMAC() },
+	"int":   func() any { return rand.IntN(10000) },
+	"float": func() any { return float64(rand.IntN(100000)) / 100 },
+	"bool":  func() any { return rand.IntN(2) == 1 },
+}
+
+var defaultJSONShape = []string{"id:int", "name:name", "email:email", "active:bool"}
+
+// GeneratorPlugin implements the plugin.Plugin interface for fake data generation.
+type GeneratorPlugin struct{}
+
+// New creates a new instance of the GeneratorPlugin.
+func New() *GeneratorPlugin {
+	return &GeneratorPlugin{}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *GeneratorPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *GeneratorPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *GeneratorPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *GeneratorPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults generates values for the requested kind.
+// An empty query shows one sample of every kind.
+func (p *GeneratorPlugin) GetResults(query string) ([]plugin.Result, error) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		results := make([]plugin.Result, 0, len(kinds)+1)
+		for _, k := range kinds {
+			value := generate(k, defaultJSONShape)
+			results = append(results, plugin.Result{
+				Title:       value,
+				Description: fmt.Sprintf("%s | !gen %s [count]", k.description, k.name),
+				Identifier:  value,
+			})
+		}
+		results = append(results, plugin.Result{
+			Title:       "Generator",
+			Description: "Usage: !gen <name|email|word|ipv4|ipv6|mac|json> [count] — enter copies the value",
+			Identifier:  infoIdentifier,
+		})
+		return results, nil
+	}
+
+	k, ok := findKind(strings.ToLower(fields[0]))
+	if !ok {
+		return errorResult(fmt.Sprintf("Unknown generator '%s'", fields[0]), "Available: name, email, word, ipv4, ipv6, mac, json"), nil
+	}
+
+	count := defaultCount
+	var shape []string
+	for _, arg := range fields[1:] {
+		if n, err := strconv.Atoi(arg); err == nil {
+			count = min(max(n, 1), maxCount)
+			continue
+		}
+		if k.name != "json" {
+			return errorResult(fmt.Sprintf("Unexpected argument '%s'", arg), fmt.Sprintf("Usage: !gen %s [count]", k.name)), nil
+		}
+		key, typ, _ := strings.Cut(arg, ":")
+		shape = append(shape, key+":"+strings.ToLower(typ)) // Keys keep their case.
+	}
+	if len(shape) == 0 {
+		shape = defaultJSONShape
+	}
+	if k.name == "json" {
+		if err := validateShape(shape); err != nil {
+			return errorResult(err.Error(), "Field types: name, first, last, email, word, ipv4, ipv6, mac, int, float, bool, [type]"), nil
+		}
+	}
+
+	results := make([]plugin.Result, count)
+	for i := range results {
+		value := generate(k, shape)
+		results[i] = plugin.Result{
+			Title:       value,
+			Description: k.description,
+			Identifier:  value,
+		}
+	}
+	return results, nil
+}
+
+func findKind(name string) (kind, bool) {
+	for _, k := range kinds {
+		if k.name == name {
+			return k, true
+		}
+	}
+	return kind{}, false
+}
+
+func errorResult(title, description string) []plugin.Result {
+	return []plugin.Result{{Title: title, Description: description, Identifier: errorIdentifier}}
+}
+
+func generate(k kind, shape []string) string {
+	if k.generate != nil {
+		return k.generate()
+	}
+	return randomJSON(shape)
+}
+
+// validateShape checks 'key:type' field specifications, where type may be wrapped in [] for arrays.
+func validateShape(shape []string) error {
+	for _, spec := range shape {
+		key, typ, ok := strings.Cut(spec, ":")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid field '%s', expected key:type", spec)
+		}
+		typ = strings.TrimSuffix(strings.TrimPrefix(typ, "["), "]")
+		if _, known := fieldGenerators[typ]; !known {
+			return fmt.Errorf("unknown field type '%s' in '%s'", typ, spec)
+		}
+	}
+	return nil
+}
+
+// randomJSON builds a compact JSON object preserving the field order of the shape.
+func randomJSON(shape []string) string {
+	parts := make([]string, 0, len(shape))
+	for _, spec := range shape {
+		key, typ, _ := strings.Cut(spec, ":")
+
+		var value any
+		if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+			gen := fieldGenerators[typ[1:len(typ)-1]]
+			values := make([]any, 1+rand.IntN(3))
+			for i := range values {
+				values[i] = gen()
+			}
+			value = values
+		} else {
+			value = fieldGenerators[typ]()
+		}
+
+		keyJSON, _ := json.Marshal(key)
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			zap.L().Debug("Failed to marshal generated JSON value.", zap.String("field", spec), zap.Error(err))
+			continue
+		}
+		parts = append(parts, string(keyJSON)+":"+string(valueJSON))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func pick(words []string) string {
+	return words[rand.IntN(len(words))]
+}
+
+func randomName() string {
+	return pick(firstNames) + " " + pick(lastNames)
+}
+
+func randomEmail() string {
+	return fmt.Sprintf("%s.%s@%s", strings.ToLower(pick(firstNames)), strings.ToLower(pick(lastNames)), pick(emailDomains))
+}
+
+func randomWord() string {
+	return pick(adjectives) + "-" + pick(nouns)
+}
+
+func randomIPv4() string {
+	// Avoid 0.x, loopback and multicast/reserved ranges for realistic-looking addresses.
+	first := 1 + rand.IntN(223)
+	if first == 127 {
+		first = 128
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", first, rand.IntN(256), rand.IntN(256), 1+rand.IntN(254))
+}
+
+func randomIPv6() string {
+	var addr [16]byte
+	for i := range addr {
+		addr[i] = byte(rand.IntN(256))
+	}
+	addr[0] = 0x20 | (addr[0] & 0x1f) // Global unicast range 2000::/3.
+	return netip.AddrFrom16(addr).String()
+}
+
+func randomMAC() string {
+	var mac [6]byte
+	for i := range mac {
+		mac[i] = byte(rand.IntN(256))
+	}
+	mac[0] = (mac[0] | 0x02) & 0xfe // Locally administered, unicast.
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", mac[0], mac[1], mac[2], mac[3], mac[4], mac[5])
+}
+
+// Execute copies the generated value to the clipboard and quits.
+func (p *GeneratorPlugin) Execute(identifier string) tea.Cmd {
+	if identifier == infoIdentifier || identifier == errorIdentifier {
+		return nil // Do nothing for info/error items.
+	}
+	if err := clipboard.WriteAll(identifier); err != nil {
+		zap.L().Error("Failed to copy generated value to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *GeneratorPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *GeneratorPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as this plugin does not maintain an error state.
+func (p *GeneratorPlugin) GetError() error {
+	return nil
+}
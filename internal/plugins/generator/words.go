@@ -0,0 +1,33 @@
+package generator
+
+// Word lists used by the generators. They are intentionally small, curated and
+// free of offensive terms so generated data is safe to paste into demos and tickets.
+
+var firstNames = []string{
+	"Ada", "Alan", "Alice", "Amara", "Ben", "Carla", "Chen", "Dana", "Diego", "Elena",
+	"Emil", "Fatima", "Felix", "Grace", "Hana", "Hugo", "Ines", "Ivan", "Jonas", "Julia",
+	"Kai", "Kenji", "Lena", "Liam", "Lucia", "Maya", "Mateo", "Nadia", "Noah", "Olga",
+	"Omar", "Paula", "Priya", "Quinn", "Rosa", "Sam", "Sofia", "Tariq", "Tessa", "Uma",
+	"Victor", "Wen", "Xavier", "Yara", "Yusuf", "Zoe",
+}
+
+var lastNames = []string{
+	"Abe", "Becker", "Costa", "Dubois", "Eriksen", "Fischer", "Garcia", "Hansen", "Ito", "Jensen",
+	"Kowalski", "Lopez", "Moreau", "Nakamura", "Novak", "Okafor", "Petrov", "Quispe", "Rossi", "Santos",
+	"Schmidt", "Silva", "Tanaka", "Umarov", "Varga", "Weber", "Xu", "Yilmaz", "Zhang", "Zimmermann",
+}
+
+var adjectives = []string{
+	"amber", "brave", "calm", "clever", "cosy", "crisp", "eager", "fancy", "gentle", "golden",
+	"happy", "humble", "jolly", "kind", "lively", "lucky", "mellow", "misty", "nimble", "proud",
+	"quiet", "rapid", "shiny", "silent", "snowy", "sunny", "swift", "tidy", "vivid", "witty",
+}
+
+var nouns = []string{
+	"anchor", "badger", "beacon", "cactus", "canyon", "comet", "falcon", "forest", "galaxy", "harbor",
+	"island", "lantern", "maple", "meadow", "nebula", "otter", "panda", "pebble", "pine", "quartz",
+	"river", "rocket", "sparrow", "summit", "thunder", "tulip", "valley", "walrus", "willow", "zephyr",
+}
+
+// Reserved example domains (RFC 2606) so generated emails never reach real people.
+var emailDomains = []string{"example.com", "example.org", "example.net"}
@@ -0,0 +1,357 @@
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/execute"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!grep"
+
+const (
+	// maxMatches stops rg once this many matching lines were collected.
+	maxMatches = 500
+	// refreshInterval is how often new matches are pulled into the list while rg runs.
+	refreshInterval = 150 * time.Millisecond
+
+	infoIdentifier  = "grep_info"
+	errorIdentifier = "grep_error"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Content Search",
+	Description: "Search file contents with ripgrep and open matches in $EDITOR.",
+	Keyword:     Keyword,
+	Flag:        "grep",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// match is a single matching line reported by rg.
+type match struct {
+	path    string
+	line    int
+	snippet string
+}
+
+// search is one rg invocation. Matches are appended while rg runs.
+type search struct {
+	query   string
+	cancel  context.CancelFunc
+	matches []match
+	running bool
+	err     error
+}
+
+// editorFinishedMsg is sent once the editor opened by Execute exits.
+type editorFinishedMsg struct {
+	err error
+}
+
+// GrepPlugin implements the plugin.Plugin interface for ripgrep content search.
+type GrepPlugin struct {
+	mu        sync.Mutex
+	directory string
+	extraArgs []string
+	current   *search
+	err       error
+}
+
+// New creates a new instance of the GrepPlugin searching directory, or the
+// home directory when empty. extraArgs are passed to every rg invocation.
+func New(directory string, extraArgs []string) *GrepPlugin {
+	if directory == "" {
+		directory, _ = os.UserHomeDir()
+	} else if rest, ok := strings.CutPrefix(directory, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			directory = filepath.Join(home, rest)
+		}
+	}
+	return &GrepPlugin{directory: directory, extraArgs: extraArgs}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *GrepPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *GrepPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *GrepPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *GrepPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// RefreshInterval pulls in new matches while rg is still running.
+func (p *GrepPlugin) RefreshInterval() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current != nil && p.current.running {
+		return refreshInterval
+	}
+	return 0
+}
+
+// GetResults starts rg for a new query, cancelling the previous search, and
+// returns the matches collected so far. Refreshes with the same query return
+// the growing list of matches without restarting rg.
+func (p *GrepPlugin) GetResults(query string) ([]plugin.Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		p.cancelSearch()
+		return []plugin.Result{{
+			Title:       "Content Search",
+			Description: fmt.Sprintf("Type a pattern to search files in %s", p.directory),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+	if _, err := exec.LookPath("rg"); err != nil {
+		return []plugin.Result{{
+			Title:       "ripgrep not found",
+			Description: "Install ripgrep (rg) to search file contents",
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.current == nil || p.current.query != query {
+		p.startSearchLocked(query)
+	}
+	s := p.current
+
+	results := make([]plugin.Result, 0, len(s.matches)+1)
+	for _, m := range s.matches {
+		rel, err := filepath.Rel(p.directory, m.path)
+		if err != nil {
+			rel = m.path
+		}
+		results = append(results, plugin.Result{
+			Title:       m.snippet,
+			Description: fmt.Sprintf("%s:%d", rel, m.line),
+			Identifier:  strconv.Itoa(m.line) + ":" + m.path,
+		})
+	}
+
+	switch {
+	case s.err != nil:
+		results = append(results, plugin.Result{Title: "Search failed", Description: s.err.Error(), Identifier: errorIdentifier})
+	case s.running:
+		results = append(results, plugin.Result{
+			Title:       fmt.Sprintf("Searching... %d matches so far", len(s.matches)),
+			Description: p.directory,
+			Identifier:  infoIdentifier,
+		})
+	case len(s.matches) == 0:
+		results = append(results, plugin.Result{Title: "No matches", Description: p.directory, Identifier: infoIdentifier})
+	case len(s.matches) >= maxMatches:
+		results = append(results, plugin.Result{
+			Title:       fmt.Sprintf("Showing the first %d matches", maxMatches),
+			Description: "Refine the pattern to narrow the search",
+			Identifier:  infoIdentifier,
+		})
+	}
+	if p.err != nil {
+		results = append(results, plugin.Result{Title: "Could not open editor", Description: p.err.Error(), Identifier: errorIdentifier})
+	}
+	return results, nil
+}
+
+// startSearchLocked cancels the running search and starts rg for query. p.mu must be held.
+func (p *GrepPlugin) startSearchLocked(query string) {
+	if p.current != nil {
+		p.current.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &search{query: query, cancel: cancel, running: true}
+	p.current = s
+
+	args := []string{
+		"--line-number", "--no-heading", "--null", "--color=never", "--smart-case",
+		"--max-columns=200", "--max-columns-preview",
+	}
+	args = append(args, p.extraArgs...)
+	args = append(args, "--", query, p.directory)
+	cmd := exec.CommandContext(ctx, "rg", args...)
+
+	go p.collect(ctx, s, cmd)
+}
+
+// collect reads rg's output line by line into s until rg exits, the search is
+// cancelled, or maxMatches is reached.
+func (p *GrepPlugin) collect(ctx context.Context, s *search, cmd *exec.Cmd) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		p.finish(s, err)
+		return
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		m, ok := parseLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		p.mu.Lock()
+		s.matches = append(s.matches, m)
+		full := len(s.matches) >= maxMatches
+		p.mu.Unlock()
+		if full {
+			s.cancel()
+			break
+		}
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		err = nil // Cancelled by a newer query or the match limit.
+	} else if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		err = nil // rg exits with 1 when nothing matched.
+	} else if err != nil {
+		err = fmt.Errorf("rg: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	p.finish(s, err)
+}
+
+func (p *GrepPlugin) finish(s *search, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.running = false
+	s.err = err
+	if err != nil {
+		zap.L().Warn("ripgrep search failed.", zap.String("query", s.query), zap.Error(err))
+	}
+}
+
+func (p *GrepPlugin) cancelSearch() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current != nil {
+		p.current.cancel()
+		p.current = nil
+	}
+}
+
+// parseLine parses "path\x00line:snippet" as printed by rg --null --line-number.
+func parseLine(line string) (match, bool) {
+	path, rest, ok := strings.Cut(line, "\x00")
+	if !ok {
+		return match{}, false
+	}
+	lineNumber, snippet, ok := strings.Cut(rest, ":")
+	if !ok {
+		return match{}, false
+	}
+	n, err := strconv.Atoi(lineNumber)
+	if err != nil {
+		return match{}, false
+	}
+	return match{path: path, line: n, snippet: strings.TrimSpace(strings.ReplaceAll(snippet, "\t", " "))}, true
+}
+
+// editorCommand builds the command opening path at line for the given editor.
+func editorCommand(editor, path string, line int) (*exec.Cmd, error) {
+	fields, err := execute.Split(editor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor command %q: %w", editor, err)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("empty editor command")
+	}
+	args := fields[1:]
+	switch filepath.Base(fields[0]) {
+	case "code", "codium", "code-insiders":
+		args = append(args, "--goto", fmt.Sprintf("%s:%d", path, line))
+	case "hx", "helix", "kak", "subl", "zed":
+		args = append(args, fmt.Sprintf("%s:%d", path, line))
+	default: // vi, vim, nvim, nano, emacs, micro and most others accept +LINE.
+		args = append(args, fmt.Sprintf("+%d", line), path)
+	}
+	return exec.Command(fields[0], args...), nil
+}
+
+// Execute opens $EDITOR (or $VISUAL) at the selected match and quits once it exits.
+func (p *GrepPlugin) Execute(identifier string) tea.Cmd {
+	lineNumber, path, ok := strings.Cut(identifier, ":")
+	line, err := strconv.Atoi(lineNumber)
+	if !ok || err != nil {
+		return nil // Do nothing for info/error items.
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd, err := editorCommand(editor, path, line)
+	if err != nil {
+		p.mu.Lock()
+		p.err = err
+		p.mu.Unlock()
+		zap.L().Error("Failed to open editor.", zap.Error(err))
+		return nil
+	}
+	p.cancelSearch()
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{err: err}
+	})
+}
+
+// Update handles the editor exiting.
+func (p *GrepPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	if msg, ok := msg.(editorFinishedMsg); ok {
+		if msg.err != nil {
+			p.mu.Lock()
+			p.err = msg.err
+			p.mu.Unlock()
+			zap.L().Error("Editor exited with an error.", zap.Error(msg.err))
+			return p, nil
+		}
+		return p, tea.Quit
+	}
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *GrepPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last editor invocation, if any.
+func (p *GrepPlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
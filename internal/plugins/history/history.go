@@ -0,0 +1,164 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/internal/app"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = app.HistoryKeyword
+
+var metadata = plugin.Metadata{
+	Name:        "History",
+	Description: "Search and re-run past selections.",
+	Keyword:     Keyword,
+	Flag:        "", // Mandatory plugins don't need a command-line flag.
+	IsMandatory: true,
+	IsDefault:   false,
+}
+
+// identifierSeparator joins the plugin keyword and the original identifier of an entry.
+const identifierSeparator = "\x00"
+
+// HistoryPlugin lists the selections recorded in the application's history.
+type HistoryPlugin struct {
+	mainPluginManager *app.PluginManager // Reference to the main application's plugin manager.
+}
+
+// New creates a new instance of the HistoryPlugin.
+// It requires the main PluginManager to read the history and re-run selections.
+func New(mainPM *app.PluginManager) *HistoryPlugin {
+	if mainPM == nil {
+		panic("HistoryPlugin requires a non-nil main PluginManager")
+	}
+	return &HistoryPlugin{
+		mainPluginManager: mainPM,
+	}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *HistoryPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *HistoryPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *HistoryPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init initializes the plugin.
+func (p *HistoryPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists past selections, most recent first, whose title,
+// description or query contain every word of the query.
+func (p *HistoryPlugin) GetResults(query string) ([]plugin.Result, error) {
+	history := p.mainPluginManager.History()
+	if history == nil {
+		return []plugin.Result{{
+			Title:       "History is not available",
+			Description: "Selections are not being recorded.",
+			Identifier:  "history_info",
+		}}, nil
+	}
+
+	entries := history.Entries()
+	if len(entries) == 0 {
+		return []plugin.Result{{
+			Title:       "No history yet",
+			Description: "Selections you execute are recorded here.",
+			Identifier:  "history_info",
+		}}, nil
+	}
+
+	plugins := p.mainPluginManager.GetAllPlugins()
+	words := strings.Fields(strings.ToLower(query))
+	results := []plugin.Result{}
+	for _, e := range entries {
+		haystack := strings.ToLower(e.Title + " " + e.Description + " " + e.Query)
+		if !containsAll(haystack, words) {
+			continue
+		}
+
+		source := e.Keyword
+		if pl, ok := plugins[e.Keyword]; ok {
+			source = pl.Name()
+		}
+		results = append(results, plugin.Result{
+			Title:       e.Title,
+			Description: fmt.Sprintf("%s | %q | %s", source, e.Query, e.LastUsed.Format("2006-01-02 15:04")),
+			Identifier:  e.Keyword + identifierSeparator + e.Identifier,
+		})
+	}
+
+	if len(results) == 0 {
+		return []plugin.Result{{
+			Title:       "No matching history",
+			Description: fmt.Sprintf("Nothing in the history matches '%s'.", query),
+			Identifier:  "history_info",
+		}}, nil
+	}
+	return results, nil
+}
+
+// containsAll reports whether s contains every word.
+func containsAll(s string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(s, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute re-runs the selected entry with the plugin that originally executed it.
+func (p *HistoryPlugin) Execute(identifier string) tea.Cmd {
+	if identifier == "history_info" {
+		return nil // Do nothing for info items.
+	}
+	keyword, original, ok := strings.Cut(identifier, identifierSeparator)
+	if !ok {
+		return nil
+	}
+	history := p.mainPluginManager.History()
+	if history == nil {
+		return nil
+	}
+	entry, ok := history.Find(keyword, original)
+	if !ok {
+		zap.L().Warn("History entry no longer exists.", zap.String("keyword", keyword), zap.String("identifier", original))
+		return nil
+	}
+
+	return p.mainPluginManager.Rerun(entry.Keyword, entry.Query, entry.Identifier, func() {
+		if err := history.Record(entry, time.Now()); err != nil {
+			zap.L().Warn("Could not save history.", zap.Error(err))
+		}
+	})
+}
+
+// Update is a no-op for this plugin.
+func (p *HistoryPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *HistoryPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as this plugin does not maintain an error state.
+func (p *HistoryPlugin) GetError() error {
+	return nil
+}
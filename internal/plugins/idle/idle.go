@@ -0,0 +1,311 @@
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!idle"
+
+const (
+	infoIdentifier   = "idle_info"
+	errorIdentifier  = "idle_error"
+	toggleIdentifier = "idle_toggle"
+	lockIdentifier   = "idle_lock"
+)
+
+// stateFile records the running inhibitor in $XDG_RUNTIME_DIR, so that its
+// status survives the launcher and is shared by all instances.
+const stateFile = "incipio/idle-inhibit"
+
+// inhibitWhy is the reason shown by tools listing inhibitors.
+const inhibitWhy = "Caffeinated from Incipio"
+
+var metadata = plugin.Metadata{
+	Name:        "Idle",
+	Description: "Keep the screen awake while presenting or watching, and lock the session.",
+	Keyword:     Keyword,
+	Flag:        "idle",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// helper is a program inhibiting idle for as long as it runs.
+type helper struct {
+	name string
+	args []string
+	// wayland marks helpers using the idle-inhibit protocol, which need a
+	// Wayland session.
+	wayland bool
+}
+
+// helpers lists the inhibitors in order of preference. Wayland compositors
+// ignore logind's idle inhibitors, so a protocol helper comes first there.
+var helpers = []helper{
+	{name: "wlinhibit", wayland: true},
+	{name: "systemd-inhibit", args: []string{"--what=idle", "--who=Incipio", "--why=" + inhibitWhy, "--mode=block", "sleep", "infinity"}},
+}
+
+// inhibitor is the state of a running inhibitor.
+type inhibitor struct {
+	pid     int
+	helper  string
+	started time.Time
+}
+
+// actionDoneMsg is sent once the inhibitor was toggled or the session
+// locked, or either failed.
+type actionDoneMsg struct {
+	err error
+}
+
+// IdlePlugin implements the plugin.Plugin interface for idle inhibition and locking.
+type IdlePlugin struct {
+	mu  sync.Mutex
+	err error // Of the last action; guarded by mu.
+}
+
+// New creates a new instance of the IdlePlugin.
+func New() *IdlePlugin {
+	return &IdlePlugin{}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *IdlePlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *IdlePlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *IdlePlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *IdlePlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults shows whether idle is inhibited, with entries to toggle it and lock the session.
+func (p *IdlePlugin) GetResults(query string) ([]plugin.Result, error) {
+	results := []plugin.Result{}
+	if running, ok := readInhibitor(); ok {
+		results = append(results,
+			plugin.Result{
+				Title:       "Caffeinated",
+				Description: fmt.Sprintf("Idle inhibited by %s since %s", running.helper, running.started.Format("15:04")),
+				Identifier:  infoIdentifier,
+			},
+			plugin.Result{
+				Title:       "Stop caffeinating",
+				Description: "Let the screen blank and lock when idle again",
+				Identifier:  toggleIdentifier,
+			},
+		)
+	} else {
+		results = append(results, plugin.Result{
+			Title:       "Caffeinate",
+			Description: "Keep the screen awake until toggled off, for presentations and movies",
+			Identifier:  toggleIdentifier,
+		})
+	}
+	results = append(results, plugin.Result{
+		Title:       "Lock now",
+		Description: "Lock the session with loginctl",
+		Identifier:  lockIdentifier,
+	})
+
+	if err := p.GetError(); err != nil {
+		results = append(results, plugin.Result{
+			Title:       "Action failed",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		})
+	}
+
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		filtered := results[:0]
+		for _, r := range results {
+			if strings.Contains(strings.ToLower(r.Title+" "+r.Description), query) {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
+	}
+	return results, nil
+}
+
+// Execute toggles idle inhibition or locks the session off the update loop,
+// and quits once done.
+func (p *IdlePlugin) Execute(identifier string) tea.Cmd {
+	var action func() error
+	switch identifier {
+	case toggleIdentifier:
+		action = toggleInhibitor
+	case lockIdentifier:
+		action = lockSession
+	default:
+		return nil // Do nothing for info/error items.
+	}
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			zap.L().Error("Idle action failed.", zap.String("identifier", identifier), zap.Error(err))
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{}
+	}
+}
+
+// toggleInhibitor stops the running inhibitor, or starts one.
+func toggleInhibitor() error {
+	if running, ok := readInhibitor(); ok {
+		return stopInhibitor(running)
+	}
+	return startInhibitor()
+}
+
+// lockSession locks the session with loginctl.
+func lockSession() error {
+	if out, err := exec.Command("loginctl", "lock-session").CombinedOutput(); err != nil {
+		return fmt.Errorf("%v %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// findHelper returns the preferred inhibitor installed for this session.
+func findHelper() (helper, error) {
+	wayland := os.Getenv("WAYLAND_DISPLAY") != ""
+	for _, h := range helpers {
+		if h.wayland && !wayland {
+			continue
+		}
+		if _, err := exec.LookPath(h.name); err == nil {
+			return h, nil
+		}
+	}
+	return helper{}, errors.New("no idle inhibitor found, install systemd-inhibit or wlinhibit")
+}
+
+// startInhibitor starts an inhibitor detached from the launcher and records it.
+func startInhibitor() error {
+	h, err := findHelper()
+	if err != nil {
+		return err
+	}
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(h.name, h.args...)
+	if err := launch.Detached(cmd); err != nil {
+		return fmt.Errorf("could not start %s: %w", h.name, err)
+	}
+	go cmd.Wait() // Reap it if it is stopped while this process runs.
+
+	state := fmt.Sprintf("%d\n%s\n%d\n", cmd.Process.Pid, h.name, time.Now().Unix())
+	if err := os.WriteFile(path, []byte(state), 0o600); err != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+		return fmt.Errorf("could not record inhibitor: %w", err)
+	}
+	zap.L().Info("Started idle inhibitor.", zap.String("helper", h.name), zap.Int("pid", cmd.Process.Pid))
+	return nil
+}
+
+// stopInhibitor stops the recorded inhibitor with its session, including
+// the command systemd-inhibit runs, and forgets it.
+func stopInhibitor(running inhibitor) error {
+	if err := syscall.Kill(-running.pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("could not stop %s: %w", running.helper, err)
+	}
+	if path, err := statePath(); err == nil {
+		_ = os.Remove(path)
+	}
+	zap.L().Info("Stopped idle inhibitor.", zap.String("helper", running.helper), zap.Int("pid", running.pid))
+	return nil
+}
+
+// readInhibitor returns the recorded inhibitor if it is still running. A
+// record whose process exited, or whose PID was reused, is ignored.
+func readInhibitor() (inhibitor, bool) {
+	path, err := statePath()
+	if err != nil {
+		return inhibitor{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			zap.L().Warn("Could not read idle inhibitor state.", zap.String("path", path), zap.Error(err))
+		}
+		return inhibitor{}, false
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return inhibitor{}, false
+	}
+	pid, pidErr := strconv.Atoi(fields[0])
+	started, startedErr := strconv.ParseInt(fields[2], 10, 64)
+	if pidErr != nil || startedErr != nil || pid <= 0 {
+		return inhibitor{}, false
+	}
+	comm, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	// comm is truncated to 15 bytes, like "systemd-inhibi".
+	name := strings.TrimSpace(string(comm))
+	if err != nil || name == "" || !strings.HasPrefix(fields[1], name) {
+		return inhibitor{}, false
+	}
+	return inhibitor{pid: pid, helper: fields[1], started: time.Unix(started, 0)}, true
+}
+
+// statePath returns the location of the inhibitor record.
+func statePath() (string, error) {
+	return xdg.RuntimeFile(stateFile)
+}
+
+// Update handles actions finishing.
+func (p *IdlePlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	if msg, ok := msg.(actionDoneMsg); ok {
+		p.setError(msg.err)
+		if msg.err == nil {
+			return p, tea.Quit
+		}
+	}
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *IdlePlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed action, if any.
+func (p *IdlePlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *IdlePlugin) setError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
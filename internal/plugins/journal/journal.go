@@ -0,0 +1,272 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!jctl"
+
+const (
+	// maxEntries is the number of most recent matching entries shown.
+	maxEntries = 100
+
+	entryIdentifierPrefix = "jctl_entry:"
+	infoIdentifier        = "jctl_info"
+	errorIdentifier       = "jctl_error"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Journal",
+	Description: "Search the systemd journal by unit, priority and message.",
+	Keyword:     Keyword,
+	Flag:        "jctl",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+var viewportKeys = viewport.KeyMap{
+	PageDown:     key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdn", "page down")),
+	PageUp:       key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+	HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "½ page up")),
+	HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "½ page down")),
+}
+
+// JournalPlugin searches journald and shows full entries in a detail view.
+type JournalPlugin struct {
+	mu      sync.Mutex
+	entries map[string]entry // Entries of the last query by cursor.
+	detail  *entry           // Entry shown in the detail view, nil for the list.
+
+	viewport   viewport.Model
+	viewWidth  int
+	viewHeight int
+	ready      bool
+
+	headerStyle lipgloss.Style
+	footerStyle lipgloss.Style
+}
+
+// New creates a new instance of the JournalPlugin.
+func New() *JournalPlugin {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewportKeys
+	p := &JournalPlugin{
+		entries:  make(map[string]entry),
+		viewport: vp,
+	}
+	p.SetTheme(theme.DefaultTheme)
+	return p
+}
+
+// SetTheme styles the plugin's view with the given theme.
+func (p *JournalPlugin) SetTheme(t theme.Theme) {
+	p.headerStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+	p.footerStyle = lipgloss.NewStyle().Foreground(t.Muted)
+}
+
+// Metadata returns the plugin's metadata.
+func (p *JournalPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *JournalPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *JournalPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *JournalPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults runs journalctl with the filters parsed from the query and lists
+// the most recent matching entries. Any new query closes the detail view.
+func (p *JournalPlugin) GetResults(query string) ([]plugin.Result, error) {
+	return p.GetResultsContext(context.Background(), query)
+}
+
+// GetResultsContext is GetResults stopping journalctl once ctx is cancelled,
+// as when the query changes.
+func (p *JournalPlugin) GetResultsContext(ctx context.Context, query string) ([]plugin.Result, error) {
+	p.mu.Lock()
+	p.detail = nil
+	p.mu.Unlock()
+
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		return []plugin.Result{{
+			Title:       "journalctl not found",
+			Description: "The journal plugin requires systemd-journald",
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+
+	f := parseQuery(query)
+	entries, err := runJournalctl(ctx, f, maxEntries)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		zap.L().Warn("journalctl query failed.", zap.String("query", query), zap.Error(err))
+		return []plugin.Result{{
+			Title:       "Journal query failed",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+
+	byCursor := make(map[string]entry, len(entries))
+	results := make([]plugin.Result, 0, len(entries)+1)
+	for _, e := range entries {
+		byCursor[e.cursor] = e
+		description := e.time.Format("Jan 02 15:04:05")
+		if e.unit != "" {
+			description += " | " + e.unit
+		}
+		if name := e.priorityName(); name != "" {
+			description += " | " + name
+		}
+		title, _, _ := strings.Cut(e.message, "\n")
+		results = append(results, plugin.Result{
+			Title:       title,
+			Description: description,
+			Identifier:  entryIdentifierPrefix + e.cursor,
+		})
+	}
+
+	p.mu.Lock()
+	p.entries = byCursor
+	p.mu.Unlock()
+
+	if len(results) == 0 {
+		results = append(results, plugin.Result{
+			Title:       "No journal entries",
+			Description: fmt.Sprintf("Nothing found for %s | filters: unit:, prio:, since:, user", f.describe()),
+			Identifier:  infoIdentifier,
+		})
+	} else if strings.TrimSpace(query) == "" {
+		results = append([]plugin.Result{{
+			Title:       "Journal",
+			Description: "Filter with unit:sshd, prio:err, since:1h or user; other words match messages",
+			Identifier:  infoIdentifier,
+		}}, results...)
+	}
+	return results, nil
+}
+
+// Execute opens the selected entry in the detail view. Executing the entry
+// shown in the detail view copies its message to the clipboard and quits.
+func (p *JournalPlugin) Execute(identifier string) tea.Cmd {
+	cursor, ok := strings.CutPrefix(identifier, entryIdentifierPrefix)
+	if !ok {
+		return nil // Do nothing for info/error items.
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.entries[cursor]
+	if !ok {
+		return nil
+	}
+	if p.detail != nil && p.detail.cursor == cursor {
+		if err := clipboard.WriteAll(e.message); err != nil {
+			zap.L().Error("Failed to copy journal message to clipboard.", zap.Error(err))
+			return nil
+		}
+		return tea.Quit
+	}
+
+	p.detail = &e
+	// Wrap long messages and field values, the viewport does not.
+	p.viewport.SetContent(lipgloss.NewStyle().Width(max(1, p.viewWidth)).Render(e.detail()))
+	p.viewport.GotoTop()
+	return nil
+}
+
+// Help lists the keys scrolling the detail view while it is shown.
+func (p *JournalPlugin) Help() []key.Binding {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.detail == nil {
+		return nil
+	}
+	return []key.Binding{viewportKeys.PageUp, viewportKeys.PageDown, viewportKeys.HalfPageUp, viewportKeys.HalfPageDown}
+}
+
+// Update handles window sizing and scrolling of the detail view.
+func (p *JournalPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Constants for main app layout estimation, matching the app's padding and input line.
+		const mainAppHorizontalPadding = 4
+		const mainAppVerticalPadding = 2
+		const textInputHeight = 1
+
+		p.viewWidth = msg.Width - mainAppHorizontalPadding
+		p.viewHeight = max(1, msg.Height-textInputHeight-mainAppVerticalPadding)
+		p.viewport.Width = max(1, p.viewWidth)
+		p.viewport.Height = max(1, p.viewHeight-1-lipgloss.Height(p.footerView()))
+		p.ready = true
+		return p, nil
+	}
+
+	if p.detail == nil {
+		return p, nil
+	}
+	var cmd tea.Cmd
+	p.viewport, cmd = p.viewport.Update(msg)
+	return p, cmd
+}
+
+func (p *JournalPlugin) headerView() string {
+	header := p.detail.time.Format("2006-01-02 15:04:05.000000")
+	if p.detail.unit != "" {
+		header += " " + p.detail.unit
+	}
+	if name := p.detail.priorityName(); name != "" {
+		header += " [" + name + "]"
+	}
+	return p.headerStyle.MaxWidth(max(1, p.viewWidth)).Render(header)
+}
+
+func (p *JournalPlugin) footerView() string {
+	return p.footerStyle.Render(fmt.Sprintf("enter: copy message · pgup/pgdn: scroll · edit query: back · %3.f%%", p.viewport.ScrollPercent()*100))
+}
+
+// View renders the selected journal entry. It is empty while no entry is
+// opened, so results are shown in the main list.
+func (p *JournalPlugin) View() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.ready || p.detail == nil {
+		return ""
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, p.headerView(), p.viewport.View(), p.footerView())
+}
+
+// GetError returns nil as errors are reported through results.
+func (p *JournalPlugin) GetError() error {
+	return nil
+}
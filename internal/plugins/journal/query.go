@@ -0,0 +1,240 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// queryTimeout bounds a single journalctl invocation.
+const queryTimeout = 5 * time.Second
+
+// priorityNames maps syslog priorities to the names journalctl accepts.
+var priorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// filter is a parsed !jctl query.
+type filter struct {
+	units    []string
+	priority string
+	since    string
+	user     bool
+	pattern  string
+}
+
+// parseQuery splits filter tokens from the free-text pattern. Supported tokens:
+// unit:NAME (u:), prio:LEVEL (p:), since:TIME (s:) and the bare word "user"
+// to read the user journal. Everything else is matched against messages.
+func parseQuery(query string) filter {
+	var f filter
+	var words []string
+	for _, token := range strings.Fields(query) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			if token == "user" {
+				f.user = true
+				continue
+			}
+			words = append(words, token)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "unit", "u":
+			f.units = append(f.units, value)
+		case "prio", "priority", "p":
+			f.priority = value
+		case "since", "s":
+			f.since = relativeTime(value)
+		default:
+			words = append(words, token)
+		}
+	}
+	f.pattern = strings.Join(words, " ")
+	return f
+}
+
+// relativeTime turns a bare duration like "1h" into journalctl's "-1h" (one hour ago).
+func relativeTime(value string) string {
+	if value[0] >= '0' && value[0] <= '9' && strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' }) > 0 {
+		return "-" + value
+	}
+	return value
+}
+
+// describe summarizes the active filters for the status row.
+func (f filter) describe() string {
+	var parts []string
+	if f.user {
+		parts = append(parts, "user journal")
+	}
+	if len(f.units) > 0 {
+		parts = append(parts, "unit "+strings.Join(f.units, ", "))
+	}
+	if f.priority != "" {
+		parts = append(parts, "priority "+f.priority)
+	}
+	if f.since != "" {
+		parts = append(parts, "since "+f.since)
+	}
+	if f.pattern != "" {
+		parts = append(parts, fmt.Sprintf("matching '%s'", f.pattern))
+	}
+	if len(parts) == 0 {
+		return "all entries"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// args returns the journalctl arguments for the filter, newest entries first.
+func (f filter) args(limit int) []string {
+	args := []string{"--no-pager", "--output=json", "--reverse", "--lines=" + strconv.Itoa(limit)}
+	if f.user {
+		args = append(args, "--user")
+	}
+	for _, unit := range f.units {
+		if f.user {
+			args = append(args, "--user-unit="+unit)
+		} else {
+			args = append(args, "--unit="+unit)
+		}
+	}
+	if f.priority != "" {
+		args = append(args, "--priority="+f.priority)
+	}
+	if f.since != "" {
+		args = append(args, "--since="+f.since)
+	}
+	if f.pattern != "" {
+		args = append(args, "--grep="+f.pattern, "--case-sensitive=false")
+	}
+	return args
+}
+
+// entry is a single journal record. Fields holds every field of the record
+// for the detail view.
+type entry struct {
+	cursor   string
+	time     time.Time
+	unit     string
+	priority int
+	message  string
+	fields   map[string]string
+}
+
+// runJournalctl returns up to limit entries matching f, stopping journalctl
+// once ctx is done.
+func runJournalctl(ctx context.Context, f filter, limit int) ([]entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "journalctl", f.args(limit)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("journalctl timed out after %s", queryTimeout)
+	}
+	// journalctl exits with 1 when --grep matched nothing.
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(bytes.TrimSpace(out)) == 0 && stderr.Len() == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("journalctl: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var entries []entry
+	for line := range bytes.SplitSeq(out, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return entries, fmt.Errorf("unexpected journalctl output: %w", err)
+		}
+		entries = append(entries, newEntry(raw))
+	}
+	return entries, nil
+}
+
+func newEntry(raw map[string]json.RawMessage) entry {
+	e := entry{priority: -1, fields: make(map[string]string, len(raw))}
+	for key, value := range raw {
+		e.fields[key] = fieldValue(value)
+	}
+
+	e.cursor = e.fields["__CURSOR"]
+	e.message = e.fields["MESSAGE"]
+	if usec, err := strconv.ParseInt(e.fields["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+		e.time = time.UnixMicro(usec)
+	}
+	if p, err := strconv.Atoi(e.fields["PRIORITY"]); err == nil && p >= 0 && p < len(priorityNames) {
+		e.priority = p
+	}
+	for _, key := range []string{"_SYSTEMD_UNIT", "_SYSTEMD_USER_UNIT", "SYSLOG_IDENTIFIER", "_COMM"} {
+		if e.fields[key] != "" {
+			e.unit = e.fields[key]
+			break
+		}
+	}
+	return e
+}
+
+// fieldValue decodes a journal JSON field. Values are strings, null for
+// oversized fields, or arrays of bytes for binary data.
+func fieldValue(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	var data []byte
+	var ints []int
+	if err := json.Unmarshal(value, &ints); err == nil {
+		for _, i := range ints {
+			data = append(data, byte(i))
+		}
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		return fmt.Sprintf("[%d bytes of binary data]", len(data))
+	}
+	return string(value)
+}
+
+// priorityName returns the syslog name of the entry's priority.
+func (e entry) priorityName() string {
+	if e.priority < 0 {
+		return ""
+	}
+	return priorityNames[e.priority]
+}
+
+// detail renders every field of the entry, well-known ones first.
+func (e entry) detail() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", e.message)
+
+	keys := make([]string, 0, len(e.fields))
+	for key := range e.fields {
+		if key != "MESSAGE" {
+			keys = append(keys, key)
+		}
+	}
+	// Trusted fields (leading underscore) and address fields (two underscores) go last.
+	rank := func(key string) int { return len(key) - len(strings.TrimLeft(key, "_")) }
+	sort.Slice(keys, func(i, j int) bool {
+		if ri, rj := rank(keys[i]), rank(keys[j]); ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, e.fields[key])
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
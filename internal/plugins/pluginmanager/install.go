@@ -0,0 +1,136 @@
+package pluginmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/internal/yaegi"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const (
+	installCommand    = "install"
+	installIdentifier = "pm_install:"
+	statusIdentifier  = "pm_status"
+
+	installTimeout = 2 * time.Minute
+	// installRefresh is how often results are refreshed while installing.
+	installRefresh = 250 * time.Millisecond
+)
+
+// installQuery returns the source of an "install <source>" query.
+func installQuery(query string) (source string, ok bool) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 || fields[0] != installCommand {
+		return "", false
+	}
+	return strings.Join(fields[1:], " "), true
+}
+
+// installResults offers to install the plugin at source.
+func (p *PluginManagerPlugin) installResults(source string) []plugin.Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := p.installStatusLocked()
+	if source == "" {
+		results = append(results, plugin.Result{
+			Title:       "Install a plugin",
+			Description: "Type the URL of a plugin file or git repository, e.g. install https://github.com/user/plugin.go",
+			Identifier:  infoIdentifier,
+		})
+	} else {
+		results = append(results, plugin.Result{
+			Title:       "Install " + source,
+			Description: fmt.Sprintf("Downloads it into %s, checks it loads and enables it", yaegi.PluginDir()),
+			Identifier:  installIdentifier + source,
+		})
+	}
+	if p.err != nil {
+		results = append(results, plugin.Result{
+			Title:       "Error",
+			Description: p.err.Error(),
+			Identifier:  errorIdentifier,
+		})
+	}
+	return results
+}
+
+// installStatusLocked lists the running or last finished install. p.mu must be held.
+func (p *PluginManagerPlugin) installStatusLocked() []plugin.Result {
+	switch {
+	case p.installing != "":
+		return []plugin.Result{{
+			Title:       "Installing...",
+			Description: p.installing,
+			Identifier:  statusIdentifier,
+		}}
+	case p.installed != "":
+		return []plugin.Result{{
+			Title:       "Installed",
+			Description: p.installed,
+			Identifier:  statusIdentifier,
+		}}
+	}
+	return nil
+}
+
+// startInstall installs the plugin at source in the background. Only one
+// install runs at a time.
+func (p *PluginManagerPlugin) startInstall(source string) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.installing != "" {
+		return nil
+	}
+	p.installing = source
+	p.installed = ""
+	p.err = nil
+	go p.install(source)
+	return changed(nil)
+}
+
+// install downloads the plugin at source, registers it enabled and saves it as
+// enabled. A plugin replacing an installed one keeps its status.
+func (p *PluginManagerPlugin) install(source string) {
+	ctx, cancel := context.WithTimeout(context.Background(), installTimeout)
+	defer cancel()
+
+	loaded, err := yaegi.Install(ctx, source, func(l yaegi.LoadedPlugin) error {
+		return p.mainPluginManager.ReloadPlugin(l.Path, l.Plugin, true)
+	})
+	var installed string
+	if err == nil {
+		meta := loaded.Plugin.Metadata()
+		status := "disabled"
+		if p.mainPluginManager.IsEnabled(meta.Keyword) {
+			status = "enabled"
+			loaded.Plugin.Init()
+			err = p.persist(meta, true)
+		}
+		installed = fmt.Sprintf("%s (%s, %s) into %s", meta.Name, meta.Keyword, status, loaded.Path) + capabilitiesNote(meta)
+		zap.L().Info("Installed plugin.", zap.String("source", source), zap.String("path", loaded.Path))
+	} else {
+		zap.L().Error("Failed to install plugin.", zap.String("source", source), zap.Error(err))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.installing = ""
+	p.installed = installed
+	p.err = err
+}
+
+// RefreshInterval refreshes the results while a plugin is being installed.
+func (p *PluginManagerPlugin) RefreshInterval() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.installing != "" {
+		return installRefresh
+	}
+	return 0
+}
@@ -2,16 +2,34 @@ package pluginmanager
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/barab-i/incipio/internal/app"
+	"github.com/barab-i/incipio/internal/yaegi"
 	"github.com/barab-i/incipio/pkgs/plugin"
 	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
 )
 
 const keyword = "!p"
 
+const (
+	infoIdentifier    = "pm_info_flag"
+	errorIdentifier   = "pm_error"
+	disableIdentifier = "pm_disable:"
+)
+
+// Sections the plugins are listed in.
+const (
+	mandatorySection = "Mandatory"
+	optionalSection  = "Optional"
+	helpSection      = "Help"
+)
+
 var metadata = plugin.Metadata{
 	Name:        "Plugin Manager",
 	Keyword:     keyword,
@@ -20,19 +38,30 @@ var metadata = plugin.Metadata{
 	IsDefault:   false,
 }
 
-// PluginManagerPlugin displays the status of registered plugins.
+// PluginManagerPlugin displays the status of registered plugins, enables or
+// disables optional plugins at runtime and installs yaegi plugins.
 type PluginManagerPlugin struct {
 	mainPluginManager *app.PluginManager // Reference to the main application's plugin manager.
+	// save persists whether the plugin with a flag is enabled.
+	save func(flag string, enabled bool) error
+
+	mu             sync.Mutex // Guards the fields below, also set by installs running in the background.
+	pendingDisable string     // Keyword of the plugin offered for disabling.
+	installing     string     // Source of the plugin being installed, if any.
+	installed      string     // Outcome of the last successful install.
+	err            error
 }
 
 // New creates a new instance of the PluginManagerPlugin.
-// It requires the main PluginManager to access the list of loaded plugins.
-func New(mainPM *app.PluginManager) *PluginManagerPlugin {
+// It requires the main PluginManager to access the list of loaded plugins,
+// and save to persist the plugins enabled or disabled, like config.SetPluginEnabled.
+func New(mainPM *app.PluginManager, save func(flag string, enabled bool) error) *PluginManagerPlugin {
 	if mainPM == nil {
 		panic("PluginManagerPlugin requires a non-nil main PluginManager")
 	}
 	return &PluginManagerPlugin{
 		mainPluginManager: mainPM,
+		save:              save,
 	}
 }
 
@@ -58,6 +87,12 @@ func (p *PluginManagerPlugin) Init() tea.Cmd {
 
 // GetResults generates a sorted list of all plugins and their status.
 func (p *PluginManagerPlugin) GetResults(query string) ([]plugin.Result, error) {
+	if source, ok := installQuery(query); ok {
+		return p.installResults(source), nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	mandatoryPlugins := []plugin.Result{}
 	optionalPlugins := []plugin.Result{}
 
@@ -67,12 +102,21 @@ func (p *PluginManagerPlugin) GetResults(query string) ([]plugin.Result, error)
 		meta := pl.Metadata()
 		result := plugin.Result{
 			Title:       meta.Name,
-			Description: fmt.Sprintf("Keyword: %s | Status: ✅ Enabled", kw),
+			Description: p.keywordsNote(kw) + " | Status: ✅ Enabled" + loadTimeNote(kw) + capabilitiesNote(meta),
 			Identifier:  kw,
 		}
+		if kw == p.pendingDisable {
+			result = plugin.Result{
+				Title:       fmt.Sprintf("Disable %s?", meta.Name),
+				Description: p.keywordsNote(kw) + " | enter disables it and saves the choice to the config",
+				Identifier:  disableIdentifier + kw,
+			}
+		}
 		if meta.IsMandatory {
+			result.Section = mandatorySection
 			mandatoryPlugins = append(mandatoryPlugins, result)
 		} else {
+			result.Section = optionalSection
 			optionalPlugins = append(optionalPlugins, result)
 		}
 	}
@@ -83,8 +127,9 @@ func (p *PluginManagerPlugin) GetResults(query string) ([]plugin.Result, error)
 		if _, exists := loadedPlugins[kw]; !exists { // Add only if not already listed as enabled.
 			optionalPlugins = append(optionalPlugins, plugin.Result{
 				Title:       meta.Name,
-				Description: fmt.Sprintf("Keyword: %s | Status: ❌ Disabled (use --plugins=%s)", kw, meta.Flag),
+				Description: p.keywordsNote(kw) + " | Status: ❌ Disabled | enter enables it" + capabilitiesNote(meta),
 				Identifier:  kw,
+				Section:     optionalSection,
 			})
 		}
 	}
@@ -97,13 +142,24 @@ func (p *PluginManagerPlugin) GetResults(query string) ([]plugin.Result, error)
 		return strings.ToLower(optionalPlugins[i].Title) < strings.ToLower(optionalPlugins[j].Title)
 	})
 
-	allResults := append(mandatoryPlugins, optionalPlugins...)
+	allResults := append(p.installStatusLocked(), mandatoryPlugins...)
+	allResults = append(allResults, optionalPlugins...)
+
+	if p.err != nil {
+		allResults = append(allResults, plugin.Result{
+			Title:       "Error",
+			Description: p.err.Error(),
+			Identifier:  errorIdentifier,
+			Section:     helpSection,
+		})
+	}
 
 	// Add informational item about enabling plugins.
 	allResults = append(allResults, plugin.Result{
 		Title:       "Info",
-		Description: "Use --plugins=flag1,flag2,... at startup to enable optional plugins.",
-		Identifier:  "pm_info_flag",
+		Description: "Select an optional plugin to enable or disable it; the choice is saved to the config file. Type install <url> to add a plugin.",
+		Identifier:  infoIdentifier,
+		Section:     helpSection,
 	})
 
 	// Filter results based on the query, excluding the info item from being filtered out.
@@ -111,7 +167,7 @@ func (p *PluginManagerPlugin) GetResults(query string) ([]plugin.Result, error)
 	if trimmedQuery != "" {
 		filteredResults := []plugin.Result{}
 		for _, r := range allResults {
-			if r.Identifier == "pm_info_flag" || // Always include the info item.
+			if r.Identifier == infoIdentifier || r.Identifier == errorIdentifier || r.Identifier == statusIdentifier || // Always include the info and status items.
 				strings.Contains(strings.ToLower(r.Title), trimmedQuery) ||
 				strings.Contains(strings.ToLower(r.Description), trimmedQuery) {
 				filteredResults = append(filteredResults, r)
@@ -123,11 +179,132 @@ func (p *PluginManagerPlugin) GetResults(query string) ([]plugin.Result, error)
 	return allResults, nil
 }
 
-// Execute is a no-op for this plugin.
+// keywordsNote lists the keywords typed to switch to the plugin known by kw,
+// which the keywords setting of the config may replace.
+func (p *PluginManagerPlugin) keywordsNote(kw string) string {
+	keywords := p.mainPluginManager.Keywords(kw)
+	switch {
+	case len(keywords) == 1 && keywords[0] == kw:
+		return "Keyword: " + kw
+	case slices.Contains(keywords, kw):
+		return "Keywords: " + strings.Join(keywords, ", ")
+	}
+	return fmt.Sprintf("Keywords: %s (instead of %s)", strings.Join(keywords, ", "), kw)
+}
+
+// loadTimeNote tells how long the yaegi plugin known by kw took to load, so
+// costly plugins stand out, and nothing for built-in plugins.
+func loadTimeNote(kw string) string {
+	t, ok := yaegi.PluginLoadTime(kw)
+	if !ok {
+		return ""
+	}
+	note := " | Loaded in " + t.Duration.Round(time.Millisecond).String()
+	if t.Cached {
+		note += " (sandbox check cached)"
+	}
+	return note
+}
+
+// capabilitiesNote warns about what a yaegi plugin may do beyond computing
+// results, as declared in its metadata.
+func capabilitiesNote(meta plugin.Metadata) string {
+	if len(meta.Capabilities) == 0 {
+		return ""
+	}
+	names := make([]string, len(meta.Capabilities))
+	for i, c := range meta.Capabilities {
+		names[i] = string(c)
+	}
+	return " | ⚠ Can use: " + strings.Join(names, ", ")
+}
+
+// Execute enables the selected disabled plugin, or offers to disable the
+// selected optional plugin and disables it once confirmed. The launcher stays
+// open and lists the new status.
 func (p *PluginManagerPlugin) Execute(identifier string) tea.Cmd {
+	if source, ok := strings.CutPrefix(identifier, installIdentifier); ok {
+		return p.startInstall(source)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending := p.pendingDisable
+	p.pendingDisable = ""
+
+	if kw, ok := strings.CutPrefix(identifier, disableIdentifier); ok {
+		if kw != pending {
+			return nil // The offer was withdrawn.
+		}
+		p.err = p.disable(kw)
+		return changed(nil)
+	}
+
+	if pl, enabled := p.mainPluginManager.GetAllPlugins()[identifier]; enabled {
+		if pl.Metadata().IsMandatory {
+			return nil
+		}
+		p.err = nil
+		p.pendingDisable = identifier
+		return changed(nil)
+	}
+	if _, disabled := p.mainPluginManager.GetAllDisabledPluginsMetadatas()[identifier]; disabled {
+		enabled, err := p.enable(identifier)
+		p.err = err
+		return changed(enabled)
+	}
+	return nil // Do nothing for info/error items.
+}
+
+// enable registers the disabled plugin with the given keyword and saves it as enabled.
+func (p *PluginManagerPlugin) enable(kw string) (plugin.Plugin, error) {
+	pl, err := p.mainPluginManager.EnablePlugin(kw)
+	if err != nil {
+		zap.L().Error("Failed to enable plugin.", zap.String("keyword", kw), zap.Error(err))
+		return nil, err
+	}
+	return pl, p.persist(pl.Metadata(), true)
+}
+
+// disable unregisters the plugin with the given keyword and saves it as disabled.
+func (p *PluginManagerPlugin) disable(kw string) error {
+	pl, enabled := p.mainPluginManager.GetAllPlugins()[kw]
+	if !enabled {
+		return fmt.Errorf("plugin '%s' is not enabled", kw)
+	}
+	if err := p.mainPluginManager.DisablePlugin(kw); err != nil {
+		zap.L().Error("Failed to disable plugin.", zap.String("keyword", kw), zap.Error(err))
+		return err
+	}
+	return p.persist(pl.Metadata(), false)
+}
+
+// persist saves whether a plugin is enabled. Plugins without a flag cannot be
+// enabled by the config, so their status only lasts for the session.
+func (p *PluginManagerPlugin) persist(meta plugin.Metadata, enabled bool) error {
+	if p.save == nil || meta.Flag == "" {
+		zap.L().Info("Plugin status not saved.", zap.String("name", meta.Name), zap.Bool("enabled", enabled))
+		return nil
+	}
+	if err := p.save(meta.Flag, enabled); err != nil {
+		zap.L().Error("Failed to save plugin status.", zap.String("name", meta.Name), zap.Error(err))
+		status := "disabled"
+		if enabled {
+			status = "enabled"
+		}
+		return fmt.Errorf("%s is %s for this session only: %w", meta.Name, status, err)
+	}
 	return nil
 }
 
+// changed returns a command refreshing the results once plugins changed,
+// initializing enabled if set.
+func changed(enabled plugin.Plugin) tea.Cmd {
+	return func() tea.Msg {
+		return app.PluginsChangedMsg{Enabled: enabled}
+	}
+}
+
 // Update is a no-op for this plugin.
 func (p *PluginManagerPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 	return p, nil
@@ -138,7 +315,9 @@ func (p *PluginManagerPlugin) View() string {
 	return ""
 }
 
-// GetError returns nil as this plugin does not maintain an error state.
+// GetError returns the error of the last failed enable, disable or install, if any.
 func (p *PluginManagerPlugin) GetError() error {
-	return nil
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
 }
@@ -0,0 +1,363 @@
+package projects
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/execute"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const Keyword = "!proj"
+
+const (
+	configFileName = "projects.yaml"
+	configDir      = "incipio"
+
+	infoIdentifier  = "proj_info"
+	errorIdentifier = "proj_error"
+)
+
+// Action prefixes of result identifiers, followed by the project index (and URL label).
+const (
+	actionEditor   = "proj_editor:"
+	actionTerminal = "proj_terminal:"
+	actionURL      = "proj_url:"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Projects",
+	Description: "Open projects from projects.yaml in an editor, terminal or browser.",
+	Keyword:     Keyword,
+	Flag:        "proj",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// Project is an entry of projects.yaml.
+type Project struct {
+	Name     string            `yaml:"name"`
+	Path     string            `yaml:"path"`
+	Editor   string            `yaml:"editor"`   // Overrides the top-level editor.
+	Terminal string            `yaml:"terminal"` // Overrides the top-level terminal.
+	URLs     map[string]string `yaml:"urls"`     // Label to URL, e.g. repo or ci.
+}
+
+// config is the layout of projects.yaml.
+type config struct {
+	// Editor is the command opening a project path, e.g. "code" or "zed".
+	// When empty, $VISUAL or $EDITOR is run inside a terminal.
+	Editor string `yaml:"editor"`
+	// Terminal is the terminal emulator started at a project path.
+	// When empty, $TERMINAL or a known emulator is used.
+	Terminal string    `yaml:"terminal"`
+	Projects []Project `yaml:"projects"`
+}
+
+// ProjectsPlugin implements the plugin.Plugin interface for a project launcher.
+type ProjectsPlugin struct {
+	mu       sync.Mutex
+	config   config
+	path     string
+	modTime  time.Time
+	loadErr  error
+	execErr  error
+	resolved bool
+}
+
+// New creates a new instance of the ProjectsPlugin.
+func New() *ProjectsPlugin {
+	return &ProjectsPlugin{}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *ProjectsPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *ProjectsPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *ProjectsPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *ProjectsPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists projects matching the query. Once the query narrows the list
+// down to a single project, its secondary actions are listed as well.
+func (p *ProjectsPlugin) GetResults(query string) ([]plugin.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reload()
+	if p.loadErr != nil {
+		return []plugin.Result{{
+			Title:       "Could not load projects",
+			Description: p.loadErr.Error(),
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+	if len(p.config.Projects) == 0 {
+		return []plugin.Result{{
+			Title:       "No projects configured",
+			Description: fmt.Sprintf("Add projects (name, path, editor, terminal, urls) to %s", p.path),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	var matches []int
+	for i, project := range p.config.Projects {
+		if lowerQuery == "" || strings.Contains(strings.ToLower(project.Name+" "+project.Path), lowerQuery) {
+			matches = append(matches, i)
+		}
+	}
+
+	results := []plugin.Result{}
+	for _, i := range matches {
+		project := p.config.Projects[i]
+		results = append(results, plugin.Result{
+			Title:       project.Name,
+			Description: fmt.Sprintf("%s | enter opens the editor", project.Path),
+			Identifier:  fmt.Sprintf("%s%d", actionEditor, i),
+		})
+	}
+	if len(matches) == 1 {
+		results = append(results, p.secondaryActions(matches[0])...)
+	} else if len(matches) > 1 {
+		results = append(results, plugin.Result{
+			Title:       "More actions",
+			Description: "Narrow the search to one project to open a terminal or its URLs",
+			Identifier:  infoIdentifier,
+		})
+	}
+
+	if p.execErr != nil {
+		results = append(results, plugin.Result{
+			Title:       "Last action failed",
+			Description: p.execErr.Error(),
+			Identifier:  errorIdentifier,
+		})
+	}
+	return results, nil
+}
+
+// secondaryActions lists the terminal and URL actions of a project.
+func (p *ProjectsPlugin) secondaryActions(i int) []plugin.Result {
+	project := p.config.Projects[i]
+	results := []plugin.Result{{
+		Title:       "  Open terminal",
+		Description: fmt.Sprintf("Terminal at %s", project.Path),
+		Identifier:  fmt.Sprintf("%s%d", actionTerminal, i),
+	}}
+
+	labels := make([]string, 0, len(project.URLs))
+	for label := range project.URLs {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		results = append(results, plugin.Result{
+			Title:       fmt.Sprintf("  Open %s", label),
+			Description: project.URLs[label],
+			Identifier:  fmt.Sprintf("%s%d:%s", actionURL, i, label),
+		})
+	}
+	return results
+}
+
+// reload reads projects.yaml when it has changed since the last load.
+func (p *ProjectsPlugin) reload() {
+	if !p.resolved {
+		path, err := xdg.ConfigFile(filepath.Join(configDir, configFileName))
+		if err != nil {
+			p.loadErr = fmt.Errorf("could not determine config path: %w", err)
+			return
+		}
+		p.path, p.resolved = path, true
+	}
+
+	info, err := os.Stat(p.path)
+	if os.IsNotExist(err) {
+		p.config, p.loadErr, p.modTime = config{}, nil, time.Time{}
+		return
+	}
+	if err != nil {
+		p.loadErr = err
+		return
+	}
+	if info.ModTime().Equal(p.modTime) {
+		return
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		p.loadErr = err
+		return
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		p.loadErr = fmt.Errorf("invalid %s: %w", configFileName, err)
+		return
+	}
+	for i := range cfg.Projects {
+		cfg.Projects[i].Path = expandHome(cfg.Projects[i].Path)
+		if cfg.Projects[i].Name == "" {
+			cfg.Projects[i].Name = filepath.Base(cfg.Projects[i].Path)
+		}
+	}
+
+	p.config, p.loadErr, p.modTime = cfg, nil, info.ModTime()
+	zap.L().Debug("Loaded projects.", zap.String("path", p.path), zap.Int("count", len(cfg.Projects)))
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/') {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + rest
+		}
+	}
+	return path
+}
+
+// Execute runs the selected project action and quits.
+func (p *ProjectsPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	switch {
+	case strings.HasPrefix(identifier, actionEditor):
+		if project, ok := p.project(strings.TrimPrefix(identifier, actionEditor)); ok {
+			err = p.openEditor(project)
+		}
+	case strings.HasPrefix(identifier, actionTerminal):
+		if project, ok := p.project(strings.TrimPrefix(identifier, actionTerminal)); ok {
+			err = p.openTerminal(project)
+		}
+	case strings.HasPrefix(identifier, actionURL):
+		index, label, _ := strings.Cut(strings.TrimPrefix(identifier, actionURL), ":")
+		if project, ok := p.project(index); ok {
+			err = launch.OpenURL(project.URLs[label])
+		}
+	default:
+		return nil // Do nothing for info/error items.
+	}
+
+	if err != nil {
+		p.execErr = err
+		zap.L().Error("Failed to run project action.", zap.String("identifier", identifier), zap.Error(err))
+		return nil
+	}
+	p.execErr = nil
+	return tea.Quit
+}
+
+func (p *ProjectsPlugin) project(index string) (Project, bool) {
+	var i int
+	if _, err := fmt.Sscanf(index, "%d", &i); err != nil || i < 0 || i >= len(p.config.Projects) {
+		zap.L().Warn("Unknown project for execution.", zap.String("index", index))
+		return Project{}, false
+	}
+	return p.config.Projects[i], true
+}
+
+// openEditor opens the project with its editor. Without a configured editor,
+// $VISUAL or $EDITOR is assumed to be a terminal editor and run in a terminal.
+func (p *ProjectsPlugin) openEditor(project Project) error {
+	editor := firstNonEmpty(project.Editor, p.config.Editor)
+	if editor != "" {
+		fields, err := commandFields("editor", editor)
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command(fields[0], append(fields[1:], project.Path)...)
+		cmd.Dir = project.Path
+		return launch.Detached(cmd)
+	}
+
+	editor = firstNonEmpty(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	if editor == "" {
+		return fmt.Errorf("no editor configured for '%s', set 'editor' in %s or $EDITOR", project.Name, configFileName)
+	}
+	fields, err := commandFields("$EDITOR", editor)
+	if err != nil {
+		return err
+	}
+	return p.inTerminal(project, append(fields, project.Path)...)
+}
+
+// commandFields splits a configured command line into its program and arguments.
+func commandFields(name, line string) ([]string, error) {
+	fields, err := execute.Split(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s command %q: %w", name, line, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty %s command", name)
+	}
+	return fields, nil
+}
+
+func (p *ProjectsPlugin) openTerminal(project Project) error {
+	return p.inTerminal(project)
+}
+
+// inTerminal starts the project's terminal at its path, optionally running a command.
+func (p *ProjectsPlugin) inTerminal(project Project, command ...string) error {
+	terminal := firstNonEmpty(project.Terminal, p.config.Terminal)
+	if terminal == "" {
+		return launch.InTerminal(project.Path, command...)
+	}
+
+	fields, err := commandFields("terminal", terminal)
+	if err != nil {
+		return err
+	}
+	argv := launch.TerminalArgs(fields, command...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = project.Path
+	return launch.Detached(cmd)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Update handles messages.
+func (p *ProjectsPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *ProjectsPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed action, if any.
+func (p *ProjectsPlugin) GetError() error {
+	return p.execErr
+}
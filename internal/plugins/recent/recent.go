@@ -0,0 +1,323 @@
+// Package recent implements the !rf plugin, which lists the documents
+// recently opened in desktop applications and opens them again.
+package recent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/desktop"
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!rf"
+
+const (
+	fileName = "recently-used.xbel"
+
+	infoIdentifier = "rf_info"
+
+	// maxResults caps the documents listed for a query.
+	maxResults = 100
+)
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionOpenWithApp = "open with last app"
+	actionCopyPath    = "copy path"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Recent Files",
+	Description: "Reopen documents recently used in desktop applications.",
+	Keyword:     Keyword,
+	Flag:        "recent",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// RecentFilesPlugin implements the plugin.Plugin interface for the recently
+// used files list shared by GTK and other desktop applications.
+type RecentFilesPlugin struct {
+	mu      sync.Mutex
+	path    string
+	docs    []document
+	modTime time.Time
+	chooser desktop.Chooser
+	err     error
+}
+
+// New creates a new instance of the RecentFilesPlugin.
+func New() *RecentFilesPlugin {
+	return &RecentFilesPlugin{path: filepath.Join(xdg.DataHome, fileName)}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *RecentFilesPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *RecentFilesPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *RecentFilesPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *RecentFilesPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists the recently used documents whose name or location contains
+// every word of the query, most recently used first.
+func (p *RecentFilesPlugin) GetResults(query string) ([]plugin.Result, error) {
+	if apps, ok := p.chooser.Results(query); ok {
+		return apps, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reload()
+
+	words := strings.Fields(strings.ToLower(query))
+	results := []plugin.Result{}
+	for _, doc := range p.docs {
+		if len(results) >= maxResults {
+			break
+		}
+		if !matches(doc, words) {
+			continue
+		}
+		results = append(results, plugin.Result{
+			Title:       doc.name,
+			Description: description(doc),
+			Identifier:  doc.uri,
+		})
+	}
+
+	if len(results) == 0 {
+		info := plugin.Result{
+			Title:       "No recent files",
+			Description: fmt.Sprintf("Nothing recorded in %s yet", p.path),
+			Identifier:  infoIdentifier,
+		}
+		if len(words) > 0 {
+			info.Description = fmt.Sprintf("Nothing matches '%s'", query)
+		}
+		results = append(results, info)
+	}
+	if p.err != nil {
+		results = append(results, plugin.Result{Title: "Error", Description: p.err.Error(), Identifier: infoIdentifier})
+	}
+	return results, nil
+}
+
+func matches(doc document, words []string) bool {
+	text := strings.ToLower(doc.name + " " + doc.uri)
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// description shows where a document is, the applications it was opened with
+// and when it was last used.
+func description(doc document) string {
+	location := doc.uri
+	if doc.path != "" {
+		location = filepath.Dir(doc.path)
+	}
+	parts := []string{location}
+	if len(doc.apps) > 0 {
+		names := make([]string, len(doc.apps))
+		for i, a := range doc.apps {
+			names[i] = a.name
+		}
+		parts = append(parts, strings.Join(names, ", "))
+	}
+	if !doc.used.IsZero() {
+		parts = append(parts, doc.used.Local().Format("Jan 2 2006 15:04"))
+	}
+	if doc.path != "" {
+		if _, err := os.Stat(doc.path); err != nil {
+			parts = append(parts, "no longer exists")
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Preview describes the document and the applications it was opened with.
+func (p *RecentFilesPlugin) Preview(identifier string) string {
+	doc, ok := p.document(identifier)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(doc.name + "\n\n")
+	if doc.path != "" {
+		b.WriteString("Path: " + doc.path + "\n")
+	} else {
+		b.WriteString("URI: " + doc.uri + "\n")
+	}
+	if doc.mimeType != "" {
+		b.WriteString("Type: " + doc.mimeType + "\n")
+	}
+	if !doc.used.IsZero() {
+		b.WriteString("Last used: " + doc.used.Local().Format("Mon Jan 2 2006 15:04") + "\n")
+	}
+	if len(doc.apps) > 0 {
+		b.WriteString("\nOpened with:\n")
+		for _, a := range doc.apps {
+			fmt.Fprintf(&b, "  %s (%d×): %s\n", a.name, a.count, strings.Join(a.command(doc), " "))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// reload reads the list when it changed since the last read, as it does each
+// time an application opens a file. p.mu must be held.
+func (p *RecentFilesPlugin) reload() {
+	info, err := os.Stat(p.path)
+	if err == nil && info.ModTime().Equal(p.modTime) {
+		return
+	}
+	docs, err := readXBEL(p.path)
+	if err != nil {
+		p.err = fmt.Errorf("could not read %s: %w", p.path, err)
+		zap.L().Warn("Could not read the recently used files.", zap.String("path", p.path), zap.Error(err))
+		return
+	}
+	p.docs, p.err = docs, nil
+	if info != nil {
+		p.modTime = info.ModTime()
+	}
+}
+
+// document returns the document with the given URI.
+func (p *RecentFilesPlugin) document(uri string) (document, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, doc := range p.docs {
+		if doc.uri == uri {
+			return doc, true
+		}
+	}
+	return document{}, false
+}
+
+// Execute opens the selected document with its default application, or with
+// the application chosen after the open with action, and quits.
+func (p *RecentFilesPlugin) Execute(identifier string) tea.Cmd {
+	if handled, err := p.chooser.Execute(identifier); handled {
+		if err != nil {
+			p.fail(err)
+			return nil
+		}
+		return tea.Quit
+	}
+	doc, ok := p.document(identifier)
+	if !ok {
+		return nil // Info items.
+	}
+	if err := launch.OpenURL(doc.uri); err != nil {
+		p.fail(fmt.Errorf("could not open %s: %w", doc.name, err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Actions lists the actions on the selected document.
+func (p *RecentFilesPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionOpenWithApp, Keys: []string{"ctrl+e"}},
+		{Name: desktop.OpenWithAction, Keys: []string{"alt+o"}},
+		{Name: actionCopyPath, Keys: []string{"ctrl+y"}},
+	}
+}
+
+// RunAction opens the selected document with the application that last
+// opened it, or copies its path, and quits. Open with lists the applications
+// able to open it instead, to open it with the one selected next.
+func (p *RecentFilesPlugin) RunAction(name, identifier string) tea.Cmd {
+	doc, ok := p.document(identifier)
+	if !ok {
+		return nil
+	}
+	switch name {
+	case actionOpenWithApp:
+		if len(doc.apps) == 0 {
+			p.fail(fmt.Errorf("no application is recorded for %s", doc.name))
+			return nil
+		}
+		last := doc.apps[0]
+		err := launch.Run(plugin.Command{Argv: last.command(doc)})
+		if err != nil {
+			p.fail(fmt.Errorf("could not open %s with %s: %w", doc.name, last.name, err))
+			return nil
+		}
+	case desktop.OpenWithAction:
+		target := doc.path
+		if target == "" {
+			target = doc.uri
+		}
+		mimeType := doc.mimeType
+		if mimeType == "" {
+			mimeType = desktop.MimeType(target)
+		}
+		if err := p.chooser.Open(target, mimeType); err != nil {
+			p.fail(err)
+		}
+		return plugin.Refresh()
+	case actionCopyPath:
+		target := doc.path
+		if target == "" {
+			target = doc.uri
+		}
+		if err := clipboard.WriteAll(target); err != nil {
+			p.fail(err)
+			return nil
+		}
+	default:
+		return nil
+	}
+	return tea.Quit
+}
+
+// fail records the error of an action, shown with the results.
+func (p *RecentFilesPlugin) fail(err error) {
+	zap.L().Error("Recent files action failed.", zap.Error(err))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Update handles messages.
+func (p *RecentFilesPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *RecentFilesPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed read or action, if any.
+func (p *RecentFilesPlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
@@ -0,0 +1,149 @@
+package recent
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/execute"
+)
+
+// xbel is the recently used files list GTK applications keep, an XML
+// Bookmark Exchange Language file. Only the parts read are declared; elements
+// are matched by local name, whatever their namespace.
+type xbel struct {
+	Bookmarks []struct {
+		Href     string `xml:"href,attr"`
+		Modified string `xml:"modified,attr"`
+		Visited  string `xml:"visited,attr"`
+		Metadata []struct {
+			MimeType struct {
+				Type string `xml:"type,attr"`
+			} `xml:"mime-type"`
+			Applications []struct {
+				Name     string `xml:"name,attr"`
+				Exec     string `xml:"exec,attr"`
+				Modified string `xml:"modified,attr"`
+				Count    int    `xml:"count,attr"`
+			} `xml:"applications>application"`
+		} `xml:"info>metadata"`
+	} `xml:"bookmark"`
+}
+
+// document is a recently used file.
+type document struct {
+	uri      string
+	path     string // Empty for files not on the local file system, e.g. sftp://.
+	name     string
+	mimeType string
+	used     time.Time
+	apps     []application // Most recently used first.
+}
+
+// application is a program a document was opened with.
+type application struct {
+	name  string
+	exec  string // Command line with a field code like %u for the document.
+	used  time.Time
+	count int
+}
+
+// readXBEL reads the documents of the recently used files list at path, most
+// recently used first. A missing file has none.
+func readXBEL(path string) ([]document, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list xbel
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	docs := make([]document, 0, len(list.Bookmarks))
+	for _, b := range list.Bookmarks {
+		doc := document{uri: b.Href, name: b.Href}
+		if u, err := url.Parse(b.Href); err == nil {
+			if u.Scheme == "file" {
+				doc.path = u.Path
+			}
+			if base := u.Path[strings.LastIndex(u.Path, "/")+1:]; base != "" {
+				doc.name = base
+			}
+		}
+		doc.used = latest(parseTime(b.Modified), parseTime(b.Visited))
+		for _, m := range b.Metadata {
+			if m.MimeType.Type != "" {
+				doc.mimeType = m.MimeType.Type
+			}
+			for _, a := range m.Applications {
+				used := parseTime(a.Modified)
+				doc.apps = append(doc.apps, application{name: a.Name, exec: a.Exec, used: used, count: a.Count})
+				doc.used = latest(doc.used, used)
+			}
+		}
+		sort.SliceStable(doc.apps, func(i, j int) bool { return doc.apps[i].used.After(doc.apps[j].used) })
+		docs = append(docs, doc)
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].used.After(docs[j].used) })
+	return docs, nil
+}
+
+// parseTime reads an ISO 8601 timestamp of the list, zero if missing or malformed.
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// command returns the program and arguments opening doc with app. GTK stores
+// the command line quoted as a single shell word, e.g. "'evince %u'"; %u and
+// %f stand for the document's URI and path, and the URI is appended if
+// neither appears.
+func (app application) command(doc document) []string {
+	line := app.exec
+	if words, err := execute.Split(line); err == nil && len(words) == 1 {
+		line = words[0]
+	}
+	fields, err := execute.Split(line)
+	if err != nil {
+		return nil
+	}
+
+	var argv []string
+	substituted := false
+	for _, field := range fields {
+		switch field {
+		case "%u", "%U":
+			argv, substituted = append(argv, doc.uri), true
+		case "%f", "%F":
+			target := doc.path
+			if target == "" {
+				target = doc.uri
+			}
+			argv, substituted = append(argv, target), true
+		default:
+			if strings.HasPrefix(field, "%") && len(field) == 2 {
+				continue // Other field codes, like %i or %c, have nothing to stand for.
+			}
+			argv = append(argv, field)
+		}
+	}
+	if len(argv) > 0 && !substituted {
+		argv = append(argv, doc.uri)
+	}
+	return argv
+}
@@ -0,0 +1,254 @@
+package regextester
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!re"
+
+// sampleSeparator splits the pattern from the sample text. Surrounding spaces are
+// required so that '|' can still be used for alternation inside the pattern.
+const sampleSeparator = " | "
+
+const (
+	maxMatches        = 50
+	maxSummaryRunes   = 200
+	infoIdentifier    = "re_info"
+	errorIdentifier   = "re_error"
+	summaryIdentifier = "re_summary"
+	// copyIdentifierBase prefixes the rows of matches and groups, followed by
+	// the row number, copyTextSep and the text copied on Execute.
+	copyIdentifierBase = "re_copy_"
+	copyTextSep        = ":"
+	// noMatchIdentifierBase prefixes the rows of groups left unmatched,
+	// followed by the match and group numbers.
+	noMatchIdentifierBase = "re_no_match_"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Regex Tester",
+	Description: "Test regular expressions against sample text or the clipboard.",
+	Keyword:     Keyword,
+	Flag:        "re",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// RegexTesterPlugin shows matches and capture groups of a pattern.
+type RegexTesterPlugin struct {
+	highlightStyle lipgloss.Style
+}
+
+// New creates a new instance of the RegexTesterPlugin.
+func New() *RegexTesterPlugin {
+	p := &RegexTesterPlugin{}
+	p.SetTheme(theme.DefaultTheme)
+	return p
+}
+
+// SetTheme sets the colors used to highlight matches.
+func (p *RegexTesterPlugin) SetTheme(t theme.Theme) {
+	p.highlightStyle = lipgloss.NewStyle().
+		Background(t.Base0B).
+		Foreground(t.Base00)
+}
+
+// Metadata returns the plugin's metadata.
+func (p *RegexTesterPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *RegexTesterPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *RegexTesterPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *RegexTesterPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults compiles the pattern and lists every match with its capture groups.
+// The query has the form "<pattern> | <sample>"; without a sample the clipboard is used.
+func (p *RegexTesterPlugin) GetResults(query string) ([]plugin.Result, error) {
+	pattern, sample, hasSample := strings.Cut(query, sampleSeparator)
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return []plugin.Result{{
+			Title:       "Regex Tester",
+			Description: `Enter a pattern and sample text (e.g., !re ^foo(\d+) | foo123 foo9 bar); without sample text the clipboard is used`,
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return []plugin.Result{{
+			Title:       "Invalid pattern",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+
+	source := "input"
+	if !hasSample {
+		clip, err := clipboard.ReadAll()
+		if err != nil {
+			return []plugin.Result{{
+				Title:       "Could not read clipboard",
+				Description: err.Error(),
+				Identifier:  errorIdentifier,
+			}}, nil
+		}
+		sample = clip
+		source = "clipboard"
+	}
+
+	matches := re.FindAllStringSubmatchIndex(sample, maxMatches)
+	groupNames := re.SubexpNames()
+
+	results := make([]plugin.Result, 0, 1+len(matches)*len(groupNames))
+	results = append(results, plugin.Result{
+		Title:       p.highlight(sample, matches),
+		Description: fmt.Sprintf("%s | %d group(s) | sample from %s", pluralizeMatches(len(matches)), re.NumSubexp(), source),
+		Identifier:  summaryIdentifier,
+	})
+
+	for i, loc := range matches {
+		matchText := sample[loc[0]:loc[1]]
+		results = append(results, copyResult(len(results),
+			fmt.Sprintf("%q", matchText),
+			fmt.Sprintf("Match %d at %d-%d | enter copies the match", i+1, loc[0], loc[1]),
+			matchText,
+		))
+
+		for g := 1; g < len(groupNames); g++ {
+			start, end := loc[2*g], loc[2*g+1]
+			label := fmt.Sprintf("$%d", g)
+			if groupNames[g] != "" {
+				label = fmt.Sprintf("$%d (%s)", g, groupNames[g])
+			}
+			if start < 0 {
+				results = append(results, plugin.Result{
+					Title:       fmt.Sprintf("  %s: <no match>", label),
+					Description: fmt.Sprintf("Group of match %d", i+1),
+					Identifier:  fmt.Sprintf("%s%d_%d", noMatchIdentifierBase, i+1, g),
+				})
+				continue
+			}
+			groupText := sample[start:end]
+			results = append(results, copyResult(len(results),
+				fmt.Sprintf("  %s: %q", label, groupText),
+				fmt.Sprintf("Group of match %d | enter copies the group", i+1),
+				groupText,
+			))
+		}
+	}
+
+	return results, nil
+}
+
+// copyResult creates the result in row n whose execution copies text to the
+// clipboard. The text is part of the identifier, so a result still copies its
+// own text once later queries ran.
+func copyResult(n int, title, description, text string) plugin.Result {
+	identifier := fmt.Sprintf("%s%d%s%s", copyIdentifierBase, n, copyTextSep, text)
+	return plugin.Result{Title: title, Description: description, Identifier: identifier}
+}
+
+// highlight renders the sample on one line with every match highlighted,
+// truncated to maxSummaryRunes so long clipboard contents stay readable.
+func (p *RegexTesterPlugin) highlight(sample string, matches [][]int) string {
+	limit := len(sample)
+	truncated := false
+	if runeCount := 0; len(sample) > maxSummaryRunes {
+		for i := range sample {
+			if runeCount == maxSummaryRunes {
+				limit, truncated = i, true
+				break
+			}
+			runeCount++
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range matches {
+		if loc[0] >= limit {
+			break
+		}
+		end := min(loc[1], limit)
+		b.WriteString(flatten(sample[last:loc[0]]))
+		b.WriteString(p.highlightStyle.Render(flatten(sample[loc[0]:end])))
+		last = end
+	}
+	b.WriteString(flatten(sample[last:limit]))
+	if truncated {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+// flatten replaces line breaks and tabs so the text fits on a single list row.
+func flatten(s string) string {
+	return strings.NewReplacer("\n", "⏎", "\r", "", "\t", " ").Replace(s)
+}
+
+func pluralizeMatches(n int) string {
+	switch {
+	case n == 0:
+		return "No matches"
+	case n == 1:
+		return "1 match"
+	case n >= maxMatches:
+		return fmt.Sprintf("%d+ matches", maxMatches)
+	default:
+		return fmt.Sprintf("%d matches", n)
+	}
+}
+
+// Execute copies the selected match or group to the clipboard and quits.
+func (p *RegexTesterPlugin) Execute(identifier string) tea.Cmd {
+	rest, ok := strings.CutPrefix(identifier, copyIdentifierBase)
+	if !ok {
+		return nil // Do nothing for info/error/summary items.
+	}
+	_, text, ok := strings.Cut(rest, copyTextSep)
+	if !ok {
+		return nil
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		zap.L().Error("Failed to copy regex match to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *RegexTesterPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *RegexTesterPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as errors are reported through results.
+func (p *RegexTesterPlugin) GetError() error {
+	return nil
+}
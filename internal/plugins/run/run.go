@@ -0,0 +1,252 @@
+// Package run implements the !run plugin, which starts any executable on
+// $PATH with arguments, like the run dialog of a desktop environment.
+package run
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/execute"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!run"
+
+const (
+	infoIdentifier  = "run_info"
+	errorIdentifier = "run_error"
+)
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionTerminal = "run in terminal"
+	actionDetached = "run detached"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Run",
+	Description: "Run executables on $PATH with arguments, detached or in a terminal.",
+	Keyword:     Keyword,
+	Flag:        "run",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// RunPlugin implements the plugin.Plugin interface for running executables.
+type RunPlugin struct {
+	mu          sync.Mutex
+	executables launch.Executables
+	terminal    map[string]bool // Whether each configured or known program runs in a terminal.
+	execErr     error
+}
+
+// New creates a new instance of the RunPlugin. Programs named in terminal run
+// in a terminal and programs named in detached run without one, overriding the
+// built-in list of terminal programs.
+func New(terminal, detached []string) *RunPlugin {
+	p := &RunPlugin{terminal: make(map[string]bool)}
+	for _, name := range launch.TerminalPrograms {
+		p.terminal[name] = true
+	}
+	for _, name := range terminal {
+		p.terminal[name] = true
+	}
+	for _, name := range detached {
+		p.terminal[name] = false
+	}
+	return p
+}
+
+// Metadata returns the plugin's metadata.
+func (p *RunPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *RunPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *RunPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init indexes the executables on $PATH.
+func (p *RunPlugin) Init() tea.Cmd {
+	p.executables.Len()
+	return nil
+}
+
+// GetResults fuzzy matches the first word of the query against the executables
+// on $PATH. Once arguments follow it, the program must be named exactly.
+func (p *RunPlugin) GetResults(query string) ([]plugin.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	argv, err := execute.Split(query)
+	if err != nil {
+		return []plugin.Result{{
+			Title:       "Invalid command line",
+			Description: err.Error(),
+			Identifier:  errorIdentifier,
+		}}, nil
+	}
+	if len(argv) == 0 {
+		return []plugin.Result{{
+			Title:       "Run a program",
+			Description: fmt.Sprintf("Type the name of one of %d executables on $PATH, then its arguments", p.executables.Len()),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	var results []plugin.Result
+	if hasArguments(query) {
+		path, ok := p.lookup(argv[0])
+		if !ok {
+			return []plugin.Result{{
+				Title:       fmt.Sprintf("%s: command not found", argv[0]),
+				Description: "Not an executable on $PATH",
+				Identifier:  errorIdentifier,
+			}}, nil
+		}
+		results = append(results, p.result(argv[0], path, expandArgs(argv[1:])))
+	} else {
+		for _, e := range p.executables.Find(argv[0]) {
+			results = append(results, p.result(e.Name, e.Path, nil))
+		}
+		if len(results) == 0 {
+			results = append(results, plugin.Result{
+				Title:       "No matching executables",
+				Description: fmt.Sprintf("Nothing on $PATH matches '%s'", argv[0]),
+				Identifier:  infoIdentifier,
+			})
+		}
+	}
+
+	if p.execErr != nil {
+		results = append(results, plugin.Result{
+			Title:       "Last command failed",
+			Description: p.execErr.Error(),
+			Identifier:  errorIdentifier,
+		})
+	}
+	return results, nil
+}
+
+// result describes running the executable at path with args.
+func (p *RunPlugin) result(name, path string, args []string) plugin.Result {
+	command := plugin.Command{Argv: append([]string{path}, args...), Detach: true, Terminal: p.terminal[name]}
+	where := "detached"
+	if command.Terminal {
+		where = "in a terminal"
+	}
+	title := name
+	if len(args) > 0 {
+		title = execute.Join(append([]string{name}, args...))
+	}
+	return plugin.Result{
+		Title:       title,
+		Description: fmt.Sprintf("%s | enter runs %s", path, where),
+		Identifier:  command.Encode(),
+	}
+}
+
+// hasArguments reports whether the query continues after the program name,
+// even if only with a space.
+func hasArguments(query string) bool {
+	return strings.ContainsAny(strings.TrimLeft(query, " \t"), " \t")
+}
+
+// expandArgs replaces a leading "~" in arguments by the home directory, as a
+// shell would for "!run mpv ~/video.mkv".
+func expandArgs(args []string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return args
+	}
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		if rest, ok := strings.CutPrefix(arg, "~"); ok && (rest == "" || rest[0] == '/') {
+			arg = home + rest
+		}
+		expanded[i] = arg
+	}
+	return expanded
+}
+
+// lookup returns the path of the named executable, which may also be a path.
+func (p *RunPlugin) lookup(name string) (string, bool) {
+	if strings.Contains(name, "/") {
+		name = expandArgs([]string{name})[0]
+	}
+	return p.executables.Lookup(name)
+}
+
+// Execute starts the selected command and quits.
+func (p *RunPlugin) Execute(identifier string) tea.Cmd {
+	command, ok := plugin.DecodeCommand(identifier)
+	if !ok {
+		return nil // Do nothing for info/error items.
+	}
+	return p.run(command)
+}
+
+// Actions lists the actions overriding where a command runs.
+func (p *RunPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionTerminal, Keys: []string{"ctrl+t"}},
+		{Name: actionDetached, Keys: []string{"ctrl+d"}},
+	}
+}
+
+// RunAction starts the selected command in a terminal or detached.
+func (p *RunPlugin) RunAction(name, identifier string) tea.Cmd {
+	command, ok := plugin.DecodeCommand(identifier)
+	if !ok {
+		return nil
+	}
+	switch name {
+	case actionTerminal:
+		command.Terminal = true
+	case actionDetached:
+		command.Terminal = false
+	default:
+		return nil
+	}
+	return p.run(command)
+}
+
+// run starts command, recording the error if it fails.
+func (p *RunPlugin) run(command plugin.Command) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := launch.Run(command); err != nil {
+		p.execErr = err
+		zap.L().Error("Failed to run command.", zap.Strings("argv", command.Argv), zap.Error(err))
+		return nil
+	}
+	p.execErr = nil
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *RunPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *RunPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed command, if any.
+func (p *RunPlugin) GetError() error {
+	return p.execErr
+}
@@ -0,0 +1,248 @@
+// Package snippets implements the !sn plugin, which stores named snippets of
+// text, such as bookmarks or canned replies, and copies them on selection.
+package snippets
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!sn"
+
+const (
+	infoIdentifier    = "sn_info"
+	errorIdentifier   = "sn_error"
+	snippetIdentifier = "sn:"
+	addIdentifier     = "sn_add:"
+
+	addCommand = "add"
+	// separator separates the name of a snippet from its value in "add name :: value".
+	separator = "::"
+)
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionDelete = "delete snippet"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Snippets",
+	Description: "Store named snippets and bookmarks, and copy them to the clipboard.",
+	Keyword:     Keyword,
+	Flag:        "snippets",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// SnippetsPlugin implements the plugin.Plugin interface for text snippets.
+type SnippetsPlugin struct {
+	store *store
+
+	mu  sync.Mutex
+	err error // Of the last save, copy or deletion; guarded by mu.
+}
+
+// New creates a new instance of the SnippetsPlugin.
+func New() *SnippetsPlugin {
+	return &SnippetsPlugin{store: loadStore()}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *SnippetsPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *SnippetsPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *SnippetsPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *SnippetsPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults offers to add a snippet for "add name :: value" queries, and
+// otherwise lists the snippets whose name fuzzily matches the query, followed
+// by those whose value contains it.
+func (p *SnippetsPlugin) GetResults(query string) ([]plugin.Result, error) {
+	query = strings.TrimSpace(query)
+	if rest, ok := cutCommand(query, addCommand); ok {
+		return p.addResults(rest), nil
+	}
+
+	snippets := p.store.list()
+	if len(snippets) == 0 {
+		return []plugin.Result{{
+			Title:       "No snippets yet",
+			Description: fmt.Sprintf("Add one with: %s %s name %s value", Keyword, addCommand, separator),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	var matched []Snippet
+	if query == "" {
+		matched = snippets
+	} else {
+		names := make([]string, len(snippets))
+		for i, s := range snippets {
+			names[i] = s.Name
+		}
+		seen := make(map[int]bool)
+		for _, m := range fuzzy.Find(query, names) {
+			matched = append(matched, snippets[m.Index])
+			seen[m.Index] = true
+		}
+		lower := strings.ToLower(query)
+		for i, s := range snippets {
+			if !seen[i] && strings.Contains(strings.ToLower(s.Value), lower) {
+				matched = append(matched, s)
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return []plugin.Result{{
+			Title:       "No matching snippets",
+			Description: fmt.Sprintf("Add one with: %s %s %s %s value", Keyword, addCommand, query, separator),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	results := make([]plugin.Result, 0, len(matched)+1)
+	for _, s := range matched {
+		results = append(results, plugin.Result{
+			Title:       s.Name,
+			Description: oneLine(s.Value),
+			Identifier:  snippetIdentifier + s.Name,
+		})
+	}
+	if err := p.GetError(); err != nil {
+		results = append(results, plugin.Result{Title: "Error", Description: err.Error(), Identifier: errorIdentifier})
+	}
+	return results, nil
+}
+
+// addResults offers to add or replace the snippet described by "name :: value".
+func (p *SnippetsPlugin) addResults(rest string) []plugin.Result {
+	name, value, ok := strings.Cut(rest, separator)
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || name == "" || value == "" {
+		return []plugin.Result{{
+			Title:       "Add a snippet",
+			Description: fmt.Sprintf("Type %s name %s value, e.g. %s docs %s https://example.com/docs", addCommand, separator, addCommand, separator),
+			Identifier:  infoIdentifier,
+		}}
+	}
+
+	title := fmt.Sprintf("Add snippet '%s'", name)
+	if existing, found := p.store.get(name); found {
+		title = fmt.Sprintf("Replace snippet '%s' (was: %s)", name, oneLine(existing.Value))
+	}
+	return []plugin.Result{{
+		Title:       title,
+		Description: oneLine(value),
+		Identifier:  addIdentifier + name + separator + value,
+	}}
+}
+
+// cutCommand returns the rest of the query if its first word is command.
+func cutCommand(query, command string) (string, bool) {
+	first, rest, _ := strings.Cut(query, " ")
+	if first != command {
+		return "", false
+	}
+	return rest, true
+}
+
+// oneLine shows a value's line breaks as ⏎, for its row in the list.
+func oneLine(value string) string {
+	return strings.ReplaceAll(value, "\n", " ⏎ ")
+}
+
+// Execute copies the selected snippet to the clipboard, or saves the snippet
+// being added, and quits.
+func (p *SnippetsPlugin) Execute(identifier string) tea.Cmd {
+	if rest, ok := strings.CutPrefix(identifier, addIdentifier); ok {
+		name, value, _ := strings.Cut(rest, separator)
+		err := p.store.put(Snippet{Name: name, Value: value, Updated: time.Now()})
+		p.setError(err)
+		if err != nil {
+			zap.L().Error("Failed to save snippet.", zap.String("name", name), zap.Error(err))
+			return nil
+		}
+		return tea.Quit
+	}
+
+	name, ok := strings.CutPrefix(identifier, snippetIdentifier)
+	if !ok {
+		return nil // Do nothing for info items.
+	}
+	s, found := p.store.get(name)
+	if !found {
+		return nil
+	}
+	err := clipboard.WriteAll(s.Value)
+	p.setError(err)
+	if err != nil {
+		zap.L().Error("Failed to copy snippet to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Actions lists the actions on the selected snippet.
+func (p *SnippetsPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionDelete, Keys: []string{"ctrl+x"}},
+	}
+}
+
+// RunAction deletes the selected snippet and refreshes the list.
+func (p *SnippetsPlugin) RunAction(name, identifier string) tea.Cmd {
+	snippet, ok := strings.CutPrefix(identifier, snippetIdentifier)
+	if name != actionDelete || !ok {
+		return nil
+	}
+	err := p.store.remove(snippet)
+	p.setError(err)
+	if err != nil {
+		zap.L().Error("Failed to delete snippet.", zap.String("name", snippet), zap.Error(err))
+	}
+	return plugin.Refresh()
+}
+
+// Update handles messages.
+func (p *SnippetsPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *SnippetsPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed save or copy, if any.
+func (p *SnippetsPlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *SnippetsPlugin) setError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
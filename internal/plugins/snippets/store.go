@@ -0,0 +1,120 @@
+package snippets
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"go.uber.org/zap"
+)
+
+const (
+	storeDir      = "incipio"
+	storeFileName = "snippets.json"
+)
+
+// Snippet is a named piece of text, such as a bookmark or a canned reply.
+type Snippet struct {
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Updated time.Time `json:"updated"`
+}
+
+// store holds the snippets persisted in an XDG data file, sorted by name.
+type store struct {
+	mu       sync.Mutex
+	path     string
+	snippets []Snippet
+}
+
+// loadStore reads the snippets file. A missing or unreadable file yields no snippets.
+func loadStore() *store {
+	s := &store{}
+
+	path, err := xdg.DataFile(filepath.Join(storeDir, storeFileName))
+	if err != nil {
+		zap.L().Warn("Could not determine snippets path, snippets will not be saved.", zap.Error(err))
+		return s
+	}
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s
+	}
+	if err != nil {
+		zap.L().Warn("Could not read snippets.", zap.String("path", path), zap.Error(err))
+		return s
+	}
+	if err := json.Unmarshal(data, &s.snippets); err != nil {
+		zap.L().Warn("Could not parse snippets, starting fresh.", zap.String("path", path), zap.Error(err))
+		s.snippets = nil
+	}
+	slices.SortFunc(s.snippets, compareNames)
+	return s
+}
+
+func compareNames(a, b Snippet) int {
+	return cmp.Compare(a.Name, b.Name)
+}
+
+// list returns all snippets, sorted by name.
+func (s *store) list() []Snippet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.snippets)
+}
+
+// get returns the snippet with the given name.
+func (s *store) get(name string) (Snippet, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, found := slices.BinarySearchFunc(s.snippets, Snippet{Name: name}, compareNames); found {
+		return s.snippets[i], true
+	}
+	return Snippet{}, false
+}
+
+// put adds a snippet, or replaces the one with the same name, and saves.
+func (s *store) put(snippet Snippet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, found := slices.BinarySearchFunc(s.snippets, snippet, compareNames); found {
+		s.snippets[i] = snippet
+	} else {
+		s.snippets = slices.Insert(s.snippets, i, snippet)
+	}
+	return s.saveLocked()
+}
+
+// remove deletes the snippet with the given name and saves.
+func (s *store) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, found := slices.BinarySearchFunc(s.snippets, Snippet{Name: name}, compareNames)
+	if !found {
+		return fmt.Errorf("no snippet named '%s'", name)
+	}
+	s.snippets = slices.Delete(s.snippets, i, i+1)
+	return s.saveLocked()
+}
+
+func (s *store) saveLocked() error {
+	if s.path == "" {
+		return fmt.Errorf("snippets path is unknown")
+	}
+
+	data, err := json.MarshalIndent(s.snippets, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write atomically so a crash never leaves truncated snippets behind.
+	return atomicfile.WriteFile(s.path, data, 0o600)
+}
@@ -0,0 +1,339 @@
+package symbols
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+	"golang.org/x/text/unicode/runenames"
+)
+
+const Keyword = "!sym"
+
+// UnicodeKeyword is the keyword of the plugin searching all of Unicode alike.
+const UnicodeKeyword = "!u"
+
+const infoIdentifier = "sym_info"
+
+// maxResults caps the characters listed for a query.
+const maxResults = 50
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionCodepoint = "copy codepoint"
+	actionHTML      = "copy html entity"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Symbols",
+	Description: "Search Unicode characters by name and copy them, their codepoint or HTML entity.",
+	Keyword:     Keyword,
+	Flag:        "sym",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+var unicodeMetadata = plugin.Metadata{
+	Name:        "Unicode",
+	Description: "Search all Unicode characters by name, in codepoint order, and copy them.",
+	Keyword:     UnicodeKeyword,
+	Flag:        "unicode",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// symbol is a named Unicode character.
+type symbol struct {
+	r    rune
+	name string
+	rank int // Lower sorts first among equally good matches; see categoryRank.
+}
+
+// names indexes every character with a name of its own in the Unicode names
+// list. Ranges named by pattern, like CJK ideographs, and unnamed code points,
+// like controls, are left out. It is built on the first search.
+var names = sync.OnceValue(func() []symbol {
+	var symbols []symbol
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		name := runenames.Name(r)
+		if name == "" || strings.HasPrefix(name, "<") {
+			continue
+		}
+		symbols = append(symbols, symbol{r: r, name: name, rank: categoryRank(r)})
+	}
+	return symbols
+})
+
+// categoryRank puts symbols and punctuation, which this plugin is for, before
+// letters and marks of the same name, e.g. "∑" before "Σ" for "sum".
+func categoryRank(r rune) int {
+	switch {
+	case unicode.Is(unicode.Sm, r):
+		return 0
+	case unicode.IsSymbol(r), unicode.IsPunct(r):
+		return 1
+	case unicode.IsNumber(r):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// category describes the general category of r.
+func category(r rune) string {
+	switch {
+	case unicode.Is(unicode.Sm, r):
+		return "Math symbol"
+	case unicode.Is(unicode.Sc, r):
+		return "Currency symbol"
+	case unicode.IsSymbol(r):
+		return "Symbol"
+	case unicode.IsPunct(r):
+		return "Punctuation"
+	case unicode.IsNumber(r):
+		return "Number"
+	case unicode.IsLetter(r):
+		return "Letter"
+	case unicode.IsMark(r):
+		return "Combining mark"
+	case unicode.IsSpace(r):
+		return "Space"
+	default:
+		return "Other"
+	}
+}
+
+// SymbolsPlugin implements the plugin.Plugin interface for searching Unicode characters.
+type SymbolsPlugin struct {
+	metadata     plugin.Metadata
+	symbolsFirst bool // Rank symbols and punctuation before letters; see categoryRank.
+	err          error
+}
+
+// New creates a new instance of the SymbolsPlugin for symbols (!sym).
+func New() *SymbolsPlugin {
+	return &SymbolsPlugin{metadata: metadata, symbolsFirst: true}
+}
+
+// NewUnicode creates a character picker (!u) treating all characters alike:
+// "greek small letter" lists the Greek letters in codepoint order.
+func NewUnicode() *SymbolsPlugin {
+	return &SymbolsPlugin{metadata: unicodeMetadata}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *SymbolsPlugin) Metadata() plugin.Metadata {
+	return p.metadata
+}
+
+// Name returns the plugin's name.
+func (p *SymbolsPlugin) Name() string {
+	return p.metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *SymbolsPlugin) Keyword() string {
+	return p.metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *SymbolsPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists the characters whose name contains every word of the
+// query as a word prefix, e.g. "right arrow" finds "→ RIGHTWARDS ARROW". A
+// query of a single character, or a codepoint like U+2192, shows that character.
+func (p *SymbolsPlugin) GetResults(query string) ([]plugin.Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []plugin.Result{{
+			Title:       "Search Unicode characters by name",
+			Description: fmt.Sprintf("e.g. %[1]s right arrow, %[1]s integral, %[1]s U+2192 | enter copies the character", p.metadata.Keyword),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	if r, ok := lookup(query); ok {
+		return []plugin.Result{result(symbol{r: r, name: cmp.Or(runenames.Name(r), "<unnamed>")})}, nil
+	}
+
+	type match struct {
+		symbol
+		exact int // Query words matching whole words of the name.
+	}
+	words := strings.Fields(strings.ToUpper(query))
+	var matches []match
+	for _, s := range names() {
+		if exact, ok := matchWords(s.name, words); ok {
+			matches = append(matches, match{s, exact})
+		}
+	}
+	if len(matches) == 0 {
+		return []plugin.Result{{
+			Title:       "No character found",
+			Description: fmt.Sprintf("No Unicode name contains %q", query),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	// Symbols first, then names with fewer words besides the query, e.g.
+	// "→ RIGHTWARDS ARROW" before "↔ LEFT RIGHT ARROW", then names matching
+	// more query words exactly. The Unicode picker keeps codepoint order
+	// instead, so alphabets and blocks read in sequence.
+	if p.symbolsFirst {
+		slices.SortStableFunc(matches, func(a, b match) int {
+			return cmp.Or(
+				cmp.Compare(a.rank, b.rank),
+				cmp.Compare(strings.Count(a.name, " "), strings.Count(b.name, " ")),
+				cmp.Compare(b.exact, a.exact),
+				cmp.Compare(len(a.name), len(b.name)),
+			)
+		})
+	}
+	results := make([]plugin.Result, 0, min(len(matches), maxResults))
+	for _, m := range matches[:min(len(matches), maxResults)] {
+		results = append(results, result(m.symbol))
+	}
+	return results, nil
+}
+
+// lookup returns the character a query names directly: a single character,
+// or a codepoint written U+2192 or 0x2192.
+func lookup(query string) (rune, bool) {
+	if r, size := utf8.DecodeRuneInString(query); size == len(query) && r != utf8.RuneError && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return r, true
+	}
+	upper := strings.ToUpper(query)
+	hex, ok := strings.CutPrefix(upper, "U+")
+	if !ok {
+		hex, ok = strings.CutPrefix(upper, "0X")
+	}
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// matchWords reports whether every word is a prefix of a word of name, and
+// returns how many of them are whole words of it.
+func matchWords(name string, words []string) (exact int, ok bool) {
+	nameWords := strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '-' })
+	for _, w := range words {
+		found := false
+		for _, nw := range nameWords {
+			if strings.HasPrefix(nw, w) {
+				found = true
+				if nw == w {
+					exact++
+					break
+				}
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return exact, true
+}
+
+// result shows a character with its codepoint, HTML entity and category. The
+// identifier is the character itself.
+func result(s symbol) plugin.Result {
+	return plugin.Result{
+		Title:       fmt.Sprintf("%s  %s", display(s.r), s.name),
+		Description: fmt.Sprintf("%s | %s | %s", codepoint(s.r), htmlEntity(s.r), category(s.r)),
+		Identifier:  string(s.r),
+	}
+}
+
+// display returns r printable in the list: combining marks are shown on a
+// dotted circle, and invisible characters as an open box.
+func display(r rune) string {
+	switch {
+	case unicode.IsMark(r):
+		return "◌" + string(r)
+	case !unicode.IsGraphic(r) || unicode.IsSpace(r):
+		return "␣"
+	default:
+		return string(r)
+	}
+}
+
+// codepoint formats r as U+XXXX.
+func codepoint(r rune) string {
+	return fmt.Sprintf("U+%04X", r)
+}
+
+// htmlEntity formats r as a numeric HTML character reference.
+func htmlEntity(r rune) string {
+	return fmt.Sprintf("&#x%X;", r)
+}
+
+// Execute copies the selected character to the clipboard and quits.
+func (p *SymbolsPlugin) Execute(identifier string) tea.Cmd {
+	return p.copy(identifier, func(r rune) string { return string(r) })
+}
+
+// Actions lists the actions copying another form of the selected character.
+func (p *SymbolsPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionCodepoint, Keys: []string{"alt+u"}},
+		{Name: actionHTML, Keys: []string{"alt+h"}},
+	}
+}
+
+// RunAction copies the codepoint or HTML entity of the selected character.
+func (p *SymbolsPlugin) RunAction(name, identifier string) tea.Cmd {
+	switch name {
+	case actionCodepoint:
+		return p.copy(identifier, codepoint)
+	case actionHTML:
+		return p.copy(identifier, htmlEntity)
+	default:
+		return nil
+	}
+}
+
+// copy copies format of the character identified by identifier and quits.
+func (p *SymbolsPlugin) copy(identifier string, format func(rune) string) tea.Cmd {
+	r, size := utf8.DecodeRuneInString(identifier)
+	if size != len(identifier) {
+		return nil // Do nothing for info items.
+	}
+	if err := clipboard.WriteAll(format(r)); err != nil {
+		p.err = err
+		zap.L().Error("Failed to copy character to clipboard.", zap.Error(err))
+		return nil
+	}
+	p.err = nil
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *SymbolsPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *SymbolsPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed copy, if any.
+func (p *SymbolsPlugin) GetError() error {
+	return p.err
+}
@@ -0,0 +1,346 @@
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/clipboard"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!sys"
+
+const (
+	refreshInterval = 2 * time.Second
+
+	summaryIdentifier  = "sys_summary"
+	copyIdentifierBase = "sys_copy_"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "System Info",
+	Description: "Show kernel, uptime, CPU, memory, disk usage and temperatures.",
+	Keyword:     Keyword,
+	Flag:        "sys",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// stat is one labelled line of system information.
+type stat struct {
+	label  string
+	value  string
+	detail string
+}
+
+// cpuSample holds the aggregate jiffies from /proc/stat.
+type cpuSample struct {
+	idle, total uint64
+}
+
+// SysInfoPlugin implements the plugin.Plugin interface for a live system dashboard.
+type SysInfoPlugin struct {
+	mu          sync.Mutex
+	lastCPU     cpuSample
+	copyTargets map[string]string // Maps result identifiers to the text copied on Execute.
+}
+
+// New creates a new instance of the SysInfoPlugin.
+func New() *SysInfoPlugin {
+	return &SysInfoPlugin{copyTargets: make(map[string]string)}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *SysInfoPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *SysInfoPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *SysInfoPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *SysInfoPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// RefreshInterval makes the application re-query the stats while the plugin is active.
+func (p *SysInfoPlugin) RefreshInterval() time.Duration {
+	return refreshInterval
+}
+
+// GetResults collects the current system stats, filtered by label or value.
+func (p *SysInfoPlugin) GetResults(query string) ([]plugin.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.collect()
+	clear(p.copyTargets)
+
+	lines := make([]string, 0, len(stats))
+	for _, s := range stats {
+		lines = append(lines, fmt.Sprintf("%s: %s", s.label, s.value))
+	}
+	p.copyTargets[summaryIdentifier] = strings.Join(lines, "\n")
+
+	results := []plugin.Result{{
+		Title:       "Copy summary",
+		Description: "Copy all stats as plain text for bug reports",
+		Identifier:  summaryIdentifier,
+	}}
+
+	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	for i, s := range stats {
+		if lowerQuery != "" && !strings.Contains(strings.ToLower(s.label+" "+s.value), lowerQuery) {
+			continue
+		}
+		identifier := fmt.Sprintf("%s%d", copyIdentifierBase, i)
+		p.copyTargets[identifier] = lines[i]
+
+		description := "enter copies the value"
+		if s.detail != "" {
+			description = s.detail + " | " + description
+		}
+		results = append(results, plugin.Result{
+			Title:       lines[i],
+			Description: description,
+			Identifier:  identifier,
+		})
+	}
+	return results, nil
+}
+
+// collect gathers all stats. Unavailable sources are skipped.
+func (p *SysInfoPlugin) collect() []stat {
+	var stats []stat
+
+	if name := osName(); name != "" {
+		stats = append(stats, stat{label: "OS", value: name})
+	}
+	if release := readTrimmed("/proc/sys/kernel/osrelease"); release != "" {
+		stats = append(stats, stat{label: "Kernel", value: release, detail: runtime.GOARCH})
+	}
+	if host, err := os.Hostname(); err == nil {
+		stats = append(stats, stat{label: "Host", value: host})
+	}
+	if uptime, ok := readUptime(); ok {
+		stats = append(stats, stat{label: "Uptime", value: formatUptime(uptime)})
+	}
+	if s, ok := p.cpuStat(); ok {
+		stats = append(stats, s)
+	}
+	if s, ok := memoryStat(); ok {
+		stats = append(stats, s...)
+	}
+	stats = append(stats, diskStats()...)
+	stats = append(stats, temperatureStats()...)
+	return stats
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// osName returns PRETTY_NAME from os-release.
+func osName() string {
+	for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for line := range strings.SplitSeq(string(data), "\n") {
+			if value, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+				return strings.Trim(value, `"'`)
+			}
+		}
+	}
+	return ""
+}
+
+func readUptime() (time.Duration, bool) {
+	var seconds float64
+	if _, err := fmt.Sscanf(readTrimmed("/proc/uptime"), "%f", &seconds); err != nil {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// cpuStat reports CPU usage since the previous sample, plus the load average.
+// The first sample after startup reports usage since boot.
+func (p *SysInfoPlugin) cpuStat() (stat, bool) {
+	fields := strings.Fields(strings.SplitN(readTrimmed("/proc/stat"), "\n", 2)[0])
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return stat{}, false
+	}
+
+	var sample cpuSample
+	for i, field := range fields[1:] {
+		var v uint64
+		fmt.Sscanf(field, "%d", &v)
+		sample.total += v
+		if i == 3 || i == 4 { // idle and iowait
+			sample.idle += v
+		}
+	}
+
+	idle, total := sample.idle-p.lastCPU.idle, sample.total-p.lastCPU.total
+	p.lastCPU = sample
+	if total == 0 {
+		return stat{}, false
+	}
+
+	usage := 100 * float64(total-idle) / float64(total)
+	detail := fmt.Sprintf("%d cores", runtime.NumCPU())
+	if load := strings.Fields(readTrimmed("/proc/loadavg")); len(load) >= 3 {
+		detail = fmt.Sprintf("load %s %s %s | %s", load[0], load[1], load[2], detail)
+	}
+	return stat{label: "CPU", value: fmt.Sprintf("%.1f%%", usage), detail: detail}, true
+}
+
+// memoryStat reports RAM and swap usage from /proc/meminfo.
+func memoryStat() ([]stat, bool) {
+	info := make(map[string]uint64)
+	for line := range strings.SplitSeq(readTrimmed("/proc/meminfo"), "\n") {
+		var key string
+		var kb uint64
+		if _, err := fmt.Sscanf(line, "%s %d", &key, &kb); err == nil {
+			info[strings.TrimSuffix(key, ":")] = kb * 1024
+		}
+	}
+	total, available := info["MemTotal"], info["MemAvailable"]
+	if total == 0 {
+		return nil, false
+	}
+
+	stats := []stat{usageStat("Memory", total-available, total)}
+	if swapTotal := info["SwapTotal"]; swapTotal > 0 {
+		stats = append(stats, usageStat("Swap", swapTotal-info["SwapFree"], swapTotal))
+	}
+	return stats, true
+}
+
+// diskStats reports usage of the root filesystem and of $HOME if it is a separate filesystem.
+func diskStats() []stat {
+	var stats []stat
+	seen := make(map[syscall.Fsid]bool)
+	home, _ := os.UserHomeDir()
+	for _, path := range []string{"/", home} {
+		if path == "" {
+			continue
+		}
+		var fs syscall.Statfs_t
+		if err := syscall.Statfs(path, &fs); err != nil {
+			continue
+		}
+		if seen[fs.Fsid] {
+			continue
+		}
+		seen[fs.Fsid] = true
+
+		total := fs.Blocks * uint64(fs.Bsize)
+		used := total - fs.Bavail*uint64(fs.Bsize)
+		stats = append(stats, usageStat("Disk "+path, used, total))
+	}
+	return stats
+}
+
+// temperatureStats reads thermal zones from sysfs.
+func temperatureStats() []stat {
+	zones, _ := filepath.Glob("/sys/class/thermal/thermal_zone*")
+	var stats []stat
+	for _, zone := range zones {
+		var milliCelsius int
+		if _, err := fmt.Sscanf(readTrimmed(filepath.Join(zone, "temp")), "%d", &milliCelsius); err != nil || milliCelsius <= 0 {
+			continue
+		}
+		name := readTrimmed(filepath.Join(zone, "type"))
+		if name == "" {
+			name = filepath.Base(zone)
+		}
+		stats = append(stats, stat{
+			label:  "Temp " + name,
+			value:  fmt.Sprintf("%.1f°C", float64(milliCelsius)/1000),
+			detail: filepath.Base(zone),
+		})
+	}
+	return stats
+}
+
+func usageStat(label string, used, total uint64) stat {
+	return stat{
+		label:  label,
+		value:  fmt.Sprintf("%s / %s (%.0f%%)", formatBytes(used), formatBytes(total), 100*float64(used)/float64(total)),
+		detail: fmt.Sprintf("%s free", formatBytes(total-used)),
+	}
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// Execute copies the selected stat, or the full summary, to the clipboard and quits.
+func (p *SysInfoPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	text, ok := p.copyTargets[identifier]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		zap.L().Error("Failed to copy system info to clipboard.", zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *SysInfoPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *SysInfoPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as this plugin does not maintain an error state.
+func (p *SysInfoPlugin) GetError() error {
+	return nil
+}
@@ -0,0 +1,409 @@
+// Package timer implements the !t plugin: countdown timers and stopwatches
+// that run inside the launcher process, such as the daemon, and notify the
+// desktop when they finish.
+package timer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/notify"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!t"
+
+const (
+	infoIdentifier      = "t_info"
+	startIdentifier     = "t_start:"
+	stopwatchIdentifier = "t_stopwatch:"
+	timerIdentifier     = "t_timer:"
+
+	refreshInterval = time.Second
+	// finishedLinger is how long finished timers stay listed.
+	finishedLinger = 10 * time.Minute
+	// barWidth caps the width of the progress bars in the countdown view.
+	barWidth = 40
+)
+
+// stopwatchCommands start a stopwatch instead of a timer.
+var stopwatchCommands = []string{"sw", "stopwatch"}
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionCancel = "cancel timer"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Timer",
+	Description: "Run countdown timers and stopwatches with desktop notifications.",
+	Keyword:     Keyword,
+	Flag:        "timer",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// timer is a countdown, or a stopwatch when duration is zero.
+type timer struct {
+	id       int
+	label    string
+	start    time.Time
+	duration time.Duration
+	finished time.Time // Zero until a countdown ends.
+	stop     *time.Timer
+}
+
+func (t *timer) isStopwatch() bool {
+	return t.duration == 0
+}
+
+// remaining returns the time left of a countdown at now.
+func (t *timer) remaining(now time.Time) time.Duration {
+	return max(0, t.duration-now.Sub(t.start))
+}
+
+// TimerPlugin implements the plugin.Plugin interface for timers and stopwatches.
+type TimerPlugin struct {
+	mu     sync.Mutex
+	timers []*timer
+	nextID int
+
+	// viewing is set while the countdown view is shown, for the query that
+	// started a timer; any other query returns to the list.
+	viewing   bool
+	viewQuery string
+	lastQuery string
+	viewWidth int
+
+	publish func(topic string, data any) // Nil until SetPublisher.
+
+	titleStyle lipgloss.Style
+	barStyle   lipgloss.Style
+	mutedStyle lipgloss.Style
+}
+
+// New creates a new instance of the TimerPlugin.
+func New() *TimerPlugin {
+	p := &TimerPlugin{}
+	p.SetTheme(theme.DefaultTheme)
+	return p
+}
+
+// SetTheme styles the countdown view with the given theme.
+func (p *TimerPlugin) SetTheme(t theme.Theme) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.titleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+	p.barStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	p.mutedStyle = lipgloss.NewStyle().Foreground(t.Muted)
+}
+
+// SetPublisher keeps the function publishing plugin.TopicTimerFinished events.
+func (p *TimerPlugin) SetPublisher(publish func(topic string, data any)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.publish = publish
+}
+
+// Metadata returns the plugin's metadata.
+func (p *TimerPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *TimerPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *TimerPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *TimerPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// RefreshInterval makes the application re-query every second while the
+// plugin is active, so countdowns tick.
+func (p *TimerPlugin) RefreshInterval() time.Duration {
+	return refreshInterval
+}
+
+// GetResults offers to start a timer for queries like "10m tea" or "25 focus"
+// (minutes), or a stopwatch for "sw label", followed by the running timers
+// whose label contains the query.
+func (p *TimerPlugin) GetResults(query string) ([]plugin.Result, error) {
+	query = strings.TrimSpace(query)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastQuery = query
+	if p.viewing && query != p.viewQuery {
+		p.viewing = false
+	}
+	now := time.Now()
+	p.pruneLocked(now)
+
+	var results []plugin.Result
+	filter := strings.ToLower(query)
+	first, label, _ := strings.Cut(query, " ")
+	label = strings.TrimSpace(label)
+	if d, ok := parseDuration(first); ok {
+		results = append(results, plugin.Result{
+			Title:       fmt.Sprintf("Start %s timer%s", formatDuration(d), quoteLabel(label)),
+			Description: fmt.Sprintf("Ends at %s", now.Add(d).Format("15:04:05")),
+			Identifier:  startIdentifier + strconv.FormatInt(int64(d), 10) + ":" + label,
+		})
+		filter = strings.ToLower(label)
+	} else if slices.Contains(stopwatchCommands, strings.ToLower(first)) {
+		results = append(results, plugin.Result{
+			Title:       "Start stopwatch" + quoteLabel(label),
+			Description: "Counts up until cancelled",
+			Identifier:  stopwatchIdentifier + label,
+		})
+		filter = strings.ToLower(label)
+	}
+
+	for _, t := range p.timers {
+		if filter != "" && !strings.Contains(strings.ToLower(t.label), filter) {
+			continue
+		}
+		results = append(results, timerResult(t, now))
+	}
+
+	if len(results) == 0 {
+		description := "e.g. !t 10m tea, !t 1h30m, !t 25 focus (minutes), !t sw run"
+		if query != "" {
+			description = fmt.Sprintf("%q is not a duration or the label of a running timer | %s", query, description)
+		}
+		results = append(results, plugin.Result{
+			Title:       "Start a timer or stopwatch",
+			Description: description,
+			Identifier:  infoIdentifier,
+		})
+	}
+	return results, nil
+}
+
+// timerResult describes a running or finished timer at now.
+func timerResult(t *timer, now time.Time) plugin.Result {
+	r := plugin.Result{Identifier: timerIdentifier + strconv.Itoa(t.id)}
+	switch {
+	case t.isStopwatch():
+		r.Title = fmt.Sprintf("%s  %s", formatClock(now.Sub(t.start).Truncate(time.Second)), labelOr(t.label, "Stopwatch"))
+		r.Description = fmt.Sprintf("Stopwatch started at %s | enter shows it, ctrl+x stops it", t.start.Format("15:04:05"))
+	case !t.finished.IsZero():
+		r.Title = fmt.Sprintf("Done  %s", labelOr(t.label, "Timer"))
+		r.Description = fmt.Sprintf("%s timer finished at %s | ctrl+x dismisses it", formatDuration(t.duration), t.finished.Format("15:04:05"))
+	default:
+		r.Title = fmt.Sprintf("%s  %s", formatClock(t.remaining(now)), labelOr(t.label, "Timer"))
+		r.Description = fmt.Sprintf("%s timer, ends at %s | enter shows it, ctrl+x cancels it", formatDuration(t.duration), t.start.Add(t.duration).Format("15:04:05"))
+	}
+	return r
+}
+
+// parseDuration parses a Go duration like 1h30m, or a number of minutes.
+func parseDuration(s string) (time.Duration, bool) {
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, true
+	}
+	if minutes, err := strconv.ParseFloat(s, 64); err == nil && minutes > 0 {
+		return time.Duration(minutes * float64(time.Minute)), true
+	}
+	return 0, false
+}
+
+// formatDuration formats d without zero units, e.g. 1h30m or 10m.
+func formatDuration(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatClock formats d as m:ss, or h:mm:ss from an hour on, rounding up so
+// a countdown shows 0:00 only once it ends.
+func formatClock(d time.Duration) string {
+	secs := int64((d + time.Second - 1) / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+func quoteLabel(label string) string {
+	if label == "" {
+		return ""
+	}
+	return fmt.Sprintf(" '%s'", label)
+}
+
+func labelOr(label, fallback string) string {
+	if label == "" {
+		return fallback
+	}
+	return label
+}
+
+// pruneLocked forgets timers that finished more than finishedLinger ago.
+func (p *TimerPlugin) pruneLocked(now time.Time) {
+	p.timers = slices.DeleteFunc(p.timers, func(t *timer) bool {
+		return !t.finished.IsZero() && now.Sub(t.finished) > finishedLinger
+	})
+}
+
+// Execute starts the timer or stopwatch being offered, or shows a running one,
+// in the countdown view. It does not quit: timers only run as long as the
+// process does, so the launcher is better hidden with the daemon.
+func (p *TimerPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(identifier, startIdentifier):
+		if p.viewing {
+			return nil // Already started; executing again would start a twin.
+		}
+		rest := strings.TrimPrefix(identifier, startIdentifier)
+		nanos, label, _ := strings.Cut(rest, ":")
+		n, err := strconv.ParseInt(nanos, 10, 64)
+		if err != nil {
+			return nil
+		}
+		p.startLocked(label, time.Duration(n))
+	case strings.HasPrefix(identifier, stopwatchIdentifier):
+		if p.viewing {
+			return nil
+		}
+		p.startLocked(strings.TrimPrefix(identifier, stopwatchIdentifier), 0)
+	case strings.HasPrefix(identifier, timerIdentifier):
+	default:
+		return nil // Do nothing for info items.
+	}
+	p.viewing = true
+	p.viewQuery = p.lastQuery
+	return nil
+}
+
+// startLocked starts a countdown of duration d, or a stopwatch if d is zero.
+func (p *TimerPlugin) startLocked(label string, d time.Duration) {
+	p.nextID++
+	t := &timer{id: p.nextID, label: label, start: time.Now(), duration: d}
+	if d > 0 {
+		t.stop = time.AfterFunc(d, func() { p.finish(t) })
+	}
+	p.timers = append(p.timers, t)
+	zap.L().Info("Timer started.", zap.String("label", label), zap.Duration("duration", d))
+}
+
+// finish marks a countdown as finished, notifies the desktop and tells other
+// plugins.
+func (p *TimerPlugin) finish(t *timer) {
+	p.mu.Lock()
+	t.finished = time.Now()
+	publish := p.publish
+	p.mu.Unlock()
+
+	if publish != nil {
+		publish(plugin.TopicTimerFinished, plugin.TimerFinished{Label: t.label, Duration: t.duration})
+	}
+
+	title := labelOr(t.label, "Timer")
+	body := fmt.Sprintf("%s timer finished", formatDuration(t.duration))
+	if err := notify.Send(title, body, notify.Critical); err != nil {
+		zap.L().Warn("Could not send timer notification.", zap.String("label", t.label), zap.Error(err))
+	}
+}
+
+// Actions lists the actions on the selected timer.
+func (p *TimerPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionCancel, Keys: []string{"ctrl+x"}},
+	}
+}
+
+// RunAction cancels the selected timer, stops the selected stopwatch or
+// dismisses the selected finished timer.
+func (p *TimerPlugin) RunAction(name, identifier string) tea.Cmd {
+	id, ok := strings.CutPrefix(identifier, timerIdentifier)
+	if name != actionCancel || !ok {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timers = slices.DeleteFunc(p.timers, func(t *timer) bool {
+		if strconv.Itoa(t.id) != id {
+			return false
+		}
+		if t.stop != nil {
+			t.stop.Stop()
+		}
+		return true
+	})
+	return nil
+}
+
+// Update tracks the width of the countdown view.
+func (p *TimerPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		// Matches the app's horizontal padding.
+		const mainAppHorizontalPadding = 4
+		p.mu.Lock()
+		p.viewWidth = msg.Width - mainAppHorizontalPadding
+		p.mu.Unlock()
+	}
+	return p, nil
+}
+
+// View renders the countdown view: every timer with its time left and a
+// progress bar, and every stopwatch with its time elapsed. It is empty
+// unless a timer was just started or opened, so the main list is shown.
+func (p *TimerPlugin) View() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.viewing || len(p.timers) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	width := min(barWidth, max(10, p.viewWidth-12))
+	rows := []string{p.titleStyle.Render("Timers"), ""}
+	for _, t := range p.timers {
+		name := labelOr(t.label, "Timer")
+		switch {
+		case t.isStopwatch():
+			rows = append(rows, fmt.Sprintf("%s  %s", p.titleStyle.Render(formatClock(now.Sub(t.start).Truncate(time.Second))), labelOr(t.label, "Stopwatch")))
+		case !t.finished.IsZero():
+			rows = append(rows, fmt.Sprintf("%s  %s", p.titleStyle.Render("Done"), name),
+				p.barStyle.Render(strings.Repeat("█", width)))
+		default:
+			done := int(float64(width) * float64(now.Sub(t.start)) / float64(t.duration))
+			done = min(max(done, 0), width)
+			rows = append(rows, fmt.Sprintf("%s  %s", p.titleStyle.Render(formatClock(t.remaining(now))), name),
+				p.barStyle.Render(strings.Repeat("█", done))+p.mutedStyle.Render(strings.Repeat("░", width-done)))
+		}
+		rows = append(rows, "")
+	}
+	rows = append(rows, p.mutedStyle.Render("edit the query to go back · timers keep running while incipio does"))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// GetError returns nil as errors are logged.
+func (p *TimerPlugin) GetError() error {
+	return nil
+}
@@ -0,0 +1,173 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// task is a line of the task file holding a task.
+type task struct {
+	line int // Index among the lines of the file.
+	text string
+	done bool
+}
+
+// format reads and writes the tasks of a file type.
+type format interface {
+	// parse returns the task on a line, if it holds one.
+	parse(line string) (text string, done, ok bool)
+	// toggle returns the line with its task marked done, or open again.
+	toggle(line string, now time.Time) string
+	// add returns the line of a new task.
+	add(text string, now time.Time) string
+}
+
+// formatFor returns the format of the file at path: a markdown checklist for
+// .md files, todo.txt otherwise.
+func formatFor(path string) format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return markdown{}
+	}
+	return todoTxt{}
+}
+
+// todoTxt is the todo.txt format: a task per line, done tasks starting with
+// "x " and their completion date. See https://github.com/todotxt/todo.txt.
+type todoTxt struct{}
+
+var (
+	todoTxtDone     = regexp.MustCompile(`^x (\d{4}-\d{2}-\d{2} )?`)
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\) `)
+	todoTxtPriTag   = regexp.MustCompile(` pri:([A-Z])$`)
+)
+
+func (todoTxt) parse(line string) (string, bool, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", false, false
+	}
+	if done := todoTxtDone.FindString(line); done != "" {
+		return line[len(done):], true, true
+	}
+	return line, false, true
+}
+
+// toggle prefixes the completion date, moving the priority to a pri: tag as
+// the format asks, or removes them.
+func (todoTxt) toggle(line string, now time.Time) string {
+	if done := todoTxtDone.FindString(line); done != "" {
+		line = line[len(done):]
+		if pri := todoTxtPriTag.FindStringSubmatch(line); pri != nil {
+			line = fmt.Sprintf("(%s) %s", pri[1], strings.TrimSuffix(line, pri[0]))
+		}
+		return line
+	}
+	if pri := todoTxtPriority.FindStringSubmatch(line); pri != nil {
+		line = strings.TrimPrefix(line, pri[0]) + " pri:" + pri[1]
+	}
+	return "x " + now.Format(time.DateOnly) + " " + line
+}
+
+func (todoTxt) add(text string, now time.Time) string {
+	return now.Format(time.DateOnly) + " " + text
+}
+
+// markdown is a markdown checklist, "- [ ] task" and "- [x] task". Other lines,
+// such as headings and notes, are kept as they are.
+type markdown struct{}
+
+var markdownTask = regexp.MustCompile(`^(\s*[-*+] \[)([ xX])(\] )`)
+
+func (markdown) parse(line string) (string, bool, bool) {
+	m := markdownTask.FindStringSubmatch(line)
+	if m == nil {
+		return "", false, false
+	}
+	return strings.TrimSpace(line[len(m[0]):]), m[2] != " ", true
+}
+
+func (markdown) toggle(line string, now time.Time) string {
+	m := markdownTask.FindStringSubmatch(line)
+	if m == nil {
+		return line
+	}
+	mark := "x"
+	if m[2] != " " {
+		mark = " "
+	}
+	return m[1] + mark + m[3] + line[len(m[0]):]
+}
+
+func (markdown) add(text string, now time.Time) string {
+	return "- [ ] " + text
+}
+
+// file is the content of the task file.
+type file struct {
+	lines []string
+	tasks []task
+}
+
+// readFile reads the task file at path. A missing file has no tasks.
+func readFile(path string, f format) (file, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file{}, nil
+	}
+	if err != nil {
+		return file{}, err
+	}
+
+	var content file
+	if len(data) > 0 {
+		content.lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	for i, line := range content.lines {
+		if text, done, ok := f.parse(line); ok {
+			content.tasks = append(content.tasks, task{line: i, text: text, done: done})
+		}
+	}
+	return content, nil
+}
+
+// find returns the task at the given line with the given text, or elsewhere if
+// lines were added or removed since the list was shown.
+func (c file) find(line int, text string) (task, bool) {
+	var found task
+	for _, t := range c.tasks {
+		if t.text != text {
+			continue
+		}
+		if t.line == line {
+			return t, true
+		}
+		if found.text == "" {
+			found = t
+		}
+	}
+	return found, found.text != ""
+}
+
+// write saves the lines to the task file at path, creating its directory.
+func (c file) write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var data string
+	if len(c.lines) > 0 {
+		data = strings.Join(c.lines, "\n") + "\n"
+	}
+	return os.WriteFile(path, []byte(data), 0o644)
+}
+
+// remove drops the line at index i.
+func (c *file) remove(i int) {
+	c.lines = slices.Delete(c.lines, i, i+1)
+}
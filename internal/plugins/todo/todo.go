@@ -0,0 +1,290 @@
+// Package todo implements the !td plugin, which captures tasks into a todo.txt
+// or markdown checklist file and marks them done.
+package todo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!td"
+
+const (
+	defaultDir      = "incipio"
+	defaultFileName = "todo.txt"
+
+	infoIdentifier = "td_info"
+	addIdentifier  = "td_add:"
+	taskIdentifier = "td_task:" // Followed by the line index, ":" and the task text.
+)
+
+// Sections the tasks are listed in.
+const (
+	openSection = "To do"
+	doneSection = "Done"
+)
+
+// Action names, bindable in plugin_keybindings.
+const (
+	actionDelete = "delete task"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "To-do",
+	Description: "Capture tasks into a todo.txt or markdown file and check them off.",
+	Keyword:     Keyword,
+	Flag:        "todo",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// TodoPlugin implements the plugin.Plugin interface for a task list kept in a
+// file, which other tools may edit too.
+type TodoPlugin struct {
+	mu      sync.Mutex
+	path    string
+	format  format
+	content file
+	modTime time.Time
+	err     error
+}
+
+// New creates a new instance of the TodoPlugin keeping tasks in the file at
+// path, ~/.local/share/incipio/todo.txt if empty. Files ending in .md are
+// markdown checklists, others todo.txt.
+func New(path string) *TodoPlugin {
+	p := &TodoPlugin{path: expandHome(path)}
+	if p.path == "" {
+		defaultPath, err := xdg.DataFile(filepath.Join(defaultDir, defaultFileName))
+		if err != nil {
+			zap.L().Warn("Could not determine the to-do file path.", zap.Error(err))
+		}
+		p.path = defaultPath
+	}
+	p.format = formatFor(p.path)
+	return p
+}
+
+// Metadata returns the plugin's metadata.
+func (p *TodoPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *TodoPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *TodoPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init reads the task file.
+func (p *TodoPlugin) Init() tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reload()
+	return nil
+}
+
+// GetResults lists the open tasks containing the query, then the done ones,
+// and offers to add the query as a new task.
+func (p *TodoPlugin) GetResults(query string) ([]plugin.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reload()
+
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+	var open, done []plugin.Result
+	for _, t := range p.content.tasks {
+		if lower != "" && !strings.Contains(strings.ToLower(t.text), lower) {
+			continue
+		}
+		result := plugin.Result{
+			Title:       t.text,
+			Description: "enter marks it done",
+			Identifier:  taskIdentifier + strconv.Itoa(t.line) + ":" + t.text,
+			Icon:        "☐",
+			Section:     openSection,
+		}
+		if t.done {
+			result.Description, result.Icon, result.Section = "enter marks it open again", "☑", doneSection
+			done = append(done, result)
+			continue
+		}
+		open = append(open, result)
+	}
+
+	var results []plugin.Result
+	if query != "" {
+		results = append(results, plugin.Result{
+			Title:       fmt.Sprintf("Add task '%s'", query),
+			Description: fmt.Sprintf("Append it to %s", p.path),
+			Identifier:  addIdentifier + query,
+		})
+	}
+	if len(open) > 0 {
+		// The add result stays first only when no open task matches.
+		results = append(open, results...)
+	}
+	results = append(results, done...)
+
+	if len(results) == 0 {
+		results = append(results, plugin.Result{
+			Title:       "No tasks yet",
+			Description: fmt.Sprintf("Type a task to add it to %s", p.path),
+			Identifier:  infoIdentifier,
+		})
+	}
+	if p.err != nil {
+		results = append(results, plugin.Result{Title: "Error", Description: p.err.Error(), Identifier: infoIdentifier})
+	}
+	return results, nil
+}
+
+// reload reads the task file when it changed since the last read, such as by
+// an editor or a sync tool. p.mu must be held.
+func (p *TodoPlugin) reload() bool {
+	info, err := os.Stat(p.path)
+	if err == nil && info.ModTime().Equal(p.modTime) {
+		return false
+	}
+	content, err := readFile(p.path, p.format)
+	if err != nil {
+		p.err = fmt.Errorf("could not read %s: %w", p.path, err)
+		zap.L().Warn("Could not read the to-do file.", zap.String("path", p.path), zap.Error(err))
+		return false
+	}
+	p.content, p.err = content, nil
+	if info != nil {
+		p.modTime = info.ModTime()
+	} else {
+		p.modTime = time.Time{}
+	}
+	return true
+}
+
+// Execute adds the task being typed and quits, or marks the selected task done
+// or open again and keeps the list open.
+func (p *TodoPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if text, ok := strings.CutPrefix(identifier, addIdentifier); ok {
+		p.reload()
+		p.content.lines = append(p.content.lines, p.format.add(text, time.Now()))
+		if !p.save() {
+			return nil
+		}
+		return tea.Quit
+	}
+
+	t, ok := p.taskFor(identifier)
+	if !ok {
+		return nil
+	}
+	p.content.lines[t.line] = p.format.toggle(p.content.lines[t.line], time.Now())
+	p.save()
+	return plugin.Refresh()
+}
+
+// taskFor finds the task of a result in the file, as it is now. p.mu must be held.
+func (p *TodoPlugin) taskFor(identifier string) (task, bool) {
+	rest, ok := strings.CutPrefix(identifier, taskIdentifier)
+	if !ok {
+		return task{}, false // Info items.
+	}
+	lineText, text, _ := strings.Cut(rest, ":")
+	line, err := strconv.Atoi(lineText)
+	if err != nil {
+		return task{}, false
+	}
+	p.reload()
+	t, found := p.content.find(line, text)
+	if !found {
+		p.err = fmt.Errorf("task '%s' is no longer in %s", text, p.path)
+	}
+	return t, found
+}
+
+// save writes the lines back to the task file and reads it again, so tasks
+// point at their new lines. p.mu must be held.
+func (p *TodoPlugin) save() bool {
+	if err := p.content.write(p.path); err != nil {
+		p.err = fmt.Errorf("could not save %s: %w", p.path, err)
+		zap.L().Error("Failed to save the to-do file.", zap.String("path", p.path), zap.Error(err))
+		return false
+	}
+	p.modTime = time.Time{}
+	p.reload()
+	return true
+}
+
+// Actions lists the actions on the selected task.
+func (p *TodoPlugin) Actions() []plugin.Action {
+	return []plugin.Action{
+		{Name: actionDelete, Keys: []string{"ctrl+x"}},
+	}
+}
+
+// RunAction deletes the selected task from the file and refreshes the list.
+func (p *TodoPlugin) RunAction(name, identifier string) tea.Cmd {
+	if name != actionDelete {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.taskFor(identifier)
+	if !ok {
+		return nil
+	}
+	p.content.remove(t.line)
+	p.save()
+	return plugin.Refresh()
+}
+
+// Update re-reads the task file when the terminal regains focus, refreshing
+// the list if it changed meanwhile.
+func (p *TodoPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	if _, ok := msg.(tea.FocusMsg); ok {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.reload() {
+			return p, plugin.Refresh()
+		}
+	}
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *TodoPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed read or save, if any.
+func (p *TodoPlugin) GetError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/') {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + rest
+		}
+	}
+	return path
+}
@@ -0,0 +1,235 @@
+package usage
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/barab-i/incipio/internal/app"
+	"github.com/barab-i/incipio/internal/stats"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const Keyword = "!stats"
+
+const (
+	infoIdentifier   = "stats_info"
+	pluginIdentifier = "stats_plugin:"
+	launchIdentifier = "stats_launch:"
+
+	// maxLaunches caps the most launched results listed.
+	maxLaunches = 20
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Usage Statistics",
+	Description: "Show which plugins are slow and what you launch most.",
+	Keyword:     Keyword,
+	Flag:        "stats",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// UsagePlugin lists the usage statistics recorded by the application.
+type UsagePlugin struct {
+	mainPluginManager *app.PluginManager // Reference to the main application's plugin manager.
+}
+
+// New creates a new instance of the UsagePlugin.
+// It requires the main PluginManager to read the statistics and plugin names.
+func New(mainPM *app.PluginManager) *UsagePlugin {
+	if mainPM == nil {
+		panic("UsagePlugin requires a non-nil main PluginManager")
+	}
+	return &UsagePlugin{
+		mainPluginManager: mainPM,
+	}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *UsagePlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *UsagePlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *UsagePlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init initializes the plugin.
+func (p *UsagePlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists the plugins, slowest first, followed by the most launched
+// results, keeping the rows containing every word of the query.
+func (p *UsagePlugin) GetResults(query string) ([]plugin.Result, error) {
+	store := p.mainPluginManager.Stats()
+	if store == nil {
+		return []plugin.Result{{
+			Title:       "Statistics are not available",
+			Description: "Usage is not being recorded.",
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+	usage := store.Plugins()
+	if len(usage) == 0 {
+		return []plugin.Result{{
+			Title:       "No statistics yet",
+			Description: "Queries and selections are counted here.",
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	results := append(p.pluginResults(usage), p.launchResults(usage)...)
+	words := strings.Fields(strings.ToLower(query))
+	results = slices.DeleteFunc(results, func(r plugin.Result) bool {
+		return !containsAll(strings.ToLower(r.Title+" "+r.Description), words)
+	})
+	if len(results) == 0 {
+		return []plugin.Result{{
+			Title:       "No matching statistics",
+			Description: fmt.Sprintf("Nothing in the statistics matches '%s'.", query),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+	return results, nil
+}
+
+// pluginResults describes each plugin's queries and executions, slowest first.
+func (p *UsagePlugin) pluginResults(usage map[string]stats.Plugin) []plugin.Result {
+	keywords := slices.Collect(maps.Keys(usage))
+	slices.SortFunc(keywords, func(a, b string) int {
+		if c := cmp.Compare(usage[b].MeanQueryTime(), usage[a].MeanQueryTime()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	results := make([]plugin.Result, 0, len(keywords))
+	for _, keyword := range keywords {
+		u := usage[keyword]
+		description := fmt.Sprintf("%d queries, avg %s, slowest %s", u.Queries, formatDuration(u.MeanQueryTime()), formatDuration(u.SlowestQuery))
+		if u.Failures > 0 {
+			description += fmt.Sprintf(", %d failed", u.Failures)
+		}
+		if u.Executions > 0 {
+			description += fmt.Sprintf(" | %d executed, avg %s, %s", u.Executions, formatDuration(u.MeanExecuteTime()), positionSummary(u))
+		}
+		results = append(results, plugin.Result{
+			Title:       p.pluginName(keyword),
+			Description: description,
+			Identifier:  pluginIdentifier + keyword,
+		})
+	}
+	return results
+}
+
+// launchResults lists the results executed most, across plugins.
+func (p *UsagePlugin) launchResults(usage map[string]stats.Plugin) []plugin.Result {
+	type launch struct {
+		keyword string
+		stats.Launch
+	}
+	var launches []launch
+	for keyword, u := range usage {
+		for _, l := range u.TopLaunches(maxLaunches) {
+			launches = append(launches, launch{keyword: keyword, Launch: l})
+		}
+	}
+	slices.SortFunc(launches, func(a, b launch) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return cmp.Compare(a.Title, b.Title)
+	})
+
+	results := make([]plugin.Result, 0, min(len(launches), maxLaunches))
+	for _, l := range launches[:min(len(launches), maxLaunches)] {
+		noun := "times"
+		if l.Count == 1 {
+			noun = "time"
+		}
+		results = append(results, plugin.Result{
+			Title:       l.Title,
+			Description: fmt.Sprintf("Launched %d %s from %s", l.Count, noun, p.pluginName(l.keyword)),
+			Identifier:  launchIdentifier + l.keyword + ":" + l.Title,
+		})
+	}
+	return results
+}
+
+// pluginName returns the name and keyword of the enabled plugin with the
+// given keyword, or the keyword alone.
+func (p *UsagePlugin) pluginName(keyword string) string {
+	if pl, ok := p.mainPluginManager.GetAllPlugins()[keyword]; ok {
+		if keyword == "" {
+			return pl.Name()
+		}
+		return fmt.Sprintf("%s (%s)", pl.Name(), keyword)
+	}
+	if keyword == "" {
+		return "Default plugin"
+	}
+	return keyword
+}
+
+// positionSummary tells how often the first result was the one executed.
+func positionSummary(u stats.Plugin) string {
+	first := 0
+	if len(u.Positions) > 0 {
+		first = u.Positions[0]
+	}
+	return fmt.Sprintf("%d%% first result", first*100/u.Executions)
+}
+
+// formatDuration rounds a duration to a precision readable at a glance.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return "<1ms"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(10 * time.Millisecond).String()
+	}
+}
+
+// containsAll reports whether s contains every word.
+func containsAll(s string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(s, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute does nothing; the statistics are informational.
+func (p *UsagePlugin) Execute(identifier string) tea.Cmd {
+	return nil
+}
+
+// Update handles messages.
+func (p *UsagePlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *UsagePlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as this plugin does not track persistent errors.
+func (p *UsagePlugin) GetError() error {
+	return nil
+}
@@ -0,0 +1,226 @@
+package websearch
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!s"
+
+const (
+	infoIdentifier = "websearch_info"
+
+	// queryPlaceholder is replaced by the escaped query in URL templates.
+	queryPlaceholder = "{query}"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Web Search",
+	Description: "Search the web in the default browser, e.g. !s g golang generics.",
+	Keyword:     Keyword,
+	Flag:        "websearch",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// engine is a search engine selected by a sub-bang, e.g. "g" in "!s g query".
+type engine struct {
+	bang     string
+	name     string
+	template string
+}
+
+// addressPattern matches queries that are web addresses rather than search
+// terms, e.g. "https://go.dev/doc" or "example.com/page".
+var addressPattern = regexp.MustCompile(`(?i)^(https?://\S+|(www\.)?[a-z0-9-]+(\.[a-z0-9-]+)*\.(com|org|net|io|dev|app|edu|gov|de|uk|fr|eu)(:\d+)?(/\S*)?)$`)
+
+// builtInEngines are available without configuration. Engines configured with
+// the same bang replace them.
+var builtInEngines = []engine{
+	{bang: "d", name: "DuckDuckGo", template: "https://duckduckgo.com/?q={query}"},
+	{bang: "g", name: "Google", template: "https://www.google.com/search?q={query}"},
+}
+
+// WebSearchPlugin opens queries on a search engine in the default browser.
+type WebSearchPlugin struct {
+	engines       []engine // Sorted by bang.
+	defaultEngine engine
+}
+
+// New creates a new instance of the WebSearchPlugin. engines maps bangs to URL
+// templates containing "{query}" and adds to the built-in DuckDuckGo (d) and
+// Google (g) engines. defaultBang selects the engine used when the query does
+// not start with a bang; it may also be an engine name like "google".
+func New(defaultBang string, engines map[string]string) *WebSearchPlugin {
+	byBang := make(map[string]engine)
+	for _, e := range builtInEngines {
+		byBang[e.bang] = e
+	}
+	for bang, template := range engines {
+		byBang[bang] = engine{bang: bang, name: engineName(template), template: template}
+	}
+
+	p := &WebSearchPlugin{}
+	for _, e := range byBang {
+		p.engines = append(p.engines, e)
+	}
+	sort.Slice(p.engines, func(i, j int) bool { return p.engines[i].bang < p.engines[j].bang })
+
+	p.defaultEngine = byBang["d"]
+	if defaultBang != "" {
+		if e, ok := p.find(defaultBang); ok {
+			p.defaultEngine = e
+		} else {
+			zap.L().Warn("Unknown default search engine, using DuckDuckGo.", zap.String("engine", defaultBang))
+		}
+	}
+	return p
+}
+
+// engineName derives a display name for a configured engine from its host,
+// e.g. "github.com" for https://github.com/search?q={query}.
+func engineName(template string) string {
+	u, err := url.Parse(strings.ReplaceAll(template, queryPlaceholder, ""))
+	if err != nil || u.Host == "" {
+		return template
+	}
+	return strings.TrimPrefix(u.Host, "www.")
+}
+
+// find looks an engine up by bang or, case-insensitively, by name.
+func (p *WebSearchPlugin) find(key string) (engine, bool) {
+	for _, e := range p.engines {
+		if e.bang == key || strings.EqualFold(e.name, key) {
+			return e, true
+		}
+	}
+	return engine{}, false
+}
+
+// Metadata returns the plugin's metadata.
+func (p *WebSearchPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *WebSearchPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *WebSearchPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *WebSearchPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// Matches claims web addresses with a scheme, like "https://go.dev", typed
+// without the keyword, to open them.
+func (p *WebSearchPlugin) Matches(query string) bool {
+	return strings.Contains(query, "://") && addressPattern.MatchString(query)
+}
+
+// GetResults offers to search the query on the engine selected by its first
+// word, or on the default engine, followed by the other engines. Web addresses
+// are offered to be opened directly first.
+func (p *WebSearchPlugin) GetResults(query string) ([]plugin.Result, error) {
+	query = strings.TrimSpace(query)
+	selected := p.defaultEngine
+	if bang, rest, _ := strings.Cut(query, " "); bang != "" {
+		for _, e := range p.engines {
+			if e.bang == bang {
+				selected, query = e, strings.TrimSpace(rest)
+				break
+			}
+		}
+	}
+
+	if query == "" {
+		bangs := make([]string, len(p.engines))
+		for i, e := range p.engines {
+			bangs[i] = fmt.Sprintf("%s (%s)", e.bang, e.name)
+		}
+		return []plugin.Result{{
+			Title:       fmt.Sprintf("Type a query to search %s", selected.name),
+			Description: "Start with an engine's bang to use it instead: " + strings.Join(bangs, ", "),
+			Identifier:  infoIdentifier,
+		}}, nil
+	}
+
+	results := []plugin.Result{}
+	if addressPattern.MatchString(query) {
+		address := query
+		if !strings.Contains(address, "://") {
+			address = "https://" + address
+		}
+		results = append(results, plugin.Result{
+			Title:       "Open " + query,
+			Description: address,
+			Identifier:  address,
+		})
+	}
+	results = append(results, p.result(selected, query))
+	for _, e := range p.engines {
+		if e.bang != selected.bang {
+			results = append(results, p.result(e, query))
+		}
+	}
+	return results, nil
+}
+
+func (p *WebSearchPlugin) result(e engine, query string) plugin.Result {
+	searchURL := e.url(query)
+	return plugin.Result{
+		Title:       fmt.Sprintf("Search %s for %q", e.name, query),
+		Description: searchURL,
+		Identifier:  searchURL,
+	}
+}
+
+// url returns the search URL for the query. Templates without a placeholder
+// get the query appended.
+func (e engine) url(query string) string {
+	escaped := url.QueryEscape(query)
+	if !strings.Contains(e.template, queryPlaceholder) {
+		return e.template + escaped
+	}
+	return strings.ReplaceAll(e.template, queryPlaceholder, escaped)
+}
+
+// Execute opens the selected search in the default browser and quits.
+func (p *WebSearchPlugin) Execute(identifier string) tea.Cmd {
+	if identifier == infoIdentifier {
+		return nil // Do nothing for info items.
+	}
+	if err := launch.OpenURL(identifier); err != nil {
+		zap.L().Error("Failed to open search in browser.", zap.String("url", identifier), zap.Error(err))
+		return nil
+	}
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *WebSearchPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *WebSearchPlugin) View() string {
+	return ""
+}
+
+// GetError returns nil as this plugin does not maintain an error state.
+func (p *WebSearchPlugin) GetError() error {
+	return nil
+}
@@ -0,0 +1,431 @@
+package workspaces
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/launch"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+const Keyword = "!code"
+
+// cacheTTL bounds how often the editors' state files are re-read while typing.
+const cacheTTL = 10 * time.Second
+
+const (
+	infoIdentifier  = "code_info"
+	errorIdentifier = "code_error"
+)
+
+var metadata = plugin.Metadata{
+	Name:        "Recent Workspaces",
+	Description: "Open recent VS Code and JetBrains workspaces.",
+	Keyword:     Keyword,
+	Flag:        "code",
+	IsMandatory: false,
+	IsDefault:   false,
+}
+
+// vsCodeVariants maps VS Code config directory names to their launcher commands.
+var vsCodeVariants = []struct{ dir, command string }{
+	{"Code", "code"},
+	{"Code - Insiders", "code-insiders"},
+	{"Code - OSS", "code-oss"},
+	{"VSCodium", "codium"},
+}
+
+// jetBrainsLaunchers maps JetBrains product directory prefixes to their launcher commands.
+var jetBrainsLaunchers = []struct{ prefix, command string }{
+	{"IntelliJIdea", "idea"},
+	{"IdeaIC", "idea"},
+	{"PyCharm", "pycharm"},
+	{"GoLand", "goland"},
+	{"CLion", "clion"},
+	{"WebStorm", "webstorm"},
+	{"PhpStorm", "phpstorm"},
+	{"RubyMine", "rubymine"},
+	{"Rider", "rider"},
+	{"DataGrip", "datagrip"},
+	{"RustRover", "rustrover"},
+	{"Fleet", "fleet"},
+}
+
+// workspace is a recently opened folder, workspace file or project.
+type workspace struct {
+	name      string
+	location  string // Local path, or URI for remote VS Code workspaces.
+	editor    string // Display name, e.g. "VS Code" or "GoLand2024.1".
+	command   string
+	args      []string
+	lastOpen  time.Time
+	isMissing bool
+}
+
+// identifier is stable across reloads, unlike the position in the list.
+func (w workspace) identifier() string {
+	return w.editor + "|" + w.location
+}
+
+// WorkspacesPlugin lists recent workspaces of VS Code and JetBrains IDEs.
+type WorkspacesPlugin struct {
+	mu         sync.Mutex
+	workspaces []workspace
+	loadedAt   time.Time
+	err        error
+}
+
+// New creates a new instance of the WorkspacesPlugin.
+func New() *WorkspacesPlugin {
+	return &WorkspacesPlugin{}
+}
+
+// Metadata returns the plugin's metadata.
+func (p *WorkspacesPlugin) Metadata() plugin.Metadata {
+	return metadata
+}
+
+// Name returns the plugin's name.
+func (p *WorkspacesPlugin) Name() string {
+	return metadata.Name
+}
+
+// Keyword returns the plugin's keyword.
+func (p *WorkspacesPlugin) Keyword() string {
+	return metadata.Keyword
+}
+
+// Init performs initial setup.
+func (p *WorkspacesPlugin) Init() tea.Cmd {
+	return nil
+}
+
+// GetResults lists recent workspaces whose name, path or editor match the query.
+func (p *WorkspacesPlugin) GetResults(query string) ([]plugin.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.loadedAt) > cacheTTL {
+		p.workspaces = loadWorkspaces()
+		p.loadedAt = time.Now()
+	}
+
+	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	results := []plugin.Result{}
+	for _, w := range p.workspaces {
+		if lowerQuery != "" && !strings.Contains(strings.ToLower(w.name+" "+w.location+" "+w.editor), lowerQuery) {
+			continue
+		}
+		description := fmt.Sprintf("%s | %s", w.editor, w.location)
+		if w.isMissing {
+			description += " (missing)"
+		}
+		results = append(results, plugin.Result{
+			Title:       w.name,
+			Description: description,
+			Identifier:  w.identifier(),
+		})
+	}
+
+	if len(p.workspaces) == 0 {
+		results = append(results, plugin.Result{
+			Title:       "No recent workspaces",
+			Description: "No VS Code storage.json or JetBrains recentProjects.xml found",
+			Identifier:  infoIdentifier,
+		})
+	}
+	if p.err != nil {
+		results = append(results, plugin.Result{
+			Title:       "Could not open workspace",
+			Description: p.err.Error(),
+			Identifier:  errorIdentifier,
+		})
+	}
+	return results, nil
+}
+
+// loadWorkspaces reads the recent workspaces of all installed editors,
+// most recently opened first where the editor records timestamps.
+func loadWorkspaces() []workspace {
+	var all []workspace
+	for _, variant := range vsCodeVariants {
+		path := filepath.Join(xdg.ConfigHome, variant.dir, "User", "globalStorage", "storage.json")
+		found, err := readVSCodeStorage(path, variant.dir, variant.command)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				zap.L().Warn("Failed to read VS Code storage.", zap.String("path", path), zap.Error(err))
+			}
+			continue
+		}
+		all = append(all, found...)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(xdg.ConfigHome, "JetBrains", "*", "options", "recentProjects.xml"))
+	for _, path := range files {
+		found, err := readJetBrainsRecentProjects(path)
+		if err != nil {
+			zap.L().Warn("Failed to read JetBrains recent projects.", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		all = append(all, found...)
+	}
+
+	// Stable sort keeps the storage order of entries without timestamps.
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].lastOpen.After(all[j].lastOpen)
+	})
+	return all
+}
+
+// vsCodeStorage holds the parts of VS Code's storage.json that reference workspaces.
+type vsCodeStorage struct {
+	ProfileAssociations struct {
+		Workspaces map[string]string `json:"workspaces"`
+	} `json:"profileAssociations"`
+	WindowsState struct {
+		LastActiveWindow vsCodeWindow   `json:"lastActiveWindow"`
+		OpenedWindows    []vsCodeWindow `json:"openedWindows"`
+	} `json:"windowsState"`
+	BackupWorkspaces struct {
+		Folders []struct {
+			FolderURI string `json:"folderUri"`
+		} `json:"folders"`
+		Workspaces []struct {
+			Workspace struct {
+				ConfigPath string `json:"configPath"`
+			} `json:"workspace"`
+		} `json:"workspaces"`
+	} `json:"backupWorkspaces"`
+}
+
+type vsCodeWindow struct {
+	Folder    string `json:"folder"`
+	Workspace struct {
+		ConfigPath string `json:"configPath"`
+	} `json:"workspace"`
+}
+
+// readVSCodeStorage collects workspace URIs from storage.json, the most recently active window first.
+func readVSCodeStorage(path, variant, command string) ([]workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var storage vsCodeStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, err
+	}
+
+	var uris []string
+	for _, w := range append([]vsCodeWindow{storage.WindowsState.LastActiveWindow}, storage.WindowsState.OpenedWindows...) {
+		uris = append(uris, w.Folder, w.Workspace.ConfigPath)
+	}
+	for _, f := range storage.BackupWorkspaces.Folders {
+		uris = append(uris, f.FolderURI)
+	}
+	for _, w := range storage.BackupWorkspaces.Workspaces {
+		uris = append(uris, w.Workspace.ConfigPath)
+	}
+	associated := make([]string, 0, len(storage.ProfileAssociations.Workspaces))
+	for uri := range storage.ProfileAssociations.Workspaces {
+		associated = append(associated, uri)
+	}
+	sort.Strings(associated) // Map order is random; keep the list stable between reloads.
+	uris = append(uris, associated...)
+
+	editor := "VS Code"
+	if variant != "Code" {
+		editor = variant
+	}
+
+	seen := make(map[string]bool)
+	var workspaces []workspace
+	for _, uri := range uris {
+		if uri == "" || seen[uri] {
+			continue
+		}
+		seen[uri] = true
+
+		w := workspace{editor: editor, command: command}
+		scheme, _, isURI := strings.Cut(uri, "://")
+		switch {
+		case !isURI:
+			continue
+		case scheme == "file":
+			parsed, err := url.Parse(uri)
+			if err != nil {
+				continue
+			}
+			w.location = parsed.Path
+			w.args = []string{parsed.Path}
+			_, statErr := os.Stat(parsed.Path)
+			w.isMissing = os.IsNotExist(statErr)
+		default:
+			// Remote workspaces (SSH, containers, WSL) are reopened by URI. Their
+			// authority is percent-encoded (e.g. ssh-remote%2Bhost), so they are not parsed.
+			w.location = uri
+			flag := "--folder-uri"
+			if strings.HasSuffix(uri, ".code-workspace") {
+				flag = "--file-uri"
+			}
+			w.args = []string{flag, uri}
+		}
+		w.name = strings.TrimSuffix(filepath.Base(w.location), ".code-workspace")
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, nil
+}
+
+// jetBrainsApplication is the layout of recentProjects.xml.
+type jetBrainsApplication struct {
+	Components []struct {
+		Name    string            `xml:"name,attr"`
+		Options []jetBrainsOption `xml:"option"`
+	} `xml:"component"`
+}
+
+type jetBrainsOption struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Map   struct {
+		Entries []struct {
+			Key   string `xml:"key,attr"`
+			Value struct {
+				Meta struct {
+					Options []jetBrainsOption `xml:"option"`
+				} `xml:"RecentProjectMetaInfo"`
+			} `xml:"value"`
+		} `xml:"entry"`
+	} `xml:"map"`
+	List struct {
+		Options []jetBrainsOption `xml:"option"`
+	} `xml:"list"`
+}
+
+// readJetBrainsRecentProjects reads the recent projects of one IDE installation.
+// Both the current 'additionalInfo' map and the legacy 'recentPaths' list are supported.
+func readJetBrainsRecentProjects(path string) ([]workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var app jetBrainsApplication
+	if err := xml.Unmarshal(data, &app); err != nil {
+		return nil, err
+	}
+
+	product := filepath.Base(filepath.Dir(filepath.Dir(path)))
+	command := jetBrainsCommand(product)
+	home, _ := os.UserHomeDir()
+
+	var workspaces []workspace
+	add := func(projectPath string, lastOpen time.Time) {
+		projectPath = strings.ReplaceAll(projectPath, "$USER_HOME$", home)
+		_, statErr := os.Stat(projectPath)
+		workspaces = append(workspaces, workspace{
+			name:      filepath.Base(projectPath),
+			location:  projectPath,
+			editor:    product,
+			command:   command,
+			args:      []string{projectPath},
+			lastOpen:  lastOpen,
+			isMissing: os.IsNotExist(statErr),
+		})
+	}
+
+	for _, component := range app.Components {
+		if !strings.HasPrefix(component.Name, "Recent") {
+			continue
+		}
+		for _, option := range component.Options {
+			switch option.Name {
+			case "additionalInfo":
+				for _, entry := range option.Map.Entries {
+					var lastOpen time.Time
+					for _, meta := range entry.Value.Meta.Options {
+						if meta.Name == "activationTimestamp" {
+							if ms, err := strconv.ParseInt(meta.Value, 10, 64); err == nil {
+								lastOpen = time.UnixMilli(ms)
+							}
+						}
+					}
+					add(entry.Key, lastOpen)
+				}
+			case "recentPaths":
+				for _, item := range option.List.Options {
+					add(item.Value, time.Time{})
+				}
+			}
+		}
+	}
+	return workspaces, nil
+}
+
+// jetBrainsCommand returns the launcher for a product directory such as "GoLand2024.1",
+// preferring a JetBrains Toolbox script when one is installed.
+func jetBrainsCommand(product string) string {
+	for _, l := range jetBrainsLaunchers {
+		if strings.HasPrefix(product, l.prefix) {
+			script := filepath.Join(xdg.DataHome, "JetBrains", "Toolbox", "scripts", l.command)
+			if _, err := os.Stat(script); err == nil {
+				return script
+			}
+			return l.command
+		}
+	}
+	return strings.ToLower(strings.TrimRight(product, "0123456789."))
+}
+
+// Execute opens the selected workspace in its editor and quits.
+func (p *WorkspacesPlugin) Execute(identifier string) tea.Cmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var w workspace
+	found := false
+	for _, candidate := range p.workspaces {
+		if candidate.identifier() == identifier {
+			w, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return nil // Do nothing for info/error items.
+	}
+
+	if err := launch.Detached(exec.Command(w.command, w.args...)); err != nil {
+		p.err = fmt.Errorf("%s: %w", w.command, err)
+		zap.L().Error("Failed to open workspace.", zap.String("command", w.command), zap.Strings("args", w.args), zap.Error(err))
+		return nil
+	}
+	p.err = nil
+	return tea.Quit
+}
+
+// Update handles messages.
+func (p *WorkspacesPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, nil
+}
+
+// View returns an empty string as this plugin uses the main application's list view.
+func (p *WorkspacesPlugin) View() string {
+	return ""
+}
+
+// GetError returns the error of the last failed launch, if any.
+func (p *WorkspacesPlugin) GetError() error {
+	return p.err
+}
@@ -0,0 +1,200 @@
+// Package profile records where the launcher spends its time, for --profile:
+// CPU and heap profiles to read with go tool pprof, and histograms of how long
+// frames take to render and plugins to answer queries, which show what makes
+// typing lag.
+package profile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Files written to the profile directory.
+const (
+	CPUFile     = "cpu.pprof"
+	HeapFile    = "heap.pprof"
+	TimingsFile = "timings.txt"
+)
+
+// Names of the timings recorded by the launcher. Plugin timings are named
+// after the call and the plugin's keyword, e.g. "GetResults !a".
+const (
+	Render = "render"
+)
+
+// Profiler profiles the process from Start to Stop and collects timings. A
+// nil Profiler records nothing, so callers need not check whether profiling
+// is on. It is safe for concurrent use.
+type Profiler struct {
+	dir string
+	cpu *os.File
+
+	mu      sync.Mutex
+	timings map[string]*Histogram
+}
+
+// Start starts profiling the CPU into dir, which is created if needed.
+func Start(dir string) (*Profiler, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	cpu, err := os.Create(filepath.Join(dir, CPUFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	return &Profiler{dir: dir, cpu: cpu, timings: make(map[string]*Histogram)}, nil
+}
+
+// Observe adds d to the timings of the given name.
+func (p *Profiler) Observe(name string, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, ok := p.timings[name]
+	if !ok {
+		h = &Histogram{}
+		p.timings[name] = h
+	}
+	h.Observe(d)
+}
+
+// Stop ends the CPU profile, writes the heap profile and the timings to the
+// profile directory, and the timings to report as well unless nil.
+func (p *Profiler) Stop(report io.Writer) error {
+	pprof.StopCPUProfile()
+	err := p.cpu.Close()
+
+	runtime.GC() // Profile the memory in use, not garbage yet to be collected.
+	heap, heapErr := os.Create(filepath.Join(p.dir, HeapFile))
+	if heapErr == nil {
+		heapErr = pprof.WriteHeapProfile(heap)
+		if closeErr := heap.Close(); heapErr == nil {
+			heapErr = closeErr
+		}
+	}
+	if heapErr != nil && err == nil {
+		err = fmt.Errorf("failed to write heap profile: %w", heapErr)
+	}
+
+	var timings bytes.Buffer
+	p.WriteTimings(&timings)
+	if writeErr := os.WriteFile(filepath.Join(p.dir, TimingsFile), timings.Bytes(), 0o644); writeErr != nil && err == nil {
+		err = fmt.Errorf("failed to write timings: %w", writeErr)
+	}
+	if report != nil {
+		report.Write(timings.Bytes())
+	}
+	return err
+}
+
+// WriteTimings writes the histogram of each timing to w, by name.
+func (p *Profiler) WriteTimings(w io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.timings) == 0 {
+		fmt.Fprintln(w, "No timings recorded.")
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.timings)) {
+		h := p.timings[name]
+		fmt.Fprintf(w, "%s: %s\n%s", name, h, h.Bars())
+	}
+}
+
+// bounds are the upper bounds of the histogram buckets; a last bucket holds
+// longer durations. Typing feels laggy from about 50ms.
+var bounds = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second,
+}
+
+// Histogram counts durations in buckets bounded by 1, 2 and 5 times powers of ten.
+type Histogram struct {
+	counts [11]int // By bucket of bounds, then longer durations.
+	n      int
+	sum    time.Duration
+	max    time.Duration
+}
+
+// Observe counts d.
+func (h *Histogram) Observe(d time.Duration) {
+	i, _ := slices.BinarySearch(bounds, d)
+	h.counts[i]++
+	h.n++
+	h.sum += d
+	h.max = max(h.max, d)
+}
+
+// Quantile returns the upper bound of the bucket holding the q-th quantile of
+// the durations, 0 < q <= 1, or the longest duration if beyond the last bound.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	rank := int(q*float64(h.n) + 0.5)
+	seen := 0
+	for i, count := range h.counts[:len(bounds)] {
+		seen += count
+		if seen >= rank {
+			return min(bounds[i], h.max)
+		}
+	}
+	return h.max
+}
+
+// String summarizes the durations, e.g. "n=120 mean=3ms p50≤2ms p90≤10ms p99≤20ms max=14ms".
+func (h *Histogram) String() string {
+	if h.n == 0 {
+		return "n=0"
+	}
+	mean := h.sum / time.Duration(h.n)
+	return fmt.Sprintf("n=%d mean=%s p50≤%s p90≤%s p99≤%s max=%s",
+		h.n, round(mean), round(h.Quantile(0.5)), round(h.Quantile(0.9)), round(h.Quantile(0.99)), round(h.max))
+}
+
+// Bars draws the histogram, one indented line per bucket from the first to
+// the last holding durations.
+func (h *Histogram) Bars() string {
+	first := slices.IndexFunc(h.counts[:], func(count int) bool { return count > 0 })
+	if first < 0 {
+		return ""
+	}
+	last := len(h.counts) - 1
+	for h.counts[last] == 0 {
+		last--
+	}
+	const width = 40
+	most := slices.Max(h.counts[:])
+	var b strings.Builder
+	for i := first; i <= last; i++ {
+		label := "> " + bounds[len(bounds)-1].String()
+		if i < len(bounds) {
+			label = "≤ " + bounds[i].String()
+		}
+		bar := strings.Repeat("█", (h.counts[i]*width+most-1)/most)
+		fmt.Fprintf(&b, "  %-8s %-*s %d\n", label, width, bar, h.counts[i])
+	}
+	return b.String()
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
@@ -0,0 +1,227 @@
+// Package stats records how plugins are used: how often and how fast they
+// answer queries, and what is executed from them. The statistics stay on the
+// machine, in the XDG data directory.
+package stats
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"go.uber.org/zap"
+)
+
+const (
+	statsDir      = "incipio"
+	statsFileName = "stats.json"
+
+	// maxPositions bounds the positions counted separately; selections further
+	// down the list are counted in the last one.
+	maxPositions = 10
+	// maxLaunches bounds the titles counted per plugin. Once exceeded, the
+	// least executed title other than the latest is dropped.
+	maxLaunches = 100
+)
+
+// Plugin holds the usage statistics of a plugin.
+type Plugin struct {
+	Queries      int           `json:"queries"`
+	Failures     int           `json:"failures"`
+	QueryTime    time.Duration `json:"query_time"` // Summed over all queries.
+	SlowestQuery time.Duration `json:"slowest_query"`
+
+	Executions  int           `json:"executions"`
+	ExecuteTime time.Duration `json:"execute_time"` // Summed over all executions.
+	// Positions counts executions by the position of the selected result,
+	// the first result at index 0.
+	Positions []int          `json:"positions"`
+	Launches  map[string]int `json:"launches"` // Executions by result title.
+}
+
+// MeanQueryTime returns the average time the plugin took to answer a query.
+func (p Plugin) MeanQueryTime() time.Duration {
+	if p.Queries == 0 {
+		return 0
+	}
+	return p.QueryTime / time.Duration(p.Queries)
+}
+
+// MeanExecuteTime returns the average time the plugin took to execute a result.
+func (p Plugin) MeanExecuteTime() time.Duration {
+	if p.Executions == 0 {
+		return 0
+	}
+	return p.ExecuteTime / time.Duration(p.Executions)
+}
+
+// Launch is a result title and the number of times it was executed.
+type Launch struct {
+	Title string
+	Count int
+}
+
+// TopLaunches returns the n most executed titles, most executed first.
+func (p Plugin) TopLaunches(n int) []Launch {
+	launches := make([]Launch, 0, len(p.Launches))
+	for title, count := range p.Launches {
+		launches = append(launches, Launch{Title: title, Count: count})
+	}
+	slices.SortFunc(launches, func(a, b Launch) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return cmp.Compare(a.Title, b.Title)
+	})
+	return launches[:min(n, len(launches))]
+}
+
+// Store is the persistent usage statistics, keyed by plugin keyword. It is
+// safe for concurrent use. Queries are saved along with the next execution
+// or by Save.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	plugins map[string]*Plugin
+	dirty   bool // Changed since the last save.
+}
+
+// Load reads the statistics file. A missing or unreadable file yields empty statistics.
+func Load() *Store {
+	s := &Store{plugins: make(map[string]*Plugin)}
+
+	path, err := xdg.DataFile(filepath.Join(statsDir, statsFileName))
+	if err != nil {
+		zap.L().Warn("Could not determine statistics path, statistics will not be saved.", zap.Error(err))
+		return s
+	}
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s
+	}
+	if err != nil {
+		zap.L().Warn("Could not read statistics.", zap.String("path", path), zap.Error(err))
+		return s
+	}
+	if err := json.Unmarshal(data, &s.plugins); err != nil || s.plugins == nil {
+		zap.L().Warn("Could not parse statistics, starting fresh.", zap.String("path", path), zap.Error(err))
+		s.plugins = make(map[string]*Plugin)
+	}
+	return s
+}
+
+// RecordQuery counts a query answered by the plugin with the given keyword.
+func (s *Store) RecordQuery(keyword string, elapsed time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.pluginLocked(keyword)
+	p.Queries++
+	if failed {
+		p.Failures++
+	}
+	p.QueryTime += elapsed
+	p.SlowestQuery = max(p.SlowestQuery, elapsed)
+	s.dirty = true
+}
+
+// RecordExecution counts the execution of the result with the given title and
+// position in the list, and saves the statistics.
+func (s *Store) RecordExecution(keyword, title string, position int, elapsed time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.pluginLocked(keyword)
+	p.Executions++
+	p.ExecuteTime += elapsed
+
+	position = min(max(position, 0), maxPositions-1)
+	if len(p.Positions) <= position {
+		p.Positions = append(p.Positions, make([]int, position+1-len(p.Positions))...)
+	}
+	p.Positions[position]++
+
+	if p.Launches == nil {
+		p.Launches = make(map[string]int)
+	}
+	p.Launches[title]++
+	if len(p.Launches) > maxLaunches {
+		launches := p.TopLaunches(len(p.Launches))
+		least := launches[len(launches)-1]
+		if least.Title == title {
+			least = launches[len(launches)-2]
+		}
+		delete(p.Launches, least.Title)
+	}
+	s.dirty = true
+	return s.saveLocked()
+}
+
+// Plugins returns the statistics of every plugin used so far, by keyword.
+func (s *Store) Plugins() map[string]Plugin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]Plugin, len(s.plugins))
+	for keyword, p := range s.plugins {
+		c := *p
+		c.Positions = slices.Clone(p.Positions)
+		c.Launches = maps.Clone(p.Launches)
+		out[keyword] = c
+	}
+	return out
+}
+
+// Reset forgets all statistics and saves.
+func (s *Store) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.plugins)
+	s.dirty = true
+	return s.saveLocked()
+}
+
+// Save writes the statistics if they changed since the last save.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+func (s *Store) pluginLocked(keyword string) *Plugin {
+	p, ok := s.plugins[keyword]
+	if !ok {
+		p = &Plugin{}
+		s.plugins[keyword] = p
+	}
+	return p
+}
+
+func (s *Store) saveLocked() error {
+	if !s.dirty {
+		return nil
+	}
+	if s.path == "" {
+		return fmt.Errorf("statistics path is unknown")
+	}
+
+	data, err := json.MarshalIndent(s.plugins, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write atomically so a crash never leaves truncated statistics behind.
+	if err := atomicfile.WriteFile(s.path, data, 0o644); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
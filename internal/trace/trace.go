@@ -0,0 +1,190 @@
+// Package trace reads and writes event traces of launcher sessions. A trace
+// records the keys pressed, window sizes, queries and result counts, with the
+// text typed anonymized, so users can attach it to bug reports and
+// maintainers can replay it with --replay.
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Kind is the kind of a trace event.
+type Kind string
+
+const (
+	// Input events drive the model on replay.
+	KindKey    Kind = "key"
+	KindResize Kind = "resize"
+
+	// Outcome events record what the input led to; replay compares them.
+	KindQuery   Kind = "query"   // A query was sent to the active plugin.
+	KindResults Kind = "results" // Results for the current query arrived.
+	KindExecute Kind = "execute" // The selected item was executed.
+)
+
+// Event is a single line of a trace.
+type Event struct {
+	Offset time.Duration `json:"offset"` // Time since the start of the session.
+	Kind   Kind          `json:"kind"`
+
+	// Key events. KeyType is a tea.KeyType; Key is its readable form.
+	Key     string `json:"key,omitempty"`
+	KeyType int    `json:"key_type,omitempty"`
+	Runes   string `json:"runes,omitempty"`
+	Alt     bool   `json:"alt,omitempty"`
+	Paste   bool   `json:"paste,omitempty"`
+
+	// Resize events.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// Outcome events.
+	Plugin   string `json:"plugin,omitempty"` // Keyword of the active plugin.
+	Query    string `json:"query,omitempty"`
+	Results  int    `json:"results,omitempty"`
+	Error    bool   `json:"error,omitempty"`
+	Selected int    `json:"selected,omitempty"` // Index of the executed item.
+}
+
+// IsInput reports whether the event drives the model on replay.
+func (e Event) IsInput() bool {
+	return e.Kind == KindKey || e.Kind == KindResize
+}
+
+// Msg returns the message replaying an input event, or nil for outcome events.
+func (e Event) Msg() tea.Msg {
+	switch e.Kind {
+	case KindKey:
+		msg := tea.KeyMsg{Type: tea.KeyType(e.KeyType), Alt: e.Alt, Paste: e.Paste}
+		if e.Runes != "" {
+			msg.Runes = []rune(e.Runes)
+		}
+		return msg
+	case KindResize:
+		return tea.WindowSizeMsg{Width: e.Width, Height: e.Height}
+	}
+	return nil
+}
+
+// String describes the event in one line, for replay reports.
+func (e Event) String() string {
+	switch e.Kind {
+	case KindKey:
+		return fmt.Sprintf("key %s", e.Key)
+	case KindResize:
+		return fmt.Sprintf("resize %dx%d", e.Width, e.Height)
+	case KindQuery:
+		return fmt.Sprintf("query %s %q", e.Plugin, e.Query)
+	case KindResults:
+		return fmt.Sprintf("results %s %q: %d (error: %t)", e.Plugin, e.Query, e.Results, e.Error)
+	case KindExecute:
+		return fmt.Sprintf("execute %s item %d", e.Plugin, e.Selected)
+	}
+	return string(e.Kind)
+}
+
+// Writer writes events as JSON lines. It is safe for concurrent use.
+type Writer struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	start time.Time
+	err   error // First write error; later events are dropped.
+}
+
+// NewWriter returns a writer whose event offsets are relative to now.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w), start: time.Now()}
+}
+
+// Write sets the event's offset and writes it.
+func (w *Writer) Write(e Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+	e.Offset = time.Since(w.start)
+	w.err = w.enc.Encode(e)
+}
+
+// Err returns the first error encountered while writing.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Read parses a trace written by Writer.
+func Read(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// KeyEvent returns the event for a key typed while the input held before.
+// Typed text is anonymized like the queries; see Anonymize.
+func KeyEvent(msg tea.KeyMsg, before string) Event {
+	key := tea.Key(msg)
+	if key.Type == tea.KeyRunes {
+		masked := Anonymize(before + string(key.Runes))
+		key.Runes = []rune(masked)[len([]rune(before)):]
+	}
+	return Event{
+		Kind:    KindKey,
+		Key:     key.String(),
+		KeyType: int(key.Type),
+		Runes:   string(key.Runes),
+		Alt:     key.Alt,
+		Paste:   key.Paste,
+	}
+}
+
+// Anonymize masks the letters and digits of a query, keeping its structure:
+// letters become x or X, digits 0, and spaces and punctuation are kept. A
+// leading plugin keyword like "!grep" is kept as is so the replay reaches the
+// same plugin.
+func Anonymize(query string) string {
+	keep := 0
+	if strings.HasPrefix(query, "!") {
+		keep = len(query)
+		if i := strings.IndexByte(query, ' '); i >= 0 {
+			keep = i
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(query[:keep])
+	for _, r := range query[keep:] {
+		switch {
+		case unicode.IsUpper(r):
+			b.WriteRune('X')
+		case unicode.IsLetter(r):
+			b.WriteRune('x')
+		case unicode.IsDigit(r):
+			b.WriteRune('0')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
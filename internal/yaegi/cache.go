@@ -0,0 +1,143 @@
+package yaegi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"go.uber.org/zap"
+)
+
+const validationCacheFile = "incipio/yaegi_validation.json"
+
+// validationVersion is bumped whenever the checks of parsePlugin change, so
+// plugins validated by earlier versions are checked again.
+const validationVersion = 2
+
+// validatedFile records a plugin file that passed parsePlugin.
+type validatedFile struct {
+	Hash         string              `json:"hash"` // SHA-256 of the source.
+	Capabilities []plugin.Capability `json:"capabilities"`
+}
+
+// validationCache lists the plugin files known to pass parsePlugin, by path,
+// so unchanged plugins are not parsed and checked again at every start.
+type validationCache struct {
+	Version int                      `json:"version"`
+	Sandbox string                   `json:"sandbox"` // See sandboxFingerprint.
+	Files   map[string]validatedFile `json:"files"`
+}
+
+var (
+	cacheMu   sync.Mutex
+	validated *validationCache // Read on first use.
+
+	loadTimesMu sync.Mutex
+	loadTimes   = make(map[string]LoadTime) // By plugin keyword.
+)
+
+// LoadTime is how long loading a yaegi plugin took.
+type LoadTime struct {
+	Duration time.Duration
+	Cached   bool // The sandbox check was skipped as the source was unchanged; the plugin was still interpreted.
+}
+
+// PluginLoadTime returns how long the yaegi plugin with the given keyword
+// took to load the last time it was, and false for other plugins.
+func PluginLoadTime(keyword string) (LoadTime, bool) {
+	loadTimesMu.Lock()
+	defer loadTimesMu.Unlock()
+	t, ok := loadTimes[keyword]
+	return t, ok
+}
+
+func recordLoadTime(keyword string, t LoadTime) {
+	loadTimesMu.Lock()
+	defer loadTimesMu.Unlock()
+	loadTimes[keyword] = t
+}
+
+// validatePlugin returns the capabilities the plugin at pluginPath declares,
+// checked by parsePlugin unless the cache holds the same source already.
+// cached reports whether the check was skipped.
+func validatePlugin(pluginPath string, src []byte) (capabilities []plugin.Capability, cached bool, err error) {
+	sum := sha256.Sum256(src)
+	hash := hex.EncodeToString(sum[:])
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if validated == nil {
+		validated = readValidationCache()
+	}
+	if file, ok := validated.Files[pluginPath]; ok && file.Hash == hash {
+		return file.Capabilities, true, nil
+	}
+
+	capabilities, err = parsePlugin(src)
+	if err != nil {
+		delete(validated.Files, pluginPath)
+		return nil, false, err
+	}
+	// Files loaded from elsewhere, such as those being installed, are not kept.
+	if filepath.Dir(pluginPath) == PluginDir() && isPluginFile(filepath.Base(pluginPath)) {
+		validated.Files[pluginPath] = validatedFile{Hash: hash, Capabilities: capabilities}
+		writeValidationCache(validated)
+	}
+	return capabilities, false, nil
+}
+
+// readValidationCache returns the cache of validated plugin files, empty if
+// missing, unreadable or written for other checks.
+func readValidationCache() *validationCache {
+	empty := &validationCache{Version: validationVersion, Sandbox: sandboxFingerprint(), Files: make(map[string]validatedFile)}
+	path, err := xdg.CacheFile(validationCacheFile)
+	if err != nil {
+		return empty
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+	var c validationCache
+	if err := json.Unmarshal(data, &c); err != nil || c.Version != empty.Version || c.Sandbox != empty.Sandbox || c.Files == nil {
+		return empty
+	}
+	return &c
+}
+
+// writeValidationCache saves the cache, dropping files that no longer exist.
+func writeValidationCache(c *validationCache) {
+	path, err := xdg.CacheFile(validationCacheFile)
+	if err != nil {
+		zap.L().Warn("Could not determine the yaegi validation cache path.", zap.Error(err))
+		return
+	}
+	for p := range c.Files {
+		if _, err := os.Stat(p); err != nil {
+			delete(c.Files, p)
+		}
+	}
+	data, err := json.Marshal(c)
+	if err == nil {
+		err = atomicfile.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		zap.L().Warn("Could not write the yaegi validation cache.", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// sandboxFingerprint identifies the sandbox rules plugins are checked
+// against, so cached validations lapse when the rules change. fmt prints maps
+// sorted by key, which keeps it stable.
+func sandboxFingerprint() string {
+	sum := sha256.Sum256(fmt.Append(nil, gatedPackages, pureNetPackages, purePackages, ungatedSymbols, gatedSymbols, capabilityConstants))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,202 @@
+package yaegi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxPluginSize caps the size of a downloaded plugin file.
+const maxPluginSize = 1 << 20
+
+// Install downloads the plugin at source into the plugin directory and
+// returns it loaded. source is either the URL of a plugin file, with GitHub
+// file pages fetched raw, or a git repository holding a single plugin file at
+// its root; see gitRepository. The plugin must load before accept is called
+// with it, and accept must succeed, typically by registering it, before the
+// file is written. An installed file of the same name is replaced.
+func Install(ctx context.Context, source string, accept func(LoadedPlugin) error) (LoadedPlugin, error) {
+	var name string
+	var src []byte
+	var err error
+	if repo, ok := gitRepository(source); ok {
+		name, src, err = cloneFile(ctx, repo)
+	} else {
+		name, src, err = download(ctx, source)
+	}
+	if err != nil {
+		return LoadedPlugin{}, err
+	}
+
+	dir := PluginDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return LoadedPlugin{}, fmt.Errorf("could not create plugin directory: %w", err)
+	}
+	// The file is checked under a hidden name, which the watcher ignores.
+	tmp, err := os.CreateTemp(dir, ".install-*.go")
+	if err != nil {
+		return LoadedPlugin{}, fmt.Errorf("could not create plugin file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return LoadedPlugin{}, fmt.Errorf("could not write plugin file: %w", err)
+	}
+
+	p, err := LoadPlugin(tmp.Name())
+	if err != nil {
+		return LoadedPlugin{}, fmt.Errorf("%s does not load: %w", name, err)
+	}
+	loaded := LoadedPlugin{Path: filepath.Join(dir, name), Plugin: p}
+	if err := accept(loaded); err != nil {
+		return LoadedPlugin{}, err
+	}
+	if err := os.Rename(tmp.Name(), loaded.Path); err != nil {
+		return LoadedPlugin{}, fmt.Errorf("could not install %s: %w", name, err)
+	}
+	return loaded, nil
+}
+
+// gitRepository returns the repository to clone if source names one: a URL
+// ending in .git or prefixed with git+, or the page of a GitHub repository.
+func gitRepository(source string) (string, bool) {
+	if repo, ok := strings.CutPrefix(source, "git+"); ok {
+		return repo, true
+	}
+	if strings.HasSuffix(source, ".git") {
+		return source, true
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.Host != "github.com" {
+		return "", false
+	}
+	return source, len(strings.Split(strings.Trim(u.Path, "/"), "/")) == 2
+}
+
+// downloadClient fetches plugin files over HTTPS only, redirects included,
+// since their code runs as soon as they are installed.
+var downloadClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing redirect to %s, plugins are only downloaded over https", req.URL)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	},
+}
+
+// download fetches a plugin file and returns its name and source.
+func download(ctx context.Context, source string) (string, []byte, error) {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme != "https" {
+		return "", nil, fmt.Errorf("%q is not an https URL", source)
+	}
+	rawURL(u)
+	name, err := pluginFileName(u.Path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("%s returned %s", u, resp.Status)
+	}
+	src, err := io.ReadAll(io.LimitReader(resp.Body, maxPluginSize+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(src) > maxPluginSize {
+		return "", nil, fmt.Errorf("%s is larger than %d bytes", name, maxPluginSize)
+	}
+	return name, src, nil
+}
+
+// rawURL rewrites the URL of a file page on GitHub to the URL of the file's content.
+func rawURL(u *url.URL) {
+	// /user/repo/blob/branch/path -> raw.githubusercontent.com/user/repo/branch/path
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 4)
+	if u.Host == "github.com" && len(parts) == 4 && parts[2] == "blob" {
+		u.Host = "raw.githubusercontent.com"
+		u.Path = "/" + path.Join(parts[0], parts[1], parts[3])
+	}
+}
+
+// pluginFileName returns the name a plugin is installed under, the base name
+// of its path, which must be a visible Go file.
+func pluginFileName(p string) (string, error) {
+	name := path.Base(p)
+	if !isPluginFile(name) {
+		return "", fmt.Errorf("%q is not a Go file", name)
+	}
+	return name, nil
+}
+
+// cloneFile clones repo and returns the name and source of its plugin file.
+// Repositories are cloned over https or ssh, never asking for credentials,
+// which would take over the terminal the launcher runs in.
+func cloneFile(ctx context.Context, repo string) (string, []byte, error) {
+	if scheme, _, ok := strings.Cut(repo, "://"); ok && scheme != "https" && scheme != "ssh" {
+		return "", nil, fmt.Errorf("%q is not an https or ssh repository URL", repo)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", nil, errors.New("git is not installed")
+	}
+	dir, err := os.MkdirTemp("", "incipio-plugin-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth=1", "--quiet", "--", repo, dir)
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=true", // Answers any credential prompt with nothing.
+		"GIT_SSH_COMMAND=ssh -o BatchMode=yes",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", nil, fmt.Errorf("git clone failed: %v %s", err, strings.TrimSpace(string(out)))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && isPluginFile(e.Name()) && !strings.HasSuffix(e.Name(), "_test.go") {
+			files = append(files, e.Name())
+		}
+	}
+	if len(files) != 1 {
+		return "", nil, fmt.Errorf("%s has %d Go files at its root, give the URL of the plugin file instead", repo, len(files))
+	}
+	src, err := os.ReadFile(filepath.Join(dir, files[0]))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(src) > maxPluginSize {
+		return "", nil, fmt.Errorf("%s is larger than %d bytes", files[0], maxPluginSize)
+	}
+	return files[0], src, nil
+}
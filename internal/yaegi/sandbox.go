@@ -0,0 +1,302 @@
+package yaegi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/symbol"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// gatedPackages maps the import paths of packages reaching outside the
+// interpreter to the capability a plugin needs to import them. Packages of
+// the net tree not listed in pureNetPackages need plugin.CapabilityNetwork.
+var gatedPackages = map[string]plugin.Capability{
+	"crypto/tls": plugin.CapabilityNetwork,
+	"log/syslog": plugin.CapabilityNetwork,
+
+	"os/exec":     plugin.CapabilityExec,
+	"os/signal":   plugin.CapabilityExec,
+	"syscall":     plugin.CapabilityExec,
+	"go/build":    plugin.CapabilityExec, // Runs the go command.
+	"go/importer": plugin.CapabilityExec,
+	"github.com/barab-i/incipio/pkgs/elevate": plugin.CapabilityExec,
+
+	"os":            plugin.CapabilityFilesystem,
+	"os/user":       plugin.CapabilityFilesystem,
+	"io/ioutil":     plugin.CapabilityFilesystem,
+	"path/filepath": plugin.CapabilityFilesystem,
+	"runtime/debug": plugin.CapabilityFilesystem, // Writes heap dumps to file descriptors.
+	"runtime/pprof": plugin.CapabilityFilesystem,
+	"runtime/trace": plugin.CapabilityFilesystem,
+	// Templates parse files through methods, which cannot be gated one by one.
+	"html/template": plugin.CapabilityFilesystem,
+	"text/template": plugin.CapabilityFilesystem,
+	"github.com/barab-i/incipio/internal/index": plugin.CapabilityFilesystem,
+}
+
+// pureNetPackages are the packages of the net tree that only parse and format.
+var pureNetPackages = []string{"net/mail", "net/netip", "net/textproto", "net/url"}
+
+// purePackages are the other standard library packages plugins may import
+// without a capability, as they only compute, parse and format, but for the
+// symbols in gatedSymbols. Standard library packages neither listed nor gated,
+// such as flag or testing, are not available to plugins, so that packages
+// yaegi exposes in later versions stay out until reviewed.
+var purePackages = []string{
+	"archive/tar", "archive/zip", "bufio", "bytes", "cmp",
+	"compress/bzip2", "compress/flate", "compress/gzip", "compress/lzw", "compress/zlib",
+	"container/heap", "container/list", "container/ring", "context",
+	"crypto", "crypto/aes", "crypto/cipher", "crypto/des", "crypto/dsa", "crypto/ecdh",
+	"crypto/ecdsa", "crypto/ed25519", "crypto/elliptic", "crypto/hmac", "crypto/md5",
+	"crypto/rand", "crypto/rc4", "crypto/rsa", "crypto/sha1", "crypto/sha256",
+	"crypto/sha512", "crypto/subtle", "crypto/x509", "crypto/x509/pkix",
+	"debug/buildinfo", "debug/dwarf", "debug/elf", "debug/gosym", "debug/macho",
+	"debug/pe", "debug/plan9obj",
+	"encoding", "encoding/ascii85", "encoding/asn1", "encoding/base32", "encoding/base64",
+	"encoding/binary", "encoding/csv", "encoding/gob", "encoding/hex", "encoding/json",
+	"encoding/pem", "encoding/xml", "errors", "fmt",
+	"go/ast", "go/build/constraint", "go/constant", "go/doc", "go/doc/comment", "go/format",
+	"go/parser", "go/printer", "go/scanner", "go/token", "go/types", "go/version",
+	"hash", "hash/adler32", "hash/crc32", "hash/crc64", "hash/fnv", "hash/maphash", "html",
+	"image", "image/color", "image/color/palette", "image/draw", "image/gif", "image/jpeg",
+	"image/png", "index/suffixarray", "io", "io/fs", "log", "log/slog", "maps",
+	"math", "math/big", "math/bits", "math/cmplx", "math/rand", "math/rand/v2",
+	"mime", "mime/multipart", "mime/quotedprintable", "path", "reflect", "regexp",
+	"regexp/syntax", "runtime", "runtime/metrics", "slices", "sort", "strconv", "strings",
+	"sync", "sync/atomic", "text/scanner", "text/tabwriter", "text/template/parse", "time",
+	"unicode", "unicode/utf16", "unicode/utf8",
+}
+
+// stdlibPackages holds the import paths of the packages yaegi's stdlib
+// exposes, which must be pure or gated to be available.
+var stdlibPackages = func() map[string]bool {
+	paths := make(map[string]bool, len(stdlib.Symbols))
+	for key := range stdlib.Symbols {
+		paths[path.Dir(key)] = true
+	}
+	return paths
+}()
+
+// ungatedSymbols lists the symbols of gated packages exposed without their
+// capability: environment lookups and path manipulation.
+var ungatedSymbols = map[string][]string{
+	"os": {
+		"Environ", "ErrClosed", "ErrDeadlineExceeded", "ErrExist", "ErrInvalid", "ErrNotExist",
+		"ErrPermission", "Expand", "ExpandEnv", "Getenv", "Getpid", "Getuid", "Hostname",
+		"IsExist", "IsNotExist", "IsPermission", "LookupEnv", "PathListSeparator", "PathSeparator",
+	},
+	"path/filepath": {
+		"Base", "Clean", "Dir", "ErrBadPattern", "Ext", "FromSlash", "IsAbs", "IsLocal", "Join",
+		"ListSeparator", "Localize", "Match", "Rel", "Separator", "SkipAll", "SkipDir", "Split",
+		"SplitList", "ToSlash", "VolumeName",
+	},
+}
+
+// gatedSymbols maps symbols of otherwise ungated packages to the capability
+// they need.
+var gatedSymbols = map[string]map[string]plugin.Capability{
+	// Functions opening files by name.
+	"archive/zip":     {"OpenReader": plugin.CapabilityFilesystem},
+	"debug/buildinfo": {"ReadFile": plugin.CapabilityFilesystem},
+	"debug/elf":       {"Open": plugin.CapabilityFilesystem},
+	"debug/macho":     {"Open": plugin.CapabilityFilesystem, "OpenFat": plugin.CapabilityFilesystem},
+	"debug/pe":        {"Open": plugin.CapabilityFilesystem},
+	"debug/plan9obj":  {"Open": plugin.CapabilityFilesystem},
+	// These read the named file when given no source.
+	"go/parser": {
+		"ParseDir":      plugin.CapabilityFilesystem,
+		"ParseExprFrom": plugin.CapabilityFilesystem,
+		"ParseFile":     plugin.CapabilityFilesystem,
+	},
+	"time": {"LoadLocation": plugin.CapabilityFilesystem},
+	// The application runs the commands plugins return through Run.
+	"github.com/barab-i/incipio/pkgs/plugin": {
+		"Run":           plugin.CapabilityExec,
+		"RunCommandMsg": plugin.CapabilityExec,
+	},
+}
+
+// packageCapability returns the capability needed to import the package, if any.
+func packageCapability(importPath string) (plugin.Capability, bool) {
+	if c, ok := gatedPackages[importPath]; ok {
+		return c, true
+	}
+	if (importPath == "net" || strings.HasPrefix(importPath, "net/")) && !slices.Contains(pureNetPackages, importPath) {
+		return plugin.CapabilityNetwork, true
+	}
+	return "", false
+}
+
+// packageAvailable reports whether plugins may import the package, given its
+// capability if gated.
+func packageAvailable(importPath string) bool {
+	if _, gated := packageCapability(importPath); gated || !stdlibPackages[importPath] {
+		return true
+	}
+	return slices.Contains(purePackages, importPath) || slices.Contains(pureNetPackages, importPath)
+}
+
+// symbolCapability returns the capability needed to use the symbol of the
+// package, if any.
+func symbolCapability(importPath, name string) (plugin.Capability, bool) {
+	if c, ok := gatedSymbols[importPath][name]; ok {
+		return c, true
+	}
+	c, gated := packageCapability(importPath)
+	if !gated || slices.Contains(ungatedSymbols[importPath], name) {
+		return "", false
+	}
+	return c, true
+}
+
+// sandboxSymbols returns the symbols exposed to a plugin with the given
+// capabilities: the standard library and Incipio's packages, less the
+// symbols of the capabilities it lacks.
+func sandboxSymbols(capabilities []plugin.Capability) map[string]map[string]reflect.Value {
+	out := make(map[string]map[string]reflect.Value)
+	for _, exports := range []map[string]map[string]reflect.Value{stdlib.Symbols, symbol.Symbols} {
+		for key, symbols := range exports {
+			// Keys are the import path followed by the package name, but for
+			// ".", which holds what the interpreter itself needs.
+			importPath := path.Dir(key)
+			if key != "." && !packageAvailable(importPath) {
+				continue
+			}
+			allowed := make(map[string]reflect.Value, len(symbols))
+			for name, v := range symbols {
+				if c, gated := symbolCapability(importPath, name); !gated || slices.Contains(capabilities, c) {
+					allowed[name] = v
+				}
+			}
+			if len(allowed) == 0 {
+				continue
+			}
+			if out[key] == nil {
+				out[key] = allowed
+				continue
+			}
+			for name, v := range allowed {
+				out[key][name] = v
+			}
+		}
+	}
+	return out
+}
+
+// parsePlugin returns the capabilities a plugin's source declares in its
+// metadata, and checks that it only uses the packages and symbols they allow,
+// so that a plugin lacking a capability fails with an error naming it rather
+// than an undefined symbol.
+func parsePlugin(src []byte) ([]plugin.Capability, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, err := declaredCapabilities(fset, file)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := make(map[string]string) // Local name to import path.
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if !packageAvailable(importPath) {
+			return nil, fmt.Errorf("imports %s, which plugins cannot use", importPath)
+		}
+		if c, gated := packageCapability(importPath); gated && !slices.Contains(capabilities, c) && ungatedSymbols[importPath] == nil {
+			return nil, missingCapability(importPath, c)
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			if importPath, imported := imports[pkg.Name]; imported {
+				if c, gated := symbolCapability(importPath, sel.Sel.Name); gated && !slices.Contains(capabilities, c) {
+					err = missingCapability(importPath+"."+sel.Sel.Name, c)
+				}
+			}
+		}
+		return true
+	})
+	return capabilities, err
+}
+
+// capabilityConstants maps the names of the plugin.Capability constants to their values.
+var capabilityConstants = map[string]plugin.Capability{
+	"CapabilityNetwork":    plugin.CapabilityNetwork,
+	"CapabilityExec":       plugin.CapabilityExec,
+	"CapabilityFilesystem": plugin.CapabilityFilesystem,
+}
+
+// declaredCapabilities collects the capabilities listed in the Capabilities
+// fields of the source's composite literals, as plugin.Capability constants
+// or strings.
+func declaredCapabilities(fset *token.FileSet, file *ast.File) ([]plugin.Capability, error) {
+	var capabilities []plugin.Capability
+	var err error
+	ast.Inspect(file, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Capabilities" {
+			return true
+		}
+		list, ok := kv.Value.(*ast.CompositeLit)
+		if !ok {
+			err = fmt.Errorf("%s: capabilities must be listed literally in the metadata", fset.Position(kv.Value.Pos()))
+			return false
+		}
+		for _, elt := range list.Elts {
+			var c plugin.Capability
+			switch e := elt.(type) {
+			case *ast.SelectorExpr:
+				c = capabilityConstants[e.Sel.Name]
+			case *ast.BasicLit:
+				s, _ := strconv.Unquote(e.Value)
+				c = plugin.Capability(s)
+			}
+			if !slices.Contains(slices.Collect(maps.Values(capabilityConstants)), c) {
+				err = fmt.Errorf("%s: unknown capability", fset.Position(elt.Pos()))
+				return false
+			}
+			if !slices.Contains(capabilities, c) {
+				capabilities = append(capabilities, c)
+			}
+		}
+		return false
+	})
+	return capabilities, err
+}
+
+// missingCapability reports the use of what without declaring its capability.
+func missingCapability(what string, c plugin.Capability) error {
+	return fmt.Errorf("uses %s, which needs the %q capability: add plugin.Capability%s to Capabilities in its metadata", what, c, strings.ToUpper(string(c[:1]))+string(c[1:]))
+}
@@ -0,0 +1,132 @@
+package yaegi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+)
+
+// pluginSource returns the source of a plugin importing pkg and calling call
+// in GetResults.
+func pluginSource(pkg, call string) string {
+	return `package main
+
+import (
+	"` + pkg + `"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type leak struct{}
+
+func New() plugin.Plugin { return leak{} }
+
+func (leak) Name() string    { return "Leak" }
+func (leak) Keyword() string { return "!leak" }
+func (leak) Metadata() plugin.Metadata {
+	return plugin.Metadata{Name: "Leak", Keyword: "!leak"}
+}
+func (leak) Init() tea.Cmd { return nil }
+func (leak) GetResults(query string) ([]plugin.Result, error) {
+	_ = ` + call + `
+	return nil, nil
+}
+func (leak) Execute(string) tea.Cmd                      { return nil }
+func (p leak) Update(tea.Msg) (plugin.Plugin, tea.Cmd) { return p, nil }
+func (leak) View() string                                { return "" }
+func (leak) GetError() error                             { return nil }
+`
+}
+
+func TestParsePluginGatesFileAndNetworkAccess(t *testing.T) {
+	tests := []struct {
+		pkg, call, what string
+		capability      plugin.Capability
+	}{
+		{"archive/zip", `zip.OpenReader`, "archive/zip.OpenReader", plugin.CapabilityFilesystem},
+		{"debug/elf", `elf.Open`, "debug/elf.Open", plugin.CapabilityFilesystem},
+		{"debug/macho", `macho.OpenFat`, "debug/macho.OpenFat", plugin.CapabilityFilesystem},
+		{"text/template", `template.ParseFiles`, "text/template", plugin.CapabilityFilesystem},
+		{"html/template", `template.ParseFiles`, "html/template", plugin.CapabilityFilesystem},
+		{"go/parser", `parser.ParseDir`, "go/parser.ParseDir", plugin.CapabilityFilesystem},
+		{"go/parser", `parser.ParseFile`, "go/parser.ParseFile", plugin.CapabilityFilesystem},
+		{"time", `time.LoadLocation`, "time.LoadLocation", plugin.CapabilityFilesystem},
+		{"runtime/debug", `debug.WriteHeapDump`, "runtime/debug", plugin.CapabilityFilesystem},
+		{"log/syslog", `syslog.Dial`, "log/syslog", plugin.CapabilityNetwork},
+		{"go/build", `build.Import`, "go/build", plugin.CapabilityExec},
+		{"go/importer", `importer.Default`, "go/importer", plugin.CapabilityExec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.call, func(t *testing.T) {
+			_, err := parsePlugin([]byte(pluginSource(tt.pkg, tt.call)))
+			want := missingCapability(tt.what, tt.capability)
+			if err == nil || err.Error() != want.Error() {
+				t.Errorf("parsePlugin() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+func TestParsePluginRejectsUnreviewedPackages(t *testing.T) {
+	for _, pkg := range []string{"flag", "testing", "database/sql", "github.com/traefik/yaegi/stdlib"} {
+		t.Run(pkg, func(t *testing.T) {
+			if _, err := parsePlugin([]byte(pluginSource(pkg, "0"))); err == nil {
+				t.Errorf("parsePlugin() accepted a plugin importing %s", pkg)
+			}
+		})
+	}
+}
+
+func TestParsePluginAllowsPureSymbols(t *testing.T) {
+	tests := []struct{ pkg, call string }{
+		{"archive/zip", `zip.NewReader`},
+		{"go/parser", `parser.ParseExpr`},
+		{"time", `time.Now`},
+		{"strings", `strings.ToUpper`},
+		{"net/url", `url.Parse`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.call, func(t *testing.T) {
+			if _, err := parsePlugin([]byte(pluginSource(tt.pkg, tt.call))); err != nil {
+				t.Errorf("parsePlugin() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSandboxSymbolsLeaveOutGatedSymbols(t *testing.T) {
+	symbols := sandboxSymbols(nil)
+	for _, tt := range []struct{ key, name string }{
+		{"archive/zip/zip", "OpenReader"},
+		{"debug/elf/elf", "Open"},
+		{"go/parser/parser", "ParseDir"},
+		{"time/time", "LoadLocation"},
+		{"text/template/template", "New"},
+		{"log/syslog/syslog", "Dial"},
+		{"go/build/build", "Import"},
+		{"github.com/traefik/yaegi/stdlib/stdlib", "Symbols"},
+	} {
+		if _, ok := symbols[tt.key][tt.name]; ok {
+			t.Errorf("%s.%s is exposed without capabilities", tt.key, tt.name)
+		}
+	}
+	if _, ok := symbols["time/time"]["Now"]; !ok {
+		t.Error("time.Now is not exposed")
+	}
+	if _, ok := symbols["."]; !ok {
+		t.Error("the interpreter's own symbols are not exposed")
+	}
+}
+
+func TestLoadPluginFailsOnMissingCapability(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leak.go")
+	if err := os.WriteFile(path, []byte(pluginSource("time", "time.LoadLocation")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlugin(path); err == nil {
+		t.Fatal("LoadPlugin() loaded a plugin using time.LoadLocation without the filesystem capability")
+	}
+}
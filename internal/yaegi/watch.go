@@ -0,0 +1,79 @@
+package yaegi
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay coalesces the burst of events editors produce when saving a file.
+const reloadDelay = 250 * time.Millisecond
+
+// Watch reports changes to plugin files in the plugin directory until done is
+// closed. onChange is called with the path of each plugin file that was
+// created, written, renamed or removed, once the file has been quiet for
+// reloadDelay. It runs on its own goroutine and must not block for long.
+func Watch(done <-chan struct{}, onChange func(path string)) error {
+	dir := PluginDir()
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not create file watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("could not watch yaegi plugin directory '%s': %w", dir, err)
+	}
+	zap.L().Debug("Watching yaegi plugin directory for changes.", zap.String("path", dir))
+
+	go func() {
+		defer watcher.Close()
+
+		var mu sync.Mutex
+		pending := make(map[string]*time.Timer)
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, t := range pending {
+				t.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zap.L().Warn("Yaegi plugin watcher error.", zap.Error(err))
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isPluginFile(filepath.Base(event.Name)) || event.Op == fsnotify.Chmod {
+					continue
+				}
+
+				path := event.Name
+				mu.Lock()
+				if t, exists := pending[path]; exists {
+					t.Reset(reloadDelay)
+				} else {
+					pending[path] = time.AfterFunc(reloadDelay, func() {
+						mu.Lock()
+						delete(pending, path)
+						mu.Unlock()
+						onChange(path)
+					})
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
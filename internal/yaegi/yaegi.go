@@ -4,21 +4,35 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/barab-i/incipio/pkgs/plugin"
-	"github.com/barab-i/incipio/pkgs/symbol"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/traefik/yaegi/interp"
-	"github.com/traefik/yaegi/stdlib"
 	"go.uber.org/zap"
 )
 
 const PluginDirName = "incipio/plugins"
 
+// LoadedPlugin is a plugin loaded from a file in the plugin directory.
+type LoadedPlugin struct {
+	Path   string
+	Plugin plugin.Plugin
+}
+
+// PluginDir returns the directory yaegi plugins are loaded from.
+func PluginDir() string {
+	return filepath.Join(xdg.ConfigHome, PluginDirName)
+}
+
 // LoadPlugins scans the plugin directory and loads Go plugins using Yaegi.
-func LoadPlugins() ([]plugin.Plugin, error) {
-	pluginDirPath := filepath.Join(xdg.ConfigHome, PluginDirName)
+// Plugins that fail to load are logged and skipped.
+func LoadPlugins() ([]LoadedPlugin, error) {
+	pluginDirPath := PluginDir()
 
 	if _, err := os.Stat(xdg.ConfigHome); os.IsNotExist(err) {
 		zap.L().Info("XDG config home directory does not exist, Yaegi plugins cannot be loaded yet.", zap.String("path", xdg.ConfigHome))
@@ -35,94 +49,203 @@ func LoadPlugins() ([]plugin.Plugin, error) {
 		return nil, fmt.Errorf("could not read yaegi plugin directory '%s': %w", pluginDirPath, err)
 	}
 
-	var loadedPlugins []plugin.Plugin
+	var loadedPlugins []LoadedPlugin
+	for _, file := range files {
+		if file.IsDir() || !isPluginFile(file.Name()) {
+			continue
+		}
+
+		pluginPath := filepath.Join(pluginDirPath, file.Name())
+		pluginInstance, err := LoadPlugin(pluginPath)
+		if err != nil {
+			zap.L().Warn("Could not load yaegi plugin.", zap.String("pluginPath", pluginPath), zap.Error(err))
+			continue
+		}
+		loadedPlugins = append(loadedPlugins, LoadedPlugin{Path: pluginPath, Plugin: pluginInstance})
+	}
+
+	return loadedPlugins, nil
+}
+
+// isPluginFile reports whether a file name in the plugin directory is a plugin source.
+// Hidden files are skipped, which also covers the temporary files many editors save through.
+func isPluginFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasPrefix(name, ".")
+}
 
+// LoadPlugin interprets a single plugin file in a fresh interpreter and returns
+// the plugin created by its exported New function. The interpreter only
+// exposes the symbols allowed by the capabilities the plugin declares, which
+// are checked once per version of the file; see validatePlugin. The time
+// taken is recorded for PluginLoadTime.
+func LoadPlugin(pluginPath string) (plugin.Plugin, error) {
+	start := time.Now()
 	wd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("could not get working directory: %w", err)
 	}
 	goPath := wd // Yaegi's GoPath is set to the project's root directory.
-	zap.L().Debug("Using project root as GOPATH for Yaegi.", zap.String("gopath", goPath))
+	zap.L().Debug("Attempting to load yaegi plugin.", zap.String("path", pluginPath), zap.String("gopath", goPath))
 
-	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") {
-			continue
-		}
+	// Create a new interpreter for each plugin to isolate contexts.
+	i := interp.New(interp.Options{
+		GoPath: goPath,
+	})
 
-		pluginPath := filepath.Join(pluginDirPath, file.Name())
-		zap.L().Debug("Attempting to load yaegi plugin.", zap.String("path", pluginPath))
+	srcBytes, err := os.ReadFile(pluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plugin file: %w", err)
+	}
 
-		// Create a new interpreter for each plugin to isolate contexts.
-		i := interp.New(interp.Options{
-			GoPath: goPath,
-		})
+	capabilities, cached, err := validatePlugin(pluginPath, srcBytes)
+	if err != nil {
+		return nil, fmt.Errorf("plugin not allowed: %w", err)
+	}
+	if err := i.Use(sandboxSymbols(capabilities)); err != nil {
+		return nil, fmt.Errorf("error loading symbols into yaegi: %w", err)
+	}
 
-		if err := i.Use(stdlib.Symbols); err != nil {
-			zap.L().Warn("Error loading stdlib symbols into yaegi.",
-				zap.String("pluginPath", pluginPath),
-				zap.Error(err))
-			continue
-		}
+	if _, err := i.Eval(string(srcBytes)); err != nil {
+		return nil, fmt.Errorf("error evaluating plugin source: %w", err)
+	}
 
-		if err := i.Use(symbol.Symbols); err != nil {
-			zap.L().Warn("Error loading incipio symbols into yaegi.",
-				zap.String("pluginPath", pluginPath),
-				zap.Error(err))
-			continue
-		}
+	// Assumes plugin's main package exports a 'New' function.
+	v, err := i.Eval("main.New")
+	if err != nil {
+		return nil, fmt.Errorf("error finding 'main.New' function in plugin: %w", err)
+	}
 
-		srcBytes, err := os.ReadFile(pluginPath)
-		if err != nil {
-			zap.L().Warn("Error reading plugin file.",
-				zap.String("pluginPath", pluginPath),
-				zap.Error(err))
-			continue
-		}
-		src := string(srcBytes)
+	newFunc, ok := v.Interface().(func() plugin.Plugin)
+	if !ok {
+		return nil, fmt.Errorf("exported 'New' in plugin is not of type func() plugin.Plugin, got %T", v.Interface())
+	}
 
-		_, err = i.Eval(src)
-		if err != nil {
-			zap.L().Warn("Error evaluating plugin source.",
-				zap.String("pluginPath", pluginPath),
-				zap.Error(err))
-			continue
-		}
+	pluginInstance := newFunc()
+	if pluginInstance == nil {
+		return nil, fmt.Errorf("'New' function in plugin returned nil")
+	}
 
-		// Assumes plugin's main package exports a 'New' function.
-		v, err := i.Eval("main.New")
-		if err != nil {
-			zap.L().Warn("Error finding 'main.New' function in plugin.",
-				zap.String("pluginPath", pluginPath),
-				zap.Error(err))
-			continue
+	for _, c := range pluginInstance.Metadata().Capabilities {
+		if !slices.Contains(capabilities, c) {
+			return nil, fmt.Errorf("plugin metadata claims capability %q, which its source does not list literally", c)
 		}
+	}
 
-		newFunc, ok := v.Interface().(func() plugin.Plugin)
-		if !ok {
-			zap.L().Warn("Exported 'New' in plugin is not of type func() plugin.Plugin.",
-				zap.String("pluginPath", pluginPath),
-				zap.Any("actualType", v.Interface()))
-			continue
-		}
+	if pluginInstance.Metadata().Name == "" {
+		zap.L().Warn("Loaded plugin has an empty name in its metadata.",
+			zap.String("pluginPath", pluginPath))
+	}
 
-		pluginInstance := newFunc()
-		if pluginInstance == nil {
-			zap.L().Warn("'New' function in plugin returned nil.",
-				zap.String("pluginPath", pluginPath))
-			continue
-		}
+	pluginInstance = wrapOptionalInterfaces(i, pluginInstance, pluginPath)
+	elapsed := time.Since(start)
+	recordLoadTime(pluginInstance.Keyword(), LoadTime{Duration: elapsed, Cached: cached})
+
+	zap.L().Info("Successfully loaded yaegi plugin.",
+		zap.String("name", pluginInstance.Name()),
+		zap.String("keyword", pluginInstance.Keyword()),
+		zap.String("path", pluginPath),
+		zap.Duration("elapsed", elapsed),
+		zap.Bool("validationCached", cached))
+	return pluginInstance, nil
+}
 
-		if pluginInstance.Metadata().Name == "" {
-			zap.L().Warn("Loaded plugin has an empty name in its metadata.",
-				zap.String("pluginPath", pluginPath))
-		}
+// interpretedPlugin exposes the optional interfaces a yaegi plugin implements.
+// Interpreted values crossing into compiled code are wrapped in a type that
+// only carries the plugin.Plugin methods, so the plugin hands out its optional
+// interfaces through exported functions; see wrapOptionalInterfaces.
+type interpretedPlugin struct {
+	plugin.Plugin
+	optional []any // Implementations of optional interfaces, found by lookupOptional.
+}
 
-		zap.L().Info("Successfully loaded yaegi plugin.",
-			zap.String("name", pluginInstance.Name()),
-			zap.String("keyword", pluginInstance.Keyword()),
-			zap.String("path", pluginPath))
-		loadedPlugins = append(loadedPlugins, pluginInstance)
+// Update keeps the wrapper in place when the interpreted plugin returns itself.
+func (p *interpretedPlugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	updated, cmd := p.Plugin.Update(msg)
+	if updated != nil {
+		p.Plugin = updated
 	}
+	return p, cmd
+}
 
-	return loadedPlugins, nil
+// Unwrap returns the wrapped plugin.
+func (p *interpretedPlugin) Unwrap() plugin.Plugin {
+	return p.Plugin
+}
+
+// OptionalInterfaces returns the optional interfaces the plugin implements,
+// which the application looks through for the one it needs.
+func (p *interpretedPlugin) OptionalInterfaces() []any {
+	return p.optional
+}
+
+// wrapOptionalInterfaces attaches optional interfaces a yaegi plugin opts into.
+// A plugin implementing plugin.Hydrator must export
+// 'func AsHydrator(p plugin.Plugin) plugin.Hydrator' returning its concrete
+// value, and likewise 'func AsActor(p plugin.Plugin) plugin.Actor' for
+// plugin.Actor, 'func AsPreviewer(p plugin.Plugin) plugin.Previewer' for
+// plugin.Previewer, 'func AsContextQuerier(p plugin.Plugin)
+// plugin.ContextQuerier' for plugin.ContextQuerier and 'func
+// AsResultStreamer(p plugin.Plugin) plugin.ResultStreamer' for
+// plugin.ResultStreamer, 'func AsHelper(p plugin.Plugin) plugin.Helper' for
+// plugin.Helper, 'func AsBatchExecutor(p plugin.Plugin)
+// plugin.BatchExecutor' for plugin.BatchExecutor, 'func AsMatcher(p
+// plugin.Plugin) plugin.Matcher' for plugin.Matcher, 'func AsFocuser(p
+// plugin.Plugin) plugin.Focuser' for plugin.Focuser, 'func AsShutdowner(p
+// plugin.Plugin) plugin.Shutdowner' for plugin.Shutdowner, 'func
+// AsPublisher(p plugin.Plugin) plugin.Publisher' for plugin.Publisher and
+// 'func AsSubscriber(p plugin.Plugin) plugin.Subscriber' for
+// plugin.Subscriber. Plugins exposing any are wrapped in an interpretedPlugin
+// holding them all.
+func wrapOptionalInterfaces(i *interp.Interpreter, p plugin.Plugin, pluginPath string) plugin.Plugin {
+	var optional []any
+	optional = appendOptional[plugin.Hydrator](optional, i, p, "AsHydrator", pluginPath)
+	optional = appendOptional[plugin.Actor](optional, i, p, "AsActor", pluginPath)
+	optional = appendOptional[plugin.Previewer](optional, i, p, "AsPreviewer", pluginPath)
+	optional = appendOptional[plugin.ContextQuerier](optional, i, p, "AsContextQuerier", pluginPath)
+	optional = appendOptional[plugin.ResultStreamer](optional, i, p, "AsResultStreamer", pluginPath)
+	optional = appendOptional[plugin.Helper](optional, i, p, "AsHelper", pluginPath)
+	optional = appendOptional[plugin.BatchExecutor](optional, i, p, "AsBatchExecutor", pluginPath)
+	optional = appendOptional[plugin.Matcher](optional, i, p, "AsMatcher", pluginPath)
+	optional = appendOptional[plugin.Focuser](optional, i, p, "AsFocuser", pluginPath)
+	optional = appendOptional[plugin.Shutdowner](optional, i, p, "AsShutdowner", pluginPath)
+	optional = appendOptional[plugin.Publisher](optional, i, p, "AsPublisher", pluginPath)
+	optional = appendOptional[plugin.Subscriber](optional, i, p, "AsSubscriber", pluginPath)
+	if len(optional) == 0 {
+		return p
+	}
+	return &interpretedPlugin{Plugin: p, optional: optional}
+}
+
+// appendOptional appends the implementation of T the plugin exposes through
+// the exported function name to optional, if any; see lookupOptional.
+func appendOptional[T any](optional []any, i *interp.Interpreter, p plugin.Plugin, name, pluginPath string) []any {
+	if value, ok := lookupOptional[T](i, p, name, pluginPath); ok {
+		return append(optional, value)
+	}
+	return optional
+}
+
+// lookupOptional calls the plugin's exported 'func <name>(p plugin.Plugin) T',
+// reporting false if the plugin does not export it or it returns nil.
+func lookupOptional[T any](i *interp.Interpreter, p plugin.Plugin, name, pluginPath string) (T, bool) {
+	var zero T
+	v, err := i.Eval("main." + name)
+	if err != nil {
+		return zero, false // Optional, most plugins don't export it.
+	}
+
+	as, ok := v.Interface().(func(plugin.Plugin) T)
+	if !ok {
+		zap.L().Warn("Exported optional interface function in plugin has the wrong type.",
+			zap.String("function", name),
+			zap.Stringer("expected", reflect.TypeFor[func(plugin.Plugin) T]()),
+			zap.String("pluginPath", pluginPath))
+		return zero, false
+	}
+
+	value := as(p)
+	if any(value) == nil {
+		return zero, false
+	}
+	return value, true
 }
@@ -0,0 +1,81 @@
+package yaegi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const previewingSource = `package main
+
+import (
+	"github.com/barab-i/incipio/pkgs/plugin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type Previewing struct{}
+
+func New() plugin.Plugin { return &Previewing{} }
+
+func AsPreviewer(p plugin.Plugin) plugin.Previewer { return p.(*Previewing) }
+func AsFocuser(p plugin.Plugin) plugin.Focuser     { return nil }
+
+func (p *Previewing) Name() string    { return "Previewing" }
+func (p *Previewing) Keyword() string { return "!pv" }
+func (p *Previewing) Metadata() plugin.Metadata {
+	return plugin.Metadata{Name: "Previewing", Keyword: "!pv"}
+}
+func (p *Previewing) Init() tea.Cmd                                      { return nil }
+func (p *Previewing) GetResults(query string) ([]plugin.Result, error)   { return nil, nil }
+func (p *Previewing) Execute(identifier string) tea.Cmd                  { return nil }
+func (p *Previewing) View() string                                       { return "" }
+func (p *Previewing) GetError() error                                    { return nil }
+func (p *Previewing) Preview(identifier string) string                   { return "preview of " + identifier }
+func (p *Previewing) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
+	return p, func() tea.Msg { return nil }
+}
+`
+
+func TestLoadPluginExposesOptionalInterfaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "previewing.go")
+	if err := os.WriteFile(path, []byte(previewingSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPlugin(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder, ok := p.(interface{ OptionalInterfaces() []any })
+	if !ok {
+		t.Fatalf("LoadPlugin() returned %T, which holds no optional interfaces", p)
+	}
+	optional := holder.OptionalInterfaces()
+	if len(optional) != 1 {
+		t.Fatalf("plugin exposes %d optional interfaces, want only plugin.Previewer", len(optional))
+	}
+	previewer, ok := optional[0].(plugin.Previewer)
+	if !ok {
+		t.Fatalf("optional interface is %T, want a plugin.Previewer", optional[0])
+	}
+	if got := previewer.Preview("x"); got != "preview of x" {
+		t.Errorf("Preview() = %q, want %q", got, "preview of x")
+	}
+	if updated, _ := p.Update(tea.WindowSizeMsg{}); updated != p {
+		t.Error("Update() replaced the wrapper")
+	}
+}
+
+func TestLoadPluginImportsThemeUnderItsOldPath(t *testing.T) {
+	for _, pkg := range []string{"github.com/barab-i/incipio/pkgs/theme", "github.com/barab-i/incipio/internal/theme"} {
+		path := filepath.Join(t.TempDir(), "themed.go")
+		if err := os.WriteFile(path, []byte(pluginSource(pkg, "theme.DefaultTheme.Accent")), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPlugin(path); err != nil {
+			t.Errorf("LoadPlugin() of a plugin importing %s: %v", pkg, err)
+		}
+	}
+}
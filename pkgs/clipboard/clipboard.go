@@ -0,0 +1,48 @@
+// Package clipboard reads and writes the system clipboard for plugins.
+//
+// Text is copied with wl-copy on Wayland, or xclip/xsel on X11. Without any of
+// these tools, WriteAll falls back to the OSC 52 escape sequence, which most
+// terminal emulators (and tmux) forward to the system clipboard.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/atotto/clipboard"
+)
+
+// ReadAll returns the text on the clipboard.
+func ReadAll() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// WriteAll copies text to the clipboard.
+func WriteAll(text string) error {
+	err := clipboard.WriteAll(text)
+	if err == nil {
+		return nil
+	}
+	if oscErr := writeOSC52(text); oscErr != nil {
+		return errors.Join(err, oscErr)
+	}
+	return nil
+}
+
+// writeOSC52 asks the terminal to set the clipboard. The sequence is wrapped
+// in a DCS passthrough when running inside tmux.
+func writeOSC52(text string) error {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return errors.New("OSC 52 fallback needs a terminal on stdout")
+	}
+
+	sequence := fmt.Sprintf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	if os.Getenv("TMUX") != "" {
+		sequence = "\x1bPtmux;\x1b" + sequence + "\x1b\\"
+	}
+	_, err = os.Stdout.WriteString(sequence)
+	return err
+}
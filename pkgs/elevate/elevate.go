@@ -0,0 +1,173 @@
+// Package elevate runs the privileged actions of plugins, like starting a
+// system unit, as root through pkexec or sudo.
+//
+// Only the wrapped command runs as root, never the launcher. Credentials are
+// remembered by polkit (auth_admin_keep) or sudo's timestamp for the rest of
+// the desktop session, so repeated actions ask for a password once. sudo runs
+// with -A and asks through $SUDO_ASKPASS, as the launcher's terminal is busy
+// drawing the UI.
+package elevate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Method is the tool commands are elevated with.
+type Method string
+
+const (
+	// Auto uses pkexec when installed and sudo otherwise.
+	Auto   Method = "auto"
+	Pkexec Method = "pkexec"
+	Sudo   Method = "sudo"
+)
+
+// Exit statuses of pkexec when no authorization was obtained.
+const (
+	pkexecDismissed    = 126
+	pkexecUnauthorized = 127
+)
+
+var (
+	// ErrCancelled is returned when the user dismissed the password prompt.
+	ErrCancelled = errors.New("authentication cancelled")
+	// ErrNotAuthorized is returned when authentication failed or the user may
+	// not run the command as root.
+	ErrNotAuthorized = errors.New("not authorized")
+	// ErrNoAskpass is returned when sudo has no way to ask for a password.
+	ErrNoAskpass = errors.New("sudo needs $SUDO_ASKPASS to ask for a password")
+)
+
+// Elevator runs commands as root with a method.
+type Elevator struct {
+	method Method
+}
+
+// New returns an Elevator using method. An empty method means Auto.
+func New(method Method) *Elevator {
+	return &Elevator{method: method}
+}
+
+var (
+	mu            sync.RWMutex
+	defaultMethod = Auto
+	pluginMethods map[string]Method
+)
+
+// Configure sets the methods used by For: the one of the plugin with the given
+// flag in methods, and fallback for the others.
+func Configure(fallback Method, methods map[string]Method) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultMethod = fallback
+	pluginMethods = methods
+}
+
+// For returns an Elevator using the method configured for the plugin with the
+// given flag, so plugins loaded with Yaegi honor the config too.
+func For(flag string) *Elevator {
+	mu.RLock()
+	defer mu.RUnlock()
+	if method, ok := pluginMethods[flag]; ok {
+		return New(method)
+	}
+	return New(defaultMethod)
+}
+
+// Method returns the tool used to elevate, resolving Auto.
+func (e *Elevator) Method() Method {
+	if e.method != Auto && e.method != "" {
+		return e.method
+	}
+	if _, err := exec.LookPath(string(Pkexec)); err == nil {
+		return Pkexec
+	}
+	return Sudo
+}
+
+// Command builds the process running argv as root, without starting it. It
+// runs argv directly when the launcher already runs as root.
+func (e *Elevator) Command(argv ...string) (*exec.Cmd, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("command has no program")
+	}
+	if os.Geteuid() == 0 {
+		return exec.Command(argv[0], argv[1:]...), nil
+	}
+
+	switch method := e.Method(); method {
+	case Pkexec:
+		return exec.Command(string(Pkexec), argv...), nil
+	case Sudo:
+		if os.Getenv("SUDO_ASKPASS") == "" {
+			return nil, ErrNoAskpass
+		}
+		return exec.Command(string(Sudo), append([]string{"-A", "--"}, argv...)...), nil
+	default:
+		return nil, fmt.Errorf("unknown elevation method %q", method)
+	}
+}
+
+// Run runs argv as root and waits for it. Failing to authenticate is reported
+// as ErrCancelled or ErrNotAuthorized, and other failures carry the command's
+// error output.
+func (e *Elevator) Run(argv ...string) error {
+	cmd, err := e.Command(argv...)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	message := strings.TrimSpace(stderr.String())
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		switch tool := Method(cmd.Args[0]); {
+		case tool == Pkexec && exitErr.ExitCode() == pkexecDismissed:
+			return ErrCancelled
+		case tool == Pkexec && exitErr.ExitCode() == pkexecUnauthorized &&
+			(message == "" || strings.Contains(message, "Not authorized")):
+			return ErrNotAuthorized
+		case tool == Sudo && isSudoAuthFailure(message):
+			return fmt.Errorf("%w: %s", ErrNotAuthorized, strings.TrimPrefix(message, "sudo: "))
+		}
+	}
+	if message != "" {
+		return fmt.Errorf("%s: %w: %s", strings.Join(argv, " "), err, message)
+	}
+	return fmt.Errorf("%s: %w", strings.Join(argv, " "), err)
+}
+
+// sudoAuthFailures are parts of the messages sudo prints when it does not run
+// the command for lack of authorization.
+var sudoAuthFailures = []string{
+	"password is required",
+	"incorrect password",
+	"no password was provided",
+	"not in the sudoers file",
+	"is not allowed to execute",
+	"no askpass program",
+}
+
+// isSudoAuthFailure reports whether sudo's error output tells authorization failed.
+func isSudoAuthFailure(message string) bool {
+	if !strings.HasPrefix(message, "sudo:") {
+		return false
+	}
+	for _, failure := range sudoAuthFailures {
+		if strings.Contains(message, failure) {
+			return true
+		}
+	}
+	return false
+}
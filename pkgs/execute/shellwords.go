@@ -0,0 +1,129 @@
+// Package execute parses and quotes command lines the way a POSIX shell splits
+// words, for plugins accepting commands typed by the user or configured as a
+// single string, such as $EDITOR.
+//
+// Only quoting is interpreted: variables, globs, command substitution and
+// operators like | or ; are not expanded and end up in the words unchanged.
+package execute
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrUnterminatedQuote is returned by Split when a quote is not closed.
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+	// ErrTrailingBackslash is returned by Split when the line ends with an escaping backslash.
+	ErrTrailingBackslash = errors.New("trailing backslash")
+)
+
+// escapedInDoubleQuotes is the quote state after a backslash in double quotes.
+const escapedInDoubleQuotes = '\\'
+
+// Split splits a command line into words. Words are separated by unquoted
+// blanks. Single quotes preserve everything up to the closing quote; double
+// quotes preserve everything but backslashes escaping $, `, ", \ or a newline;
+// outside quotes, a backslash escapes any character. An escaped newline joins
+// lines. Quoted empty strings are kept as empty words.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool // A word is started, possibly empty through quotes.
+		escaped bool // The previous character was an unquoted backslash.
+		quote   byte // The open quote, 0 outside quotes.
+	)
+	// Special characters are all ASCII, so bytes are copied as they are and
+	// invalid UTF-8 survives.
+	for i := 0; i < len(line); i++ {
+		r := line[i]
+		switch {
+		case escaped:
+			escaped = false
+			if r != '\n' {
+				word.WriteByte(r)
+				inWord = true
+			}
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteByte(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				quote = escapedInDoubleQuotes
+			default:
+				word.WriteByte(r)
+			}
+		case quote == escapedInDoubleQuotes:
+			quote = '"'
+			switch r {
+			case '$', '`', '"', '\\':
+				word.WriteByte(r)
+			case '\n':
+			default:
+				word.WriteByte('\\')
+				word.WriteByte(r)
+			}
+		case r == '\\':
+			escaped = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if escaped {
+		return nil, ErrTrailingBackslash
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Quote returns word quoted so that Split, or a POSIX shell, reads it back as
+// a single word. Words without special characters are returned unchanged.
+func Quote(word string) string {
+	if word == "" {
+		return "''"
+	}
+	if strings.IndexFunc(word, needsQuoting) < 0 {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// Join quotes each word and joins them with spaces, the inverse of Split.
+func Join(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = Quote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// needsQuoting reports whether r is special to a shell.
+func needsQuoting(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./,:=+@%^", r)
+}
@@ -0,0 +1,91 @@
+package execute
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line  string
+		words []string
+		err   error
+	}{
+		{``, nil, nil},
+		{"  \t\n ", nil, nil},
+		{`vim -p a b`, []string{"vim", "-p", "a", "b"}, nil},
+		{`'a b' c`, []string{"a b", "c"}, nil},
+		{`"a b" c`, []string{"a b", "c"}, nil},
+		{`'' ""`, []string{"", ""}, nil},
+		{`a''b`, []string{"ab"}, nil},
+		{`'a\b' "a\b"`, []string{`a\b`, `a\b`}, nil},
+		{`"\$ \` + "`" + ` \" \\"`, []string{"$ ` \" \\"}, nil},
+		{`'it'\''s'`, []string{"it's"}, nil},
+		{`a\ b`, []string{"a b"}, nil},
+		{`\'\"`, []string{`'"`}, nil},
+		{"a\\\nb", []string{"ab"}, nil},
+		{"\"a\\\nb\"", []string{"ab"}, nil},
+		{"'a\\\nb'", []string{"a\\\nb"}, nil},
+		{"a \\\n b", []string{"a", "b"}, nil},
+		{`$HOME *.go a|b`, []string{"$HOME", "*.go", "a|b"}, nil},
+		{"\xff 'caf\xe9'", []string{"\xff", "caf\xe9"}, nil},
+		{`'a`, nil, ErrUnterminatedQuote},
+		{`"a`, nil, ErrUnterminatedQuote},
+		{`"a\"`, nil, ErrUnterminatedQuote},
+		{`a'b"`, nil, ErrUnterminatedQuote},
+		{`a\`, nil, ErrTrailingBackslash},
+		{`'a'\`, nil, ErrTrailingBackslash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			words, err := Split(tt.line)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Split(%q) error = %v, want %v", tt.line, err, tt.err)
+			}
+			if !slices.Equal(words, tt.words) {
+				t.Errorf("Split(%q) = %q, want %q", tt.line, words, tt.words)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct{ word, quoted string }{
+		{``, `''`},
+		{`file.go`, `file.go`},
+		{`--flag=a,b`, `--flag=a,b`},
+		{`a b`, `'a b'`},
+		{`it's`, `'it'\''s'`},
+		{`$HOME`, `'$HOME'`},
+		{"\xff", "'\xff'"},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.word); got != tt.quoted {
+			t.Errorf("Quote(%q) = %q, want %q", tt.word, got, tt.quoted)
+		}
+	}
+}
+
+// FuzzSplit checks that Split reads back what Join writes. The fuzzed string
+// holds the words separated by NUL bytes.
+func FuzzSplit(f *testing.F) {
+	for _, seed := range []string{"", "a", "a\x00b", "\x00", "it's", "a b\x00\"c\"", "\\\n", "$x\x00`y`", "\xff\x00caf\xe9"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var words []string
+		if s != "" {
+			words = strings.Split(s, "\x00")
+		}
+		line := Join(words)
+		got, err := Split(line)
+		if err != nil {
+			t.Fatalf("Split(Join(%q)) error = %v", words, err)
+		}
+		if !slices.Equal(got, words) {
+			t.Fatalf("Split(%q) = %q, want %q", line, got, words)
+		}
+	})
+}
@@ -0,0 +1,385 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file speaks just enough of the D-Bus wire protocol to call
+// org.freedesktop.Notifications.Notify on the session bus: authentication,
+// method calls and their replies.
+
+const (
+	busName           = "org.freedesktop.DBus"
+	busPath           = "/org/freedesktop/DBus"
+	notificationsName = "org.freedesktop.Notifications"
+	notificationsPath = "/org/freedesktop/Notifications"
+
+	// dbusTimeout bounds the whole exchange with the bus.
+	dbusTimeout = 2 * time.Second
+	// maxMessageSize is the largest message the specification allows.
+	maxMessageSize = 128 << 20
+	// expireDefault lets the notification daemon decide when notifications expire.
+	expireDefault = -1
+)
+
+// Message types and header fields of the D-Bus specification.
+const (
+	typeMethodCall   = 1
+	typeMethodReturn = 2
+	typeError        = 3
+
+	fieldPath        = 1
+	fieldInterface   = 2
+	fieldMember      = 3
+	fieldErrorName   = 4
+	fieldReplySerial = 5
+	fieldDestination = 6
+	fieldSignature   = 8
+)
+
+// sendDBus calls Notify on the notification daemon over the session bus.
+func sendDBus(title, body string, urgency Urgency) error {
+	conn, err := dialSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(dbusTimeout)); err != nil {
+		return err
+	}
+
+	c := &busConn{conn: conn, r: bufio.NewReader(conn)}
+	if err := c.auth(); err != nil {
+		return fmt.Errorf("d-bus authentication: %w", err)
+	}
+	if err := c.call(busName, busPath, busName, "Hello", "", nil); err != nil {
+		return err
+	}
+
+	var e encoder
+	e.string(AppName)
+	e.uint32(0)  // replaces_id: a new notification.
+	e.string("") // app_icon
+	e.string(title)
+	e.string(body)
+	e.array(4, func() {}) // actions
+	e.array(8, func() {   // hints
+		e.align(8)
+		e.string("urgency")
+		e.signature("y")
+		e.byte(byte(urgency))
+	})
+	e.int32(expireDefault)
+	return c.call(notificationsName, notificationsPath, notificationsName, "Notify", "susssasa{sv}i", e.buf)
+}
+
+// dialSessionBus connects to the first reachable unix socket listed in
+// DBUS_SESSION_BUS_ADDRESS, or to $XDG_RUNTIME_DIR/bus without it.
+func dialSessionBus() (net.Conn, error) {
+	addresses := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if addresses == "" {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return nil, errors.New("no session bus: DBUS_SESSION_BUS_ADDRESS and XDG_RUNTIME_DIR are unset")
+		}
+		return net.Dial("unix", filepath.Join(runtimeDir, "bus"))
+	}
+
+	var errs []error
+	for _, address := range strings.Split(addresses, ";") {
+		transport, params, _ := strings.Cut(address, ":")
+		if transport != "unix" {
+			continue
+		}
+		for _, param := range strings.Split(params, ",") {
+			key, value, _ := strings.Cut(param, "=")
+			value, err := url.PathUnescape(value)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			var socket string
+			switch key {
+			case "path":
+				socket = value
+			case "abstract":
+				socket = "@" + value
+			default:
+				continue
+			}
+			conn, err := net.Dial("unix", socket)
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no unix socket in session bus address %q", addresses)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// busConn is an authenticated connection to a message bus.
+type busConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	serial uint32
+}
+
+// auth authenticates as the current user with the EXTERNAL mechanism.
+func (c *busConn) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(c.conn, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("rejected: %s", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(c.conn, "BEGIN\r\n")
+	return err
+}
+
+// call sends a method call with the body marshalled per signature and waits
+// for its reply, skipping signals and other messages meanwhile.
+func (c *busConn) call(destination, path, iface, member, signature string, body []byte) error {
+	c.serial++
+	serial := c.serial
+
+	var h encoder
+	h.byte('l')
+	h.byte(typeMethodCall)
+	h.byte(0) // flags
+	h.byte(1) // protocol version
+	h.uint32(uint32(len(body)))
+	h.uint32(serial)
+	h.array(8, func() {
+		field := func(code byte, sig string, value func()) {
+			h.align(8)
+			h.byte(code)
+			h.signature(sig)
+			value()
+		}
+		field(fieldPath, "o", func() { h.string(path) })
+		field(fieldInterface, "s", func() { h.string(iface) })
+		field(fieldMember, "s", func() { h.string(member) })
+		field(fieldDestination, "s", func() { h.string(destination) })
+		if signature != "" {
+			field(fieldSignature, "g", func() { h.signature(signature) })
+		}
+	})
+	h.align(8)
+	if _, err := c.conn.Write(append(h.buf, body...)); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := c.readMessage()
+		if err != nil {
+			return err
+		}
+		if msg.replySerial != serial {
+			continue
+		}
+		switch msg.typ {
+		case typeMethodReturn:
+			return nil
+		case typeError:
+			if text, err := msg.body.string(); err == nil && text != "" {
+				return fmt.Errorf("%s.%s: %s: %s", iface, member, msg.errorName, text)
+			}
+			return fmt.Errorf("%s.%s: %s", iface, member, msg.errorName)
+		}
+	}
+}
+
+// message is a received message, reduced to what replies need.
+type message struct {
+	typ         byte
+	replySerial uint32
+	errorName   string
+	body        *decoder
+}
+
+// readMessage reads the next message from the bus.
+func (c *busConn) readMessage() (message, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(c.r, fixed); err != nil {
+		return message{}, err
+	}
+	var order binary.ByteOrder
+	switch fixed[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return message{}, fmt.Errorf("invalid d-bus message endianness %q", fixed[0])
+	}
+	bodyLen := int(order.Uint32(fixed[4:]))
+	fieldsLen := int(order.Uint32(fixed[12:]))
+	if bodyLen+fieldsLen > maxMessageSize {
+		return message{}, fmt.Errorf("d-bus message of %d bytes is too large", bodyLen+fieldsLen)
+	}
+	headerLen := (16 + fieldsLen + 7) &^ 7
+	raw := make([]byte, headerLen+bodyLen)
+	copy(raw, fixed)
+	if _, err := io.ReadFull(c.r, raw[16:]); err != nil {
+		return message{}, err
+	}
+
+	msg := message{typ: fixed[1], body: &decoder{buf: raw[headerLen:], order: order}}
+	d := &decoder{buf: raw[:16+fieldsLen], pos: 16, order: order}
+	for d.pos < len(d.buf) {
+		d.align(8)
+		code, err := d.byte()
+		if err != nil {
+			return message{}, err
+		}
+		sig, err := d.signature()
+		if err != nil {
+			return message{}, err
+		}
+		switch sig {
+		case "u":
+			v, err := d.uint32()
+			if err != nil {
+				return message{}, err
+			}
+			if code == fieldReplySerial {
+				msg.replySerial = v
+			}
+		case "s", "o":
+			v, err := d.string()
+			if err != nil {
+				return message{}, err
+			}
+			if code == fieldErrorName {
+				msg.errorName = v
+			}
+		case "g":
+			if _, err := d.signature(); err != nil {
+				return message{}, err
+			}
+		default:
+			return message{}, fmt.Errorf("unexpected d-bus header field signature %q", sig)
+		}
+	}
+	return msg, nil
+}
+
+// encoder marshals little-endian D-Bus values, aligned relative to the start of buf.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) int32(v int32) {
+	e.uint32(uint32(v))
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(append(e.buf, s...), 0)
+}
+
+func (e *encoder) signature(s string) {
+	e.buf = append(append(append(e.buf, byte(len(s))), s...), 0)
+}
+
+// array writes the elements written by elements, which are aligned to
+// alignment, preceded by their length in bytes.
+func (e *encoder) array(alignment int, elements func()) {
+	e.uint32(0)
+	lengthAt := len(e.buf) - 4
+	e.align(alignment)
+	start := len(e.buf)
+	elements()
+	binary.LittleEndian.PutUint32(e.buf[lengthAt:], uint32(len(e.buf)-start))
+}
+
+// decoder unmarshals D-Bus values, aligned relative to the start of buf.
+type decoder struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+var errShortMessage = errors.New("d-bus message too short")
+
+func (d *decoder) align(n int) {
+	d.pos = (d.pos + n - 1) &^ (n - 1)
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, errShortMessage
+	}
+	d.pos++
+	return d.buf[d.pos-1], nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	d.align(4)
+	if d.pos+4 > len(d.buf) {
+		return 0, errShortMessage
+	}
+	d.pos += 4
+	return d.order.Uint32(d.buf[d.pos-4:]), nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	return d.text(int(n))
+}
+
+func (d *decoder) signature() (string, error) {
+	n, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	return d.text(int(n))
+}
+
+// text reads n bytes followed by a nul byte.
+func (d *decoder) text(n int) (string, error) {
+	if n < 0 || d.pos+n+1 > len(d.buf) {
+		return "", errShortMessage
+	}
+	s := string(d.buf[d.pos : d.pos+n])
+	d.pos += n + 1
+	return s, nil
+}
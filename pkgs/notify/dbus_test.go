@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var e encoder
+	e.byte(1)
+	e.string("ab")
+	e.signature("y")
+	e.array(8, func() {
+		e.align(8)
+		e.string("x")
+	})
+	e.int32(-1)
+
+	want := []byte{
+		0x01, 0x00, 0x00, 0x00, // byte, padded for the string length
+		0x02, 0x00, 0x00, 0x00, 'a', 'b', 0x00, // string
+		0x01, 'y', 0x00, // signature
+		0x00, 0x00, // padding
+		0x06, 0x00, 0x00, 0x00, // array length, excluding the padding after it
+		0x00, 0x00, 0x00, 0x00, // padding to the 8-byte aligned elements
+		0x01, 0x00, 0x00, 0x00, 'x', 0x00, // element
+		0x00, 0x00, // padding
+		0xff, 0xff, 0xff, 0xff, // int32
+	}
+	if !bytes.Equal(e.buf, want) {
+		t.Errorf("encoded\n% x\nwant\n% x", e.buf, want)
+	}
+}
+
+// helloCall is the method call of (*busConn).call for Hello with serial 1.
+const helloCall = "l\x01\x00\x01" + "\x00\x00\x00\x00" + "\x01\x00\x00\x00" + "\x6d\x00\x00\x00" +
+	"\x01\x01o\x00" + "\x15\x00\x00\x00/org/freedesktop/DBus\x00" + "\x00\x00" +
+	"\x02\x01s\x00" + "\x14\x00\x00\x00org.freedesktop.DBus\x00" + "\x00\x00\x00" +
+	"\x03\x01s\x00" + "\x05\x00\x00\x00Hello\x00" + "\x00\x00" +
+	"\x06\x01s\x00" + "\x14\x00\x00\x00org.freedesktop.DBus\x00" + "\x00\x00\x00"
+
+func TestCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies string
+		err     string
+	}{
+		{
+			name: "method return",
+			replies: "l\x02\x00\x01" + "\x00\x00\x00\x00" + "\x02\x00\x00\x00" + "\x08\x00\x00\x00" +
+				"\x05\x01u\x00" + "\x01\x00\x00\x00",
+		},
+		{
+			name: "reply to another call skipped",
+			replies: "l\x02\x00\x01" + "\x00\x00\x00\x00" + "\x02\x00\x00\x00" + "\x08\x00\x00\x00" +
+				"\x05\x01u\x00" + "\x09\x00\x00\x00" +
+				"l\x02\x00\x01" + "\x00\x00\x00\x00" + "\x03\x00\x00\x00" + "\x08\x00\x00\x00" +
+				"\x05\x01u\x00" + "\x01\x00\x00\x00",
+		},
+		{
+			name: "big-endian error",
+			replies: "B\x03\x00\x01" + "\x00\x00\x00\x07" + "\x00\x00\x00\x02" + "\x00\x00\x00\x18" +
+				"\x04\x01s\x00" + "\x00\x00\x00\x03e.X\x00" + "\x00\x00\x00\x00" +
+				"\x05\x01u\x00" + "\x00\x00\x00\x01" +
+				"\x00\x00\x00\x02no\x00",
+			err: "org.freedesktop.DBus.Hello: e.X: no",
+		},
+		{
+			name:    "invalid endianness",
+			replies: "x\x02\x00\x01" + "\x00\x00\x00\x00" + "\x02\x00\x00\x00" + "\x00\x00\x00\x00",
+			err:     `invalid d-bus message endianness 'x'`,
+		},
+		{
+			name:    "truncated",
+			replies: "l\x02\x00\x01" + "\x00\x00\x00\x00" + "\x02\x00\x00\x00" + "\x08\x00\x00\x00" + "\x05\x01",
+			err:     io.ErrUnexpectedEOF.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+
+			go func() {
+				defer server.Close()
+				got := make([]byte, len(helloCall))
+				if _, err := io.ReadFull(server, got); err != nil {
+					t.Errorf("reading call: %v", err)
+					return
+				}
+				if string(got) != helloCall {
+					t.Errorf("sent\n% x\nwant\n% x", got, helloCall)
+				}
+				io.WriteString(server, tt.replies)
+			}()
+
+			c := &busConn{conn: client, r: bufio.NewReader(client)}
+			err := c.call(busName, busPath, busName, "Hello", "", nil)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("call error = %v", err)
+			case tt.err != "" && (err == nil || err.Error() != tt.err):
+				t.Errorf("call error = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package notify shows desktop notifications for plugins, e.g. once a
+// detached command finished or a timer ran out.
+//
+// Notifications are sent to org.freedesktop.Notifications on the session
+// D-Bus. Without a session bus, Send falls back to the notify-send tool.
+package notify
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// AppName is the application notifications are sent as.
+const AppName = "incipio"
+
+// Urgency tells the notification daemon how to present a notification.
+type Urgency byte
+
+const (
+	// Low is for notifications that may go unnoticed, like background progress.
+	Low Urgency = iota
+	// Normal is for most notifications.
+	Normal
+	// Critical notifications stay until dismissed, like an alarm.
+	Critical
+)
+
+// String returns the urgency as notify-send spells it.
+func (u Urgency) String() string {
+	switch u {
+	case Low:
+		return "low"
+	case Critical:
+		return "critical"
+	default:
+		return "normal"
+	}
+}
+
+// Send shows a desktop notification with the given title and body.
+func Send(title, body string, urgency Urgency) error {
+	err := sendDBus(title, body, urgency)
+	if err == nil {
+		return nil
+	}
+	if fallbackErr := sendNotifySend(title, body, urgency); fallbackErr != nil {
+		return errors.Join(err, fallbackErr)
+	}
+	return nil
+}
+
+// sendNotifySend shows the notification with libnotify's notify-send.
+func sendNotifySend(title, body string, urgency Urgency) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return err
+	}
+	return exec.Command(path, "--app-name="+AppName, "--urgency="+urgency.String(), "--", title, body).Run()
+}
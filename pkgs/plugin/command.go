@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"encoding/json"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandIdentifierPrefix marks identifiers holding an encoded Command.
+const commandIdentifierPrefix = "command:"
+
+// Command is a program run when a result is executed. Plugins return it from
+// Execute through Run rather than packing a command line into the identifier
+// and splitting it again, so arguments containing spaces or quotes are passed
+// unchanged.
+type Command struct {
+	// Argv is the program, looked up in PATH, followed by its arguments.
+	Argv []string `json:"argv"`
+	// Env holds "KEY=value" pairs added to the launcher's environment.
+	Env []string `json:"env,omitempty"`
+	// Dir is the working directory. Empty means the launcher's.
+	Dir string `json:"dir,omitempty"`
+	// Detach starts the program in its own session so it outlives the
+	// launcher, which quits. Otherwise the program runs in the launcher's
+	// terminal, and the launcher quits once it exits successfully.
+	Detach bool `json:"detach,omitempty"`
+	// Terminal runs the program in a new terminal emulator window. It implies Detach.
+	Terminal bool `json:"terminal,omitempty"`
+}
+
+// RunCommandMsg asks the application to run a command. Plugins return it through Run.
+type RunCommandMsg struct {
+	Command Command
+}
+
+// CommandFinishedMsg is sent to the active plugin when a command run in the
+// launcher's terminal exits, or when a command could not be started. The
+// launcher stays open when Err is set.
+type CommandFinishedMsg struct {
+	Command Command
+	Err     error
+}
+
+// Run returns a command asking the application to run c.
+func Run(c Command) tea.Cmd {
+	return func() tea.Msg {
+		return RunCommandMsg{Command: c}
+	}
+}
+
+// Encode returns c as a result identifier, for plugins that keep no state
+// between GetResults and Execute. DecodeCommand restores it.
+func (c Command) Encode() string {
+	data, _ := json.Marshal(c) // Only strings and booleans, which always marshal.
+	return commandIdentifierPrefix + string(data)
+}
+
+// DecodeCommand restores a command encoded with Command.Encode. It reports
+// false for other identifiers, such as those of info items.
+func DecodeCommand(identifier string) (Command, bool) {
+	data, ok := strings.CutPrefix(identifier, commandIdentifierPrefix)
+	if !ok {
+		return Command{}, false
+	}
+	var c Command
+	if err := json.Unmarshal([]byte(data), &c); err != nil || len(c.Argv) == 0 {
+		return Command{}, false
+	}
+	return c, true
+}
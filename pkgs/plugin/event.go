@@ -0,0 +1,50 @@
+package plugin
+
+import "time"
+
+// Topics of the events built-in plugins publish, with the type of their Data.
+// Plugins may publish events of topics of their own, named like these.
+const (
+	// TopicTimerFinished is published by the timer plugin when a countdown
+	// ends. Data is a TimerFinished.
+	TopicTimerFinished = "timer.finished"
+)
+
+// TimerFinished is the Data of TopicTimerFinished events.
+type TimerFinished struct {
+	Label    string // Empty for timers started without one.
+	Duration time.Duration
+}
+
+// Event is a message a plugin publishes for other plugins, such as a timer
+// finishing, so plugins can work together without knowing each other.
+type Event struct {
+	// Topic names the kind of event, e.g. TopicTimerFinished.
+	Topic string
+	// Source is the keyword of the plugin that published the event.
+	Source string
+	// Data carries the event's details; its type depends on the topic.
+	Data any
+}
+
+// EventMsg delivers an Event to the Update of each plugin subscribed to its
+// topic, other than the one that published it, whether active or not.
+type EventMsg struct {
+	Event
+}
+
+// Publisher is an optional interface for plugins publishing events. The
+// application calls SetPublisher when the plugin is registered.
+type Publisher interface {
+	// SetPublisher passes the function publishing an event with the given
+	// topic and data. It may be called from any goroutine and does not block;
+	// events are dropped while too many are waiting for delivery.
+	SetPublisher(publish func(topic string, data any))
+}
+
+// Subscriber is an optional interface for plugins receiving the events of
+// other plugins, as EventMsg in Update.
+type Subscriber interface {
+	// Topics lists the topics of the events the plugin receives.
+	Topics() []string
+}
@@ -1,6 +1,13 @@
 package plugin
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"context"
+	"time"
+
+	"github.com/barab-i/incipio/pkgs/theme"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 // Metadata holds descriptive information about a plugin.
 type Metadata struct {
@@ -17,8 +24,26 @@ type Metadata struct {
 	IsMandatory bool
 	// IsDefault indicates if the plugin should be active by default when no keyword is entered.
 	IsDefault bool
+	// Capabilities lists what a yaegi plugin may do beyond computing results.
+	// The loader only exposes the packages and functions of the declared
+	// capabilities, so they must be listed literally in the plugin's source.
+	// Built-in plugins are not restricted and leave it empty.
+	Capabilities []Capability
 }
 
+// Capability is a permission a yaegi plugin declares in its metadata.
+type Capability string
+
+const (
+	// CapabilityNetwork allows net, net/http and other networking packages.
+	CapabilityNetwork Capability = "network"
+	// CapabilityExec allows running programs, with os/exec, syscall and plugin.Run.
+	CapabilityExec Capability = "exec"
+	// CapabilityFilesystem allows reading and writing files, with os, io/ioutil
+	// and path/filepath beyond path manipulation.
+	CapabilityFilesystem Capability = "filesystem"
+)
+
 // Plugin defines the interface that all plugins must implement.
 type Plugin interface {
 	// Name returns the display name of the plugin.
@@ -56,4 +81,151 @@ type Result struct {
 	// Identifier is a unique string that the plugin uses to identify this specific result,
 	// particularly when the Execute method is called.
 	Identifier string
+	// Icon is an optional glyph shown before the title, such as a Nerd Font
+	// symbol or an emoji. It should be a single character wide or two at most.
+	Icon string
+	// Lazy indicates that Description has not been loaded yet. The application calls
+	// Hydrate on plugins implementing Hydrator once the row becomes visible.
+	Lazy bool
+	// Section groups the result under a header, shown above the first result
+	// of each section. The list keeps the order of the results, so results of
+	// a section must be adjacent. Results without a section get no header.
+	Section string
+}
+
+// Hydrator is an optional interface for plugins producing very large result sets.
+// Such plugins can return Lazy results with an empty Description and load the
+// details on demand, keeping resident memory bounded.
+type Hydrator interface {
+	// Hydrate returns the fully populated result for the given identifier.
+	Hydrate(identifier string) (Result, error)
+}
+
+// Previewer is an optional interface for plugins that can describe a result in
+// more detail than its row, such as an article summary. With the preview pane
+// toggled on, the application shows the preview of the highlighted result next
+// to the list.
+type Previewer interface {
+	// Preview returns the text shown for the result with the given identifier.
+	// It is called outside the update loop, so it may block briefly, e.g. on a
+	// network request, but must be safe to call concurrently with other methods.
+	Preview(identifier string) string
+}
+
+// Action is an operation a plugin offers on the selected result besides
+// Execute, such as opening it in a browser. Users bind keys to actions by name
+// in the plugin_keybindings section of the config.
+type Action struct {
+	// Name identifies the action and describes it in the help overlay, e.g. "open in browser".
+	Name string
+	// Keys trigger the action unless the config binds others. It may be empty,
+	// leaving the action unbound until the user binds it.
+	Keys []string
+}
+
+// Actor is an optional interface for plugins offering actions on results.
+// While the plugin is active, keys bound to an action run it on the selected
+// result, taking precedence over the application's keybindings.
+type Actor interface {
+	// Actions lists the actions the plugin offers.
+	Actions() []Action
+	// RunAction performs the named action on the result with the given identifier.
+	RunAction(name, identifier string) tea.Cmd
+}
+
+// Helper is an optional interface for plugins handling keys of their own in
+// Update, such as scrolling a custom view. The help overlay lists the bindings
+// under the plugin's name, next to its actions.
+type Helper interface {
+	// Help returns the plugin's keybindings. It is called whenever the overlay
+	// is drawn, so it may list only the keys that work in the plugin's current
+	// state, e.g. scrolling keys while an article is shown.
+	Help() []key.Binding
+}
+
+// BatchExecutor is an optional interface for plugins whose results can be
+// executed several at once, such as launching apps or killing processes.
+// While such a plugin is active, users mark results with ctrl+space, and enter
+// executes the marked results together instead of the selected one.
+type BatchExecutor interface {
+	// ExecuteBatch executes the results with the given identifiers, in the
+	// order they were marked. Marks persist across queries, so identifiers
+	// may belong to earlier results.
+	ExecuteBatch(identifiers []string) tea.Cmd
+}
+
+// Refresher is an optional interface for plugins whose results change over time,
+// such as live system status. While such a plugin is active, the application
+// re-runs GetResults for the current query every RefreshInterval.
+type Refresher interface {
+	// RefreshInterval returns how often results are refreshed.
+	RefreshInterval() time.Duration
+}
+
+// ContextQuerier is an optional interface for plugins whose queries do slow
+// work, such as HTTP requests or running programs. The application calls
+// GetResultsContext instead of GetResults, and cancels ctx as soon as the user
+// types a new query, so the plugin can stop work whose results would be
+// discarded.
+type ContextQuerier interface {
+	// GetResultsContext returns the results for the query, like GetResults.
+	GetResultsContext(ctx context.Context, query string) ([]Result, error)
+}
+
+// ResultStreamer is an optional interface for plugins with slow sources, such
+// as network searches or nix-locate. While such a plugin is active, the
+// application calls StreamResults instead of GetResults and shows each batch
+// of results as it arrives, so the list fills in incrementally.
+type ResultStreamer interface {
+	// StreamResults produces the results for the query, passing each batch to
+	// send, which appends it to the results shown, and returns once all were
+	// sent or the source failed. It runs outside the update loop. ctx is
+	// cancelled when the query is superseded, after which batches are dropped.
+	StreamResults(ctx context.Context, query string, send func([]Result)) error
+}
+
+// Matcher is an optional interface for plugins recognizing queries typed
+// without their keyword, such as a calculator claiming "2+2". When no keyword
+// starts the query, the first enabled plugin claiming it becomes active
+// instead of the default plugin, in the order of the match_order config.
+type Matcher interface {
+	// Matches reports whether the plugin handles the query, which has no
+	// keyword. It is called for every query typed, so it must be cheap, such
+	// as a regular expression match.
+	Matches(query string) bool
+}
+
+// Focuser is an optional interface for plugins doing work only while shown,
+// such as polling a source. OnFocus is called when the plugin becomes the
+// active plugin while the launcher is shown, and OnBlur when another plugin
+// becomes active or the launcher is hidden or quits. Calls alternate, starting
+// with OnFocus.
+type Focuser interface {
+	// OnFocus resumes the plugin's work. It runs in the update loop, so it
+	// must return quickly.
+	OnFocus()
+	// OnBlur pauses the plugin's work.
+	OnBlur()
+}
+
+// Shutdowner is an optional interface for plugins holding resources or state
+// to save, such as caches to flush. Shutdown is called once on every enabled
+// plugin when the application quits, after the interface left the terminal.
+type Shutdowner interface {
+	// Shutdown frees the plugin's resources. The plugin is not used afterwards.
+	Shutdown()
+}
+
+// Themed is an optional interface for plugins that style their output. The
+// application calls SetTheme with the plugin's theme, including its overrides
+// from theme.yaml, when the plugin is registered and whenever the theme changes.
+type Themed interface {
+	// SetTheme replaces the colors the plugin renders with.
+	SetTheme(t theme.Theme)
+}
+
+// ThemeChangedMsg is sent to every plugin's Update when the theme changes at
+// runtime. Plugins resolve their colors with Theme.For(keyword).
+type ThemeChangedMsg struct {
+	Theme *theme.Handle
 }
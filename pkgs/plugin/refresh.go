@@ -0,0 +1,15 @@
+package plugin
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// RefreshMsg asks the application to run the current query again, for
+// plugins whose results changed outside GetResults, such as after an action
+// deleted one. Plugins return it through Refresh.
+type RefreshMsg struct{}
+
+// Refresh returns a command asking the application to run the current query again.
+func Refresh() tea.Cmd {
+	return func() tea.Msg {
+		return RefreshMsg{}
+	}
+}
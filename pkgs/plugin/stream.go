@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Stream is a handle to text a plugin produces over time, such as the answer
+// of a language model. The plugin writes chunks to the stream from any
+// goroutine and returns Start as a command; the application then shows the
+// stream in place of the plugin's view, appending chunks as they arrive and
+// following the end unless the user scrolls up. Escape cancels the stream.
+type Stream struct {
+	title  string
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	text    strings.Builder
+	pending strings.Builder // Written since the last StreamChunkMsg.
+	closed  bool
+	err     error
+	updated chan struct{} // Signalled, without blocking, on writes.
+	done    chan struct{} // Closed by Close.
+}
+
+// StreamStartMsg makes the application show a stream. Plugins return it
+// through Stream.Start.
+type StreamStartMsg struct {
+	Stream *Stream
+}
+
+// StreamChunkMsg is sent to the plugin that started a shown stream when chunks
+// were written to it. Chunk holds all text written since the previous message.
+type StreamChunkMsg struct {
+	Stream *Stream
+	Chunk  string
+}
+
+// StreamDoneMsg is sent to the plugin that started a shown stream once it is
+// closed. Err is context.Canceled when the user cancelled it.
+type StreamDoneMsg struct {
+	Stream *Stream
+	Err    error
+}
+
+// NewStream returns an open stream shown under the given title.
+func NewStream(title string) *Stream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Stream{
+		title:   title,
+		ctx:     ctx,
+		cancel:  cancel,
+		updated: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Title returns the title the stream is shown under.
+func (s *Stream) Title() string {
+	return s.title
+}
+
+// Context is cancelled when the stream is cancelled or closed. Producers pass
+// it to the requests they stream from.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// Write appends a chunk of text. Writes to a closed stream are dropped.
+func (s *Stream) Write(chunk string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || chunk == "" {
+		return
+	}
+	s.text.WriteString(chunk)
+	s.pending.WriteString(chunk)
+	select {
+	case s.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Close marks the stream as complete, with the error that ended it, if any.
+// Only the first call has an effect.
+func (s *Stream) Close(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	s.cancel()
+	close(s.done)
+}
+
+// Cancel stops the stream: its context is cancelled and it is closed with
+// context.Canceled.
+func (s *Stream) Cancel() {
+	s.Close(context.Canceled)
+}
+
+// Text returns all text written so far.
+func (s *Stream) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text.String()
+}
+
+// Done reports whether the stream is closed.
+func (s *Stream) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Err returns the error the stream was closed with.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Start returns a command asking the application to show the stream.
+func (s *Stream) Start() tea.Cmd {
+	return func() tea.Msg {
+		return StreamStartMsg{Stream: s}
+	}
+}
+
+// Next returns a command waiting for the next chunks, which yields a
+// StreamChunkMsg, or for the stream to close, which yields a StreamDoneMsg.
+// The application calls it for the stream it shows; only one command should
+// wait at a time.
+func (s *Stream) Next() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-s.updated:
+		case <-s.done:
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.pending.Len() > 0 {
+			chunk := s.pending.String()
+			s.pending.Reset()
+			return StreamChunkMsg{Stream: s, Chunk: chunk}
+		}
+		if s.closed {
+			return StreamDoneMsg{Stream: s, Err: s.err}
+		}
+		return StreamChunkMsg{Stream: s}
+	}
+}
@@ -0,0 +1,177 @@
+// Package store gives plugins a persistent key-value store of their own, to
+// keep data between runs such as the output of slow commands or API responses.
+//
+// Each namespace, usually the plugin's flag, is a JSON file under
+// $XDG_DATA_HOME/incipio/store. Values are stored as JSON, so any value
+// encoding/json handles can be kept, and may expire after a time to live.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/barab-i/incipio/internal/atomicfile"
+	"go.uber.org/zap"
+)
+
+const dataDir = "incipio/store"
+
+// ErrNotFound is returned by Get for keys that are not stored or expired.
+var ErrNotFound = errors.New("key not found")
+
+// namespacePattern keeps namespaces to a single file name.
+var namespacePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+
+// entry is a stored value.
+type entry struct {
+	Value   json.RawMessage `json:"value"`
+	Expires time.Time       `json:"expires,omitzero"` // Zero for values kept until deleted.
+}
+
+// Store is the key-value store of a namespace. Stores returned by Open for the
+// same namespace are the same, so they can be shared between plugin instances.
+// It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+var (
+	openMu sync.Mutex
+	opened = make(map[string]*Store)
+)
+
+// Open returns the store of the namespace, reading what it holds. Namespaces
+// are file names: letters, digits, '_', '-' and '.', not starting with a dot.
+func Open(namespace string) (*Store, error) {
+	if !namespacePattern.MatchString(namespace) {
+		return nil, fmt.Errorf("invalid store namespace %q", namespace)
+	}
+	openMu.Lock()
+	defer openMu.Unlock()
+	if s, ok := opened[namespace]; ok {
+		return s, nil
+	}
+
+	path, err := xdg.DataFile(filepath.Join(dataDir, namespace+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the path of store '%s': %w", namespace, err)
+	}
+	s := &Store{path: path, entries: make(map[string]entry)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read store '%s': %w", namespace, err)
+	default:
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			// A damaged store only loses cached data, so start afresh.
+			zap.L().Warn("Ignoring unreadable store.", zap.String("path", path), zap.Error(err))
+			s.entries = make(map[string]entry)
+		}
+	}
+	opened[namespace] = s
+	return s, nil
+}
+
+// Get decodes the value stored under key into v, a pointer, as
+// json.Unmarshal does. It returns ErrNotFound if there is none or it expired.
+func (s *Store) Get(key string, v any) error {
+	s.mu.Lock()
+	e, ok := s.entries[key]
+	s.mu.Unlock()
+	if !ok || e.expired(time.Now()) {
+		return ErrNotFound
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return fmt.Errorf("failed to decode the value of '%s': %w", key, err)
+	}
+	return nil
+}
+
+// Set stores v under key until deleted, replacing any value, and saves the store.
+func (s *Store) Set(key string, v any) error {
+	return s.SetTTL(key, v, 0)
+}
+
+// SetTTL stores v under key for ttl, replacing any value, and saves the store.
+// Zero keeps the value until deleted.
+func (s *Store) SetTTL(key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode the value of '%s': %w", key, err)
+	}
+	e := entry{Value: data}
+	if ttl > 0 {
+		e.Expires = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = e
+	return s.saveLocked()
+}
+
+// Delete removes the value stored under key, if any, and saves the store.
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	delete(s.entries, key)
+	return s.saveLocked()
+}
+
+// Keys returns the keys of the values stored and not expired, sorted.
+func (s *Store) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	keys := make([]string, 0, len(s.entries))
+	for key, e := range s.entries {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Clear removes all values and saves the store.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.entries)
+	return s.saveLocked()
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+// saveLocked writes the store, leaving out expired values.
+func (s *Store) saveLocked() error {
+	now := time.Now()
+	for key, e := range s.entries {
+		if e.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	// Write atomically so a crash never leaves a truncated store behind.
+	return atomicfile.WriteFile(s.path, data, 0o600)
+}
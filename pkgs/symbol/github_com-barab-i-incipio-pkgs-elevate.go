@@ -0,0 +1,27 @@
+// Code generated by 'yaegi extract github.com/barab-i/incipio/pkgs/elevate'. DO NOT EDIT.
+
+package symbol
+
+import (
+	"github.com/barab-i/incipio/pkgs/elevate"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/barab-i/incipio/pkgs/elevate/elevate"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"Auto":             reflect.ValueOf(elevate.Auto),
+		"Configure":        reflect.ValueOf(elevate.Configure),
+		"ErrCancelled":     reflect.ValueOf(&elevate.ErrCancelled).Elem(),
+		"ErrNoAskpass":     reflect.ValueOf(&elevate.ErrNoAskpass).Elem(),
+		"ErrNotAuthorized": reflect.ValueOf(&elevate.ErrNotAuthorized).Elem(),
+		"For":              reflect.ValueOf(elevate.For),
+		"New":              reflect.ValueOf(elevate.New),
+		"Pkexec":           reflect.ValueOf(elevate.Pkexec),
+		"Sudo":             reflect.ValueOf(elevate.Sudo),
+
+		// type definitions
+		"Elevator": reflect.ValueOf((*elevate.Elevator)(nil)),
+		"Method":   reflect.ValueOf((*elevate.Method)(nil)),
+	}
+}
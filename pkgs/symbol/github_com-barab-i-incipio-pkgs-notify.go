@@ -0,0 +1,24 @@
+// Code generated by 'yaegi extract github.com/barab-i/incipio/pkgs/notify'. DO NOT EDIT.
+
+package symbol
+
+import (
+	"github.com/barab-i/incipio/pkgs/notify"
+	"go/constant"
+	"go/token"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/barab-i/incipio/pkgs/notify/notify"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"AppName":  reflect.ValueOf(constant.MakeFromLiteral("\"incipio\"", token.STRING, 0)),
+		"Critical": reflect.ValueOf(notify.Critical),
+		"Low":      reflect.ValueOf(notify.Low),
+		"Normal":   reflect.ValueOf(notify.Normal),
+		"Send":     reflect.ValueOf(notify.Send),
+
+		// type definitions
+		"Urgency": reflect.ValueOf((*notify.Urgency)(nil)),
+	}
+}
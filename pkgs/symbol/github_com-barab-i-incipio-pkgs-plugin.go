@@ -3,23 +3,159 @@
 package symbol
 
 import (
+	"context"
 	"github.com/barab-i/incipio/pkgs/plugin"
+	"github.com/barab-i/incipio/pkgs/theme"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbletea"
+	"go/constant"
+	"go/token"
 	"reflect"
+	"time"
 )
 
 func init() {
 	Symbols["github.com/barab-i/incipio/pkgs/plugin/plugin"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"CapabilityExec":       reflect.ValueOf(plugin.CapabilityExec),
+		"CapabilityFilesystem": reflect.ValueOf(plugin.CapabilityFilesystem),
+		"CapabilityNetwork":    reflect.ValueOf(plugin.CapabilityNetwork),
+		"DecodeCommand":        reflect.ValueOf(plugin.DecodeCommand),
+		"NewStream":            reflect.ValueOf(plugin.NewStream),
+		"Refresh":              reflect.ValueOf(plugin.Refresh),
+		"Run":                  reflect.ValueOf(plugin.Run),
+		"TopicTimerFinished":   reflect.ValueOf(constant.MakeFromLiteral("\"timer.finished\"", token.STRING, 0)),
+
 		// type definitions
-		"Metadata": reflect.ValueOf((*plugin.Metadata)(nil)),
-		"Plugin":   reflect.ValueOf((*plugin.Plugin)(nil)),
-		"Result":   reflect.ValueOf((*plugin.Result)(nil)),
+		"Action":             reflect.ValueOf((*plugin.Action)(nil)),
+		"Actor":              reflect.ValueOf((*plugin.Actor)(nil)),
+		"BatchExecutor":      reflect.ValueOf((*plugin.BatchExecutor)(nil)),
+		"Capability":         reflect.ValueOf((*plugin.Capability)(nil)),
+		"Command":            reflect.ValueOf((*plugin.Command)(nil)),
+		"CommandFinishedMsg": reflect.ValueOf((*plugin.CommandFinishedMsg)(nil)),
+		"ContextQuerier":     reflect.ValueOf((*plugin.ContextQuerier)(nil)),
+		"Event":              reflect.ValueOf((*plugin.Event)(nil)),
+		"EventMsg":           reflect.ValueOf((*plugin.EventMsg)(nil)),
+		"Focuser":            reflect.ValueOf((*plugin.Focuser)(nil)),
+		"Helper":             reflect.ValueOf((*plugin.Helper)(nil)),
+		"Hydrator":           reflect.ValueOf((*plugin.Hydrator)(nil)),
+		"Matcher":            reflect.ValueOf((*plugin.Matcher)(nil)),
+		"Metadata":           reflect.ValueOf((*plugin.Metadata)(nil)),
+		"Plugin":             reflect.ValueOf((*plugin.Plugin)(nil)),
+		"Previewer":          reflect.ValueOf((*plugin.Previewer)(nil)),
+		"Publisher":          reflect.ValueOf((*plugin.Publisher)(nil)),
+		"RefreshMsg":         reflect.ValueOf((*plugin.RefreshMsg)(nil)),
+		"Refresher":          reflect.ValueOf((*plugin.Refresher)(nil)),
+		"Result":             reflect.ValueOf((*plugin.Result)(nil)),
+		"ResultStreamer":     reflect.ValueOf((*plugin.ResultStreamer)(nil)),
+		"RunCommandMsg":      reflect.ValueOf((*plugin.RunCommandMsg)(nil)),
+		"Shutdowner":         reflect.ValueOf((*plugin.Shutdowner)(nil)),
+		"Stream":             reflect.ValueOf((*plugin.Stream)(nil)),
+		"StreamChunkMsg":     reflect.ValueOf((*plugin.StreamChunkMsg)(nil)),
+		"StreamDoneMsg":      reflect.ValueOf((*plugin.StreamDoneMsg)(nil)),
+		"StreamStartMsg":     reflect.ValueOf((*plugin.StreamStartMsg)(nil)),
+		"Subscriber":         reflect.ValueOf((*plugin.Subscriber)(nil)),
+		"ThemeChangedMsg":    reflect.ValueOf((*plugin.ThemeChangedMsg)(nil)),
+		"Themed":             reflect.ValueOf((*plugin.Themed)(nil)),
+		"TimerFinished":      reflect.ValueOf((*plugin.TimerFinished)(nil)),
 
 		// interface wrapper definitions
-		"_Plugin": reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Plugin)(nil)),
+		"_Actor":          reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Actor)(nil)),
+		"_BatchExecutor":  reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_BatchExecutor)(nil)),
+		"_ContextQuerier": reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_ContextQuerier)(nil)),
+		"_Focuser":        reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Focuser)(nil)),
+		"_Helper":         reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Helper)(nil)),
+		"_Hydrator":       reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Hydrator)(nil)),
+		"_Matcher":        reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Matcher)(nil)),
+		"_Plugin":         reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Plugin)(nil)),
+		"_Previewer":      reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Previewer)(nil)),
+		"_Publisher":      reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Publisher)(nil)),
+		"_Refresher":      reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Refresher)(nil)),
+		"_ResultStreamer": reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_ResultStreamer)(nil)),
+		"_Shutdowner":     reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Shutdowner)(nil)),
+		"_Subscriber":     reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Subscriber)(nil)),
+		"_Themed":         reflect.ValueOf((*_github_com_barab_i_incipio_pkgs_plugin_Themed)(nil)),
 	}
 }
 
+// _github_com_barab_i_incipio_pkgs_plugin_Actor is an interface wrapper for Actor type
+type _github_com_barab_i_incipio_pkgs_plugin_Actor struct {
+	IValue     interface{}
+	WActions   func() []plugin.Action
+	WRunAction func(name string, identifier string) tea.Cmd
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Actor) Actions() []plugin.Action {
+	return W.WActions()
+}
+func (W _github_com_barab_i_incipio_pkgs_plugin_Actor) RunAction(name string, identifier string) tea.Cmd {
+	return W.WRunAction(name, identifier)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_BatchExecutor is an interface wrapper for BatchExecutor type
+type _github_com_barab_i_incipio_pkgs_plugin_BatchExecutor struct {
+	IValue        interface{}
+	WExecuteBatch func(identifiers []string) tea.Cmd
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_BatchExecutor) ExecuteBatch(identifiers []string) tea.Cmd {
+	return W.WExecuteBatch(identifiers)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_ContextQuerier is an interface wrapper for ContextQuerier type
+type _github_com_barab_i_incipio_pkgs_plugin_ContextQuerier struct {
+	IValue             interface{}
+	WGetResultsContext func(ctx context.Context, query string) ([]plugin.Result, error)
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_ContextQuerier) GetResultsContext(ctx context.Context, query string) ([]plugin.Result, error) {
+	return W.WGetResultsContext(ctx, query)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Focuser is an interface wrapper for Focuser type
+type _github_com_barab_i_incipio_pkgs_plugin_Focuser struct {
+	IValue   interface{}
+	WOnBlur  func()
+	WOnFocus func()
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Focuser) OnBlur() {
+	W.WOnBlur()
+}
+func (W _github_com_barab_i_incipio_pkgs_plugin_Focuser) OnFocus() {
+	W.WOnFocus()
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Helper is an interface wrapper for Helper type
+type _github_com_barab_i_incipio_pkgs_plugin_Helper struct {
+	IValue interface{}
+	WHelp  func() []key.Binding
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Helper) Help() []key.Binding {
+	return W.WHelp()
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Hydrator is an interface wrapper for Hydrator type
+type _github_com_barab_i_incipio_pkgs_plugin_Hydrator struct {
+	IValue   interface{}
+	WHydrate func(identifier string) (plugin.Result, error)
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Hydrator) Hydrate(identifier string) (plugin.Result, error) {
+	return W.WHydrate(identifier)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Matcher is an interface wrapper for Matcher type
+type _github_com_barab_i_incipio_pkgs_plugin_Matcher struct {
+	IValue   interface{}
+	WMatches func(query string) bool
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Matcher) Matches(query string) bool {
+	return W.WMatches(query)
+}
+
 // _github_com_barab_i_incipio_pkgs_plugin_Plugin is an interface wrapper for Plugin type
 type _github_com_barab_i_incipio_pkgs_plugin_Plugin struct {
 	IValue      interface{}
@@ -61,3 +197,73 @@ func (W _github_com_barab_i_incipio_pkgs_plugin_Plugin) Update(msg tea.Msg) (plu
 func (W _github_com_barab_i_incipio_pkgs_plugin_Plugin) View() string {
 	return W.WView()
 }
+
+// _github_com_barab_i_incipio_pkgs_plugin_Previewer is an interface wrapper for Previewer type
+type _github_com_barab_i_incipio_pkgs_plugin_Previewer struct {
+	IValue   interface{}
+	WPreview func(identifier string) string
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Previewer) Preview(identifier string) string {
+	return W.WPreview(identifier)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Publisher is an interface wrapper for Publisher type
+type _github_com_barab_i_incipio_pkgs_plugin_Publisher struct {
+	IValue        interface{}
+	WSetPublisher func(publish func(topic string, data any))
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Publisher) SetPublisher(publish func(topic string, data any)) {
+	W.WSetPublisher(publish)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Refresher is an interface wrapper for Refresher type
+type _github_com_barab_i_incipio_pkgs_plugin_Refresher struct {
+	IValue           interface{}
+	WRefreshInterval func() time.Duration
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Refresher) RefreshInterval() time.Duration {
+	return W.WRefreshInterval()
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_ResultStreamer is an interface wrapper for ResultStreamer type
+type _github_com_barab_i_incipio_pkgs_plugin_ResultStreamer struct {
+	IValue         interface{}
+	WStreamResults func(ctx context.Context, query string, send func([]plugin.Result)) error
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_ResultStreamer) StreamResults(ctx context.Context, query string, send func([]plugin.Result)) error {
+	return W.WStreamResults(ctx, query, send)
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Shutdowner is an interface wrapper for Shutdowner type
+type _github_com_barab_i_incipio_pkgs_plugin_Shutdowner struct {
+	IValue    interface{}
+	WShutdown func()
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Shutdowner) Shutdown() {
+	W.WShutdown()
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Subscriber is an interface wrapper for Subscriber type
+type _github_com_barab_i_incipio_pkgs_plugin_Subscriber struct {
+	IValue  interface{}
+	WTopics func() []string
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Subscriber) Topics() []string {
+	return W.WTopics()
+}
+
+// _github_com_barab_i_incipio_pkgs_plugin_Themed is an interface wrapper for Themed type
+type _github_com_barab_i_incipio_pkgs_plugin_Themed struct {
+	IValue    interface{}
+	WSetTheme func(t theme.Theme)
+}
+
+func (W _github_com_barab_i_incipio_pkgs_plugin_Themed) SetTheme(t theme.Theme) {
+	W.WSetTheme(t)
+}
@@ -0,0 +1,34 @@
+// Code generated by 'yaegi extract github.com/barab-i/incipio/pkgs/theme'. DO NOT EDIT.
+
+package symbol
+
+import (
+	"github.com/barab-i/incipio/pkgs/theme"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/barab-i/incipio/pkgs/theme/theme"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"Bundled":           reflect.ValueOf(theme.Bundled),
+		"CurrentTheme":      reflect.ValueOf(&theme.CurrentTheme).Elem(),
+		"Dark":              reflect.ValueOf(theme.Dark),
+		"Default":           reflect.ValueOf(theme.Default),
+		"DefaultTheme":      reflect.ValueOf(&theme.DefaultTheme).Elem(),
+		"DetectMode":        reflect.ValueOf(theme.DetectMode),
+		"For":               reflect.ValueOf(theme.For),
+		"Light":             reflect.ValueOf(theme.Light),
+		"Load":              reflect.ValueOf(theme.Load),
+		"LoadNamed":         reflect.ValueOf(theme.LoadNamed),
+		"LoadThemeFromFile": reflect.ValueOf(theme.LoadThemeFromFile),
+		"Names":             reflect.ValueOf(theme.Names),
+		"SetCurrent":        reflect.ValueOf(theme.SetCurrent),
+		"UserThemesDir":     reflect.ValueOf(theme.UserThemesDir),
+		"Watch":             reflect.ValueOf(theme.Watch),
+
+		// type definitions
+		"Handle": reflect.ValueOf((*theme.Handle)(nil)),
+		"Mode":   reflect.ValueOf((*theme.Mode)(nil)),
+		"Theme":  reflect.ValueOf((*theme.Theme)(nil)),
+	}
+}
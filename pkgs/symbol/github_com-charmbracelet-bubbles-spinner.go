@@ -0,0 +1,37 @@
+// Code generated by 'yaegi extract github.com/charmbracelet/bubbles/spinner'. DO NOT EDIT.
+
+package symbol
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/charmbracelet/bubbles/spinner/spinner"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"Dot":         reflect.ValueOf(&spinner.Dot).Elem(),
+		"Ellipsis":    reflect.ValueOf(&spinner.Ellipsis).Elem(),
+		"Globe":       reflect.ValueOf(&spinner.Globe).Elem(),
+		"Hamburger":   reflect.ValueOf(&spinner.Hamburger).Elem(),
+		"Jump":        reflect.ValueOf(&spinner.Jump).Elem(),
+		"Line":        reflect.ValueOf(&spinner.Line).Elem(),
+		"Meter":       reflect.ValueOf(&spinner.Meter).Elem(),
+		"MiniDot":     reflect.ValueOf(&spinner.MiniDot).Elem(),
+		"Monkey":      reflect.ValueOf(&spinner.Monkey).Elem(),
+		"Moon":        reflect.ValueOf(&spinner.Moon).Elem(),
+		"New":         reflect.ValueOf(spinner.New),
+		"NewModel":    reflect.ValueOf(&spinner.NewModel).Elem(),
+		"Points":      reflect.ValueOf(&spinner.Points).Elem(),
+		"Pulse":       reflect.ValueOf(&spinner.Pulse).Elem(),
+		"Tick":        reflect.ValueOf(spinner.Tick),
+		"WithSpinner": reflect.ValueOf(spinner.WithSpinner),
+		"WithStyle":   reflect.ValueOf(spinner.WithStyle),
+
+		// type definitions
+		"Model":   reflect.ValueOf((*spinner.Model)(nil)),
+		"Option":  reflect.ValueOf((*spinner.Option)(nil)),
+		"Spinner": reflect.ValueOf((*spinner.Spinner)(nil)),
+		"TickMsg": reflect.ValueOf((*spinner.TickMsg)(nil)),
+	}
+}
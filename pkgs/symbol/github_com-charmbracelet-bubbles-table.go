@@ -0,0 +1,32 @@
+// Code generated by 'yaegi extract github.com/charmbracelet/bubbles/table'. DO NOT EDIT.
+
+package symbol
+
+import (
+	"github.com/charmbracelet/bubbles/table"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/charmbracelet/bubbles/table/table"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"DefaultKeyMap": reflect.ValueOf(table.DefaultKeyMap),
+		"DefaultStyles": reflect.ValueOf(table.DefaultStyles),
+		"New":           reflect.ValueOf(table.New),
+		"WithColumns":   reflect.ValueOf(table.WithColumns),
+		"WithFocused":   reflect.ValueOf(table.WithFocused),
+		"WithHeight":    reflect.ValueOf(table.WithHeight),
+		"WithKeyMap":    reflect.ValueOf(table.WithKeyMap),
+		"WithRows":      reflect.ValueOf(table.WithRows),
+		"WithStyles":    reflect.ValueOf(table.WithStyles),
+		"WithWidth":     reflect.ValueOf(table.WithWidth),
+
+		// type definitions
+		"Column": reflect.ValueOf((*table.Column)(nil)),
+		"KeyMap": reflect.ValueOf((*table.KeyMap)(nil)),
+		"Model":  reflect.ValueOf((*table.Model)(nil)),
+		"Option": reflect.ValueOf((*table.Option)(nil)),
+		"Row":    reflect.ValueOf((*table.Row)(nil)),
+		"Styles": reflect.ValueOf((*table.Styles)(nil)),
+	}
+}
@@ -0,0 +1,32 @@
+// Code generated by 'yaegi extract github.com/charmbracelet/bubbles/textinput'. DO NOT EDIT.
+
+package symbol
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/charmbracelet/bubbles/textinput/textinput"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"Blink":         reflect.ValueOf(textinput.Blink),
+		"CursorBlink":   reflect.ValueOf(textinput.CursorBlink),
+		"CursorHide":    reflect.ValueOf(textinput.CursorHide),
+		"CursorStatic":  reflect.ValueOf(textinput.CursorStatic),
+		"DefaultKeyMap": reflect.ValueOf(&textinput.DefaultKeyMap).Elem(),
+		"EchoNone":      reflect.ValueOf(textinput.EchoNone),
+		"EchoNormal":    reflect.ValueOf(textinput.EchoNormal),
+		"EchoPassword":  reflect.ValueOf(textinput.EchoPassword),
+		"New":           reflect.ValueOf(textinput.New),
+		"NewModel":      reflect.ValueOf(&textinput.NewModel).Elem(),
+		"Paste":         reflect.ValueOf(textinput.Paste),
+
+		// type definitions
+		"CursorMode":   reflect.ValueOf((*textinput.CursorMode)(nil)),
+		"EchoMode":     reflect.ValueOf((*textinput.EchoMode)(nil)),
+		"KeyMap":       reflect.ValueOf((*textinput.KeyMap)(nil)),
+		"Model":        reflect.ValueOf((*textinput.Model)(nil)),
+		"ValidateFunc": reflect.ValueOf((*textinput.ValidateFunc)(nil)),
+	}
+}
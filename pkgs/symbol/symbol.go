@@ -2,15 +2,14 @@ package symbol
 
 import (
 	"reflect"
-
-	"github.com/barab-i/incipio/internal/theme"
 )
 
 // Symbols contains the map of symbols for packages used by plugins,
 // making them available to Yaegi interpreters.
-var Symbols = map[string]map[string]reflect.Value{
-	// Expose theme variable for Yaegi plugins
-	"github.com/barab-i/incipio/internal/theme": {
-		"CurrentTheme": reflect.ValueOf(&theme.CurrentTheme).Elem(),
-	},
+var Symbols = map[string]map[string]reflect.Value{}
+
+func init() {
+	// The theme package was internal/theme before plugins could import it from
+	// compiled code too; plugins written against that path keep loading.
+	Symbols["github.com/barab-i/incipio/internal/theme/theme"] = Symbols["github.com/barab-i/incipio/pkgs/theme/theme"]
 }
@@ -0,0 +1,41 @@
+package theme
+
+import "sync"
+
+// CurrentTheme holds the theme in use.
+//
+// Deprecated: Plugins receive their theme through plugin.Themed and
+// plugin.ThemeChangedMsg. CurrentTheme is kept in sync by SetCurrent for
+// Yaegi plugins written against the global.
+var CurrentTheme = DefaultTheme
+
+var (
+	currentMu sync.RWMutex
+	current   = Default()
+)
+
+// SetCurrent updates CurrentTheme and the package-level For to the given
+// handle. The application calls it whenever it loads or changes the theme.
+func SetCurrent(h *Handle) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = h
+	CurrentTheme = h.Base()
+}
+
+// For returns the current theme for the plugin with the given keyword.
+//
+// Deprecated: Use the theme passed to plugin.Themed.SetTheme or carried by
+// plugin.ThemeChangedMsg.
+func For(keyword string) Theme {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current.For(keyword)
+}
+
+// LoadThemeFromFile loads the theme config file and makes it current.
+//
+// Deprecated: Use Load.
+func LoadThemeFromFile() {
+	SetCurrent(Load())
+}
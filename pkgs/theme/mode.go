@@ -0,0 +1,46 @@
+package theme
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Mode is the brightness of the terminal background. Themes can come in a
+// variant for each: theme-light.yaml and theme-dark.yaml next to theme.yaml,
+// and named themes suffixed with "-light" or "-dark".
+type Mode string
+
+const (
+	Dark  Mode = "dark"
+	Light Mode = "light"
+)
+
+// DetectMode guesses the terminal background from $COLORFGBG, set by some
+// terminals like rxvt and Konsole, and otherwise with hasDarkBackground, which
+// typically asks the terminal with an OSC 11 query.
+func DetectMode(colorFGBG string, hasDarkBackground func() bool) Mode {
+	if mode, ok := modeFromColorFGBG(colorFGBG); ok {
+		return mode
+	}
+	if hasDarkBackground() {
+		return Dark
+	}
+	return Light
+}
+
+// modeFromColorFGBG parses $COLORFGBG, "fg;bg" or "fg;default;bg" with ANSI
+// color numbers. White (7) and the bright colors but gray (9-15) are light.
+func modeFromColorFGBG(value string) (Mode, bool) {
+	if value == "" {
+		return "", false
+	}
+	fields := strings.Split(value, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return "", false
+	}
+	if bg == 7 || bg >= 9 {
+		return Light, true
+	}
+	return Dark, true
+}
@@ -0,0 +1,93 @@
+package theme
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// themesDir holds the user's named themes inside the config directory, one
+// Base16 scheme per file, e.g. ~/.config/incipio/themes/gruvbox.yaml.
+const themesDir = "themes"
+
+// themeExt is the extension of theme files; the name of a theme is its file
+// name without it.
+const themeExt = ".yaml"
+
+// bundled holds popular Base16 schemes selectable by name without installing
+// them. Files in the user's themes directory take precedence.
+//
+//go:embed themes/*.yaml
+var bundled embed.FS
+
+// Bundled returns the files of the bundled themes, for "incipio assets export".
+func Bundled() fs.FS {
+	sub, err := fs.Sub(bundled, themesDir)
+	if err != nil {
+		panic(err) // The directory is embedded.
+	}
+	return sub
+}
+
+// UserThemesDir returns the directory named themes are loaded from.
+func UserThemesDir() string {
+	return filepath.Join(xdg.ConfigHome, configDir, themesDir)
+}
+
+// Names returns the names of the available themes, bundled and from the
+// user's themes directory, sorted.
+func Names() []string {
+	sources := []struct {
+		fsys fs.FS
+		dir  string
+	}{
+		{os.DirFS(UserThemesDir()), "."},
+		{bundled, themesDir},
+	}
+
+	var names []string
+	for _, source := range sources {
+		entries, _ := fs.ReadDir(source.fsys, source.dir) // A missing user directory has no themes.
+		for _, entry := range entries {
+			if name, ok := strings.CutSuffix(entry.Name(), themeExt); ok && !entry.IsDir() {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// readNamed reads the named theme from the user's themes directory or the
+// bundled themes, returning its raw keys and a description of its source.
+func readNamed(name string) (map[string]string, string, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, "", fmt.Errorf("invalid theme name %q", name)
+	}
+
+	path := filepath.Join(UserThemesDir(), name+themeExt)
+	raw, err := readRaw(path)
+	if err == nil {
+		return raw, path, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", err
+	}
+
+	data, err := bundled.ReadFile(themesDir + "/" + name + themeExt)
+	if err != nil {
+		return nil, "", fmt.Errorf("no theme named %q", name)
+	}
+	raw, err = unmarshalRaw(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, "bundled theme " + name, nil
+}
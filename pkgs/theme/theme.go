@@ -0,0 +1,280 @@
+package theme
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/charmbracelet/lipgloss"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Theme defines the color scheme using Base16 names.
+type Theme struct {
+	Base00 lipgloss.Color `yaml:"base00"`
+	Base01 lipgloss.Color `yaml:"base01"`
+	Base02 lipgloss.Color `yaml:"base02"`
+	Base03 lipgloss.Color `yaml:"base03"`
+	Base04 lipgloss.Color `yaml:"base04"`
+	Base05 lipgloss.Color `yaml:"base05"`
+	Base06 lipgloss.Color `yaml:"base06"`
+	Base07 lipgloss.Color `yaml:"base07"`
+	Base08 lipgloss.Color `yaml:"base08"`
+	Base09 lipgloss.Color `yaml:"base09"`
+	Base0A lipgloss.Color `yaml:"base0a"`
+	Base0B lipgloss.Color `yaml:"base0b"`
+	Base0C lipgloss.Color `yaml:"base0c"`
+	Base0D lipgloss.Color `yaml:"base0d"`
+	Base0E lipgloss.Color `yaml:"base0e"`
+	Base0F lipgloss.Color `yaml:"base0f"`
+
+	// Semantic roles. Unless set explicitly they follow the base colors.
+	Accent    lipgloss.Color `yaml:"accent"`    // Headers and highlights, Base0D.
+	Error     lipgloss.Color `yaml:"error"`     // Error messages, Base08.
+	Muted     lipgloss.Color `yaml:"muted"`     // Secondary text, Base03.
+	Selection lipgloss.Color `yaml:"selection"` // The selected item, Base0E.
+	Match     lipgloss.Color `yaml:"match"`     // Characters of results matching the query, Base0A.
+}
+
+// roles maps the theme.yaml names of the semantic roles to their fields.
+func (t *Theme) roles() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"accent":    &t.Accent,
+		"error":     &t.Error,
+		"muted":     &t.Muted,
+		"selection": &t.Selection,
+		"match":     &t.Match,
+	}
+}
+
+// DefaultTheme provides a default Base16-like theme.
+var DefaultTheme = Theme{ // Base16 Catppuccin Mocha
+	Base00: lipgloss.Color("#1e1e2e"),
+	Base01: lipgloss.Color("#181825"),
+	Base02: lipgloss.Color("#313244"),
+	Base03: lipgloss.Color("#45475a"),
+	Base04: lipgloss.Color("#585b70"),
+	Base05: lipgloss.Color("#cdd6f4"),
+	Base06: lipgloss.Color("#f5e0dc"),
+	Base07: lipgloss.Color("#b4befe"),
+	Base08: lipgloss.Color("#f38ba8"),
+	Base09: lipgloss.Color("#fab387"),
+	Base0A: lipgloss.Color("#f9e2af"),
+	Base0B: lipgloss.Color("#a6e3a1"),
+	Base0C: lipgloss.Color("#94e2d5"),
+	Base0D: lipgloss.Color("#89b4fa"),
+	Base0E: lipgloss.Color("#cba6f7"),
+	Base0F: lipgloss.Color("#f2cdcd"),
+
+	Accent:    lipgloss.Color("#89b4fa"),
+	Error:     lipgloss.Color("#f38ba8"),
+	Muted:     lipgloss.Color("#45475a"),
+	Selection: lipgloss.Color("#cba6f7"),
+	Match:     lipgloss.Color("#f9e2af"),
+}
+
+// Handle is a loaded theme: the colors and roles plus the per-plugin role
+// overrides. Handles are immutable and safe to share; plugins receive theirs
+// through plugin.Themed and plugin.ThemeChangedMsg.
+type Handle struct {
+	base Theme
+	// pluginRoles holds the per-plugin role overrides from theme.yaml, keyed by
+	// plugin keyword without its "!" prefix and then by role name.
+	pluginRoles map[string]map[string]lipgloss.Color
+}
+
+// Default returns a handle for DefaultTheme without overrides.
+func Default() *Handle {
+	return &Handle{base: DefaultTheme}
+}
+
+// Base returns the theme without any plugin overrides.
+func (h *Handle) Base() Theme {
+	return h.base
+}
+
+// For returns the theme for the plugin with the given keyword: the base theme
+// with the plugin's role overrides applied, e.g. "w.accent" for "!w".
+func (h *Handle) For(keyword string) Theme {
+	t := h.base
+	fields := t.roles()
+	for role, color := range h.pluginRoles[pluginKey(keyword)] {
+		*fields[role] = color
+	}
+	return t
+}
+
+// pluginKey normalizes a plugin keyword to its theme.yaml prefix.
+func pluginKey(keyword string) string {
+	return strings.TrimPrefix(strings.ToLower(keyword), "!")
+}
+
+const configFileName = "theme.yaml"
+const configDir = "incipio"
+
+// Load reads the theme colors from the YAML config file. If loading fails or
+// the file doesn't exist, it falls back to DefaultTheme.
+func Load() *Handle {
+	return LoadNamed("", "")
+}
+
+// LoadNamed loads the named theme (see Names) with the keys set in theme.yaml
+// applied on top of it. An empty name loads theme.yaml alone. With a mode, the
+// variants for it are preferred when they exist: "<name>-<mode>" over the named
+// theme and theme-<mode>.yaml over theme.yaml. An unknown name is logged and
+// ignored, and colors that are missing or invalid keep their DefaultTheme value.
+func LoadNamed(name string, mode Mode) *Handle {
+	raw := make(map[string]string)
+	var sources []string
+
+	if name != "" {
+		if mode != "" && slices.Contains(Names(), name+"-"+string(mode)) {
+			name += "-" + string(mode)
+		}
+		scheme, source, err := readNamed(name)
+		if err != nil {
+			zap.L().Warn("Could not load the configured theme, ignoring it.",
+				zap.String("theme", name),
+				zap.Strings("available", Names()),
+				zap.Error(err))
+		} else {
+			maps.Copy(raw, scheme)
+			sources = append(sources, source)
+		}
+	}
+
+	configPath, err := configFile(mode)
+	if err != nil {
+		zap.L().Warn("Could not determine theme config path, ignoring theme.yaml.", zap.Error(err))
+	} else if config, err := readRaw(configPath); errors.Is(err, fs.ErrNotExist) {
+		zap.L().Info("Theme config file not found.", zap.String("path", configPath))
+	} else if err != nil {
+		zap.L().Warn("Error reading theme config file, ignoring it.", zap.String("path", configPath), zap.Error(err))
+	} else {
+		maps.Copy(raw, config)
+		sources = append(sources, configPath)
+	}
+
+	if len(sources) == 0 {
+		zap.L().Info("Using default theme.")
+		return Default()
+	}
+	source := strings.Join(sources, ", ")
+	h := parse(raw, source)
+	zap.L().Info("Theme loaded.", zap.String("sources", source))
+	return h
+}
+
+// configFile returns the path of theme-<mode>.yaml if it exists, and of
+// theme.yaml otherwise.
+func configFile(mode Mode) (string, error) {
+	if mode != "" {
+		path, err := xdg.ConfigFile(filepath.Join(configDir, modeConfigFileName(mode)))
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return xdg.ConfigFile(filepath.Join(configDir, configFileName))
+}
+
+// modeConfigFileName returns the name of the theme config file for mode.
+func modeConfigFileName(mode Mode) string {
+	return strings.TrimSuffix(configFileName, themeExt) + "-" + string(mode) + themeExt
+}
+
+// readRaw reads a theme file into a map of lowercased keys to values.
+func readRaw(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalRaw(data)
+}
+
+// unmarshalRaw parses theme YAML into a map of lowercased keys to values.
+func unmarshalRaw(data []byte) (map[string]string, error) {
+	raw := make(map[string]string)
+	if err := yaml.Unmarshal([]byte(strings.ToLower(string(data))), &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// parse builds a theme from the raw keys of theme files. source names the
+// files in warnings.
+func parse(raw map[string]string, source string) *Handle {
+	getColor := func(lowerKey string, defaultValue lipgloss.Color) lipgloss.Color {
+		val, ok := raw[lowerKey]
+		if !ok || val == "" {
+			return defaultValue
+		}
+
+		if !strings.HasPrefix(val, "#") {
+			val = "#" + val
+		}
+		// Basic hex format validation
+		if len(val) != 7 {
+			zap.L().Warn("Invalid hex color format in theme config, using default for key.",
+				zap.String("key", lowerKey),
+				zap.String("value", val),
+				zap.String("path", source))
+			return defaultValue
+		}
+		// Further validation could be added here if lipgloss.Color doesn't handle invalid hex gracefully.
+		return lipgloss.Color(val)
+	}
+
+	t := Theme{
+		Base00: getColor("base00", DefaultTheme.Base00),
+		Base01: getColor("base01", DefaultTheme.Base01),
+		Base02: getColor("base02", DefaultTheme.Base02),
+		Base03: getColor("base03", DefaultTheme.Base03),
+		Base04: getColor("base04", DefaultTheme.Base04),
+		Base05: getColor("base05", DefaultTheme.Base05),
+		Base06: getColor("base06", DefaultTheme.Base06),
+		Base07: getColor("base07", DefaultTheme.Base07),
+		Base08: getColor("base08", DefaultTheme.Base08),
+		Base09: getColor("base09", DefaultTheme.Base09),
+		Base0A: getColor("base0a", DefaultTheme.Base0A),
+		Base0B: getColor("base0b", DefaultTheme.Base0B),
+		Base0C: getColor("base0c", DefaultTheme.Base0C),
+		Base0D: getColor("base0d", DefaultTheme.Base0D),
+		Base0E: getColor("base0e", DefaultTheme.Base0E),
+		Base0F: getColor("base0f", DefaultTheme.Base0F),
+	}
+	t.Accent = getColor("accent", t.Base0D)
+	t.Error = getColor("error", t.Base08)
+	t.Muted = getColor("muted", t.Base03)
+	t.Selection = getColor("selection", t.Base0E)
+	t.Match = getColor("match", t.Base0A)
+
+	// Keys like "w.accent" override a role for a single plugin.
+	overrides := map[string]map[string]lipgloss.Color{}
+	for lowerKey := range raw {
+		name, role, ok := strings.Cut(lowerKey, ".")
+		if !ok {
+			continue
+		}
+		if _, known := t.roles()[role]; !known || name == "" {
+			zap.L().Warn("Unknown plugin theme key in theme config, ignoring.",
+				zap.String("key", lowerKey),
+				zap.String("path", source))
+			continue
+		}
+		if overrides[name] == nil {
+			overrides[name] = map[string]lipgloss.Color{}
+		}
+		overrides[name][role] = getColor(lowerKey, *t.roles()[role])
+	}
+
+	return &Handle{base: t, pluginRoles: overrides}
+}
@@ -0,0 +1,84 @@
+package theme
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay coalesces the burst of events editors produce when saving a file.
+const reloadDelay = 250 * time.Millisecond
+
+// Watch reports changes to the theme files until done is closed: theme.yaml,
+// its light and dark variants, and the files of the user's themes directory.
+// onChange is called once the files have been quiet for reloadDelay, to load
+// the theme again with LoadNamed. It runs on its own goroutine and must not
+// block for long.
+//
+// The directories are watched rather than the files, so the files may be
+// created later and editors may save them by renaming a new file over them.
+func Watch(done <-chan struct{}, onChange func()) error {
+	path, err := xdg.ConfigFile(filepath.Join(configDir, configFileName))
+	if err != nil {
+		return fmt.Errorf("could not determine theme config path: %w", err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not create file watcher: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("could not watch theme config directory '%s': %w", dir, err)
+	}
+	zap.L().Debug("Watching theme config file for changes.", zap.String("path", path))
+
+	configFiles := []string{configFileName, modeConfigFileName(Light), modeConfigFileName(Dark)}
+	if err := watcher.Add(UserThemesDir()); err != nil {
+		// Bundled themes don't change; a themes directory created later is picked up on restart.
+		zap.L().Debug("Not watching the themes directory.", zap.Error(err))
+	}
+	isThemeFile := func(name string) bool {
+		if filepath.Dir(name) == UserThemesDir() {
+			return filepath.Ext(name) == themeExt
+		}
+		return filepath.Dir(name) == dir && slices.Contains(configFiles, filepath.Base(name))
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(reloadDelay)
+		timer.Stop()
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zap.L().Warn("Theme watcher error.", zap.Error(err))
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isThemeFile(event.Name) || event.Op == fsnotify.Chmod {
+					continue
+				}
+				timer.Reset(reloadDelay)
+			case <-timer.C:
+				zap.L().Info("Theme files changed, reloading theme.")
+				onChange()
+			}
+		}
+	}()
+	return nil
+}